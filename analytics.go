@@ -0,0 +1,123 @@
+package subscriptionstore
+
+import (
+	"errors"
+	"math"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// MRRMovement breaks down the change of the monthly recurring revenue in one
+// currency between two dates. EndMRR is StartMRR plus New and Expansion, less
+// Contraction and Churned.
+type MRRMovement struct {
+	Currency string
+	StartMRR string
+	// New is the revenue of the subscriptions which had none at the start,
+	// including reactivated ones
+	New string
+	// Expansion is the increase of the revenue of the subscriptions which
+	// had some at the start, from upgrades, seats and add-ons
+	Expansion string
+	// Contraction is the decrease of the revenue of the subscriptions which
+	// still have some at the end
+	Contraction string
+	// Churned is the revenue at the start of the subscriptions which have
+	// none at the end
+	Churned string
+	EndMRR  string
+}
+
+// ChurnReport counts the subscriptions lost over a date range
+type ChurnReport struct {
+	From string
+	To   string
+	// ActiveAtStart is the number of subscriptions live at the start
+	ActiveAtStart int64
+	// Churned is the number of the subscriptions live at the start which
+	// were cancelled by the end
+	Churned int64
+	// New is the number of subscriptions signed up during the range
+	New int64
+	// Rate is Churned divided by ActiveAtStart, zero if there were none
+	Rate float64
+}
+
+// SignupCohort follows the subscriptions signed up in one month
+type SignupCohort struct {
+	// Month is the month of the signups, as YYYY-MM
+	Month string
+	Size  int64
+	// Retained is the number of the subscriptions still live at the end of
+	// each month, starting with the month of the signups
+	Retained []int64
+}
+
+// SubscriptionCountReport counts the subscriptions by status and by plan
+type SubscriptionCountReport struct {
+	ByStatus map[string]int64
+	ByPlan   map[string]int64
+}
+
+// MonthlyAmount normalizes an amount billed every plan interval to a month.
+// A year has 12 months, 52 weeks and 365 days. Plans without an interval do
+// not recur, so their monthly amount is zero.
+func MonthlyAmount(amount string, interval string) (string, error) {
+	cents, err := amountCents(amount)
+	if err != nil {
+		return "", err
+	}
+
+	monthly, err := monthlyCents(cents, interval)
+	if err != nil {
+		return "", err
+	}
+
+	return centsAmount(monthly), nil
+}
+
+// monthlyCents normalizes cents billed every plan interval to a month
+func monthlyCents(cents int64, interval string) (int64, error) {
+	switch interval {
+	case PLAN_INTERVAL_DAILY:
+		return int64(math.Round(float64(cents) * 365 / 12)), nil
+	case PLAN_INTERVAL_WEEKLY:
+		return int64(math.Round(float64(cents) * 52 / 12)), nil
+	case PLAN_INTERVAL_MONTHLY:
+		return cents, nil
+	case PLAN_INTERVAL_QUARTERLY:
+		return int64(math.Round(float64(cents) / 3)), nil
+	case PLAN_INTERVAL_YEARLY:
+		return int64(math.Round(float64(cents) / 12)), nil
+	case PLAN_INTERVAL_NONE:
+		return 0, nil
+	}
+	return 0, errors.New("unsupported plan interval: " + interval)
+}
+
+// subscriptionCancelledAt returns when the cancellation of the subscription
+// took or takes effect, or nil if it is not cancelled. Subscriptions cancelled
+// before cancellations were recorded are taken as cancelled when they were
+// last updated.
+func subscriptionCancelledAt(subscription SubscriptionInterface) *carbon.Carbon {
+	if subscription.GetCancellationEffectiveAt() != MAX_DATETIME {
+		return subscription.GetCancellationEffectiveAtCarbon()
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return subscription.GetUpdatedAtCarbon()
+	}
+	return nil
+}
+
+// subscriptionLiveAt returns whether the subscription was signed up and not
+// yet cancelled at the given time. Inactive subscriptions are never live.
+func subscriptionLiveAt(subscription SubscriptionInterface, at *carbon.Carbon) bool {
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_INACTIVE {
+		return false
+	}
+	if subscription.GetCreatedAtCarbon().Gt(at) {
+		return false
+	}
+	cancelledAt := subscriptionCancelledAt(subscription)
+	return cancelledAt == nil || cancelledAt.Gt(at)
+}
@@ -0,0 +1,77 @@
+package subscriptionstore
+
+import (
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestMonthlyAmount(t *testing.T) {
+	testCases := []struct {
+		interval string
+		amount   string
+		expected string
+	}{
+		{PLAN_INTERVAL_DAILY, "1.00", "30.42"},
+		{PLAN_INTERVAL_WEEKLY, "12.00", "52.00"},
+		{PLAN_INTERVAL_MONTHLY, "9.99", "9.99"},
+		{PLAN_INTERVAL_QUARTERLY, "30.00", "10.00"},
+		{PLAN_INTERVAL_YEARLY, "100.00", "8.33"},
+		{PLAN_INTERVAL_NONE, "49.00", "0.00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.interval, func(t *testing.T) {
+			monthly, err := MonthlyAmount(tc.amount, tc.interval)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if monthly != tc.expected {
+				t.Fatalf("expected monthly amount %s, got %s", tc.expected, monthly)
+			}
+		})
+	}
+
+	if _, err := MonthlyAmount("10.00", "fortnightly"); err == nil {
+		t.Fatal("expected error for an unsupported interval")
+	}
+	if _, err := MonthlyAmount("ten", PLAN_INTERVAL_MONTHLY); err == nil {
+		t.Fatal("expected error for an invalid amount")
+	}
+}
+
+func TestSubscriptionLiveAt(t *testing.T) {
+	subscription := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetCreatedAt("2025-01-10 00:00:00")
+
+	if subscriptionLiveAt(subscription, carbon.Parse("2025-01-09 00:00:00", carbon.UTC)) {
+		t.Error("expected a subscription NOT live before it was created")
+	}
+	if !subscriptionLiveAt(subscription, carbon.Parse("2025-03-01 00:00:00", carbon.UTC)) {
+		t.Error("expected a subscription without cancellation to be live")
+	}
+
+	subscription.SetCancellationEffectiveAt("2025-02-10 00:00:00")
+	if !subscriptionLiveAt(subscription, carbon.Parse("2025-02-09 00:00:00", carbon.UTC)) {
+		t.Error("expected a subscription live before its cancellation takes effect")
+	}
+	if subscriptionLiveAt(subscription, carbon.Parse("2025-02-10 00:00:00", carbon.UTC)) {
+		t.Error("expected a subscription NOT live once its cancellation takes effect")
+	}
+
+	legacy := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_CANCELLED).
+		SetCreatedAt("2025-01-10 00:00:00").
+		SetUpdatedAt("2025-01-20 00:00:00")
+	if !subscriptionLiveAt(legacy, carbon.Parse("2025-01-15 00:00:00", carbon.UTC)) || subscriptionLiveAt(legacy, carbon.Parse("2025-01-25 00:00:00", carbon.UTC)) {
+		t.Error("expected a cancellation without date to take effect when last updated")
+	}
+
+	inactive := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_INACTIVE).
+		SetCreatedAt("2025-01-10 00:00:00")
+	if subscriptionLiveAt(inactive, carbon.Parse("2025-03-01 00:00:00", carbon.UTC)) {
+		t.Error("expected an inactive subscription NOT to be live")
+	}
+}
@@ -0,0 +1,98 @@
+package subscriptionstore
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is the backend of the cached store. Values are the column values of
+// the entities returned by the store, from which every read builds new
+// entities.
+//
+// The cache must be in-process, and not shared with other processes: writes
+// invalidate the values cached by the store they are made through only, so
+// another process would keep reading values its writes have changed.
+type Cache interface {
+	// Get returns the value of the key, and whether it was found and has not
+	// expired
+	Get(key string) (any, bool)
+	// Set stores the value of the key until the time to live has passed
+	Set(key string, value any, ttl time.Duration)
+}
+
+// NewLRUCache creates an in-process cache keeping at most capacity values,
+// evicting the least recently used value when full
+func NewLRUCache(capacity int) Cache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lruCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+var _ Cache = (*lruCache)(nil)
+
+// lruEntry is a value of the LRU cache
+type lruEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// lruCache is a least recently used cache whose values expire. The most
+// recently used values are at the front of the order.
+type lruCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+func (c *lruCache) Get(key string) (any, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *lruCache) Set(key string, value any, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
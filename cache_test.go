@@ -0,0 +1,60 @@
+package subscriptionstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2)
+
+	cache.Set("a", 1, time.Minute)
+	cache.Set("b", 2, time.Minute)
+
+	// Reading a makes b the least recently used
+	if value, found := cache.Get("a"); !found || value != 1 {
+		t.Fatalf("expected a to be 1, got %v / %v", value, found)
+	}
+
+	cache.Set("c", 3, time.Minute)
+
+	if _, found := cache.Get("b"); found {
+		t.Error("expected b to be evicted")
+	}
+	if value, found := cache.Get("a"); !found || value != 1 {
+		t.Errorf("expected a to be kept, got %v / %v", value, found)
+	}
+	if value, found := cache.Get("c"); !found || value != 3 {
+		t.Errorf("expected c to be 3, got %v / %v", value, found)
+	}
+
+	cache.Set("a", 10, time.Minute)
+	if value, _ := cache.Get("a"); value != 10 {
+		t.Errorf("expected a to be replaced by 10, got %v", value)
+	}
+}
+
+func TestLRUCacheExpiresValues(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewLRUCache(10).(*lruCache)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1, time.Minute)
+	cache.Set("b", nil, time.Hour)
+
+	now = now.Add(59 * time.Second)
+	if _, found := cache.Get("a"); !found {
+		t.Fatal("expected a to be found before its time to live has passed")
+	}
+
+	now = now.Add(time.Second)
+	if _, found := cache.Get("a"); found {
+		t.Error("expected a to be expired")
+	}
+	if value, found := cache.Get("b"); !found || value != nil {
+		t.Errorf("expected b to be cached as nil, got %v / %v", value, found)
+	}
+	if cache.order.Len() != 1 {
+		t.Errorf("expected the expired value to be removed, got %d values", cache.order.Len())
+	}
+}
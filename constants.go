@@ -2,27 +2,83 @@ package subscriptionstore
 
 const MAX_DATETIME = "9999-12-31 23:59:59"
 
+const COLUMN_AMOUNT = "amount"
+const COLUMN_AMOUNT_OFF = "amount_off"
+const COLUMN_APPLIED_AT = "applied_at"
+const COLUMN_ATTEMPT = "attempt"
+const COLUMN_ATTEMPTED_AT = "attempted_at"
+const COLUMN_BRAND = "brand"
+const COLUMN_CANCELLATION_EFFECTIVE_AT = "cancellation_effective_at"
+const COLUMN_CANCELLATION_FEEDBACK = "cancellation_feedback"
+const COLUMN_CANCELLATION_REASON = "cancellation_reason"
+const COLUMN_CANCELLATION_REQUESTED_AT = "cancellation_requested_at"
+const COLUMN_CANCELLED_BY = "cancelled_by"
 const COLUMN_CANCEL_AT_PERIOD_END = "cancel_at_period_end"
+const COLUMN_CODE = "code"
+const COLUMN_COMPLETED_AT = "completed_at"
+const COLUMN_COUNTRY = "country"
+const COLUMN_COUPON_ID = "coupon_id"
 const COLUMN_CREATED_AT = "created_at"
 const COLUMN_CURRENCY = "currency"
+const COLUMN_CURRENT_PHASE = "current_phase"
 const COLUMN_DESCRIPTION = "description"
+const COLUMN_DURATION = "duration"
+const COLUMN_DURATION_PERIODS = "duration_periods"
+const COLUMN_EMAIL = "email"
+const COLUMN_ENDS_AT = "ends_at"
+const COLUMN_EXPIRES_AT = "expires_at"
+const COLUMN_EXP_MONTH = "exp_month"
+const COLUMN_EXP_YEAR = "exp_year"
+const COLUMN_EXTERNAL_ID = "external_id"
+const COLUMN_FAILURE_REASON = "failure_reason"
 const COLUMN_FEATURES = "features"
+const COLUMN_FROM_VERSION_ID = "from_version_id"
 const COLUMN_ID = "id"
 const COLUMN_INTERVAL = "interval"
+const COLUMN_IS_DEFAULT = "is_default"
+const COLUMN_LAST4 = "last4"
+const COLUMN_LOCAL_ID = "local_id"
+const COLUMN_MAX_REDEMPTIONS = "max_redemptions"
 const COLUMN_MEMO = "memo"
 const COLUMN_METAS = "metas"
+const COLUMN_MIGRATED_COUNT = "migrated_count"
+const COLUMN_NAME = "name"
+const COLUMN_NEXT_PHASE_AT = "next_phase_at"
+const COLUMN_OBJECT_TYPE = "object_type"
+const COLUMN_PERCENT_OFF = "percent_off"
 const COLUMN_PERIOD_END = "period_end"
 const COLUMN_PERIOD_START = "period_start"
+const COLUMN_PAUSED_AT = "paused_at"
 const COLUMN_PAYMENT_METHOD_ID = "payment_method_id"
+const COLUMN_PHASES = "phases"
 const COLUMN_PLAN_ID = "plan_id"
+const COLUMN_PLAN_SNAPSHOT = "plan_snapshot"
+const COLUMN_PLAN_VERSION_ID = "plan_version_id"
 const COLUMN_PRICE = "price"
+const COLUMN_PRICE_TIERS = "price_tiers"
+const COLUMN_PRICING_MODEL = "pricing_model"
+const COLUMN_PROMOTION_CODE_ID = "promotion_code_id"
+const COLUMN_PROVIDER = "provider"
+const COLUMN_QUANTITY = "quantity"
+const COLUMN_REDEEM_BY = "redeem_by"
+const COLUMN_REGION = "region"
+const COLUMN_RESUME_AT = "resume_at"
+const COLUMN_SCHEDULED_AT = "scheduled_at"
 const COLUMN_SOFT_DELETED_AT = "soft_deleted_at"
+const COLUMN_STARTS_AT = "starts_at"
 const COLUMN_STATUS = "status"
 const COLUMN_STRIPE_PRICE_ID = "stripe_price_id"
+const COLUMN_SUBSCRIBED_BEFORE = "subscribed_before"
 const COLUMN_SUBSCRIBER_ID = "subscriber_id"
+const COLUMN_SUBSCRIPTION_ID = "subscription_id"
+const COLUMN_TAX_ID = "tax_id"
+const COLUMN_TIMES_REDEEMED = "times_redeemed"
 const COLUMN_TITLE = "title"
+const COLUMN_TO_VERSION_ID = "to_version_id"
 const COLUMN_TYPE = "type"
 const COLUMN_UPDATED_AT = "updated_at"
+const COLUMN_VERSION = "version"
+const COLUMN_VOID_ID = "void_id"
 
 const CURRENCY_USD = "USD"
 const CURRENCY_EUR = "EUR"
@@ -44,9 +100,70 @@ const PLAN_INTERVAL_QUARTERLY = "quarterly"
 const PLAN_INTERVAL_YEARLY = "yearly"
 const PLAN_INTERVAL_NONE = "none"
 
+const PLAN_PRICING_MODEL_FLAT = "flat"
+const PLAN_PRICING_MODEL_PER_UNIT = "per_unit"
+const PLAN_PRICING_MODEL_GRADUATED = "graduated"
+const PLAN_PRICING_MODEL_VOLUME = "volume"
+
 const SUBSCRIPTION_STATUS_ACTIVE = "active"
 const SUBSCRIPTION_STATUS_INACTIVE = "inactive"
 const SUBSCRIPTION_STATUS_CANCELLED = "cancelled"
+const SUBSCRIPTION_STATUS_PAUSED = "paused"
+const SUBSCRIPTION_STATUS_PAST_DUE = "past_due"
+
+const DUNNING_ATTEMPT_STATUS_SCHEDULED = "scheduled"
+const DUNNING_ATTEMPT_STATUS_FAILED = "failed"
+const DUNNING_ATTEMPT_STATUS_SUCCEEDED = "succeeded"
+
+const INVOICE_STATUS_DRAFT = "draft"
+const INVOICE_STATUS_OPEN = "open"
+const INVOICE_STATUS_PAID = "paid"
+const INVOICE_STATUS_VOID = "void"
+
+const PAYMENT_PROVIDER_PADDLE = "paddle"
+const PAYMENT_PROVIDER_PAYPAL = "paypal"
+const PAYMENT_PROVIDER_STRIPE = "stripe"
+
+const PAYMENT_METHOD_TYPE_BANK_ACCOUNT = "bank_account"
+const PAYMENT_METHOD_TYPE_CARD = "card"
+const PAYMENT_METHOD_TYPE_PAYPAL = "paypal"
+const PAYMENT_METHOD_TYPE_SEPA_DEBIT = "sepa_debit"
+
+const PROVIDER_OBJECT_TYPE_CUSTOMER = "customer"
+const PROVIDER_OBJECT_TYPE_PLAN = "plan"
+const PROVIDER_OBJECT_TYPE_PRICE = "price"
+const PROVIDER_OBJECT_TYPE_SUBSCRIPTION = "subscription"
+
+const COUPON_TYPE_AMOUNT = "amount"
+const COUPON_TYPE_PERCENT = "percent"
+
+const COUPON_DURATION_ONCE = "once"
+const COUPON_DURATION_REPEATING = "repeating"
+const COUPON_DURATION_FOREVER = "forever"
+
+const PROMOTION_CODE_STATUS_ACTIVE = "active"
+const PROMOTION_CODE_STATUS_INACTIVE = "inactive"
+
+const PLAN_VERSION_MIGRATION_STATUS_SCHEDULED = "scheduled"
+const PLAN_VERSION_MIGRATION_STATUS_COMPLETED = "completed"
+const PLAN_VERSION_MIGRATION_STATUS_CANCELLED = "cancelled"
+
+const CANCELLATION_REASON_CUSTOMER_SERVICE = "customer_service"
+const CANCELLATION_REASON_LOW_QUALITY = "low_quality"
+const CANCELLATION_REASON_MISSING_FEATURES = "missing_features"
+const CANCELLATION_REASON_OTHER = "other"
+const CANCELLATION_REASON_SWITCHED_SERVICE = "switched_service"
+const CANCELLATION_REASON_TOO_COMPLEX = "too_complex"
+const CANCELLATION_REASON_TOO_EXPENSIVE = "too_expensive"
+const CANCELLATION_REASON_UNUSED = "unused"
+
+const CANCELLED_BY_ADMIN = "admin"
+const CANCELLED_BY_SUBSCRIBER = "subscriber"
+const CANCELLED_BY_SYSTEM = "system"
+
+const SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE = "active"
+const SUBSCRIPTION_SCHEDULE_STATUS_COMPLETED = "completed"
+const SUBSCRIPTION_SCHEDULE_STATUS_CANCELLED = "cancelled"
 
 const YES = "yes"
 const NO = "no"
@@ -0,0 +1,264 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+// CouponInterface defines the methods for a Coupon entity.
+// A coupon is a discount, a percentage or a fixed amount off the price of
+// a plan, for one, several or all of the billing periods of a subscription.
+type CouponInterface interface {
+	GetAmountOff() string
+	GetAmountOffFloat() float64
+	SetAmountOff(amountOff string) CouponInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) CouponInterface
+
+	GetCurrency() string
+	SetCurrency(currency string) CouponInterface
+
+	GetDuration() string
+	SetDuration(duration string) CouponInterface
+
+	GetDurationPeriods() int
+	SetDurationPeriods(durationPeriods int) CouponInterface
+
+	GetID() string
+	SetID(id string) CouponInterface
+
+	GetMaxRedemptions() int
+	SetMaxRedemptions(maxRedemptions int) CouponInterface
+
+	GetMemo() string
+	SetMemo(memo string) CouponInterface
+
+	GetName() string
+	SetName(name string) CouponInterface
+
+	GetPercentOff() string
+	GetPercentOffFloat() float64
+	SetPercentOff(percentOff string) CouponInterface
+
+	GetRedeemBy() string
+	GetRedeemByCarbon() *carbon.Carbon
+	SetRedeemBy(redeemBy string) CouponInterface
+
+	GetTimesRedeemed() int
+	SetTimesRedeemed(timesRedeemed int) CouponInterface
+
+	GetType() string
+	SetType(type_ string) CouponInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) CouponInterface
+}
+
+var _ CouponInterface = (*couponImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type couponImplementation struct {
+	orm.ShortID
+
+	NameField            string `db:"name"`
+	TypeField            string `db:"type"`
+	PercentOffField      string `db:"percent_off"`
+	AmountOffField       string `db:"amount_off"`
+	CurrencyField        string `db:"currency"`
+	DurationField        string `db:"duration"`
+	DurationPeriodsField int    `db:"duration_periods"`
+	MaxRedemptionsField  int    `db:"max_redemptions"`
+	TimesRedeemedField   int    `db:"times_redeemed"`
+	RedeemByField        string `db:"redeem_by"`
+	MemoField            string `db:"memo"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewCoupon() CouponInterface {
+	o := &couponImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetName("")
+	o.SetType(COUPON_TYPE_PERCENT)
+	o.SetPercentOff("0")
+	o.SetAmountOff("0.00")
+	o.SetCurrency("")
+	o.SetDuration(COUPON_DURATION_ONCE)
+	o.SetDurationPeriods(0)
+	o.SetMaxRedemptions(0)
+	o.SetTimesRedeemed(0)
+	o.SetRedeemBy(MAX_DATETIME)
+	o.SetMemo("")
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *couponImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *couponImplementation) SetID(id string) CouponInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *couponImplementation) GetName() string {
+	return o.NameField
+}
+
+func (o *couponImplementation) SetName(name string) CouponInterface {
+	o.NameField = name
+	return o
+}
+
+func (o *couponImplementation) GetType() string {
+	return o.TypeField
+}
+
+func (o *couponImplementation) SetType(type_ string) CouponInterface {
+	o.TypeField = type_
+	return o
+}
+
+func (o *couponImplementation) GetPercentOff() string {
+	return o.PercentOffField
+}
+
+func (o *couponImplementation) GetPercentOffFloat() float64 {
+	return cast.ToFloat64(o.PercentOffField)
+}
+
+func (o *couponImplementation) SetPercentOff(percentOff string) CouponInterface {
+	o.PercentOffField = percentOff
+	return o
+}
+
+func (o *couponImplementation) GetAmountOff() string {
+	return o.AmountOffField
+}
+
+func (o *couponImplementation) GetAmountOffFloat() float64 {
+	return cast.ToFloat64(o.AmountOffField)
+}
+
+func (o *couponImplementation) SetAmountOff(amountOff string) CouponInterface {
+	o.AmountOffField = amountOff
+	return o
+}
+
+func (o *couponImplementation) GetCurrency() string {
+	return o.CurrencyField
+}
+
+func (o *couponImplementation) SetCurrency(currency string) CouponInterface {
+	o.CurrencyField = currency
+	return o
+}
+
+func (o *couponImplementation) GetDuration() string {
+	return o.DurationField
+}
+
+func (o *couponImplementation) SetDuration(duration string) CouponInterface {
+	o.DurationField = duration
+	return o
+}
+
+func (o *couponImplementation) GetDurationPeriods() int {
+	return o.DurationPeriodsField
+}
+
+func (o *couponImplementation) SetDurationPeriods(durationPeriods int) CouponInterface {
+	o.DurationPeriodsField = durationPeriods
+	return o
+}
+
+func (o *couponImplementation) GetMaxRedemptions() int {
+	return o.MaxRedemptionsField
+}
+
+func (o *couponImplementation) SetMaxRedemptions(maxRedemptions int) CouponInterface {
+	o.MaxRedemptionsField = maxRedemptions
+	return o
+}
+
+func (o *couponImplementation) GetTimesRedeemed() int {
+	return o.TimesRedeemedField
+}
+
+func (o *couponImplementation) SetTimesRedeemed(timesRedeemed int) CouponInterface {
+	o.TimesRedeemedField = timesRedeemed
+	return o
+}
+
+func (o *couponImplementation) GetRedeemBy() string {
+	return o.RedeemByField
+}
+
+func (o *couponImplementation) GetRedeemByCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetRedeemBy(), carbon.UTC)
+}
+
+func (o *couponImplementation) SetRedeemBy(redeemBy string) CouponInterface {
+	o.RedeemByField = redeemBy
+	return o
+}
+
+func (o *couponImplementation) GetMemo() string {
+	return o.MemoField
+}
+
+func (o *couponImplementation) SetMemo(memo string) CouponInterface {
+	o.MemoField = memo
+	return o
+}
+
+func (o *couponImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *couponImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *couponImplementation) SetCreatedAt(createdAt string) CouponInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *couponImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *couponImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *couponImplementation) SetUpdatedAt(updatedAt string) CouponInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
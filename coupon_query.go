@@ -0,0 +1,129 @@
+package subscriptionstore
+
+import "errors"
+
+// CouponQueryInterface defines the interface for querying coupons.
+type CouponQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) CouponQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) CouponQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) CouponQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) CouponQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) CouponQueryInterface
+}
+
+// CouponQuery is a shortcut alias for NewCouponQuery
+func CouponQuery() CouponQueryInterface {
+	return NewCouponQuery()
+}
+
+// NewCouponQuery creates a new coupon query
+func NewCouponQuery() CouponQueryInterface {
+	return &couponQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ CouponQueryInterface = (*couponQueryImplementation)(nil)
+
+type couponQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *couponQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("coupon query. id cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("coupon query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("coupon query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *couponQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *couponQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *couponQueryImplementation) SetID(id string) CouponQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *couponQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *couponQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *couponQueryImplementation) SetOffset(offset int) CouponQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *couponQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *couponQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *couponQueryImplementation) SetLimit(limit int) CouponQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *couponQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *couponQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *couponQueryImplementation) SetOrderBy(orderBy string) CouponQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *couponQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *couponQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *couponQueryImplementation) SetSortOrder(sortOrder string) CouponQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *couponQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
@@ -0,0 +1,44 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCouponQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(CouponQueryInterface)
+		contains string
+	}{
+		{
+			name:     "id empty",
+			setup:    func(q CouponQueryInterface) { q.SetID("") },
+			contains: "id cannot be empty",
+		},
+		{
+			name:     "limit negative",
+			setup:    func(q CouponQueryInterface) { q.SetLimit(-1) },
+			contains: "limit cannot be negative",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q CouponQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewCouponQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
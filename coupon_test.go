@@ -0,0 +1,63 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewCouponDefaults(t *testing.T) {
+	coupon := NewCoupon()
+
+	if coupon.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if coupon.GetType() != COUPON_TYPE_PERCENT {
+		t.Fatalf("expected type %s, got %s", COUPON_TYPE_PERCENT, coupon.GetType())
+	}
+	if coupon.GetDuration() != COUPON_DURATION_ONCE {
+		t.Fatalf("expected duration %s, got %s", COUPON_DURATION_ONCE, coupon.GetDuration())
+	}
+	if coupon.GetRedeemBy() != MAX_DATETIME {
+		t.Fatalf("expected redeem by %s, got %s", MAX_DATETIME, coupon.GetRedeemBy())
+	}
+	if coupon.GetMaxRedemptions() != 0 || coupon.GetTimesRedeemed() != 0 {
+		t.Fatalf("expected no redemptions, got %d of %d", coupon.GetTimesRedeemed(), coupon.GetMaxRedemptions())
+	}
+}
+
+func TestCouponSettersAndGetters(t *testing.T) {
+	coupon := NewCoupon().
+		SetName("Spring sale").
+		SetType(COUPON_TYPE_AMOUNT).
+		SetPercentOff("0").
+		SetAmountOff("5.50").
+		SetCurrency(CURRENCY_EUR).
+		SetDuration(COUPON_DURATION_REPEATING).
+		SetDurationPeriods(3).
+		SetMaxRedemptions(100).
+		SetTimesRedeemed(7).
+		SetRedeemBy("2025-06-01 00:00:00").
+		SetMemo("Newsletter")
+
+	if coupon.GetName() != "Spring sale" {
+		t.Fatalf("expected name Spring sale, got %s", coupon.GetName())
+	}
+	if coupon.GetType() != COUPON_TYPE_AMOUNT {
+		t.Fatalf("expected type %s, got %s", COUPON_TYPE_AMOUNT, coupon.GetType())
+	}
+	if coupon.GetAmountOff() != "5.50" || coupon.GetAmountOffFloat() != 5.5 {
+		t.Fatalf("expected amount off 5.50, got %s", coupon.GetAmountOff())
+	}
+	if coupon.GetCurrency() != CURRENCY_EUR {
+		t.Fatalf("expected currency %s, got %s", CURRENCY_EUR, coupon.GetCurrency())
+	}
+	if coupon.GetDuration() != COUPON_DURATION_REPEATING || coupon.GetDurationPeriods() != 3 {
+		t.Fatalf("expected repeating for 3 periods, got %s for %d", coupon.GetDuration(), coupon.GetDurationPeriods())
+	}
+	if coupon.GetMaxRedemptions() != 100 || coupon.GetTimesRedeemed() != 7 {
+		t.Fatalf("expected 7 of 100 redemptions, got %d of %d", coupon.GetTimesRedeemed(), coupon.GetMaxRedemptions())
+	}
+	if coupon.GetRedeemByCarbon().ToDateTimeString() != "2025-06-01 00:00:00" {
+		t.Fatalf("expected redeem by 2025-06-01 00:00:00, got %s", coupon.GetRedeemBy())
+	}
+	if coupon.GetMemo() != "Newsletter" {
+		t.Fatalf("expected memo Newsletter, got %s", coupon.GetMemo())
+	}
+}
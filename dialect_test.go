@@ -0,0 +1,238 @@
+package subscriptionstore
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dracory/neat"
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	contractsschema "github.com/dracory/neat/contracts/database/schema"
+	contractslog "github.com/dracory/neat/contracts/log"
+	"github.com/dracory/neat/database"
+	"github.com/dracory/neat/database/schema"
+	"github.com/dracory/neat/database/schema/grammars"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+type dialectDefinition struct {
+	driver  string
+	grammar contractsschema.Grammar
+}
+
+type tableDefinition struct {
+	name   string
+	table  string
+	create bool
+	define func(table contractsschema.Blueprint)
+	// unique lists the unique indexes added by the definition, which
+	// migrateUniqueIndexes creates without the grammar on SQLite
+	unique [][]string
+}
+
+func dialectDefinitions() []dialectDefinition {
+	return []dialectDefinition{
+		{driver: "sqlite", grammar: grammars.NewSqlite(contractslog.NewStdLogger(), "")},
+		{driver: "postgres", grammar: grammars.NewPostgres("")},
+		{driver: "mysql", grammar: grammars.NewMysql("")},
+	}
+}
+
+// tableDefinitions lists the DDL of every migration, in migration order,
+// as applied by migrationApply
+func tableDefinitions(st *storeImplementation) []tableDefinition {
+	definitions := []tableDefinition{
+		{name: "migration table", table: st.migrationTableName, create: true, define: migrationTableDefinition},
+	}
+
+	for _, m := range migrations() {
+		for _, change := range m.schema(st) {
+			if change.define != nil {
+				definitions = append(definitions, tableDefinition{name: m.id, table: change.table, create: change.create, define: change.define})
+			}
+
+			if len(change.uniqueIndexes) > 0 {
+				name := m.id
+				if change.define != nil {
+					name += " unique indexes"
+				}
+				tableName, list := change.table, change.uniqueIndexes
+				definitions = append(definitions, tableDefinition{name: name, table: tableName, unique: list, define: func(table contractsschema.Blueprint) {
+					for _, columns := range list {
+						table.Unique(columns...).Name(uniqueIndexName(tableName, columns))
+					}
+				}})
+			}
+
+			if len(change.indexes) > 0 {
+				name := m.id
+				if change.define != nil || len(change.uniqueIndexes) > 0 {
+					name += " indexes"
+				}
+				tableName, list := change.table, change.indexes
+				definitions = append(definitions, tableDefinition{name: name, table: tableName, define: func(table contractsschema.Blueprint) {
+					for _, columns := range list {
+						table.Index(columns...).Name(indexName(tableName, columns))
+					}
+				}})
+			}
+
+			if len(change.dropIndexes) > 0 {
+				name := m.id
+				if change.define != nil || len(change.uniqueIndexes) > 0 || len(change.indexes) > 0 {
+					name += " dropped indexes"
+				}
+				tableName, list := change.table, change.dropIndexes
+				definitions = append(definitions, tableDefinition{name: name, table: tableName, define: func(table contractsschema.Blueprint) {
+					for _, columns := range list {
+						table.DropIndexByName(indexName(tableName, columns))
+					}
+				}})
+			}
+		}
+	}
+
+	return definitions
+}
+
+// initDialectStore returns a store which generates SQL for the given driver,
+// with the default table names. The connection is never used to run the
+// generated SQL.
+func initDialectStore(t *testing.T, driver string) *storeImplementation {
+	store, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		PlanTableName:         "plans",
+		SubscriptionTableName: "subscriptions",
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	neatDB, err := neat.NewFromSQLDB(initDB(":memory:"), database.WithDriver(driver))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+	st.db = neatDB
+	return st
+}
+
+func assertGolden(t *testing.T, name string, actual string) {
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error (run the tests with -update to create the golden file):", err)
+	}
+
+	if string(expected) != actual {
+		t.Errorf("SQL does not match golden file %s (run the tests with -update after verifying the change)\nexpected:\n%s\nactual:\n%s", path, expected, actual)
+	}
+}
+
+func TestDialectSchemaGolden(t *testing.T) {
+	for _, dialect := range dialectDefinitions() {
+		t.Run(dialect.driver, func(t *testing.T) {
+			sql := strings.Builder{}
+
+			for _, definition := range tableDefinitions(initDialectStore(t, dialect.driver)) {
+				blueprint := schema.NewBlueprint(nil, "", definition.table)
+				if definition.create {
+					blueprint.Create()
+				}
+				definition.define(blueprint)
+
+				statements, err := blueprint.ToSql(dialect.grammar)
+				if err != nil {
+					t.Fatal("unexpected error:", err)
+				}
+				if dialect.driver == "sqlite" && len(definition.unique) > 0 {
+					statements = []string{}
+					for _, columns := range definition.unique {
+						statements = append(statements, sqliteUniqueIndexSQL(definition.table, columns))
+					}
+				}
+
+				sql.WriteString("-- " + definition.name + "\n")
+				for _, statement := range statements {
+					if strings.TrimSpace(statement) == "" {
+						continue
+					}
+					sql.WriteString(statement + ";\n")
+				}
+				sql.WriteString("\n")
+			}
+
+			assertGolden(t, dialect.driver+"_schema.sql", sql.String())
+		})
+	}
+}
+
+func TestDialectQueryGolden(t *testing.T) {
+	for _, dialect := range dialectDefinitions() {
+		t.Run(dialect.driver, func(t *testing.T) {
+			st := initDialectStore(t, dialect.driver)
+
+			planQuery := PlanQuery().
+				SetStatus(PLAN_STATUS_ACTIVE).
+				SetIntervalIn([]string{PLAN_INTERVAL_MONTHLY, PLAN_INTERVAL_YEARLY}).
+				SetOrderBy(COLUMN_CREATED_AT).
+				SetSortOrder("asc").
+				SetLimit(10).
+				SetOffset(20)
+
+			subscriptionQuery := SubscriptionQuery().
+				SetSubscriberID("user_1").
+				SetStatusIn([]string{SUBSCRIPTION_STATUS_ACTIVE, SUBSCRIPTION_STATUS_CANCELLED}).
+				SetPlanID("plan_1")
+
+			promoted := initDialectStore(t, dialect.driver)
+			promoted.planMetaColumns = []string{"tenant_id"}
+
+			countByQuery := func(q contractsorm.Query, err error) contractsorm.Query {
+				if err != nil {
+					t.Fatal("unexpected error:", err)
+				}
+				return q
+			}
+
+			var rows []map[string]any
+			queries := []struct {
+				name string
+				sql  string
+			}{
+				{"plan list", st.buildPlanQuery(planQuery).Table(st.planTableName).ToSql().Get(&rows)},
+				{"plan count", st.buildPlanQuery(PlanQuery().SetID("plan_1")).Table(st.planTableName).ToSql().Count()},
+				{"plan list with soft deleted", st.buildPlanQuery(PlanQuery().SetSoftDeletedIncluded(true)).Table(st.planTableName).ToSql().Get(&rows)},
+				{"plan count by type", countByQuery(st.buildPlanCountByQuery(PlanQuery().SetStatus(PLAN_STATUS_ACTIVE), COLUMN_TYPE)).ToSql().Get(&rows)},
+				{"plan count by interval", countByQuery(st.buildPlanCountByQuery(PlanQuery(), COLUMN_INTERVAL)).ToSql().Get(&rows)},
+				{"subscription list", st.buildSubscriptionQuery(subscriptionQuery).Table(st.subscriptionTableName).ToSql().Get(&rows)},
+				{"subscription count", st.buildSubscriptionQuery(SubscriptionQuery().SetStatus(SUBSCRIPTION_STATUS_ACTIVE)).Table(st.subscriptionTableName).ToSql().Count()},
+				{"subscription cancellations by reason", st.buildSubscriptionCancellationsByReasonQuery(SubscriptionQuery().SetCancellationRequestedAtGte("2025-01-01 00:00:00")).ToSql().Get(&rows)},
+				{"subscription list by meta", st.buildSubscriptionQuery(SubscriptionQuery().SetMetaEquals("tenant_id", "acme").SetMetaHasKey("crm_id")).Table(st.subscriptionTableName).ToSql().Get(&rows)},
+				{"plan list by promoted meta", promoted.buildPlanQuery(PlanQuery().SetMetaEquals("tenant_id", "acme").SetMetaHasKey("tenant_id")).Table(promoted.planTableName).ToSql().Get(&rows)},
+				{"subscription count by status", countByQuery(st.buildSubscriptionCountByQuery(SubscriptionQuery().SetPlanID("plan_1"), COLUMN_STATUS)).ToSql().Get(&rows)},
+			}
+
+			sql := strings.Builder{}
+			for _, query := range queries {
+				if dialect.driver == "postgres" && strings.Contains(query.sql, "?") {
+					t.Errorf("%s: placeholder not rebound for postgres: %s", query.name, query.sql)
+				}
+				sql.WriteString("-- " + query.name + "\n" + query.sql + ";\n\n")
+			}
+
+			assertGolden(t, dialect.driver+"_queries.sql", sql.String())
+		})
+	}
+}
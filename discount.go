@@ -0,0 +1,186 @@
+package subscriptionstore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PeriodPrice is what a subscription is charged for a billing period
+type PeriodPrice struct {
+	PeriodStart string
+	PeriodEnd   string
+	Currency    string
+	// Quantity is the number of seats charged for
+	Quantity int
+	// Subtotal is the amount of the quantity under the pricing model of the
+	// plan, before discounts
+	Subtotal string
+	Discount string
+	Total    string
+	// CouponID is the coupon of the discount, empty if there is none
+	CouponID string
+	// Lines are the amounts of the plan and of the items of the
+	// subscription, which add up to the subtotal
+	Lines []PriceLine
+}
+
+// PriceLine is the amount of one of the plans of a subscription
+type PriceLine struct {
+	PlanID   string
+	Quantity int
+	Amount   string
+}
+
+// CalculatePrice returns the price of the plan with the coupon applied.
+// The coupon may be nil. The discount never exceeds the price of the plan.
+// The returned price has no period.
+func CalculatePrice(plan PlanInterface, coupon CouponInterface) (PeriodPrice, error) {
+	return CalculatePriceForQuantity(plan, 1, coupon)
+}
+
+// CalculatePriceForQuantity returns the price of the given number of seats
+// of the plan, as CalculateAmount prices it, with the coupon applied.
+// A percent off applies to the whole subtotal, and an amount off is taken
+// once, not per seat.
+func CalculatePriceForQuantity(plan PlanInterface, quantity int, coupon CouponInterface) (PeriodPrice, error) {
+	return calculatePrice(plan, quantity, nil, coupon)
+}
+
+// calculatePrice returns the price of the quantity of the plan plus the
+// lines of the subscription items, with the coupon applied to the total
+func calculatePrice(plan PlanInterface, quantity int, itemLines []PriceLine, coupon CouponInterface) (PeriodPrice, error) {
+	if plan == nil {
+		return PeriodPrice{}, errors.New("calculate price. plan cannot be nil")
+	}
+	if quantity < 1 {
+		return PeriodPrice{}, errors.New("calculate price. quantity must be at least 1")
+	}
+
+	subtotal, err := planAmountCents(plan, quantity)
+	if err != nil {
+		return PeriodPrice{}, errors.New("calculate price. " + err.Error())
+	}
+
+	lines := []PriceLine{{PlanID: plan.GetID(), Quantity: quantity, Amount: centsAmount(subtotal)}}
+	for _, line := range itemLines {
+		cents, err := amountCents(line.Amount)
+		if err != nil {
+			return PeriodPrice{}, errors.New("calculate price. item " + err.Error())
+		}
+		subtotal += cents
+		lines = append(lines, line)
+	}
+
+	discount := int64(0)
+	couponID := ""
+	if coupon != nil {
+		discount, err = couponDiscountCents(coupon, subtotal, plan.GetCurrency())
+		if err != nil {
+			return PeriodPrice{}, errors.New("calculate price. " + err.Error())
+		}
+		couponID = coupon.GetID()
+	}
+
+	return PeriodPrice{
+		Currency: plan.GetCurrency(),
+		Quantity: quantity,
+		Subtotal: centsAmount(subtotal),
+		Discount: centsAmount(discount),
+		Total:    centsAmount(subtotal - discount),
+		CouponID: couponID,
+		Lines:    lines,
+	}, nil
+}
+
+// couponValidate checks that the coupon describes a valid discount
+func couponValidate(coupon CouponInterface) error {
+	switch coupon.GetType() {
+	case COUPON_TYPE_PERCENT:
+		percent := coupon.GetPercentOffFloat()
+		if percent <= 0 || percent > 100 {
+			return errors.New("percent off must be between 0 and 100")
+		}
+	case COUPON_TYPE_AMOUNT:
+		amountOff, err := amountCents(coupon.GetAmountOff())
+		if err != nil {
+			return errors.New("amount off " + err.Error())
+		}
+		if amountOff <= 0 {
+			return errors.New("amount off must be positive")
+		}
+		if coupon.GetCurrency() == "" {
+			return errors.New("currency cannot be empty for an amount off")
+		}
+	default:
+		return errors.New("unknown type " + coupon.GetType())
+	}
+
+	switch coupon.GetDuration() {
+	case COUPON_DURATION_ONCE, COUPON_DURATION_FOREVER:
+	case COUPON_DURATION_REPEATING:
+		if coupon.GetDurationPeriods() <= 0 {
+			return errors.New("duration periods must be positive for a repeating coupon")
+		}
+	default:
+		return errors.New("unknown duration " + coupon.GetDuration())
+	}
+
+	if coupon.GetMaxRedemptions() < 0 {
+		return errors.New("max redemptions cannot be negative")
+	}
+
+	return nil
+}
+
+// couponDiscountCents returns the discount of the coupon on the amount,
+// in cents, capped at the amount
+func couponDiscountCents(coupon CouponInterface, amount int64, currency string) (int64, error) {
+	discount := int64(0)
+
+	switch coupon.GetType() {
+	case COUPON_TYPE_PERCENT:
+		discount = int64(math.Round(float64(amount) * coupon.GetPercentOffFloat() / 100))
+	case COUPON_TYPE_AMOUNT:
+		if !strings.EqualFold(coupon.GetCurrency(), currency) {
+			return 0, errors.New("coupon currency " + coupon.GetCurrency() + " does not match " + currency)
+		}
+		amountOff, err := amountCents(coupon.GetAmountOff())
+		if err != nil {
+			return 0, errors.New("coupon amount off " + err.Error())
+		}
+		discount = amountOff
+	default:
+		return 0, errors.New("unknown coupon type " + coupon.GetType())
+	}
+
+	return min(discount, amount), nil
+}
+
+// amountCents parses a decimal amount, i.e. "19.99", into cents.
+// An empty amount is zero.
+func amountCents(amount string) (int64, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, errors.New("amount is not a number: " + amount)
+	}
+
+	return int64(math.Round(value * 100)), nil
+}
+
+// centsAmount formats cents as a decimal amount with two decimals
+func centsAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,223 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCalculatePrice(t *testing.T) {
+	plan := NewPlan().SetPrice("19.99").SetCurrency(CURRENCY_USD)
+
+	testCases := []struct {
+		name     string
+		coupon   CouponInterface
+		discount string
+		total    string
+	}{
+		{
+			name:     "no coupon",
+			coupon:   nil,
+			discount: "0.00",
+			total:    "19.99",
+		},
+		{
+			name:     "percent off is rounded to the cent",
+			coupon:   NewCoupon().SetType(COUPON_TYPE_PERCENT).SetPercentOff("15"),
+			discount: "3.00",
+			total:    "16.99",
+		},
+		{
+			name:     "amount off",
+			coupon:   NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("5").SetCurrency("usd"),
+			discount: "5.00",
+			total:    "14.99",
+		},
+		{
+			name:     "amount off is capped at the price",
+			coupon:   NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("50.00").SetCurrency(CURRENCY_USD),
+			discount: "19.99",
+			total:    "0.00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			price, err := CalculatePrice(plan, tc.coupon)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if price.Subtotal != "19.99" || price.Currency != CURRENCY_USD {
+				t.Errorf("expected subtotal 19.99 USD, got %s %s", price.Subtotal, price.Currency)
+			}
+			if price.Discount != tc.discount || price.Total != tc.total {
+				t.Errorf("expected discount %s and total %s, got %s and %s", tc.discount, tc.total, price.Discount, price.Total)
+			}
+			if tc.coupon != nil && price.CouponID != tc.coupon.GetID() {
+				t.Errorf("expected coupon id %s, got %s", tc.coupon.GetID(), price.CouponID)
+			}
+		})
+	}
+}
+
+func TestCalculatePriceErrors(t *testing.T) {
+	plan := NewPlan().SetPrice("19.99").SetCurrency(CURRENCY_USD)
+
+	if _, err := CalculatePrice(nil, nil); err == nil {
+		t.Error("expected error for a nil plan")
+	}
+
+	coupon := NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("5.00").SetCurrency(CURRENCY_EUR)
+	if _, err := CalculatePrice(plan, coupon); err == nil {
+		t.Error("expected error for an amount off in another currency")
+	}
+
+	if _, err := CalculatePrice(NewPlan().SetPrice("free"), nil); err == nil {
+		t.Error("expected error for a price which is not a number")
+	}
+}
+
+func TestCalculatePriceForQuantity(t *testing.T) {
+	plan := NewPlan().SetPrice("10.00").SetCurrency(CURRENCY_USD)
+
+	testCases := []struct {
+		name     string
+		quantity int
+		coupon   CouponInterface
+		subtotal string
+		discount string
+		total    string
+	}{
+		{
+			name:     "single seat",
+			quantity: 1,
+			subtotal: "10.00",
+			discount: "0.00",
+			total:    "10.00",
+		},
+		{
+			name:     "five seats",
+			quantity: 5,
+			subtotal: "50.00",
+			discount: "0.00",
+			total:    "50.00",
+		},
+		{
+			name:     "percent off the subtotal",
+			quantity: 5,
+			coupon:   NewCoupon().SetType(COUPON_TYPE_PERCENT).SetPercentOff("10"),
+			subtotal: "50.00",
+			discount: "5.00",
+			total:    "45.00",
+		},
+		{
+			name:     "amount off taken once",
+			quantity: 5,
+			coupon:   NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("15.00").SetCurrency(CURRENCY_USD),
+			subtotal: "50.00",
+			discount: "15.00",
+			total:    "35.00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			price, err := CalculatePriceForQuantity(plan, tc.quantity, tc.coupon)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if price.Quantity != tc.quantity {
+				t.Errorf("expected quantity %d, got %d", tc.quantity, price.Quantity)
+			}
+			if price.Subtotal != tc.subtotal || price.Discount != tc.discount || price.Total != tc.total {
+				t.Errorf("expected %s - %s = %s, got %s - %s = %s", tc.subtotal, tc.discount, tc.total, price.Subtotal, price.Discount, price.Total)
+			}
+		})
+	}
+
+	if _, err := CalculatePriceForQuantity(plan, 0, nil); err == nil {
+		t.Error("expected error for a zero quantity")
+	}
+}
+
+func TestCouponValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		coupon   CouponInterface
+		contains string
+	}{
+		{
+			name:     "percent off zero",
+			coupon:   NewCoupon(),
+			contains: "percent off must be between 0 and 100",
+		},
+		{
+			name:     "percent off over 100",
+			coupon:   NewCoupon().SetPercentOff("120"),
+			contains: "percent off must be between 0 and 100",
+		},
+		{
+			name:     "amount off zero",
+			coupon:   NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetCurrency(CURRENCY_USD),
+			contains: "amount off must be positive",
+		},
+		{
+			name:     "amount off without currency",
+			coupon:   NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("5.00"),
+			contains: "currency cannot be empty",
+		},
+		{
+			name:     "unknown type",
+			coupon:   NewCoupon().SetType("bogo"),
+			contains: "unknown type",
+		},
+		{
+			name:     "repeating without periods",
+			coupon:   NewCoupon().SetPercentOff("20").SetDuration(COUPON_DURATION_REPEATING),
+			contains: "duration periods must be positive",
+		},
+		{
+			name:     "unknown duration",
+			coupon:   NewCoupon().SetPercentOff("20").SetDuration("weekly"),
+			contains: "unknown duration",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := couponValidate(tc.coupon)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
+
+func TestAmountCents(t *testing.T) {
+	testCases := []struct {
+		amount string
+		cents  int64
+		format string
+	}{
+		{amount: "", cents: 0, format: "0.00"},
+		{amount: "19.99", cents: 1999, format: "19.99"},
+		{amount: "0.1", cents: 10, format: "0.10"},
+		{amount: " 7 ", cents: 700, format: "7.00"},
+		{amount: "-2.5", cents: -250, format: "-2.50"},
+	}
+
+	for _, tc := range testCases {
+		cents, err := amountCents(tc.amount)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if cents != tc.cents {
+			t.Errorf("expected %q to be %d cents, got %d", tc.amount, tc.cents, cents)
+		}
+		if centsAmount(cents) != tc.format {
+			t.Errorf("expected %d cents to format as %s, got %s", cents, tc.format, centsAmount(cents))
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// DunningAttemptInterface defines the methods for a DunningAttempt entity.
+// A dunning attempt is a charge of a past due subscription, for the billing
+// period whose renewal failed.
+type DunningAttemptInterface interface {
+	GetAttempt() int
+	SetAttempt(attempt int) DunningAttemptInterface
+
+	GetAttemptedAt() string
+	GetAttemptedAtCarbon() *carbon.Carbon
+	SetAttemptedAt(attemptedAt string) DunningAttemptInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) DunningAttemptInterface
+
+	GetFailureReason() string
+	SetFailureReason(failureReason string) DunningAttemptInterface
+
+	GetID() string
+	SetID(id string) DunningAttemptInterface
+
+	GetPeriodEnd() string
+	GetPeriodEndCarbon() *carbon.Carbon
+	SetPeriodEnd(periodEnd string) DunningAttemptInterface
+
+	GetPeriodStart() string
+	GetPeriodStartCarbon() *carbon.Carbon
+	SetPeriodStart(periodStart string) DunningAttemptInterface
+
+	GetScheduledAt() string
+	GetScheduledAtCarbon() *carbon.Carbon
+	SetScheduledAt(scheduledAt string) DunningAttemptInterface
+
+	GetStatus() string
+	SetStatus(status string) DunningAttemptInterface
+
+	GetSubscriptionID() string
+	SetSubscriptionID(subscriptionID string) DunningAttemptInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) DunningAttemptInterface
+}
+
+var _ DunningAttemptInterface = (*dunningAttemptImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type dunningAttemptImplementation struct {
+	orm.ShortID
+
+	SubscriptionIDField string `db:"subscription_id"`
+	PeriodStartField    string `db:"period_start"`
+	PeriodEndField      string `db:"period_end"`
+	AttemptField        int    `db:"attempt"`
+	StatusField         string `db:"status"`
+	ScheduledAtField    string `db:"scheduled_at"`
+	AttemptedAtField    string `db:"attempted_at"`
+	FailureReasonField  string `db:"failure_reason"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewDunningAttempt() DunningAttemptInterface {
+	o := &dunningAttemptImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetStatus(DUNNING_ATTEMPT_STATUS_SCHEDULED)
+	o.SetAttempt(1)
+	o.SetSubscriptionID("")
+	o.SetFailureReason("")
+	o.SetPeriodStart(MAX_DATETIME)
+	o.SetPeriodEnd(MAX_DATETIME)
+	o.SetScheduledAt(MAX_DATETIME)
+	o.SetAttemptedAt(MAX_DATETIME)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *dunningAttemptImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *dunningAttemptImplementation) SetID(id string) DunningAttemptInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetSubscriptionID() string {
+	return o.SubscriptionIDField
+}
+
+func (o *dunningAttemptImplementation) SetSubscriptionID(subscriptionID string) DunningAttemptInterface {
+	o.SubscriptionIDField = subscriptionID
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetPeriodStart() string {
+	return o.PeriodStartField
+}
+
+func (o *dunningAttemptImplementation) GetPeriodStartCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetPeriodStart(), carbon.UTC)
+}
+
+func (o *dunningAttemptImplementation) SetPeriodStart(periodStart string) DunningAttemptInterface {
+	o.PeriodStartField = periodStart
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetPeriodEnd() string {
+	return o.PeriodEndField
+}
+
+func (o *dunningAttemptImplementation) GetPeriodEndCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetPeriodEnd(), carbon.UTC)
+}
+
+func (o *dunningAttemptImplementation) SetPeriodEnd(periodEnd string) DunningAttemptInterface {
+	o.PeriodEndField = periodEnd
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetAttempt() int {
+	return o.AttemptField
+}
+
+func (o *dunningAttemptImplementation) SetAttempt(attempt int) DunningAttemptInterface {
+	o.AttemptField = attempt
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetStatus() string {
+	return o.StatusField
+}
+
+func (o *dunningAttemptImplementation) SetStatus(status string) DunningAttemptInterface {
+	o.StatusField = status
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetScheduledAt() string {
+	return o.ScheduledAtField
+}
+
+func (o *dunningAttemptImplementation) GetScheduledAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetScheduledAt(), carbon.UTC)
+}
+
+func (o *dunningAttemptImplementation) SetScheduledAt(scheduledAt string) DunningAttemptInterface {
+	o.ScheduledAtField = scheduledAt
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetAttemptedAt() string {
+	return o.AttemptedAtField
+}
+
+func (o *dunningAttemptImplementation) GetAttemptedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetAttemptedAt(), carbon.UTC)
+}
+
+func (o *dunningAttemptImplementation) SetAttemptedAt(attemptedAt string) DunningAttemptInterface {
+	o.AttemptedAtField = attemptedAt
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetFailureReason() string {
+	return o.FailureReasonField
+}
+
+func (o *dunningAttemptImplementation) SetFailureReason(failureReason string) DunningAttemptInterface {
+	o.FailureReasonField = failureReason
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *dunningAttemptImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *dunningAttemptImplementation) SetCreatedAt(createdAt string) DunningAttemptInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *dunningAttemptImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *dunningAttemptImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *dunningAttemptImplementation) SetUpdatedAt(updatedAt string) DunningAttemptInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
@@ -0,0 +1,209 @@
+package subscriptionstore
+
+import "errors"
+
+// DunningAttemptQueryInterface defines the interface for querying dunning attempts.
+type DunningAttemptQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) DunningAttemptQueryInterface
+
+	HasSubscriptionID() bool
+	SubscriptionID() string
+	SetSubscriptionID(subscriptionID string) DunningAttemptQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) DunningAttemptQueryInterface
+
+	HasPeriodEnd() bool
+	PeriodEnd() string
+	SetPeriodEnd(periodEnd string) DunningAttemptQueryInterface
+
+	HasScheduledAtLte() bool
+	ScheduledAtLte() string
+	SetScheduledAtLte(scheduledAtLte string) DunningAttemptQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) DunningAttemptQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) DunningAttemptQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) DunningAttemptQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) DunningAttemptQueryInterface
+}
+
+// DunningAttemptQuery is a shortcut alias for NewDunningAttemptQuery
+func DunningAttemptQuery() DunningAttemptQueryInterface {
+	return NewDunningAttemptQuery()
+}
+
+// NewDunningAttemptQuery creates a new dunning attempt query
+func NewDunningAttemptQuery() DunningAttemptQueryInterface {
+	return &dunningAttemptQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ DunningAttemptQueryInterface = (*dunningAttemptQueryImplementation)(nil)
+
+type dunningAttemptQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *dunningAttemptQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("dunning attempt query. id cannot be empty")
+	}
+	if q.HasSubscriptionID() && q.SubscriptionID() == "" {
+		return errors.New("dunning attempt query. subscription_id cannot be empty")
+	}
+	if q.HasStatus() && q.Status() == "" {
+		return errors.New("dunning attempt query. status cannot be empty")
+	}
+	if q.HasPeriodEnd() && q.PeriodEnd() == "" {
+		return errors.New("dunning attempt query. period_end cannot be empty")
+	}
+	if q.HasScheduledAtLte() && q.ScheduledAtLte() == "" {
+		return errors.New("dunning attempt query. scheduled_at_lte cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("dunning attempt query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("dunning attempt query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *dunningAttemptQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *dunningAttemptQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetID(id string) DunningAttemptQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasSubscriptionID() bool {
+	return q.hasProperty("subscription_id")
+}
+
+func (q *dunningAttemptQueryImplementation) SubscriptionID() string {
+	return q.properties["subscription_id"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetSubscriptionID(subscriptionID string) DunningAttemptQueryInterface {
+	q.properties["subscription_id"] = subscriptionID
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasStatus() bool {
+	return q.hasProperty("status")
+}
+
+func (q *dunningAttemptQueryImplementation) Status() string {
+	return q.properties["status"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetStatus(status string) DunningAttemptQueryInterface {
+	q.properties["status"] = status
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasPeriodEnd() bool {
+	return q.hasProperty("period_end")
+}
+
+func (q *dunningAttemptQueryImplementation) PeriodEnd() string {
+	return q.properties["period_end"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetPeriodEnd(periodEnd string) DunningAttemptQueryInterface {
+	q.properties["period_end"] = periodEnd
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasScheduledAtLte() bool {
+	return q.hasProperty("scheduled_at_lte")
+}
+
+func (q *dunningAttemptQueryImplementation) ScheduledAtLte() string {
+	return q.properties["scheduled_at_lte"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetScheduledAtLte(scheduledAtLte string) DunningAttemptQueryInterface {
+	q.properties["scheduled_at_lte"] = scheduledAtLte
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *dunningAttemptQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *dunningAttemptQueryImplementation) SetOffset(offset int) DunningAttemptQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *dunningAttemptQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *dunningAttemptQueryImplementation) SetLimit(limit int) DunningAttemptQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *dunningAttemptQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetOrderBy(orderBy string) DunningAttemptQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *dunningAttemptQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *dunningAttemptQueryImplementation) SetSortOrder(sortOrder string) DunningAttemptQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *dunningAttemptQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
@@ -0,0 +1,71 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDunningAttemptQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(DunningAttemptQueryInterface)
+		contains string
+	}{
+		{
+			name:     "id empty",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetID("") },
+			contains: "id cannot be empty",
+		},
+		{
+			name:     "subscription_id empty",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetSubscriptionID("") },
+			contains: "subscription_id cannot be empty",
+		},
+		{
+			name:     "status empty",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetStatus("") },
+			contains: "status cannot be empty",
+		},
+		{
+			name:     "period_end empty",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetPeriodEnd("") },
+			contains: "period_end cannot be empty",
+		},
+		{
+			name:     "scheduled_at_lte empty",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetScheduledAtLte("") },
+			contains: "scheduled_at_lte cannot be empty",
+		},
+		{
+			name:     "limit negative",
+			setup:    func(q DunningAttemptQueryInterface) { q.SetLimit(-1) },
+			contains: "limit cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewDunningAttemptQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
+
+func TestDunningAttemptQueryValidateSuccess(t *testing.T) {
+	query := NewDunningAttemptQuery().
+		SetSubscriptionID("sub_1").
+		SetStatus(DUNNING_ATTEMPT_STATUS_SCHEDULED).
+		SetScheduledAtLte("2025-01-01 00:00:00").
+		SetLimit(10)
+
+	if err := query.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewDunningAttemptDefaults(t *testing.T) {
+	attempt := NewDunningAttempt()
+
+	if attempt.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if attempt.GetStatus() != DUNNING_ATTEMPT_STATUS_SCHEDULED {
+		t.Fatalf("expected status %s, got %s", DUNNING_ATTEMPT_STATUS_SCHEDULED, attempt.GetStatus())
+	}
+	if attempt.GetAttempt() != 1 {
+		t.Fatalf("expected attempt 1, got %d", attempt.GetAttempt())
+	}
+	if attempt.GetScheduledAt() != MAX_DATETIME {
+		t.Fatalf("expected scheduled at %s, got %s", MAX_DATETIME, attempt.GetScheduledAt())
+	}
+	if attempt.GetAttemptedAt() != MAX_DATETIME {
+		t.Fatalf("expected attempted at %s, got %s", MAX_DATETIME, attempt.GetAttemptedAt())
+	}
+	if attempt.GetCreatedAt() == "" {
+		t.Fatal("created at should not be empty")
+	}
+	if attempt.GetUpdatedAt() == "" {
+		t.Fatal("updated at should not be empty")
+	}
+}
+
+func TestDunningAttemptSettersAndGetters(t *testing.T) {
+	attempt := NewDunningAttempt().
+		SetSubscriptionID("sub_1").
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetPeriodEnd("2025-02-01 00:00:00").
+		SetAttempt(2).
+		SetStatus(DUNNING_ATTEMPT_STATUS_FAILED).
+		SetScheduledAt("2025-02-02 00:00:00").
+		SetAttemptedAt("2025-02-02 01:00:00").
+		SetFailureReason("card_declined")
+
+	if attempt.GetSubscriptionID() != "sub_1" {
+		t.Fatalf("expected subscription id sub_1, got %s", attempt.GetSubscriptionID())
+	}
+	if attempt.GetPeriodStart() != "2025-01-01 00:00:00" {
+		t.Fatalf("expected period start 2025-01-01 00:00:00, got %s", attempt.GetPeriodStart())
+	}
+	if attempt.GetPeriodEnd() != "2025-02-01 00:00:00" {
+		t.Fatalf("expected period end 2025-02-01 00:00:00, got %s", attempt.GetPeriodEnd())
+	}
+	if attempt.GetAttempt() != 2 {
+		t.Fatalf("expected attempt 2, got %d", attempt.GetAttempt())
+	}
+	if attempt.GetStatus() != DUNNING_ATTEMPT_STATUS_FAILED {
+		t.Fatalf("expected status %s, got %s", DUNNING_ATTEMPT_STATUS_FAILED, attempt.GetStatus())
+	}
+	if attempt.GetScheduledAt() != "2025-02-02 00:00:00" {
+		t.Fatalf("expected scheduled at 2025-02-02 00:00:00, got %s", attempt.GetScheduledAt())
+	}
+	if attempt.GetAttemptedAt() != "2025-02-02 01:00:00" {
+		t.Fatalf("expected attempted at 2025-02-02 01:00:00, got %s", attempt.GetAttemptedAt())
+	}
+	if attempt.GetFailureReason() != "card_declined" {
+		t.Fatalf("expected failure reason card_declined, got %s", attempt.GetFailureReason())
+	}
+}
@@ -0,0 +1,208 @@
+package subscriptionstore
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// maxIndexNameLength is the longest identifier PostgreSQL accepts (MySQL allows 64)
+const maxIndexNameLength = 63
+
+// planIndexes returns the secondary indexes of the plan table
+func planIndexes() [][]string {
+	return [][]string{
+		{COLUMN_STATUS},
+		{COLUMN_SOFT_DELETED_AT},
+	}
+}
+
+// subscriptionIndexes returns the secondary indexes of the subscription table.
+// The composite index covers the most common lookup: the active, non-deleted
+// subscriptions of a subscriber.
+func subscriptionIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIBER_ID},
+		{COLUMN_PLAN_ID},
+		{COLUMN_STATUS},
+		{COLUMN_PERIOD_END},
+		{COLUMN_SOFT_DELETED_AT},
+		{COLUMN_SUBSCRIBER_ID, COLUMN_STATUS, COLUMN_SOFT_DELETED_AT},
+	}
+}
+
+// dunningAttemptIndexes returns the secondary indexes of the dunning attempt
+// table. The composite index serves the due attempts polled by the payment worker.
+func dunningAttemptIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID},
+		{COLUMN_STATUS, COLUMN_SCHEDULED_AT},
+	}
+}
+
+// invoiceIndexes returns the secondary indexes of the invoice table
+func invoiceIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID},
+		{COLUMN_SUBSCRIBER_ID},
+		{COLUMN_STATUS},
+	}
+}
+
+// invoiceUniqueIndexes returns the unique indexes of the invoice table. A
+// subscription has a single invoice per period which is not void, void
+// invoices being told apart by their void id.
+func invoiceUniqueIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID, COLUMN_PERIOD_START, COLUMN_VOID_ID},
+	}
+}
+
+// providerReferenceUniqueIndexes returns the unique indexes of the provider
+// reference table. A local object maps to a single external object per
+// provider, and the other way round.
+func providerReferenceUniqueIndexes() [][]string {
+	return [][]string{
+		{COLUMN_PROVIDER, COLUMN_OBJECT_TYPE, COLUMN_LOCAL_ID},
+		{COLUMN_PROVIDER, COLUMN_OBJECT_TYPE, COLUMN_EXTERNAL_ID},
+	}
+}
+
+// promotionCodeUniqueIndexes returns the unique indexes of the promotion code table
+func promotionCodeUniqueIndexes() [][]string {
+	return [][]string{
+		{COLUMN_CODE},
+	}
+}
+
+// promotionCodeIndexes returns the secondary indexes of the promotion code table
+func promotionCodeIndexes() [][]string {
+	return [][]string{
+		{COLUMN_COUPON_ID},
+	}
+}
+
+// subscriptionDiscountIndexes returns the secondary indexes of the
+// subscription discount table
+func subscriptionDiscountIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID},
+		{COLUMN_COUPON_ID},
+	}
+}
+
+// subscriptionItemIndexes returns the secondary indexes of the
+// subscription item table
+func subscriptionItemIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID},
+		{COLUMN_PLAN_ID},
+	}
+}
+
+// subscriptionItemUniqueIndexes returns the unique indexes of the
+// subscription item table. A plan is added to a subscription only once.
+func subscriptionItemUniqueIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIPTION_ID, COLUMN_PLAN_ID},
+	}
+}
+
+// planVersionIndexes returns the secondary indexes of the plan version
+// table, as created by the plan version table migration. The unique index
+// on the same columns replaces them.
+func planVersionIndexes() [][]string {
+	return [][]string{
+		{COLUMN_PLAN_ID, COLUMN_VERSION},
+	}
+}
+
+// planVersionUniqueIndexes returns the unique indexes of the plan version
+// table. A plan has a single row per version number.
+func planVersionUniqueIndexes() [][]string {
+	return [][]string{
+		{COLUMN_PLAN_ID, COLUMN_VERSION},
+	}
+}
+
+// planVersionMigrationIndexes returns the secondary indexes of the plan
+// version migration table
+func planVersionMigrationIndexes() [][]string {
+	return [][]string{
+		{COLUMN_PLAN_ID},
+		{COLUMN_STATUS, COLUMN_SCHEDULED_AT},
+	}
+}
+
+// subscriptionScheduleIndexes returns the secondary indexes of the
+// subscription schedule table
+func subscriptionScheduleIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIBER_ID},
+		{COLUMN_SUBSCRIPTION_ID},
+		{COLUMN_STATUS, COLUMN_NEXT_PHASE_AT},
+	}
+}
+
+// subscriptionCancellationIndexes returns the secondary indexes of the
+// subscription table on its cancellation columns
+func subscriptionCancellationIndexes() [][]string {
+	return [][]string{
+		{COLUMN_CANCELLATION_REQUESTED_AT},
+	}
+}
+
+// subscriberIndexes returns the secondary indexes of the subscriber table
+func subscriberIndexes() [][]string {
+	return [][]string{
+		{COLUMN_EMAIL},
+	}
+}
+
+// paymentMethodIndexes returns the secondary indexes of the payment method
+// table
+func paymentMethodIndexes() [][]string {
+	return [][]string{
+		{COLUMN_SUBSCRIBER_ID},
+		{COLUMN_EXPIRES_AT},
+	}
+}
+
+// indexName returns the name of the index on the given columns, following
+// neat's naming convention. Names longer than the identifier limits of
+// PostgreSQL and MySQL are shortened, keeping them unique with a checksum.
+func indexName(tableName string, columns []string) string {
+	return shortIndexName(tableName + "_" + strings.Join(columns, "_") + "_index")
+}
+
+// uniqueIndexName returns the name of the unique index on the given columns,
+// following neat's naming convention
+func uniqueIndexName(tableName string, columns []string) string {
+	return shortIndexName(tableName + "_" + strings.Join(columns, "_") + "_unique")
+}
+
+// sqliteUniqueIndexSQL returns the statement adding the unique index on the
+// given columns on SQLite, whose neat grammar creates unique indexes as plain
+// ones
+func sqliteUniqueIndexSQL(tableName string, columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, `"`+column+`"`)
+	}
+	return `create unique index "` + uniqueIndexName(tableName, columns) + `" on "` + tableName + `" (` + strings.Join(quoted, ", ") + `)`
+}
+
+// shortIndexName normalizes the index name, and shortens it to the
+// identifier limits of PostgreSQL and MySQL
+func shortIndexName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "-", "_")
+	name = strings.ReplaceAll(name, ".", "_")
+
+	if len(name) <= maxIndexNameLength {
+		return name
+	}
+
+	checksum := fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(name)))
+	return name[:maxIndexNameLength-len(checksum)-1] + "_" + checksum
+}
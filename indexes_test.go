@@ -0,0 +1,109 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+
+	contractsschema "github.com/dracory/neat/contracts/database/schema"
+)
+
+func TestStoreMigrateUpCreatesIndexes(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+
+	for _, columns := range planIndexes() {
+		name := indexName(st.planTableName, columns)
+		if !st.db.Schema().HasIndex(st.planTableName, name) {
+			t.Errorf("expected plan index %s to exist", name)
+		}
+	}
+
+	for _, columns := range subscriptionIndexes() {
+		name := indexName(st.subscriptionTableName, columns)
+		if !st.db.Schema().HasIndex(st.subscriptionTableName, name) {
+			t.Errorf("expected subscription index %s to exist", name)
+		}
+	}
+
+	// The unique index on the plan and version columns replaces the index
+	// on the same columns
+	for _, columns := range planVersionIndexes() {
+		name := indexName(st.planVersionTableName, columns)
+		if st.db.Schema().HasIndex(st.planVersionTableName, name) {
+			t.Errorf("expected plan version index %s to be dropped", name)
+		}
+	}
+}
+
+func TestStoreMigrateUpAddsIndexesToExistingTables(t *testing.T) {
+	db := initDB(":memory:")
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+
+	// Tables created by an older version of the store, without indexes
+	err = st.db.Schema().Create(st.subscriptionTableName, func(table contractsschema.Blueprint) {
+		table.String(COLUMN_ID, 40)
+		table.Primary(COLUMN_ID)
+		table.String(COLUMN_STATUS, 40)
+		table.String(COLUMN_SUBSCRIBER_ID, 50)
+		table.String(COLUMN_PLAN_ID, 50)
+		table.DateTime(COLUMN_PERIOD_END)
+		table.DateTime(COLUMN_SOFT_DELETED_AT)
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	name := indexName(st.subscriptionTableName, []string{COLUMN_SUBSCRIBER_ID, COLUMN_STATUS, COLUMN_SOFT_DELETED_AT})
+	if st.db.Schema().HasIndex(st.subscriptionTableName, name) {
+		t.Fatal("index MUST NOT exist before migration")
+	}
+
+	if err := store.MigrateUp(context.Background()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, columns := range subscriptionIndexes() {
+		name := indexName(st.subscriptionTableName, columns)
+		if !st.db.Schema().HasIndex(st.subscriptionTableName, name) {
+			t.Errorf("expected subscription index %s to exist", name)
+		}
+	}
+
+	// Running the migration again must be a no-op
+	if err := store.MigrateUp(context.Background()); err != nil {
+		t.Fatal("unexpected error on second migration:", err)
+	}
+}
+
+func TestIndexNameIsShortenedForLongTableNames(t *testing.T) {
+	columns := []string{COLUMN_SUBSCRIBER_ID, COLUMN_STATUS, COLUMN_SOFT_DELETED_AT}
+
+	name := indexName("subscription_table", columns)
+	if name != "subscription_table_subscriber_id_status_soft_deleted_at_index" {
+		t.Errorf("unexpected index name %s", name)
+	}
+
+	long := indexName("tenant_one_billing_subscriptions", columns)
+	if len(long) > maxIndexNameLength {
+		t.Errorf("expected index name of at most %d characters, got %d: %s", maxIndexNameLength, len(long), long)
+	}
+
+	other := indexName("tenant_two_billing_subscriptions", columns)
+	if long == other {
+		t.Errorf("expected shortened index names to be unique, got %s twice", long)
+	}
+}
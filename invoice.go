@@ -0,0 +1,260 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+// InvoiceInterface defines the methods for an Invoice entity.
+// An invoice records what was billed to a subscription for a billing period,
+// together with a snapshot of the plan at the time it was issued.
+type InvoiceInterface interface {
+	GetAmount() string
+	GetAmountFloat() float64
+	SetAmount(amount string) InvoiceInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) InvoiceInterface
+
+	GetCurrency() string
+	SetCurrency(currency string) InvoiceInterface
+
+	GetID() string
+	SetID(id string) InvoiceInterface
+
+	GetMemo() string
+	SetMemo(memo string) InvoiceInterface
+
+	GetPeriodEnd() string
+	GetPeriodEndCarbon() *carbon.Carbon
+	SetPeriodEnd(periodEnd string) InvoiceInterface
+
+	GetPeriodStart() string
+	GetPeriodStartCarbon() *carbon.Carbon
+	SetPeriodStart(periodStart string) InvoiceInterface
+
+	GetPlanID() string
+	SetPlanID(planID string) InvoiceInterface
+
+	GetPlanSnapshot() (map[string]string, error)
+	SetPlanSnapshot(snapshot map[string]string) (InvoiceInterface, error)
+
+	GetStatus() string
+	SetStatus(status string) InvoiceInterface
+
+	GetSubscriberID() string
+	SetSubscriberID(subscriberID string) InvoiceInterface
+
+	GetSubscriptionID() string
+	SetSubscriptionID(subscriptionID string) InvoiceInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) InvoiceInterface
+}
+
+var _ InvoiceInterface = (*invoiceImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type invoiceImplementation struct {
+	orm.ShortID
+
+	SubscriptionIDField string `db:"subscription_id"`
+	SubscriberIDField   string `db:"subscriber_id"`
+	PlanIDField         string `db:"plan_id"`
+	PlanSnapshotField   string `db:"plan_snapshot"`
+	PeriodStartField    string `db:"period_start"`
+	PeriodEndField      string `db:"period_end"`
+	AmountField         string `db:"amount"`
+	CurrencyField       string `db:"currency"`
+	StatusField         string `db:"status"`
+	MemoField           string `db:"memo"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewInvoice() InvoiceInterface {
+	o := &invoiceImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetStatus(INVOICE_STATUS_DRAFT)
+	o.SetAmount("0.00")
+	o.SetMemo("")
+	o.SetPeriodStart(MAX_DATETIME)
+	o.SetPeriodEnd(MAX_DATETIME)
+	o.PlanSnapshotField = "{}"
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *invoiceImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *invoiceImplementation) SetID(id string) InvoiceInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *invoiceImplementation) GetSubscriptionID() string {
+	return o.SubscriptionIDField
+}
+
+func (o *invoiceImplementation) SetSubscriptionID(subscriptionID string) InvoiceInterface {
+	o.SubscriptionIDField = subscriptionID
+	return o
+}
+
+func (o *invoiceImplementation) GetSubscriberID() string {
+	return o.SubscriberIDField
+}
+
+func (o *invoiceImplementation) SetSubscriberID(subscriberID string) InvoiceInterface {
+	o.SubscriberIDField = subscriberID
+	return o
+}
+
+func (o *invoiceImplementation) GetPlanID() string {
+	return o.PlanIDField
+}
+
+func (o *invoiceImplementation) SetPlanID(planID string) InvoiceInterface {
+	o.PlanIDField = planID
+	return o
+}
+
+func (o *invoiceImplementation) GetPlanSnapshot() (map[string]string, error) {
+	if o.PlanSnapshotField == "" {
+		return nil, nil
+	}
+	var snapshot map[string]string
+	err := json.Unmarshal([]byte(o.PlanSnapshotField), &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (o *invoiceImplementation) SetPlanSnapshot(snapshot map[string]string) (InvoiceInterface, error) {
+	jsonBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	o.PlanSnapshotField = string(jsonBytes)
+	return o, nil
+}
+
+func (o *invoiceImplementation) GetPeriodStart() string {
+	return o.PeriodStartField
+}
+
+func (o *invoiceImplementation) GetPeriodStartCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetPeriodStart(), carbon.UTC)
+}
+
+func (o *invoiceImplementation) SetPeriodStart(periodStart string) InvoiceInterface {
+	o.PeriodStartField = periodStart
+	return o
+}
+
+func (o *invoiceImplementation) GetPeriodEnd() string {
+	return o.PeriodEndField
+}
+
+func (o *invoiceImplementation) GetPeriodEndCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetPeriodEnd(), carbon.UTC)
+}
+
+func (o *invoiceImplementation) SetPeriodEnd(periodEnd string) InvoiceInterface {
+	o.PeriodEndField = periodEnd
+	return o
+}
+
+func (o *invoiceImplementation) GetAmount() string {
+	return o.AmountField
+}
+
+func (o *invoiceImplementation) GetAmountFloat() float64 {
+	return cast.ToFloat64(o.AmountField)
+}
+
+func (o *invoiceImplementation) SetAmount(amount string) InvoiceInterface {
+	o.AmountField = amount
+	return o
+}
+
+func (o *invoiceImplementation) GetCurrency() string {
+	return o.CurrencyField
+}
+
+func (o *invoiceImplementation) SetCurrency(currency string) InvoiceInterface {
+	o.CurrencyField = currency
+	return o
+}
+
+func (o *invoiceImplementation) GetStatus() string {
+	return o.StatusField
+}
+
+func (o *invoiceImplementation) SetStatus(status string) InvoiceInterface {
+	o.StatusField = status
+	return o
+}
+
+func (o *invoiceImplementation) GetMemo() string {
+	return o.MemoField
+}
+
+func (o *invoiceImplementation) SetMemo(memo string) InvoiceInterface {
+	o.MemoField = memo
+	return o
+}
+
+func (o *invoiceImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *invoiceImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *invoiceImplementation) SetCreatedAt(createdAt string) InvoiceInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *invoiceImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *invoiceImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *invoiceImplementation) SetUpdatedAt(updatedAt string) InvoiceInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
@@ -0,0 +1,209 @@
+package subscriptionstore
+
+import "errors"
+
+// InvoiceQueryInterface defines the interface for querying invoices.
+type InvoiceQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) InvoiceQueryInterface
+
+	HasSubscriptionID() bool
+	SubscriptionID() string
+	SetSubscriptionID(subscriptionID string) InvoiceQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) InvoiceQueryInterface
+
+	HasSubscriberID() bool
+	SubscriberID() string
+	SetSubscriberID(subscriberID string) InvoiceQueryInterface
+
+	HasPeriodStart() bool
+	PeriodStart() string
+	SetPeriodStart(periodStart string) InvoiceQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) InvoiceQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) InvoiceQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) InvoiceQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) InvoiceQueryInterface
+}
+
+// InvoiceQuery is a shortcut alias for NewInvoiceQuery
+func InvoiceQuery() InvoiceQueryInterface {
+	return NewInvoiceQuery()
+}
+
+// NewInvoiceQuery creates a new invoice query
+func NewInvoiceQuery() InvoiceQueryInterface {
+	return &invoiceQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ InvoiceQueryInterface = (*invoiceQueryImplementation)(nil)
+
+type invoiceQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *invoiceQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("invoice query. id cannot be empty")
+	}
+	if q.HasSubscriptionID() && q.SubscriptionID() == "" {
+		return errors.New("invoice query. subscription_id cannot be empty")
+	}
+	if q.HasStatus() && q.Status() == "" {
+		return errors.New("invoice query. status cannot be empty")
+	}
+	if q.HasSubscriberID() && q.SubscriberID() == "" {
+		return errors.New("invoice query. subscriber_id cannot be empty")
+	}
+	if q.HasPeriodStart() && q.PeriodStart() == "" {
+		return errors.New("invoice query. period_start cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("invoice query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("invoice query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *invoiceQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *invoiceQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetID(id string) InvoiceQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasSubscriptionID() bool {
+	return q.hasProperty("subscription_id")
+}
+
+func (q *invoiceQueryImplementation) SubscriptionID() string {
+	return q.properties["subscription_id"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetSubscriptionID(subscriptionID string) InvoiceQueryInterface {
+	q.properties["subscription_id"] = subscriptionID
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasPeriodStart() bool {
+	return q.hasProperty("period_start")
+}
+
+func (q *invoiceQueryImplementation) PeriodStart() string {
+	return q.properties["period_start"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetPeriodStart(periodStart string) InvoiceQueryInterface {
+	q.properties["period_start"] = periodStart
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasStatus() bool {
+	return q.hasProperty("status")
+}
+
+func (q *invoiceQueryImplementation) Status() string {
+	return q.properties["status"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetStatus(status string) InvoiceQueryInterface {
+	q.properties["status"] = status
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasSubscriberID() bool {
+	return q.hasProperty("subscriber_id")
+}
+
+func (q *invoiceQueryImplementation) SubscriberID() string {
+	return q.properties["subscriber_id"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetSubscriberID(subscriberID string) InvoiceQueryInterface {
+	q.properties["subscriber_id"] = subscriberID
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *invoiceQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *invoiceQueryImplementation) SetOffset(offset int) InvoiceQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *invoiceQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *invoiceQueryImplementation) SetLimit(limit int) InvoiceQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *invoiceQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetOrderBy(orderBy string) InvoiceQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *invoiceQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *invoiceQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *invoiceQueryImplementation) SetSortOrder(sortOrder string) InvoiceQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *invoiceQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
@@ -0,0 +1,70 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInvoiceQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(InvoiceQueryInterface)
+		contains string
+	}{
+		{
+			name:     "id empty",
+			setup:    func(q InvoiceQueryInterface) { q.SetID("") },
+			contains: "id cannot be empty",
+		},
+		{
+			name:     "subscription_id empty",
+			setup:    func(q InvoiceQueryInterface) { q.SetSubscriptionID("") },
+			contains: "subscription_id cannot be empty",
+		},
+		{
+			name:     "subscriber_id empty",
+			setup:    func(q InvoiceQueryInterface) { q.SetSubscriberID("") },
+			contains: "subscriber_id cannot be empty",
+		},
+		{
+			name:     "period_start empty",
+			setup:    func(q InvoiceQueryInterface) { q.SetPeriodStart("") },
+			contains: "period_start cannot be empty",
+		},
+		{
+			name:     "status empty",
+			setup:    func(q InvoiceQueryInterface) { q.SetStatus("") },
+			contains: "status cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q InvoiceQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewInvoiceQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
+
+func TestInvoiceQueryValidateSuccess(t *testing.T) {
+	query := NewInvoiceQuery().
+		SetSubscriberID("user_1").
+		SetStatus(INVOICE_STATUS_OPEN).
+		SetLimit(10)
+
+	if err := query.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
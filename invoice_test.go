@@ -0,0 +1,77 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewInvoiceDefaults(t *testing.T) {
+	invoice := NewInvoice()
+
+	if invoice.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if invoice.GetStatus() != INVOICE_STATUS_DRAFT {
+		t.Fatalf("expected status %s, got %s", INVOICE_STATUS_DRAFT, invoice.GetStatus())
+	}
+	if invoice.GetAmount() != "0.00" {
+		t.Fatalf("expected amount 0.00, got %s", invoice.GetAmount())
+	}
+	if invoice.GetPeriodStart() != MAX_DATETIME || invoice.GetPeriodEnd() != MAX_DATETIME {
+		t.Fatalf("expected period %s - %s, got %s - %s", MAX_DATETIME, MAX_DATETIME, invoice.GetPeriodStart(), invoice.GetPeriodEnd())
+	}
+	snapshot, err := invoice.GetPlanSnapshot()
+	if err != nil {
+		t.Fatalf("unexpected error retrieving plan snapshot: %v", err)
+	}
+	if snapshot == nil || len(snapshot) != 0 {
+		t.Fatalf("expected empty plan snapshot, got %v", snapshot)
+	}
+}
+
+func TestInvoiceSettersAndGetters(t *testing.T) {
+	invoice := NewInvoice().
+		SetSubscriptionID("sub_1").
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetPeriodEnd("2025-02-01 00:00:00").
+		SetAmount("19.99").
+		SetCurrency(CURRENCY_EUR).
+		SetStatus(INVOICE_STATUS_PAID).
+		SetMemo("Paid by card")
+
+	invoice, err := invoice.SetPlanSnapshot(map[string]string{COLUMN_TITLE: "Pro"})
+	if err != nil {
+		t.Fatalf("unexpected error setting plan snapshot: %v", err)
+	}
+
+	if invoice.GetSubscriptionID() != "sub_1" {
+		t.Fatalf("expected subscription id sub_1, got %s", invoice.GetSubscriptionID())
+	}
+	if invoice.GetSubscriberID() != "user_1" {
+		t.Fatalf("expected subscriber id user_1, got %s", invoice.GetSubscriberID())
+	}
+	if invoice.GetPlanID() != "plan_1" {
+		t.Fatalf("expected plan id plan_1, got %s", invoice.GetPlanID())
+	}
+	if invoice.GetPeriodStart() != "2025-01-01 00:00:00" || invoice.GetPeriodEnd() != "2025-02-01 00:00:00" {
+		t.Fatalf("unexpected period %s - %s", invoice.GetPeriodStart(), invoice.GetPeriodEnd())
+	}
+	if invoice.GetAmount() != "19.99" || invoice.GetAmountFloat() != 19.99 {
+		t.Fatalf("expected amount 19.99, got %s", invoice.GetAmount())
+	}
+	if invoice.GetCurrency() != CURRENCY_EUR {
+		t.Fatalf("expected currency %s, got %s", CURRENCY_EUR, invoice.GetCurrency())
+	}
+	if invoice.GetStatus() != INVOICE_STATUS_PAID {
+		t.Fatalf("expected status %s, got %s", INVOICE_STATUS_PAID, invoice.GetStatus())
+	}
+	if invoice.GetMemo() != "Paid by card" {
+		t.Fatalf("expected memo Paid by card, got %s", invoice.GetMemo())
+	}
+	snapshot, err := invoice.GetPlanSnapshot()
+	if err != nil {
+		t.Fatalf("unexpected error retrieving plan snapshot: %v", err)
+	}
+	if snapshot[COLUMN_TITLE] != "Pro" {
+		t.Fatalf("unexpected plan snapshot: %v", snapshot)
+	}
+}
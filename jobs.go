@@ -0,0 +1,86 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// RunSubscriptionResumeJob resumes the paused subscriptions which are due,
+// immediately and then every interval, until the context is cancelled.
+// Store errors are logged, and retried on the next tick. It returns the
+// context error on cancellation.
+func RunSubscriptionResumeJob(ctx context.Context, store StoreInterface, interval time.Duration) error {
+	if store == nil {
+		return errors.New("subscriptionstore > subscription resume job. store cannot be nil")
+	}
+	if interval <= 0 {
+		return errors.New("subscriptionstore > subscription resume job. interval must be positive")
+	}
+
+	return runPeriodicJob(ctx, "subscription resume job", interval, func(now string) error {
+		_, err := store.SubscriptionResumeDue(ctx, now)
+		return err
+	})
+}
+
+// RunPlanVersionMigrationJob runs the plan version migrations which are due,
+// immediately and then every interval, until the context is cancelled.
+// Store errors are logged, and retried on the next tick. It returns the
+// context error on cancellation.
+func RunPlanVersionMigrationJob(ctx context.Context, store StoreInterface, interval time.Duration) error {
+	if store == nil {
+		return errors.New("subscriptionstore > plan version migration job. store cannot be nil")
+	}
+	if interval <= 0 {
+		return errors.New("subscriptionstore > plan version migration job. interval must be positive")
+	}
+
+	return runPeriodicJob(ctx, "plan version migration job", interval, func(now string) error {
+		_, err := store.PlanVersionMigrationRunDue(ctx, now)
+		return err
+	})
+}
+
+// RunSubscriptionScheduleJob applies the phases of the subscription schedules
+// which are due, immediately and then every interval, until the context is
+// cancelled. Store errors are logged, and retried on the next tick. It
+// returns the context error on cancellation.
+func RunSubscriptionScheduleJob(ctx context.Context, store StoreInterface, interval time.Duration) error {
+	if store == nil {
+		return errors.New("subscriptionstore > subscription schedule job. store cannot be nil")
+	}
+	if interval <= 0 {
+		return errors.New("subscriptionstore > subscription schedule job. interval must be positive")
+	}
+
+	return runPeriodicJob(ctx, "subscription schedule job", interval, func(now string) error {
+		_, err := store.SubscriptionScheduleRunDue(ctx, now)
+		return err
+	})
+}
+
+// runPeriodicJob calls fn with the current UTC date time, immediately and
+// then every interval, until the context is cancelled. A failed run is
+// logged and does not stop the job, so a transient store error is retried
+// on the next tick.
+func runPeriodicJob(ctx context.Context, name string, interval time.Duration, fn func(now string) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+		if err := fn(now); err != nil && ctx.Err() == nil {
+			slog.Error("subscriptionstore > "+name+" failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunPeriodicJobContinuesAfterError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	runs := 0
+	err := runPeriodicJob(ctx, "test job", 5*time.Millisecond, func(now string) error {
+		runs++
+		if now == "" {
+			t.Error("expected the current date time")
+		}
+		return errors.New("transient error")
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected context deadline error, got:", err)
+	}
+	if runs < 2 {
+		t.Errorf("expected the job to run again after an error, ran %d times", runs)
+	}
+}
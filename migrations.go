@@ -0,0 +1,1240 @@
+package subscriptionstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dracory/neat/contracts/database"
+	contractsschema "github.com/dracory/neat/contracts/database/schema"
+	"github.com/dromara/carbon/v2"
+)
+
+// MigrationState describes a schema migration and whether it has been
+// applied to the database
+type MigrationState struct {
+	ID        string
+	Applied   bool
+	AppliedAt string
+}
+
+// migration is a single, ordered schema change. Migrations are applied
+// in the order they are listed and rolled back in reverse order.
+//
+// The schema changes are skipped when already present, so databases created
+// before versioned migrations existed can be brought up to date. Data steps
+// must tolerate being run again, and down steps the change being absent.
+// The schema changes are declared, rather than run, so the dialect golden
+// tests generate their SQL from the same list.
+type migration struct {
+	id string
+	// schema lists the tables, columns and indexes added by the migration
+	schema func(st *storeImplementation) []migrationSchema
+	// data optionally fills in the existing rows, once the schema is changed
+	data func(st *storeImplementation) error
+	down func(st *storeImplementation) error
+}
+
+// migrationSchema is a schema change of a single table. A new table is
+// created with its definition, an existing table gets the columns created by
+// the definition. The indexes are added after the columns.
+type migrationSchema struct {
+	table         string
+	create        bool
+	columns       []string
+	define        func(table contractsschema.Blueprint)
+	uniqueIndexes [][]string
+	indexes       [][]string
+	// dropIndexes lists the secondary indexes made redundant by the
+	// change, dropped after the new indexes are added
+	dropIndexes [][]string
+}
+
+// migrations returns the ordered list of schema migrations.
+// New migrations MUST be appended to the end, and existing ones
+// MUST never be renamed or reordered.
+func migrations() []migration {
+	return []migration{
+		{id: "0001_create_plan_table", schema: migrationCreatePlanTable, down: migrationDropPlanTable},
+		{id: "0002_create_subscription_table", schema: migrationCreateSubscriptionTable, down: migrationDropSubscriptionTable},
+		{id: "0003_add_plan_indexes", schema: migrationAddPlanIndexes, down: migrationDropPlanIndexes},
+		{id: "0004_add_subscription_indexes", schema: migrationAddSubscriptionIndexes, down: migrationDropSubscriptionIndexes},
+		{id: "0005_add_subscription_pause_columns", schema: migrationAddSubscriptionPauseColumns, down: migrationDropSubscriptionPauseColumns},
+		{id: "0006_create_dunning_attempt_table", schema: migrationCreateDunningAttemptTable, down: migrationDropDunningAttemptTable},
+		{id: "0007_create_invoice_table", schema: migrationCreateInvoiceTable, down: migrationDropInvoiceTable},
+		{id: "0008_create_provider_reference_table", schema: migrationCreateProviderReferenceTable, down: migrationDropProviderReferenceTable},
+		{id: "0009_create_coupon_table", schema: migrationCreateCouponTable, down: migrationDropCouponTable},
+		{id: "0010_create_promotion_code_table", schema: migrationCreatePromotionCodeTable, down: migrationDropPromotionCodeTable},
+		{id: "0011_create_subscription_discount_table", schema: migrationCreateSubscriptionDiscountTable, down: migrationDropSubscriptionDiscountTable},
+		{id: "0012_add_subscription_quantity_column", schema: migrationAddSubscriptionQuantityColumn, down: migrationDropSubscriptionQuantityColumn},
+		{id: "0013_add_plan_pricing_columns", schema: migrationAddPlanPricingColumns, down: migrationDropPlanPricingColumns},
+		{id: "0014_create_subscription_item_table", schema: migrationCreateSubscriptionItemTable, down: migrationDropSubscriptionItemTable},
+		{id: "0015_create_plan_version_table", schema: migrationCreatePlanVersionTable, data: migrationCreatePlanVersions, down: migrationDropPlanVersionTable},
+		{id: "0016_add_subscription_plan_version_column", schema: migrationAddSubscriptionPlanVersionColumn, data: migrationPinSubscriptionPlanVersions, down: migrationDropSubscriptionPlanVersionColumn},
+		{id: "0017_create_plan_version_migration_table", schema: migrationCreatePlanVersionMigrationTable, down: migrationDropPlanVersionMigrationTable},
+		{id: "0018_create_subscription_schedule_table", schema: migrationCreateSubscriptionScheduleTable, down: migrationDropSubscriptionScheduleTable},
+		{id: "0019_add_subscription_cancellation_columns", schema: migrationAddSubscriptionCancellationColumns, down: migrationDropSubscriptionCancellationColumns},
+		{id: "0020_create_subscriber_table", schema: migrationCreateSubscriberTable, down: migrationDropSubscriberTable},
+		{id: "0021_add_subscription_currency_column", schema: migrationAddSubscriptionCurrencyColumn, data: migrationFillSubscriptionCurrencies, down: migrationDropSubscriptionCurrencyColumn},
+		{id: "0022_create_payment_method_table", schema: migrationCreatePaymentMethodTable, down: migrationDropPaymentMethodTable},
+		{id: "0023_add_subscription_item_unique_index", schema: migrationAddSubscriptionItemUniqueIndex, down: migrationDropSubscriptionItemUniqueIndex},
+		{id: "0024_add_plan_version_unique_index", schema: migrationAddPlanVersionUniqueIndex, down: migrationDropPlanVersionUniqueIndex},
+		{id: "0025_add_invoice_void_id_column", schema: migrationAddInvoiceVoidIDColumn, data: migrationFillInvoiceVoidIDs, down: migrationDropInvoiceVoidIDColumn},
+		{id: "0026_add_invoice_period_unique_index", schema: migrationAddInvoicePeriodUniqueIndex, down: migrationDropInvoicePeriodUniqueIndex},
+	}
+}
+
+// migrationList returns the versioned migrations, followed by the migrations
+// of the meta keys promoted to columns by the options of the store
+func (st *storeImplementation) migrationList() []migration {
+	list := migrations()
+	list = append(list, metaColumnMigrations("plan_meta_column", st.planTableName, st.planMetaColumns)...)
+	return append(list, metaColumnMigrations("subscription_meta_column", st.subscriptionTableName, st.subscriptionMetaColumns)...)
+}
+
+// == STORE METHODS ============================================================
+
+// MigrateUp applies all pending schema migrations in order. On SQLite and
+// PostgreSQL, whose schema changes are transactional, each migration is
+// applied and recorded in one transaction, so a failed migration leaves
+// nothing behind and the next run applies it again. MySQL commits each
+// schema change as it runs, so a failed migration may be left half applied.
+//
+// Given a transaction, the migrations run in it, each in a savepoint, and
+// the caller commits or rolls them back with the rest of the transaction.
+// MySQL would commit the transaction on the first schema change, so it is
+// rejected there.
+func (st *storeImplementation) MigrateUp(ctx context.Context, tx ...*sql.Tx) error {
+	if len(tx) == 0 || tx[0] == nil {
+		return st.migrateUp()
+	}
+
+	txStore, closeTx, err := st.withSQLTx(ctx, tx[0])
+	if err != nil {
+		return errors.New("subscriptionstore > migrate up. " + err.Error())
+	}
+	defer closeTx()
+
+	return txStore.migrateUp()
+}
+
+// MigrateDown rolls back all schema migrations in reverse order,
+// and drops the migration table. Given a transaction, the migrations are
+// rolled back in it, as with MigrateUp.
+func (st *storeImplementation) MigrateDown(ctx context.Context, tx ...*sql.Tx) error {
+	if len(tx) == 0 || tx[0] == nil {
+		return st.migrateDown()
+	}
+
+	txStore, closeTx, err := st.withSQLTx(ctx, tx[0])
+	if err != nil {
+		return errors.New("subscriptionstore > migrate down. " + err.Error())
+	}
+	defer closeTx()
+
+	return txStore.migrateDown()
+}
+
+// MigrationStatus returns the state of every known schema migration
+func (st *storeImplementation) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	applied := map[string]time.Time{}
+
+	if st.schema().HasTable(st.migrationTableName) {
+		var err error
+		applied, err = st.migrationsApplied()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	states := []MigrationState{}
+	for _, m := range st.migrationList() {
+		state := MigrationState{ID: m.id}
+		if appliedAt, ok := applied[m.id]; ok {
+			state.Applied = true
+			state.AppliedAt = carbon.CreateFromStdTime(appliedAt, carbon.UTC).ToDateTimeString(carbon.UTC)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// migrateUp applies the pending migrations, see MigrateUp
+func (st *storeImplementation) migrateUp() error {
+	if err := st.migrationTableCreate(); err != nil {
+		if st.debugEnabled {
+			st.sqlLogger.Error("MigrateUp: migration table failed", "error", err)
+		}
+		return err
+	}
+
+	applied, err := st.migrationsApplied()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range st.migrationList() {
+		if _, ok := applied[m.id]; ok {
+			continue
+		}
+
+		if err := st.migrationRun(m); err != nil {
+			if st.debugEnabled {
+				st.sqlLogger.Error("MigrateUp: migration failed", "migration", m.id, "error", err)
+			}
+			return err
+		}
+
+		if st.debugEnabled {
+			st.sqlLogger.Info("MigrateUp: migration applied", "migration", m.id)
+		}
+	}
+
+	return nil
+}
+
+// migrateDown rolls back the migrations, see MigrateDown
+func (st *storeImplementation) migrateDown() error {
+	list := st.migrationList()
+	for i := len(list) - 1; i >= 0; i-- {
+		m := list[i]
+		if err := m.down(st); err != nil {
+			if st.debugEnabled {
+				st.sqlLogger.Error("MigrateDown: migration failed", "migration", m.id, "error", err)
+			}
+			return err
+		}
+	}
+
+	return st.dropTableIfExists(st.migrationTableName)
+}
+
+// migrationRun applies the migration and records it as applied, in one
+// transaction where the schema changes of the database are transactional
+func (st *storeImplementation) migrationRun(m migration) error {
+	if !schemaTransactional(st.query().Driver()) {
+		return st.migrationApplyAndRecord(m)
+	}
+
+	return st.transaction(func(txStore *storeImplementation) error {
+		return txStore.migrationApplyAndRecord(m)
+	})
+}
+
+// migrationApplyAndRecord applies the migration, then records it as applied
+func (st *storeImplementation) migrationApplyAndRecord(m migration) error {
+	if err := st.migrationApply(m); err != nil {
+		return err
+	}
+
+	return st.query().Table(st.migrationTableName).Create(map[string]any{
+		COLUMN_ID:         m.id,
+		COLUMN_APPLIED_AT: dateTimeValue(carbon.Now(carbon.UTC)),
+	})
+}
+
+// schemaTransactional returns true if the schema changes of the driver run
+// in transactions, rather than committing the transaction they run in
+func schemaTransactional(driver database.Driver) bool {
+	return driver == database.DriverSqlite || driver == database.DriverPostgres
+}
+
+// migrationTableCreate creates the migration table if it does not exist
+func (st *storeImplementation) migrationTableCreate() error {
+	if st.schema().HasTable(st.migrationTableName) {
+		return nil
+	}
+
+	return st.schema().Create(st.migrationTableName, migrationTableDefinition)
+}
+
+// migrationApply applies the schema changes of the migration, then fills in
+// the existing rows
+func (st *storeImplementation) migrationApply(m migration) error {
+	for _, change := range m.schema(st) {
+		if change.create && !st.schema().HasTable(change.table) {
+			if err := st.schema().Create(change.table, change.define); err != nil {
+				return err
+			}
+		}
+
+		if len(change.columns) > 0 {
+			if err := st.addColumns(change.table, change.columns, change.define); err != nil {
+				return err
+			}
+		}
+
+		if err := st.migrateUniqueIndexes(change.table, change.uniqueIndexes); err != nil {
+			return err
+		}
+
+		if err := st.migrateIndexes(change.table, change.indexes); err != nil {
+			return err
+		}
+
+		if err := st.dropIndexes(change.table, change.dropIndexes); err != nil {
+			return err
+		}
+	}
+
+	if m.data == nil {
+		return nil
+	}
+
+	return m.data(st)
+}
+
+// migrationsApplied returns the applied migrations keyed by id
+func (st *storeImplementation) migrationsApplied() (map[string]time.Time, error) {
+	type migrationRow struct {
+		ID        string    `db:"id"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+
+	var rows []migrationRow
+	if err := st.query().Table(st.migrationTableName).Get(&rows); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.ID] = r.AppliedAt
+	}
+
+	return applied, nil
+}
+
+// migrateIndexes adds the given secondary indexes to an existing table,
+// skipping the ones that are already present
+func (st *storeImplementation) migrateIndexes(tableName string, indexes [][]string) error {
+	missing := [][]string{}
+	for _, columns := range indexes {
+		if !st.schema().HasIndex(tableName, indexName(tableName, columns)) {
+			missing = append(missing, columns)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return st.schema().Table(tableName, func(table contractsschema.Blueprint) {
+		for _, columns := range missing {
+			table.Index(columns...).Name(indexName(tableName, columns))
+		}
+	})
+}
+
+// migrateUniqueIndexes adds the given unique indexes to an existing table,
+// skipping the ones that are already present
+func (st *storeImplementation) migrateUniqueIndexes(tableName string, indexes [][]string) error {
+	missing := [][]string{}
+	for _, columns := range indexes {
+		if !st.schema().HasIndex(tableName, uniqueIndexName(tableName, columns)) {
+			missing = append(missing, columns)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if st.query().Driver() == database.DriverSqlite {
+		for _, columns := range missing {
+			if _, err := st.query().Exec(sqliteUniqueIndexSQL(tableName, columns)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return st.schema().Table(tableName, func(table contractsschema.Blueprint) {
+		for _, columns := range missing {
+			table.Unique(columns...).Name(uniqueIndexName(tableName, columns))
+		}
+	})
+}
+
+// dropUniqueIndexes drops the given unique indexes, skipping the ones that
+// do not exist
+func (st *storeImplementation) dropUniqueIndexes(tableName string, indexes [][]string) error {
+	if !st.schema().HasTable(tableName) {
+		return nil
+	}
+
+	existing := [][]string{}
+	for _, columns := range indexes {
+		if st.schema().HasIndex(tableName, uniqueIndexName(tableName, columns)) {
+			existing = append(existing, columns)
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil
+	}
+
+	return st.schema().Table(tableName, func(table contractsschema.Blueprint) {
+		for _, columns := range existing {
+			table.DropUniqueByName(uniqueIndexName(tableName, columns))
+		}
+	})
+}
+
+// dropIndexes drops the given secondary indexes, skipping the ones
+// that do not exist
+func (st *storeImplementation) dropIndexes(tableName string, indexes [][]string) error {
+	if !st.schema().HasTable(tableName) {
+		return nil
+	}
+
+	existing := [][]string{}
+	for _, columns := range indexes {
+		if st.schema().HasIndex(tableName, indexName(tableName, columns)) {
+			existing = append(existing, columns)
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil
+	}
+
+	return st.schema().Table(tableName, func(table contractsschema.Blueprint) {
+		for _, columns := range existing {
+			table.DropIndexByName(indexName(tableName, columns))
+		}
+	})
+}
+
+// addColumns adds the columns created by the given definition to an existing
+// table, unless the table already has all of them
+func (st *storeImplementation) addColumns(tableName string, columns []string, define func(table contractsschema.Blueprint)) error {
+	if st.schema().HasColumns(tableName, columns) {
+		return nil
+	}
+	return st.schema().Table(tableName, define)
+}
+
+// dropColumns drops the given columns, skipping the ones that do not exist
+func (st *storeImplementation) dropColumns(tableName string, columns []string) error {
+	if !st.schema().HasTable(tableName) {
+		return nil
+	}
+
+	existing := []string{}
+	for _, column := range columns {
+		if st.schema().HasColumn(tableName, column) {
+			existing = append(existing, column)
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil
+	}
+
+	return st.schema().DropColumns(tableName, existing)
+}
+
+// dropTableIfExists drops the given table if it exists
+func (st *storeImplementation) dropTableIfExists(tableName string) error {
+	if !st.schema().HasTable(tableName) {
+		return nil
+	}
+	return st.schema().Drop(tableName)
+}
+
+// == TABLE DEFINITIONS ========================================================
+
+// migrationTableDefinition defines the columns of the migration table
+func migrationTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 100)
+	table.Primary(COLUMN_ID)
+	table.DateTime(COLUMN_APPLIED_AT)
+}
+
+// planTableDefinition defines the columns of the plan table,
+// as created by the first migration
+func planTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_TYPE, 50)
+	table.String(COLUMN_STATUS, 40)
+	table.String(COLUMN_TITLE, 100)
+	table.Text(COLUMN_DESCRIPTION)
+	table.String(COLUMN_INTERVAL, 40)
+	table.String(COLUMN_CURRENCY, 40)
+	table.String(COLUMN_PRICE, 40)
+	table.String(COLUMN_STRIPE_PRICE_ID, 100)
+	table.Text(COLUMN_FEATURES)
+	table.Text(COLUMN_MEMO)
+	table.Text(COLUMN_METAS)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+	table.DateTime(COLUMN_SOFT_DELETED_AT)
+}
+
+// planPricingColumnsDefinition defines the pricing model columns of the plan
+// table. Existing plans are priced per unit. The tiers are nullable, as TEXT
+// columns cannot have a default on MySQL.
+func planPricingColumnsDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_PRICING_MODEL, 40).Default(PLAN_PRICING_MODEL_PER_UNIT)
+	table.Text(COLUMN_PRICE_TIERS).Nullable()
+}
+
+// subscriptionTableDefinition defines the columns of the subscription table,
+// as created by the second migration
+func subscriptionTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_STATUS, 40)
+	table.String(COLUMN_SUBSCRIBER_ID, 50)
+	table.String(COLUMN_PLAN_ID, 50)
+	table.DateTime(COLUMN_PERIOD_START)
+	table.DateTime(COLUMN_PERIOD_END)
+	table.String(COLUMN_CANCEL_AT_PERIOD_END, 3)
+	table.String(COLUMN_PAYMENT_METHOD_ID, 40)
+	table.Text(COLUMN_MEMO)
+	table.Text(COLUMN_METAS)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+	table.DateTime(COLUMN_SOFT_DELETED_AT)
+}
+
+// subscriptionPauseColumnsDefinition defines the pause window columns
+// of the subscription table
+func subscriptionPauseColumnsDefinition(table contractsschema.Blueprint) {
+	table.DateTime(COLUMN_PAUSED_AT).Default(MAX_DATETIME)
+	table.DateTime(COLUMN_RESUME_AT).Default(MAX_DATETIME)
+}
+
+// subscriptionQuantityColumnDefinition defines the quantity column of the
+// subscription table. Existing subscriptions are for a single seat.
+func subscriptionQuantityColumnDefinition(table contractsschema.Blueprint) {
+	table.Integer(COLUMN_QUANTITY).Default(1)
+}
+
+// subscriptionCancellationColumnsDefinition defines the columns recording
+// why and when the subscriptions were cancelled. Existing subscriptions have
+// no cancellation recorded.
+func subscriptionCancellationColumnsDefinition(table contractsschema.Blueprint) {
+	table.DateTime(COLUMN_CANCELLATION_REQUESTED_AT).Default(MAX_DATETIME)
+	table.DateTime(COLUMN_CANCELLATION_EFFECTIVE_AT).Default(MAX_DATETIME)
+	table.String(COLUMN_CANCELLATION_REASON, 40).Default("")
+	table.Text(COLUMN_CANCELLATION_FEEDBACK).Nullable()
+	table.String(COLUMN_CANCELLED_BY, 40).Default("")
+}
+
+// dunningAttemptTableDefinition defines the columns of the dunning attempt table
+func dunningAttemptTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIPTION_ID, 40)
+	table.DateTime(COLUMN_PERIOD_START)
+	table.DateTime(COLUMN_PERIOD_END)
+	table.Integer(COLUMN_ATTEMPT)
+	table.String(COLUMN_STATUS, 40)
+	table.DateTime(COLUMN_SCHEDULED_AT)
+	table.DateTime(COLUMN_ATTEMPTED_AT)
+	table.Text(COLUMN_FAILURE_REASON)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// invoiceTableDefinition defines the columns of the invoice table
+func invoiceTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIPTION_ID, 40)
+	table.String(COLUMN_SUBSCRIBER_ID, 50)
+	table.String(COLUMN_PLAN_ID, 50)
+	table.Text(COLUMN_PLAN_SNAPSHOT)
+	table.DateTime(COLUMN_PERIOD_START)
+	table.DateTime(COLUMN_PERIOD_END)
+	table.String(COLUMN_AMOUNT, 40)
+	table.String(COLUMN_CURRENCY, 40)
+	table.String(COLUMN_STATUS, 40)
+	table.Text(COLUMN_MEMO)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// providerReferenceTableDefinition defines the columns of the provider reference table
+func providerReferenceTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_PROVIDER, 40)
+	table.String(COLUMN_OBJECT_TYPE, 40)
+	table.String(COLUMN_LOCAL_ID, 50)
+	table.String(COLUMN_EXTERNAL_ID, 255)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// couponTableDefinition defines the columns of the coupon table
+func couponTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_NAME, 100)
+	table.String(COLUMN_TYPE, 40)
+	table.String(COLUMN_PERCENT_OFF, 40)
+	table.String(COLUMN_AMOUNT_OFF, 40)
+	table.String(COLUMN_CURRENCY, 40)
+	table.String(COLUMN_DURATION, 40)
+	table.Integer(COLUMN_DURATION_PERIODS)
+	table.Integer(COLUMN_MAX_REDEMPTIONS)
+	table.Integer(COLUMN_TIMES_REDEEMED)
+	table.DateTime(COLUMN_REDEEM_BY)
+	table.Text(COLUMN_MEMO)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// promotionCodeTableDefinition defines the columns of the promotion code table
+func promotionCodeTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_COUPON_ID, 40)
+	table.String(COLUMN_CODE, 100)
+	table.String(COLUMN_STATUS, 40)
+	table.Integer(COLUMN_MAX_REDEMPTIONS)
+	table.Integer(COLUMN_TIMES_REDEEMED)
+	table.DateTime(COLUMN_EXPIRES_AT)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriptionDiscountTableDefinition defines the columns of the subscription discount table
+func subscriptionDiscountTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIPTION_ID, 40)
+	table.String(COLUMN_COUPON_ID, 40)
+	table.String(COLUMN_PROMOTION_CODE_ID, 40)
+	table.DateTime(COLUMN_STARTS_AT)
+	table.DateTime(COLUMN_ENDS_AT)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriptionItemTableDefinition defines the columns of the subscription item table
+func subscriptionItemTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIPTION_ID, 40)
+	table.String(COLUMN_PLAN_ID, 50)
+	table.Integer(COLUMN_QUANTITY)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// planVersionTableDefinition defines the columns of the plan version table
+func planVersionTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_PLAN_ID, 50)
+	table.Integer(COLUMN_VERSION)
+	table.String(COLUMN_INTERVAL, 40)
+	table.String(COLUMN_CURRENCY, 40)
+	table.String(COLUMN_PRICE, 40)
+	table.String(COLUMN_PRICING_MODEL, 40)
+	table.Text(COLUMN_PRICE_TIERS)
+	table.Text(COLUMN_FEATURES)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriptionPlanVersionColumnDefinition defines the plan version column of
+// the subscription table
+func subscriptionPlanVersionColumnDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_PLAN_VERSION_ID, 40).Default("")
+}
+
+// planVersionMigrationTableDefinition defines the columns of the plan
+// version migration table
+func planVersionMigrationTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_PLAN_ID, 50)
+	table.String(COLUMN_FROM_VERSION_ID, 40)
+	table.String(COLUMN_TO_VERSION_ID, 40)
+	table.DateTime(COLUMN_SUBSCRIBED_BEFORE)
+	table.DateTime(COLUMN_SCHEDULED_AT)
+	table.String(COLUMN_STATUS, 40)
+	table.Integer(COLUMN_MIGRATED_COUNT)
+	table.DateTime(COLUMN_COMPLETED_AT)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriptionScheduleTableDefinition defines the columns of the
+// subscription schedule table
+func subscriptionScheduleTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIBER_ID, 40)
+	table.String(COLUMN_SUBSCRIPTION_ID, 40)
+	table.String(COLUMN_STATUS, 40)
+	table.Text(COLUMN_PHASES)
+	table.Integer(COLUMN_CURRENT_PHASE)
+	table.DateTime(COLUMN_NEXT_PHASE_AT)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriberTableDefinition defines the columns of the subscriber table. The
+// ID is as long as the subscriber ID of the subscriptions.
+func subscriberTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 50)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_EMAIL, 191)
+	table.String(COLUMN_NAME, 191)
+	table.String(COLUMN_COUNTRY, 40)
+	table.String(COLUMN_REGION, 40)
+	table.String(COLUMN_TAX_ID, 50)
+	table.String(COLUMN_CURRENCY, 40)
+	table.String(COLUMN_PAYMENT_METHOD_ID, 40)
+	table.Text(COLUMN_MEMO)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// subscriptionCurrencyColumnDefinition defines the currency column of the
+// subscription table
+func subscriptionCurrencyColumnDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_CURRENCY, 40).Default("")
+}
+
+// invoiceVoidIDColumnDefinition defines the void id column of the invoice
+// table, which is empty unless the invoice is void, and is then its id
+func invoiceVoidIDColumnDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_VOID_ID, 40).Default("")
+}
+
+// paymentMethodTableDefinition defines the columns of the payment method
+// table. The expiry date is kept as the end of the expiry month, so that
+// expiring payment methods can be queried.
+func paymentMethodTableDefinition(table contractsschema.Blueprint) {
+	table.String(COLUMN_ID, 40)
+	table.Primary(COLUMN_ID)
+	table.String(COLUMN_SUBSCRIBER_ID, 50)
+	table.String(COLUMN_PROVIDER, 40)
+	table.String(COLUMN_TYPE, 40)
+	table.String(COLUMN_BRAND, 40)
+	table.String(COLUMN_LAST4, 4)
+	table.Integer(COLUMN_EXP_MONTH)
+	table.Integer(COLUMN_EXP_YEAR)
+	table.DateTime(COLUMN_EXPIRES_AT)
+	table.String(COLUMN_IS_DEFAULT, 3)
+	table.DateTime(COLUMN_CREATED_AT)
+	table.DateTime(COLUMN_UPDATED_AT)
+}
+
+// == MIGRATIONS ===============================================================
+
+func migrationCreatePlanTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.planTableName, create: true, define: planTableDefinition},
+	}
+}
+
+func migrationDropPlanTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.planTableName)
+}
+
+func migrationCreateSubscriptionTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.subscriptionTableName, create: true, define: subscriptionTableDefinition},
+	}
+}
+
+func migrationDropSubscriptionTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.subscriptionTableName)
+}
+
+func migrationAddPlanIndexes(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.planTableName, indexes: planIndexes()},
+	}
+}
+
+func migrationDropPlanIndexes(st *storeImplementation) error {
+	return st.dropIndexes(st.planTableName, planIndexes())
+}
+
+func migrationAddSubscriptionIndexes(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.subscriptionTableName, indexes: subscriptionIndexes()},
+	}
+}
+
+func migrationDropSubscriptionIndexes(st *storeImplementation) error {
+	return st.dropIndexes(st.subscriptionTableName, subscriptionIndexes())
+}
+
+func migrationAddSubscriptionPauseColumns(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionTableName,
+			columns: []string{COLUMN_PAUSED_AT, COLUMN_RESUME_AT},
+			define:  subscriptionPauseColumnsDefinition,
+		},
+	}
+}
+
+func migrationDropSubscriptionPauseColumns(st *storeImplementation) error {
+	return st.dropColumns(st.subscriptionTableName, []string{COLUMN_PAUSED_AT, COLUMN_RESUME_AT})
+}
+
+func migrationCreateDunningAttemptTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.dunningAttemptTableName,
+			create:  true,
+			define:  dunningAttemptTableDefinition,
+			indexes: dunningAttemptIndexes(),
+		},
+	}
+}
+
+func migrationDropDunningAttemptTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.dunningAttemptTableName)
+}
+
+func migrationCreateInvoiceTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.invoiceTableName,
+			create:  true,
+			define:  invoiceTableDefinition,
+			indexes: invoiceIndexes(),
+		},
+	}
+}
+
+func migrationDropInvoiceTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.invoiceTableName)
+}
+
+func migrationCreateProviderReferenceTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:         st.providerReferenceTableName,
+			create:        true,
+			define:        providerReferenceTableDefinition,
+			uniqueIndexes: providerReferenceUniqueIndexes(),
+		},
+	}
+}
+
+func migrationDropProviderReferenceTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.providerReferenceTableName)
+}
+
+func migrationCreateCouponTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.couponTableName, create: true, define: couponTableDefinition},
+	}
+}
+
+func migrationDropCouponTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.couponTableName)
+}
+
+func migrationCreatePromotionCodeTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:         st.promotionCodeTableName,
+			create:        true,
+			define:        promotionCodeTableDefinition,
+			uniqueIndexes: promotionCodeUniqueIndexes(),
+			indexes:       promotionCodeIndexes(),
+		},
+	}
+}
+
+func migrationDropPromotionCodeTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.promotionCodeTableName)
+}
+
+func migrationCreateSubscriptionDiscountTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionDiscountTableName,
+			create:  true,
+			define:  subscriptionDiscountTableDefinition,
+			indexes: subscriptionDiscountIndexes(),
+		},
+	}
+}
+
+func migrationDropSubscriptionDiscountTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.subscriptionDiscountTableName)
+}
+
+func migrationAddSubscriptionQuantityColumn(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionTableName,
+			columns: []string{COLUMN_QUANTITY},
+			define:  subscriptionQuantityColumnDefinition,
+		},
+	}
+}
+
+func migrationDropSubscriptionQuantityColumn(st *storeImplementation) error {
+	return st.dropColumns(st.subscriptionTableName, []string{COLUMN_QUANTITY})
+}
+
+func migrationAddPlanPricingColumns(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.planTableName,
+			columns: []string{COLUMN_PRICING_MODEL, COLUMN_PRICE_TIERS},
+			define:  planPricingColumnsDefinition,
+		},
+	}
+}
+
+func migrationDropPlanPricingColumns(st *storeImplementation) error {
+	return st.dropColumns(st.planTableName, []string{COLUMN_PRICING_MODEL, COLUMN_PRICE_TIERS})
+}
+
+func migrationCreateSubscriptionItemTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionItemTableName,
+			create:  true,
+			define:  subscriptionItemTableDefinition,
+			indexes: subscriptionItemIndexes(),
+		},
+	}
+}
+
+func migrationDropSubscriptionItemTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.subscriptionItemTableName)
+}
+
+func migrationCreatePlanVersionTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.planVersionTableName,
+			create:  true,
+			define:  planVersionTableDefinition,
+			indexes: planVersionIndexes(),
+		},
+	}
+}
+
+// migrationCreatePlanVersions creates the first version of the existing plans
+func migrationCreatePlanVersions(st *storeImplementation) error {
+	// The tables are read directly, as the store methods follow the columns
+	// of the latest migration
+	type planRow struct {
+		ID           string `db:"id"`
+		Interval     string `db:"interval"`
+		Currency     string `db:"currency"`
+		Price        string `db:"price"`
+		PricingModel string `db:"pricing_model"`
+		PriceTiers   string `db:"price_tiers"`
+		Features     string `db:"features"`
+	}
+
+	var plans []planRow
+	if err := st.query().Table(st.planTableName).Get(&plans); err != nil {
+		return err
+	}
+
+	now := dateTimeValue(carbon.Now(carbon.UTC))
+	for _, plan := range plans {
+		var count int64
+		if err := st.query().Table(st.planVersionTableName).Where(COLUMN_PLAN_ID+" = ?", plan.ID).Count(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		err := st.query().Table(st.planVersionTableName).Create(map[string]any{
+			COLUMN_ID:            NewPlanVersion().GetID(),
+			COLUMN_PLAN_ID:       plan.ID,
+			COLUMN_VERSION:       1,
+			COLUMN_INTERVAL:      plan.Interval,
+			COLUMN_CURRENCY:      plan.Currency,
+			COLUMN_PRICE:         plan.Price,
+			COLUMN_PRICING_MODEL: plan.PricingModel,
+			COLUMN_PRICE_TIERS:   plan.PriceTiers,
+			COLUMN_FEATURES:      plan.Features,
+			COLUMN_CREATED_AT:    now,
+			COLUMN_UPDATED_AT:    now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrationDropPlanVersionTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.planVersionTableName)
+}
+
+func migrationAddSubscriptionPlanVersionColumn(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionTableName,
+			columns: []string{COLUMN_PLAN_VERSION_ID},
+			define:  subscriptionPlanVersionColumnDefinition,
+		},
+	}
+}
+
+// migrationPinSubscriptionPlanVersions pins the existing subscriptions to
+// the current version of their plan
+func migrationPinSubscriptionPlanVersions(st *storeImplementation) error {
+	type planVersionRow struct {
+		ID      string `db:"id"`
+		PlanID  string `db:"plan_id"`
+		Version int    `db:"version"`
+	}
+
+	var versions []planVersionRow
+	if err := st.query().Table(st.planVersionTableName).OrderBy(COLUMN_VERSION, "asc").Get(&versions); err != nil {
+		return err
+	}
+
+	latest := map[string]string{}
+	for _, version := range versions {
+		latest[version.PlanID] = version.ID
+	}
+
+	for planID, versionID := range latest {
+		_, err := st.query().Table(st.subscriptionTableName).
+			Where(COLUMN_PLAN_ID+" = ?", planID).
+			Where(COLUMN_PLAN_VERSION_ID+" = ?", "").
+			Update(map[string]any{COLUMN_PLAN_VERSION_ID: versionID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrationDropSubscriptionPlanVersionColumn(st *storeImplementation) error {
+	return st.dropColumns(st.subscriptionTableName, []string{COLUMN_PLAN_VERSION_ID})
+}
+
+func migrationCreatePlanVersionMigrationTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.planVersionMigrationTableName,
+			create:  true,
+			define:  planVersionMigrationTableDefinition,
+			indexes: planVersionMigrationIndexes(),
+		},
+	}
+}
+
+func migrationDropPlanVersionMigrationTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.planVersionMigrationTableName)
+}
+
+func migrationCreateSubscriptionScheduleTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionScheduleTableName,
+			create:  true,
+			define:  subscriptionScheduleTableDefinition,
+			indexes: subscriptionScheduleIndexes(),
+		},
+	}
+}
+
+func migrationDropSubscriptionScheduleTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.subscriptionScheduleTableName)
+}
+
+func migrationAddSubscriptionCancellationColumns(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table: st.subscriptionTableName,
+			columns: []string{
+				COLUMN_CANCELLATION_REQUESTED_AT,
+				COLUMN_CANCELLATION_EFFECTIVE_AT,
+				COLUMN_CANCELLATION_REASON,
+				COLUMN_CANCELLATION_FEEDBACK,
+				COLUMN_CANCELLED_BY,
+			},
+			define:  subscriptionCancellationColumnsDefinition,
+			indexes: subscriptionCancellationIndexes(),
+		},
+	}
+}
+
+func migrationDropSubscriptionCancellationColumns(st *storeImplementation) error {
+	if err := st.dropIndexes(st.subscriptionTableName, subscriptionCancellationIndexes()); err != nil {
+		return err
+	}
+	return st.dropColumns(st.subscriptionTableName, []string{
+		COLUMN_CANCELLATION_REQUESTED_AT,
+		COLUMN_CANCELLATION_EFFECTIVE_AT,
+		COLUMN_CANCELLATION_REASON,
+		COLUMN_CANCELLATION_FEEDBACK,
+		COLUMN_CANCELLED_BY,
+	})
+}
+
+func migrationCreateSubscriberTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriberTableName,
+			create:  true,
+			define:  subscriberTableDefinition,
+			indexes: subscriberIndexes(),
+		},
+	}
+}
+
+func migrationDropSubscriberTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.subscriberTableName)
+}
+
+func migrationAddSubscriptionCurrencyColumn(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.subscriptionTableName,
+			columns: []string{COLUMN_CURRENCY},
+			define:  subscriptionCurrencyColumnDefinition,
+		},
+	}
+}
+
+// migrationFillSubscriptionCurrencies fills in the currency of the existing
+// subscriptions from their plans
+func migrationFillSubscriptionCurrencies(st *storeImplementation) error {
+	type planRow struct {
+		ID       string `db:"id"`
+		Currency string `db:"currency"`
+	}
+
+	var plans []planRow
+	if err := st.query().Table(st.planTableName).Get(&plans); err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		if plan.Currency == "" {
+			continue
+		}
+
+		_, err := st.query().Table(st.subscriptionTableName).
+			Where(COLUMN_PLAN_ID+" = ?", plan.ID).
+			Where(COLUMN_CURRENCY+" = ?", "").
+			Update(map[string]any{COLUMN_CURRENCY: plan.Currency})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrationDropSubscriptionCurrencyColumn(st *storeImplementation) error {
+	return st.dropColumns(st.subscriptionTableName, []string{COLUMN_CURRENCY})
+}
+
+func migrationCreatePaymentMethodTable(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.paymentMethodTableName,
+			create:  true,
+			define:  paymentMethodTableDefinition,
+			indexes: paymentMethodIndexes(),
+		},
+	}
+}
+
+func migrationDropPaymentMethodTable(st *storeImplementation) error {
+	return st.dropTableIfExists(st.paymentMethodTableName)
+}
+
+func migrationAddSubscriptionItemUniqueIndex(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.subscriptionItemTableName, uniqueIndexes: subscriptionItemUniqueIndexes()},
+	}
+}
+
+func migrationDropSubscriptionItemUniqueIndex(st *storeImplementation) error {
+	return st.dropUniqueIndexes(st.subscriptionItemTableName, subscriptionItemUniqueIndexes())
+}
+
+// migrationAddPlanVersionUniqueIndex replaces the index on the plan and
+// version columns by a unique index on the same columns
+func migrationAddPlanVersionUniqueIndex(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.planVersionTableName, uniqueIndexes: planVersionUniqueIndexes(), dropIndexes: planVersionIndexes()},
+	}
+}
+
+func migrationDropPlanVersionUniqueIndex(st *storeImplementation) error {
+	if err := st.migrateIndexes(st.planVersionTableName, planVersionIndexes()); err != nil {
+		return err
+	}
+	return st.dropUniqueIndexes(st.planVersionTableName, planVersionUniqueIndexes())
+}
+
+func migrationAddInvoiceVoidIDColumn(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{
+			table:   st.invoiceTableName,
+			columns: []string{COLUMN_VOID_ID},
+			define:  invoiceVoidIDColumnDefinition,
+		},
+	}
+}
+
+// migrationFillInvoiceVoidIDs sets the void id of the existing void
+// invoices, so they do not count against the unique index on the period
+func migrationFillInvoiceVoidIDs(st *storeImplementation) error {
+	type invoiceRow struct {
+		ID string `db:"id"`
+	}
+
+	var invoices []invoiceRow
+	err := st.query().Table(st.invoiceTableName).
+		Where(COLUMN_STATUS+" = ?", INVOICE_STATUS_VOID).
+		Where(COLUMN_VOID_ID+" = ?", "").
+		Get(&invoices)
+	if err != nil {
+		return err
+	}
+
+	for _, invoice := range invoices {
+		_, err := st.query().Table(st.invoiceTableName).
+			Where(COLUMN_ID+" = ?", invoice.ID).
+			Update(map[string]any{COLUMN_VOID_ID: invoice.ID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrationDropInvoiceVoidIDColumn(st *storeImplementation) error {
+	return st.dropColumns(st.invoiceTableName, []string{COLUMN_VOID_ID})
+}
+
+func migrationAddInvoicePeriodUniqueIndex(st *storeImplementation) []migrationSchema {
+	return []migrationSchema{
+		{table: st.invoiceTableName, uniqueIndexes: invoiceUniqueIndexes()},
+	}
+}
+
+func migrationDropInvoicePeriodUniqueIndex(st *storeImplementation) error {
+	return st.dropUniqueIndexes(st.invoiceTableName, invoiceUniqueIndexes())
+}
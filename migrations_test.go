@@ -0,0 +1,322 @@
+package subscriptionstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreMigrationStatus(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	states, err := store.MigrationStatus(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(states) != len(migrations()) {
+		t.Fatalf("expected %d migrations, got %d", len(migrations()), len(states))
+	}
+
+	for i, state := range states {
+		if state.ID != migrations()[i].id {
+			t.Errorf("expected migration %s at position %d, got %s", migrations()[i].id, i, state.ID)
+		}
+		if !state.Applied {
+			t.Errorf("expected migration %s to be applied", state.ID)
+		}
+		if state.AppliedAt == "" {
+			t.Errorf("expected migration %s to have applied at", state.ID)
+		}
+	}
+}
+
+func TestStoreMigrateUpIsIdempotent(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	if err := store.MigrateUp(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+	var count int64
+	if err := st.db.Query().Table(st.migrationTableName).Count(&count); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != int64(len(migrations())) {
+		t.Fatalf("expected %d migration records, got %d", len(migrations()), count)
+	}
+}
+
+func TestStoreMigrateUpRecordsExistingTables(t *testing.T) {
+	db := initDB(":memory:")
+	store, err := NewStore(NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+
+	// Tables created by an older version of the store, without migration records
+	if err := st.migrationApply(migrations()[0]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := st.migrationApply(migrations()[1]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	plan := NewPlan().SetTitle("Legacy Plan")
+	ctx := context.Background()
+
+	now := carbon.Now(carbon.UTC).StdTime()
+	maxDatetime := carbon.Parse(MAX_DATETIME, carbon.UTC).StdTime()
+	err = st.db.Query().Table(st.planTableName).Create(map[string]any{
+		COLUMN_ID:              plan.GetID(),
+		COLUMN_TYPE:            "",
+		COLUMN_STATUS:          PLAN_STATUS_ACTIVE,
+		COLUMN_TITLE:           plan.GetTitle(),
+		COLUMN_DESCRIPTION:     "",
+		COLUMN_INTERVAL:        PLAN_INTERVAL_MONTHLY,
+		COLUMN_CURRENCY:        CURRENCY_USD,
+		COLUMN_PRICE:           "9.99",
+		COLUMN_STRIPE_PRICE_ID: "",
+		COLUMN_FEATURES:        "",
+		COLUMN_MEMO:            "",
+		COLUMN_METAS:           "",
+		COLUMN_CREATED_AT:      now,
+		COLUMN_UPDATED_AT:      now,
+		COLUMN_SOFT_DELETED_AT: maxDatetime,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = st.db.Query().Table(st.subscriptionTableName).Create(map[string]any{
+		COLUMN_ID:                   "legacy_subscription",
+		COLUMN_STATUS:               SUBSCRIPTION_STATUS_ACTIVE,
+		COLUMN_SUBSCRIBER_ID:        "user_1",
+		COLUMN_PLAN_ID:              plan.GetID(),
+		COLUMN_PERIOD_START:         now,
+		COLUMN_PERIOD_END:           maxDatetime,
+		COLUMN_CANCEL_AT_PERIOD_END: NO,
+		COLUMN_PAYMENT_METHOD_ID:    "",
+		COLUMN_MEMO:                 "",
+		COLUMN_METAS:                "",
+		COLUMN_CREATED_AT:           now,
+		COLUMN_UPDATED_AT:           now,
+		COLUMN_SOFT_DELETED_AT:      maxDatetime,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.MigrateUp(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	states, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for _, state := range states {
+		if !state.Applied {
+			t.Errorf("expected migration %s to be applied", state.ID)
+		}
+	}
+
+	planFound, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if planFound == nil {
+		t.Fatal("existing data MUST be kept by the migration")
+	}
+	if planFound.GetPricingModel() != PLAN_PRICING_MODEL_PER_UNIT {
+		t.Errorf("expected pricing model %s, got %s", PLAN_PRICING_MODEL_PER_UNIT, planFound.GetPricingModel())
+	}
+
+	subscriptionFound, err := store.SubscriptionFindByID(ctx, "legacy_subscription")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscriptionFound == nil {
+		t.Fatal("existing data MUST be kept by the migration")
+	}
+	if subscriptionFound.GetResumeAt() != MAX_DATETIME {
+		t.Errorf("expected ResumeAt %s, got %s", MAX_DATETIME, subscriptionFound.GetResumeAt())
+	}
+	if subscriptionFound.GetQuantity() != 1 {
+		t.Errorf("expected Quantity 1, got %d", subscriptionFound.GetQuantity())
+	}
+	if subscriptionFound.GetCancellationRequestedAt() != MAX_DATETIME || subscriptionFound.GetCancellationReason() != "" {
+		t.Errorf("expected no cancellation, got %s / %s", subscriptionFound.GetCancellationRequestedAt(), subscriptionFound.GetCancellationReason())
+	}
+	if subscriptionFound.GetCurrency() != CURRENCY_USD {
+		t.Errorf("expected the currency of the plan %s, got %s", CURRENCY_USD, subscriptionFound.GetCurrency())
+	}
+
+	versions, err := store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID(plan.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 1 || versions[0].GetPrice() != "9.99" {
+		t.Fatalf("expected the existing plan recorded as version 1, got %d versions", len(versions))
+	}
+	if subscriptionFound.GetPlanVersionID() != versions[0].GetID() {
+		t.Errorf("expected PlanVersionID %s, got %s", versions[0].GetID(), subscriptionFound.GetPlanVersionID())
+	}
+}
+
+func TestStoreMigrateDown(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	if err := store.MigrateDown(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+	for _, table := range []string{st.planTableName, st.subscriptionTableName, st.migrationTableName} {
+		if st.db.Schema().HasTable(table) {
+			t.Errorf("expected table %s to be dropped", table)
+		}
+	}
+
+	states, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for _, state := range states {
+		if state.Applied {
+			t.Errorf("expected migration %s NOT to be applied", state.ID)
+		}
+	}
+
+	if err := store.MigrateUp(ctx); err != nil {
+		t.Fatal("unexpected error migrating up again:", err)
+	}
+}
+
+func TestStoreMigrateUpRollsBackFailedMigration(t *testing.T) {
+	db := initDB(":memory:")
+	store, err := NewStore(NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	long := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if _, err := long.SetMeta("tenant_id", strings.Repeat("a", metaColumnLength+1)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCreate(ctx, long); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The promoted column is added, then cannot be filled in
+	store, err = NewStore(NewStoreOptions{
+		DB:                      db,
+		PlanTableName:           "plan_table",
+		SubscriptionTableName:   "subscription_table",
+		SubscriptionMetaColumns: []string{"tenant_id"},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.MigrateUp(ctx); err == nil {
+		t.Fatal("expected error for a meta value longer than its column")
+	}
+
+	st := store.(*storeImplementation)
+	if st.db.Schema().HasColumn(st.subscriptionTableName, "meta_tenant_id") {
+		t.Error("expected the column of the failed migration to be rolled back")
+	}
+
+	states, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if last := states[len(states)-1]; last.ID != "subscription_meta_column_tenant_id" || last.Applied {
+		t.Errorf("expected the failed migration NOT to be recorded, got %+v", last)
+	}
+}
+
+func TestStoreMigrateUpInTransaction(t *testing.T) {
+	db := initDB(":memory:")
+	store, err := NewStore(NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	st := store.(*storeImplementation)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.MigrateUp(ctx, tx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for _, table := range []string{st.planTableName, st.subscriptionTableName, st.migrationTableName} {
+		if st.db.Schema().HasTable(table) {
+			t.Errorf("expected table %s to be rolled back with the transaction", table)
+		}
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.MigrateUp(ctx, tx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	states, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for _, state := range states {
+		if !state.Applied {
+			t.Errorf("expected migration %s to be applied", state.ID)
+		}
+	}
+
+	if err := store.PlanCreate(ctx, NewPlan().SetTitle("Plan").SetStatus(PLAN_STATUS_ACTIVE)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
@@ -0,0 +1,227 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// PaymentMethodInterface defines the methods for a PaymentMethod entity.
+// A payment method belongs to a subscriber, and its ID is the payment method
+// ID of the subscriptions charged to it, i.e. the ID of the payment method at
+// the provider.
+type PaymentMethodInterface interface {
+	GetBrand() string
+	SetBrand(brand string) PaymentMethodInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) PaymentMethodInterface
+
+	GetDefault() bool
+	SetDefault(isDefault bool) PaymentMethodInterface
+
+	GetExpMonth() int
+	SetExpMonth(expMonth int) PaymentMethodInterface
+
+	GetExpYear() int
+	SetExpYear(expYear int) PaymentMethodInterface
+
+	GetExpiresAt() string
+	GetExpiresAtCarbon() *carbon.Carbon
+
+	GetID() string
+	SetID(id string) PaymentMethodInterface
+
+	GetLast4() string
+	SetLast4(last4 string) PaymentMethodInterface
+
+	GetProvider() string
+	SetProvider(provider string) PaymentMethodInterface
+
+	GetSubscriberID() string
+	SetSubscriberID(subscriberID string) PaymentMethodInterface
+
+	GetType() string
+	SetType(type_ string) PaymentMethodInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) PaymentMethodInterface
+}
+
+var _ PaymentMethodInterface = (*paymentMethodImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type paymentMethodImplementation struct {
+	orm.ShortID
+
+	SubscriberIDField string `db:"subscriber_id"`
+	ProviderField     string `db:"provider"`
+	TypeField         string `db:"type"`
+	BrandField        string `db:"brand"`
+	Last4Field        string `db:"last4"`
+	ExpMonthField     int    `db:"exp_month"`
+	ExpYearField      int    `db:"exp_year"`
+	DefaultField      string `db:"is_default"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewPaymentMethod() PaymentMethodInterface {
+	o := &paymentMethodImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetSubscriberID("")
+	o.SetProvider("")
+	o.SetType(PAYMENT_METHOD_TYPE_CARD)
+	o.SetBrand("")
+	o.SetLast4("")
+	o.SetExpMonth(0)
+	o.SetExpYear(0)
+	o.DefaultField = NO
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *paymentMethodImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *paymentMethodImplementation) SetID(id string) PaymentMethodInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *paymentMethodImplementation) GetSubscriberID() string {
+	return o.SubscriberIDField
+}
+
+func (o *paymentMethodImplementation) SetSubscriberID(subscriberID string) PaymentMethodInterface {
+	o.SubscriberIDField = subscriberID
+	return o
+}
+
+func (o *paymentMethodImplementation) GetProvider() string {
+	return o.ProviderField
+}
+
+func (o *paymentMethodImplementation) SetProvider(provider string) PaymentMethodInterface {
+	o.ProviderField = provider
+	return o
+}
+
+func (o *paymentMethodImplementation) GetType() string {
+	return o.TypeField
+}
+
+func (o *paymentMethodImplementation) SetType(type_ string) PaymentMethodInterface {
+	o.TypeField = type_
+	return o
+}
+
+func (o *paymentMethodImplementation) GetBrand() string {
+	return o.BrandField
+}
+
+func (o *paymentMethodImplementation) SetBrand(brand string) PaymentMethodInterface {
+	o.BrandField = brand
+	return o
+}
+
+func (o *paymentMethodImplementation) GetLast4() string {
+	return o.Last4Field
+}
+
+func (o *paymentMethodImplementation) SetLast4(last4 string) PaymentMethodInterface {
+	o.Last4Field = last4
+	return o
+}
+
+func (o *paymentMethodImplementation) GetExpMonth() int {
+	return o.ExpMonthField
+}
+
+func (o *paymentMethodImplementation) SetExpMonth(expMonth int) PaymentMethodInterface {
+	o.ExpMonthField = expMonth
+	return o
+}
+
+func (o *paymentMethodImplementation) GetExpYear() int {
+	return o.ExpYearField
+}
+
+func (o *paymentMethodImplementation) SetExpYear(expYear int) PaymentMethodInterface {
+	o.ExpYearField = expYear
+	return o
+}
+
+func (o *paymentMethodImplementation) GetDefault() bool {
+	return o.DefaultField == YES
+}
+
+func (o *paymentMethodImplementation) SetDefault(isDefault bool) PaymentMethodInterface {
+	if isDefault {
+		o.DefaultField = YES
+	} else {
+		o.DefaultField = NO
+	}
+	return o
+}
+
+// GetExpiresAt returns the end of the expiry month, or MAX_DATETIME if the
+// payment method does not expire
+func (o *paymentMethodImplementation) GetExpiresAt() string {
+	if o.GetExpMonth() < 1 || o.GetExpMonth() > 12 || o.GetExpYear() < 1 {
+		return MAX_DATETIME
+	}
+	return carbon.CreateFromDate(o.GetExpYear(), o.GetExpMonth(), 1, carbon.UTC).EndOfMonth().ToDateTimeString(carbon.UTC)
+}
+
+func (o *paymentMethodImplementation) GetExpiresAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetExpiresAt(), carbon.UTC)
+}
+
+func (o *paymentMethodImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *paymentMethodImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *paymentMethodImplementation) SetCreatedAt(createdAt string) PaymentMethodInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *paymentMethodImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *paymentMethodImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *paymentMethodImplementation) SetUpdatedAt(updatedAt string) PaymentMethodInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
@@ -0,0 +1,229 @@
+package subscriptionstore
+
+import "errors"
+
+// PaymentMethodQueryInterface defines the interface for querying payment methods.
+type PaymentMethodQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) PaymentMethodQueryInterface
+
+	HasSubscriberID() bool
+	SubscriberID() string
+	SetSubscriberID(subscriberID string) PaymentMethodQueryInterface
+
+	HasProvider() bool
+	Provider() string
+	SetProvider(provider string) PaymentMethodQueryInterface
+
+	HasType() bool
+	Type() string
+	SetType(type_ string) PaymentMethodQueryInterface
+
+	HasExpiresAtGte() bool
+	ExpiresAtGte() string
+	SetExpiresAtGte(expiresAtGte string) PaymentMethodQueryInterface
+
+	HasExpiresAtLte() bool
+	ExpiresAtLte() string
+	SetExpiresAtLte(expiresAtLte string) PaymentMethodQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) PaymentMethodQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) PaymentMethodQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) PaymentMethodQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) PaymentMethodQueryInterface
+}
+
+// PaymentMethodQuery is a shortcut alias for NewPaymentMethodQuery
+func PaymentMethodQuery() PaymentMethodQueryInterface {
+	return NewPaymentMethodQuery()
+}
+
+// NewPaymentMethodQuery creates a new payment method query
+func NewPaymentMethodQuery() PaymentMethodQueryInterface {
+	return &paymentMethodQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ PaymentMethodQueryInterface = (*paymentMethodQueryImplementation)(nil)
+
+type paymentMethodQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *paymentMethodQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("payment method query. id cannot be empty")
+	}
+	if q.HasSubscriberID() && q.SubscriberID() == "" {
+		return errors.New("payment method query. subscriber_id cannot be empty")
+	}
+	if q.HasProvider() && q.Provider() == "" {
+		return errors.New("payment method query. provider cannot be empty")
+	}
+	if q.HasType() && q.Type() == "" {
+		return errors.New("payment method query. type cannot be empty")
+	}
+	if q.HasExpiresAtGte() && q.ExpiresAtGte() == "" {
+		return errors.New("payment method query. expires_at_gte cannot be empty")
+	}
+	if q.HasExpiresAtLte() && q.ExpiresAtLte() == "" {
+		return errors.New("payment method query. expires_at_lte cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("payment method query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("payment method query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *paymentMethodQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *paymentMethodQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetID(id string) PaymentMethodQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasSubscriberID() bool {
+	return q.hasProperty("subscriber_id")
+}
+
+func (q *paymentMethodQueryImplementation) SubscriberID() string {
+	return q.properties["subscriber_id"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetSubscriberID(subscriberID string) PaymentMethodQueryInterface {
+	q.properties["subscriber_id"] = subscriberID
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasProvider() bool {
+	return q.hasProperty("provider")
+}
+
+func (q *paymentMethodQueryImplementation) Provider() string {
+	return q.properties["provider"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetProvider(provider string) PaymentMethodQueryInterface {
+	q.properties["provider"] = provider
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasType() bool {
+	return q.hasProperty("type")
+}
+
+func (q *paymentMethodQueryImplementation) Type() string {
+	return q.properties["type"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetType(type_ string) PaymentMethodQueryInterface {
+	q.properties["type"] = type_
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasExpiresAtGte() bool {
+	return q.hasProperty("expires_at_gte")
+}
+
+func (q *paymentMethodQueryImplementation) ExpiresAtGte() string {
+	return q.properties["expires_at_gte"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetExpiresAtGte(expiresAtGte string) PaymentMethodQueryInterface {
+	q.properties["expires_at_gte"] = expiresAtGte
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasExpiresAtLte() bool {
+	return q.hasProperty("expires_at_lte")
+}
+
+func (q *paymentMethodQueryImplementation) ExpiresAtLte() string {
+	return q.properties["expires_at_lte"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetExpiresAtLte(expiresAtLte string) PaymentMethodQueryInterface {
+	q.properties["expires_at_lte"] = expiresAtLte
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *paymentMethodQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *paymentMethodQueryImplementation) SetOffset(offset int) PaymentMethodQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *paymentMethodQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *paymentMethodQueryImplementation) SetLimit(limit int) PaymentMethodQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *paymentMethodQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetOrderBy(orderBy string) PaymentMethodQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *paymentMethodQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *paymentMethodQueryImplementation) SetSortOrder(sortOrder string) PaymentMethodQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *paymentMethodQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
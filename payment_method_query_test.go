@@ -0,0 +1,49 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaymentMethodQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(PaymentMethodQueryInterface)
+		contains string
+	}{
+		{
+			name:     "subscriber id empty",
+			setup:    func(q PaymentMethodQueryInterface) { q.SetSubscriberID("") },
+			contains: "subscriber_id cannot be empty",
+		},
+		{
+			name:     "type empty",
+			setup:    func(q PaymentMethodQueryInterface) { q.SetType("") },
+			contains: "type cannot be empty",
+		},
+		{
+			name:     "expires at lte empty",
+			setup:    func(q PaymentMethodQueryInterface) { q.SetExpiresAtLte("") },
+			contains: "expires_at_lte cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q PaymentMethodQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewPaymentMethodQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
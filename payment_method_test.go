@@ -0,0 +1,54 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewPaymentMethodDefaults(t *testing.T) {
+	paymentMethod := NewPaymentMethod()
+
+	if paymentMethod.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if paymentMethod.GetType() != PAYMENT_METHOD_TYPE_CARD {
+		t.Fatalf("expected type %s, got %s", PAYMENT_METHOD_TYPE_CARD, paymentMethod.GetType())
+	}
+	if paymentMethod.GetDefault() {
+		t.Fatal("expected not to be the default")
+	}
+	if paymentMethod.GetExpiresAt() != MAX_DATETIME {
+		t.Fatalf("expected no expiry, got %s", paymentMethod.GetExpiresAt())
+	}
+	if paymentMethod.GetCreatedAt() == "" || paymentMethod.GetUpdatedAt() == "" {
+		t.Fatal("expected created and updated dates to be set")
+	}
+}
+
+func TestPaymentMethodSettersAndGetters(t *testing.T) {
+	paymentMethod := NewPaymentMethod().
+		SetID("pm_1").
+		SetSubscriberID("user_1").
+		SetProvider(PAYMENT_PROVIDER_STRIPE).
+		SetType(PAYMENT_METHOD_TYPE_CARD).
+		SetBrand("visa").
+		SetLast4("4242").
+		SetExpMonth(2).
+		SetExpYear(2028).
+		SetDefault(true)
+
+	if paymentMethod.GetID() != "pm_1" || paymentMethod.GetSubscriberID() != "user_1" {
+		t.Fatalf("unexpected IDs %s / %s", paymentMethod.GetID(), paymentMethod.GetSubscriberID())
+	}
+	if paymentMethod.GetProvider() != PAYMENT_PROVIDER_STRIPE || paymentMethod.GetBrand() != "visa" || paymentMethod.GetLast4() != "4242" {
+		t.Fatalf("unexpected card %s / %s / %s", paymentMethod.GetProvider(), paymentMethod.GetBrand(), paymentMethod.GetLast4())
+	}
+	if paymentMethod.GetExpMonth() != 2 || paymentMethod.GetExpYear() != 2028 {
+		t.Fatalf("unexpected expiry %d/%d", paymentMethod.GetExpMonth(), paymentMethod.GetExpYear())
+	}
+	if !paymentMethod.GetDefault() {
+		t.Fatal("expected to be the default")
+	}
+
+	// Cards expire at the end of their expiry month
+	if paymentMethod.GetExpiresAt() != "2028-02-29 23:59:59" {
+		t.Fatalf("expected expiry at 2028-02-29 23:59:59, got %s", paymentMethod.GetExpiresAt())
+	}
+}
@@ -0,0 +1,152 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+)
+
+// PaymentProvider is a payment service provider, such as Stripe, Paddle or
+// PayPal, which bills the subscriptions of the store.
+//
+// Implementations only talk to the provider. The mapping between the local
+// objects and the objects of the provider is kept in the provider reference
+// table, so the same plans can be billed by several providers.
+type PaymentProvider interface {
+	// Name returns the name of the provider, i.e. PAYMENT_PROVIDER_STRIPE
+	Name() string
+
+	// CustomerCreate creates the customer of the subscriber, and returns its id
+	CustomerCreate(ctx context.Context, subscriberID string) (string, error)
+
+	// PlanCreate creates the plan and its price, and returns their ids
+	PlanCreate(ctx context.Context, plan PlanInterface) (planID string, priceID string, err error)
+
+	// SubscriptionCreate subscribes the customer to the price, and returns the
+	// id of the subscription
+	SubscriptionCreate(ctx context.Context, customerID string, priceID string) (string, error)
+
+	// SubscriptionCancel cancels the subscription, immediately or at the end
+	// of the current billing period
+	SubscriptionCancel(ctx context.Context, subscriptionID string, atPeriodEnd bool) error
+}
+
+// ProviderSubscriptionCreate creates the subscription at the payment provider.
+// The customer of the subscriber and the plan are created at the provider
+// first, unless they are already referenced, and every created object is
+// recorded in the provider reference table.
+func ProviderSubscriptionCreate(ctx context.Context, store StoreInterface, provider PaymentProvider, subscription SubscriptionInterface) (ProviderReferenceInterface, error) {
+	if store == nil || provider == nil {
+		return nil, errors.New("subscriptionstore > provider subscription create. store and provider cannot be nil")
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > provider subscription create. subscription cannot be nil")
+	}
+
+	existing, err := store.ProviderReferenceFindByLocalID(ctx, provider.Name(), PROVIDER_OBJECT_TYPE_SUBSCRIPTION, subscription.GetID())
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("subscriptionstore > provider subscription create. subscription already exists at " + provider.Name())
+	}
+
+	customer, err := providerCustomer(ctx, store, provider, subscription.GetSubscriberID())
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := providerPrice(ctx, store, provider, subscription.GetPlanID())
+	if err != nil {
+		return nil, err
+	}
+
+	externalID, err := provider.SubscriptionCreate(ctx, customer.GetExternalID(), price.GetExternalID())
+	if err != nil {
+		return nil, err
+	}
+
+	return providerReferenceCreate(ctx, store, provider, PROVIDER_OBJECT_TYPE_SUBSCRIPTION, subscription.GetID(), externalID)
+}
+
+// ProviderSubscriptionCancel cancels the subscription at the payment provider
+func ProviderSubscriptionCancel(ctx context.Context, store StoreInterface, provider PaymentProvider, subscription SubscriptionInterface, atPeriodEnd bool) error {
+	if store == nil || provider == nil {
+		return errors.New("subscriptionstore > provider subscription cancel. store and provider cannot be nil")
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > provider subscription cancel. subscription cannot be nil")
+	}
+
+	reference, err := store.ProviderReferenceFindByLocalID(ctx, provider.Name(), PROVIDER_OBJECT_TYPE_SUBSCRIPTION, subscription.GetID())
+	if err != nil {
+		return err
+	}
+	if reference == nil {
+		return errors.New("subscriptionstore > provider subscription cancel. subscription does not exist at " + provider.Name())
+	}
+
+	return provider.SubscriptionCancel(ctx, reference.GetExternalID(), atPeriodEnd)
+}
+
+// providerCustomer returns the reference of the subscriber's customer,
+// creating the customer if it does not exist yet
+func providerCustomer(ctx context.Context, store StoreInterface, provider PaymentProvider, subscriberID string) (ProviderReferenceInterface, error) {
+	if subscriberID == "" {
+		return nil, errors.New("subscriptionstore > provider customer. subscriber id cannot be empty")
+	}
+
+	reference, err := store.ProviderReferenceFindByLocalID(ctx, provider.Name(), PROVIDER_OBJECT_TYPE_CUSTOMER, subscriberID)
+	if err != nil || reference != nil {
+		return reference, err
+	}
+
+	externalID, err := provider.CustomerCreate(ctx, subscriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	return providerReferenceCreate(ctx, store, provider, PROVIDER_OBJECT_TYPE_CUSTOMER, subscriberID, externalID)
+}
+
+// providerPrice returns the reference of the plan's price, creating the
+// plan if it does not exist yet
+func providerPrice(ctx context.Context, store StoreInterface, provider PaymentProvider, planID string) (ProviderReferenceInterface, error) {
+	reference, err := store.ProviderReferenceFindByLocalID(ctx, provider.Name(), PROVIDER_OBJECT_TYPE_PRICE, planID)
+	if err != nil || reference != nil {
+		return reference, err
+	}
+
+	plan, err := store.PlanFindByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > provider price. plan not found")
+	}
+
+	externalPlanID, externalPriceID, err := provider.PlanCreate(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := providerReferenceCreate(ctx, store, provider, PROVIDER_OBJECT_TYPE_PLAN, planID, externalPlanID); err != nil {
+		return nil, err
+	}
+
+	return providerReferenceCreate(ctx, store, provider, PROVIDER_OBJECT_TYPE_PRICE, planID, externalPriceID)
+}
+
+// providerReferenceCreate records the external object of a local object
+func providerReferenceCreate(ctx context.Context, store StoreInterface, provider PaymentProvider, objectType string, localID string, externalID string) (ProviderReferenceInterface, error) {
+	reference := NewProviderReference().
+		SetProvider(provider.Name()).
+		SetObjectType(objectType).
+		SetLocalID(localID).
+		SetExternalID(externalID)
+
+	if err := store.ProviderReferenceCreate(ctx, reference); err != nil {
+		return nil, err
+	}
+
+	return reference, nil
+}
@@ -0,0 +1,144 @@
+package subscriptionstore_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/subscriptionstore/providertest"
+	_ "modernc.org/sqlite"
+)
+
+func initProviderStore(t *testing.T) subscriptionstore.StoreInterface {
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true&loc=UTC&_loc=UTC")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := subscriptionstore.NewStore(subscriptionstore.NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return store
+}
+
+func createSubscription(t *testing.T, store subscriptionstore.StoreInterface, subscriberID string, planID string) subscriptionstore.SubscriptionInterface {
+	subscription := subscriptionstore.NewSubscription().
+		SetSubscriberID(subscriberID).
+		SetPlanID(planID).
+		SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(context.Background(), subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return subscription
+}
+
+func TestProviderSubscriptionCreate(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+
+	plan := subscriptionstore.NewPlan().SetTitle("Pro").SetPrice("19.99").SetInterval(subscriptionstore.PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	stripe := providertest.NewFakeProvider(subscriptionstore.PAYMENT_PROVIDER_STRIPE)
+	paddle := providertest.NewFakeProvider(subscriptionstore.PAYMENT_PROVIDER_PADDLE)
+
+	first := createSubscription(t, store, "user_1", plan.GetID())
+	second := createSubscription(t, store, "user_1", plan.GetID())
+
+	reference, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, stripe, first)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, stripe, second); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The customer and the plan are created once per provider
+	if len(stripe.Customers()) != 1 || len(stripe.Plans()) != 1 {
+		t.Fatalf("expected 1 customer and 1 plan, got %d and %d", len(stripe.Customers()), len(stripe.Plans()))
+	}
+
+	external, ok := stripe.Subscription(reference.GetExternalID())
+	if !ok {
+		t.Fatalf("expected subscription %s at the provider", reference.GetExternalID())
+	}
+
+	price, err := store.ProviderReferenceFindByLocalID(ctx, stripe.Name(), subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price == nil || external.PriceID != price.GetExternalID() {
+		t.Fatalf("expected the subscription to be billed by the referenced price, got %v", price)
+	}
+
+	// The same plan is mapped to another provider independently
+	if _, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, paddle, first); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(paddle.Plans()) != 1 {
+		t.Fatalf("expected the plan to be created at paddle, got %d plans", len(paddle.Plans()))
+	}
+
+	if _, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, stripe, first); err == nil {
+		t.Error("expected error creating the same subscription twice")
+	}
+
+	if err := subscriptionstore.ProviderSubscriptionCancel(ctx, store, stripe, first, true); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	external, _ = stripe.Subscription(reference.GetExternalID())
+	if !external.Cancelled || !external.AtPeriodEnd {
+		t.Errorf("expected the subscription to be cancelled at period end, got %+v", external)
+	}
+}
+
+func TestProviderSubscriptionCreateProviderFailure(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+
+	plan := subscriptionstore.NewPlan().SetTitle("Pro")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	subscription := createSubscription(t, store, "user_1", plan.GetID())
+
+	provider := providertest.NewFakeProvider(subscriptionstore.PAYMENT_PROVIDER_PAYPAL)
+	provider.FailWith(errors.New("provider unavailable"))
+
+	if _, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, provider, subscription); err == nil {
+		t.Fatal("expected the provider error")
+	}
+
+	references, err := store.ProviderReferenceList(ctx, subscriptionstore.ProviderReferenceQuery().SetProvider(provider.Name()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(references) != 0 {
+		t.Fatalf("expected no references after a failure, got %d", len(references))
+	}
+
+	provider.FailWith(nil)
+	if _, err := subscriptionstore.ProviderSubscriptionCreate(ctx, store, provider, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestProviderSubscriptionCancelWithoutReference(t *testing.T) {
+	store := initProviderStore(t)
+	subscription := createSubscription(t, store, "user_1", "plan_1")
+
+	err := subscriptionstore.ProviderSubscriptionCancel(context.Background(), store, providertest.NewFakeProvider("fake"), subscription, false)
+	if err == nil {
+		t.Fatal("expected error for a subscription which does not exist at the provider")
+	}
+}
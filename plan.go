@@ -48,6 +48,12 @@ type PlanInterface interface {
 	GetPriceFloat() float64
 	SetPrice(price string) PlanInterface
 
+	GetPriceTiers() ([]PriceTier, error)
+	SetPriceTiers(tiers []PriceTier) (PlanInterface, error)
+
+	GetPricingModel() string
+	SetPricingModel(pricingModel string) PlanInterface
+
 	GetSoftDeletedAt() string
 	GetSoftDeletedAtCarbon() *carbon.Carbon
 	SetSoftDeletedAt(deletedAt string) PlanInterface
@@ -83,6 +89,8 @@ type planImplementation struct {
 	IntervalField      string `db:"interval"`
 	CurrencyField      string `db:"currency"`
 	PriceField         string `db:"price"`
+	PricingModelField  string `db:"pricing_model"`
+	PriceTiersField    string `db:"price_tiers"`
 	StripePriceIDField string `db:"stripe_price_id"`
 	FeaturesField      string `db:"features"`
 	MemoField          string `db:"memo"`
@@ -100,6 +108,7 @@ func NewPlan() PlanInterface {
 	o.SetID(neatuid.GenerateShortID())
 	o.SetStatus(PLAN_STATUS_INACTIVE)
 	o.SetStripePriceID("")
+	o.SetPricingModel(PLAN_PRICING_MODEL_PER_UNIT)
 	o.SetDescription("")
 	o.SetFeatures("")
 	o.SetMemo("")
@@ -123,6 +132,8 @@ func NewPlanFromExistingData(data map[string]string) PlanInterface {
 	o.SetInterval(data[COLUMN_INTERVAL])
 	o.SetCurrency(data[COLUMN_CURRENCY])
 	o.SetPrice(data[COLUMN_PRICE])
+	o.SetPricingModel(data[COLUMN_PRICING_MODEL])
+	o.PriceTiersField = data[COLUMN_PRICE_TIERS]
 	o.SetStripePriceID(data[COLUMN_STRIPE_PRICE_ID])
 	o.SetFeatures(data[COLUMN_FEATURES])
 	o.SetMemo(data[COLUMN_MEMO])
@@ -223,6 +234,36 @@ func (o *planImplementation) SetPrice(price string) PlanInterface {
 	return o
 }
 
+func (o *planImplementation) GetPriceTiers() ([]PriceTier, error) {
+	if o.PriceTiersField == "" {
+		return nil, nil
+	}
+	var tiers []PriceTier
+	err := json.Unmarshal([]byte(o.PriceTiersField), &tiers)
+	if err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+func (o *planImplementation) SetPriceTiers(tiers []PriceTier) (PlanInterface, error) {
+	tiersJSON, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+	o.PriceTiersField = tiersJSON
+	return o, nil
+}
+
+func (o *planImplementation) GetPricingModel() string {
+	return o.PricingModelField
+}
+
+func (o *planImplementation) SetPricingModel(pricingModel string) PlanInterface {
+	o.PricingModelField = pricingModel
+	return o
+}
+
 func (o *planImplementation) GetStripePriceID() string {
 	return o.StripePriceIDField
 }
@@ -0,0 +1,31 @@
+package subscriptionstore
+
+import (
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// planIntervalPeriodEnd returns the end of the billing period which starts at
+// periodStart and lasts one plan interval. Plans without an interval never
+// renew, so their period does not end.
+//
+// Months, quarters and years do not overflow, so a period starting on
+// January 31st ends on the last day of February.
+func planIntervalPeriodEnd(periodStart *carbon.Carbon, interval string) (*carbon.Carbon, error) {
+	switch interval {
+	case PLAN_INTERVAL_DAILY:
+		return periodStart.AddDay(), nil
+	case PLAN_INTERVAL_WEEKLY:
+		return periodStart.AddWeek(), nil
+	case PLAN_INTERVAL_MONTHLY:
+		return periodStart.AddMonthNoOverflow(), nil
+	case PLAN_INTERVAL_QUARTERLY:
+		return periodStart.AddQuarterNoOverflow(), nil
+	case PLAN_INTERVAL_YEARLY:
+		return periodStart.AddYearNoOverflow(), nil
+	case PLAN_INTERVAL_NONE:
+		return carbon.Parse(MAX_DATETIME, carbon.UTC), nil
+	}
+	return nil, errors.New("unsupported plan interval: " + interval)
+}
@@ -0,0 +1,38 @@
+package subscriptionstore
+
+import (
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestPlanIntervalPeriodEnd(t *testing.T) {
+	testCases := []struct {
+		interval string
+		start    string
+		expected string
+	}{
+		{PLAN_INTERVAL_DAILY, "2025-01-31 10:00:00", "2025-02-01 10:00:00"},
+		{PLAN_INTERVAL_WEEKLY, "2025-01-31 10:00:00", "2025-02-07 10:00:00"},
+		{PLAN_INTERVAL_MONTHLY, "2025-01-31 10:00:00", "2025-02-28 10:00:00"},
+		{PLAN_INTERVAL_QUARTERLY, "2025-01-15 10:00:00", "2025-04-15 10:00:00"},
+		{PLAN_INTERVAL_YEARLY, "2024-02-29 10:00:00", "2025-02-28 10:00:00"},
+		{PLAN_INTERVAL_NONE, "2025-01-31 10:00:00", MAX_DATETIME},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.interval, func(t *testing.T) {
+			end, err := planIntervalPeriodEnd(carbon.Parse(tc.start, carbon.UTC), tc.interval)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if end.ToDateTimeString(carbon.UTC) != tc.expected {
+				t.Fatalf("expected period end %s, got %s", tc.expected, end.ToDateTimeString(carbon.UTC))
+			}
+		})
+	}
+
+	if _, err := planIntervalPeriodEnd(carbon.Now(carbon.UTC), "fortnightly"); err == nil {
+		t.Fatal("expected error for an unsupported interval")
+	}
+}
@@ -34,10 +34,22 @@ type PlanQueryInterface interface {
 	IntervalIn() []string
 	SetIntervalIn(intervalIn []string) PlanQueryInterface
 
+	HasStripePriceID() bool
+	StripePriceID() string
+	SetStripePriceID(stripePriceID string) PlanQueryInterface
+
 	HasType() bool
 	Type() string
 	SetType(type_ string) PlanQueryInterface
 
+	HasMetaEquals() bool
+	MetaEquals() map[string]string
+	SetMetaEquals(key string, value string) PlanQueryInterface
+
+	HasMetaHasKey() bool
+	MetaHasKey() []string
+	SetMetaHasKey(key string) PlanQueryInterface
+
 	HasOffset() bool
 	Offset() int
 	SetOffset(offset int) PlanQueryInterface
@@ -96,9 +108,26 @@ func (q *planQueryImplementation) Validate() error {
 	if q.HasIntervalIn() && len(q.IntervalIn()) < 1 {
 		return errors.New("plan query. interval_in cannot be empty array")
 	}
+	if q.HasStripePriceID() && q.StripePriceID() == "" {
+		return errors.New("plan query. stripe_price_id cannot be empty")
+	}
 	if q.HasType() && q.Type() == "" {
 		return errors.New("plan query. type cannot be empty")
 	}
+	if q.HasMetaEquals() {
+		for key := range q.MetaEquals() {
+			if key == "" {
+				return errors.New("plan query. meta_equals key cannot be empty")
+			}
+		}
+	}
+	if q.HasMetaHasKey() {
+		for _, key := range q.MetaHasKey() {
+			if key == "" {
+				return errors.New("plan query. meta_has_key key cannot be empty")
+			}
+		}
+	}
 	if q.HasLimit() && q.Limit() < 0 {
 		return errors.New("plan query. limit cannot be negative")
 	}
@@ -199,6 +228,19 @@ func (q *planQueryImplementation) SetIntervalIn(intervalIn []string) PlanQueryIn
 	return q
 }
 
+func (q *planQueryImplementation) HasStripePriceID() bool {
+	return q.hasProperty("stripe_price_id")
+}
+
+func (q *planQueryImplementation) StripePriceID() string {
+	return q.properties["stripe_price_id"].(string)
+}
+
+func (q *planQueryImplementation) SetStripePriceID(stripePriceID string) PlanQueryInterface {
+	q.properties["stripe_price_id"] = stripePriceID
+	return q
+}
+
 func (q *planQueryImplementation) HasType() bool {
 	return q.hasProperty("type")
 }
@@ -212,6 +254,42 @@ func (q *planQueryImplementation) SetType(type_ string) PlanQueryInterface {
 	return q
 }
 
+func (q *planQueryImplementation) HasMetaEquals() bool {
+	return q.hasProperty("meta_equals")
+}
+
+func (q *planQueryImplementation) MetaEquals() map[string]string {
+	return q.properties["meta_equals"].(map[string]string)
+}
+
+// SetMetaEquals filters on the meta key having the value. Repeated calls
+// filter on all of the keys.
+func (q *planQueryImplementation) SetMetaEquals(key string, value string) PlanQueryInterface {
+	if !q.HasMetaEquals() {
+		q.properties["meta_equals"] = map[string]string{}
+	}
+	q.MetaEquals()[key] = value
+	return q
+}
+
+func (q *planQueryImplementation) HasMetaHasKey() bool {
+	return q.hasProperty("meta_has_key")
+}
+
+func (q *planQueryImplementation) MetaHasKey() []string {
+	return q.properties["meta_has_key"].([]string)
+}
+
+// SetMetaHasKey filters on the meta key being set. Repeated calls filter on
+// all of the keys.
+func (q *planQueryImplementation) SetMetaHasKey(key string) PlanQueryInterface {
+	if !q.HasMetaHasKey() {
+		q.properties["meta_has_key"] = []string{}
+	}
+	q.properties["meta_has_key"] = append(q.MetaHasKey(), key)
+	return q
+}
+
 func (q *planQueryImplementation) HasOffset() bool {
 	return q.hasProperty("offset")
 }
@@ -13,6 +13,7 @@ func TestPlanQuerySettersAndGetters(t *testing.T) {
 		SetStatusIn([]string{PLAN_STATUS_ACTIVE, PLAN_STATUS_INACTIVE}).
 		SetInterval(PLAN_INTERVAL_MONTHLY).
 		SetIntervalIn([]string{PLAN_INTERVAL_MONTHLY, PLAN_INTERVAL_YEARLY}).
+		SetStripePriceID("price_1").
 		SetType(PLAN_TYPE_GOLD).
 		SetOffset(5).
 		SetLimit(10).
@@ -39,6 +40,9 @@ func TestPlanQuerySettersAndGetters(t *testing.T) {
 	if !query.HasIntervalIn() || len(query.IntervalIn()) != 2 {
 		t.Fatalf("expected HasIntervalIn true with 2 items")
 	}
+	if !query.HasStripePriceID() || query.StripePriceID() != "price_1" {
+		t.Fatalf("expected HasStripePriceID true with value price_1")
+	}
 	if !query.HasType() || query.Type() != PLAN_TYPE_GOLD {
 		t.Fatalf("expected HasType true with value %s", PLAN_TYPE_GOLD)
 	}
@@ -110,6 +114,13 @@ func TestPlanQueryValidateErrors(t *testing.T) {
 			},
 			contains: "interval_in cannot be empty array",
 		},
+		{
+			name: "stripe_price_id empty",
+			setup: func(q PlanQueryInterface) {
+				q.SetStripePriceID("")
+			},
+			contains: "stripe_price_id cannot be empty",
+		},
 		{
 			name: "type empty",
 			setup: func(q PlanQueryInterface) {
@@ -117,6 +128,20 @@ func TestPlanQueryValidateErrors(t *testing.T) {
 			},
 			contains: "type cannot be empty",
 		},
+		{
+			name: "meta equals key empty",
+			setup: func(q PlanQueryInterface) {
+				q.SetMetaEquals("", "acme")
+			},
+			contains: "meta_equals key cannot be empty",
+		},
+		{
+			name: "meta has key empty",
+			setup: func(q PlanQueryInterface) {
+				q.SetMetaHasKey("")
+			},
+			contains: "meta_has_key key cannot be empty",
+		},
 		{
 			name: "limit negative",
 			setup: func(q PlanQueryInterface) {
@@ -157,3 +182,21 @@ func TestPlanQueryValidateSuccess(t *testing.T) {
 		t.Fatalf("unexpected validation error: %v", err)
 	}
 }
+
+func TestPlanQueryMetaFilters(t *testing.T) {
+	query := NewPlanQuery().
+		SetMetaEquals("tenant_id", "acme").
+		SetMetaEquals("region", "eu").
+		SetMetaHasKey("crm_id").
+		SetMetaHasKey("referrer")
+
+	if !query.HasMetaEquals() || len(query.MetaEquals()) != 2 || query.MetaEquals()["tenant_id"] != "acme" || query.MetaEquals()["region"] != "eu" {
+		t.Fatalf("expected both meta equals filters, got %v", query.MetaEquals())
+	}
+	if !query.HasMetaHasKey() || len(query.MetaHasKey()) != 2 || query.MetaHasKey()[0] != "crm_id" || query.MetaHasKey()[1] != "referrer" {
+		t.Fatalf("expected both meta has key filters, got %v", query.MetaHasKey())
+	}
+	if err := query.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
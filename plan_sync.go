@@ -0,0 +1,463 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+const PLAN_DRIFT_CHANGED = "changed"
+const PLAN_DRIFT_MISSING_LOCAL = "missing_local"
+const PLAN_DRIFT_MISSING_REMOTE = "missing_remote"
+
+const PLAN_DRIFT_FIELD_ACTIVE = "active"
+const PLAN_DRIFT_FIELD_CURRENCY = "currency"
+const PLAN_DRIFT_FIELD_INTERVAL = "interval"
+const PLAN_DRIFT_FIELD_PRICE = "price"
+const PLAN_DRIFT_FIELD_PRICE_TIERS = "price_tiers"
+const PLAN_DRIFT_FIELD_PRICING_MODEL = "pricing_model"
+
+const PLAN_SYNC_ACTION_NONE = "none"
+const PLAN_SYNC_ACTION_LOCAL_CREATE = "local_create"
+const PLAN_SYNC_ACTION_LOCAL_UPDATE = "local_update"
+const PLAN_SYNC_ACTION_REMOTE_ACTIVATE = "remote_activate"
+const PLAN_SYNC_ACTION_REMOTE_CREATE = "remote_create"
+const PLAN_SYNC_ACTION_REMOTE_REPLACE = "remote_replace"
+
+const PLAN_SYNC_DIRECTION_PULL = "pull"
+const PLAN_SYNC_DIRECTION_PUSH = "push"
+
+// CatalogPrice is a recurring price in the catalog of a payment provider
+type CatalogPrice struct {
+	ID string
+	// Title is the name of the product of the price
+	Title    string
+	Price    string
+	Currency string
+	// Interval is one of the PLAN_INTERVAL_* constants
+	Interval string
+	// PricingModel is one of the PLAN_PRICING_MODEL_* constants. Empty is
+	// per unit, as for the plans.
+	PricingModel string
+	// Tiers are the tiers of the graduated and volume pricing models
+	Tiers  []PriceTier
+	Active bool
+}
+
+// PlanCatalog is the price catalog of a payment provider.
+//
+// Prices are immutable at most providers, so a change of the amount,
+// currency, interval or pricing of a plan is pushed as a new price, and the
+// old price is deactivated.
+type PlanCatalog interface {
+	// Name returns the name of the provider, i.e. PAYMENT_PROVIDER_STRIPE
+	Name() string
+
+	// PriceList returns all the prices of the catalog, active or not
+	PriceList(ctx context.Context) ([]CatalogPrice, error)
+
+	// PriceCreate creates a price for the plan, active if the plan is
+	PriceCreate(ctx context.Context, plan PlanInterface) (CatalogPrice, error)
+
+	// PriceSetActive activates or deactivates the price
+	PriceSetActive(ctx context.Context, priceID string, active bool) error
+}
+
+// PlanDrift is a difference between a local plan and its catalog price
+type PlanDrift struct {
+	// Kind is one of the PLAN_DRIFT_* constants
+	Kind string
+	// Fields are the PLAN_DRIFT_FIELD_* that differ, for changed plans
+	Fields []string
+	// Action is the PLAN_SYNC_ACTION_* taken, or to be taken on a dry run
+	Action string
+
+	// Plan is nil for prices missing locally
+	Plan PlanInterface
+	// Price is nil for plans missing at the provider
+	Price *CatalogPrice
+}
+
+// PlanSyncReport is the result of a push or a pull
+type PlanSyncReport struct {
+	Direction string
+	DryRun    bool
+	Drifts    []PlanDrift
+}
+
+// NewPlanSyncOptions define the options for creating a new plan sync
+type NewPlanSyncOptions struct {
+	Store   StoreInterface
+	Catalog PlanCatalog
+}
+
+// PlanSync keeps the plans of the store in sync with the catalog of a
+// payment provider.
+//
+// A plan is linked to its catalog price by a price reference in the provider
+// reference table. For Stripe, plans which are not referenced yet are linked
+// by their stripe price id, and the reference is recorded on the next push
+// or pull that is not a dry run.
+type PlanSync struct {
+	store   StoreInterface
+	catalog PlanCatalog
+}
+
+// NewPlanSync creates a new plan sync
+func NewPlanSync(opts NewPlanSyncOptions) (*PlanSync, error) {
+	if opts.Store == nil {
+		return nil, errors.New("plan sync: Store is required")
+	}
+	if opts.Catalog == nil {
+		return nil, errors.New("plan sync: Catalog is required")
+	}
+
+	return &PlanSync{
+		store:   opts.Store,
+		catalog: opts.Catalog,
+	}, nil
+}
+
+// Diff returns the drift between the local plans and the catalog. The
+// action of each drift is PLAN_SYNC_ACTION_NONE.
+func (s *PlanSync) Diff(ctx context.Context) ([]PlanDrift, error) {
+	links, err := s.link(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := []PlanDrift{}
+	for _, l := range links {
+		if drift, ok := l.drift(); ok {
+			drift.Action = PLAN_SYNC_ACTION_NONE
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts, nil
+}
+
+// Push makes the catalog match the local plans: plans missing at the provider
+// are created, changed prices are replaced, and the active flag is updated.
+// Prices missing locally are reported only. On a dry run nothing is changed.
+func (s *PlanSync) Push(ctx context.Context, dryRun bool) (PlanSyncReport, error) {
+	return s.sync(ctx, PLAN_SYNC_DIRECTION_PUSH, dryRun)
+}
+
+// Pull makes the local plans match the catalog: prices missing locally are
+// created as plans, and changed plans are updated. Plans missing at the
+// provider are reported only. On a dry run nothing is changed.
+func (s *PlanSync) Pull(ctx context.Context, dryRun bool) (PlanSyncReport, error) {
+	return s.sync(ctx, PLAN_SYNC_DIRECTION_PULL, dryRun)
+}
+
+func (s *PlanSync) sync(ctx context.Context, direction string, dryRun bool) (PlanSyncReport, error) {
+	report := PlanSyncReport{
+		Direction: direction,
+		DryRun:    dryRun,
+		Drifts:    []PlanDrift{},
+	}
+
+	links, err := s.link(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, l := range links {
+		drift, ok := l.drift()
+		if !ok {
+			// In sync, but possibly only linked by the stripe price id
+			if !dryRun && l.reference == nil {
+				if err := s.referenceReplace(ctx, l.plan, nil, l.price.ID); err != nil {
+					return report, err
+				}
+			}
+			continue
+		}
+
+		if direction == PLAN_SYNC_DIRECTION_PUSH {
+			drift.Action = pushAction(drift)
+		} else {
+			drift.Action = pullAction(drift)
+		}
+
+		if !dryRun {
+			if err := s.apply(ctx, l, drift); err != nil {
+				return report, err
+			}
+		}
+
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	return report, nil
+}
+
+// apply takes the action of the drift
+func (s *PlanSync) apply(ctx context.Context, l planLink, drift PlanDrift) error {
+	switch drift.Action {
+	case PLAN_SYNC_ACTION_REMOTE_CREATE, PLAN_SYNC_ACTION_REMOTE_REPLACE:
+		price, err := s.catalog.PriceCreate(ctx, l.plan)
+		if err != nil {
+			return err
+		}
+		if l.price != nil && l.price.Active {
+			if err := s.catalog.PriceSetActive(ctx, l.price.ID, false); err != nil {
+				return err
+			}
+		}
+		return s.referenceReplace(ctx, l.plan, l.reference, price.ID)
+
+	case PLAN_SYNC_ACTION_REMOTE_ACTIVATE:
+		if err := s.catalog.PriceSetActive(ctx, l.price.ID, planActive(l.plan)); err != nil {
+			return err
+		}
+		return s.referenceReplace(ctx, l.plan, l.reference, l.price.ID)
+
+	case PLAN_SYNC_ACTION_LOCAL_CREATE:
+		plan := NewPlan().SetTitle(l.price.Title)
+		if err := applyCatalogPrice(plan, *l.price); err != nil {
+			return err
+		}
+		if err := s.store.PlanCreate(ctx, plan); err != nil {
+			return err
+		}
+		return s.referenceReplace(ctx, plan, nil, l.price.ID)
+
+	case PLAN_SYNC_ACTION_LOCAL_UPDATE:
+		if err := applyCatalogPrice(l.plan, *l.price); err != nil {
+			return err
+		}
+		if err := s.store.PlanUpdate(ctx, l.plan); err != nil {
+			return err
+		}
+		return s.referenceReplace(ctx, l.plan, l.reference, l.price.ID)
+	}
+
+	return nil
+}
+
+// referenceReplace records the price of the plan, replacing the existing
+// reference if any
+func (s *PlanSync) referenceReplace(ctx context.Context, plan PlanInterface, existing ProviderReferenceInterface, priceID string) error {
+	if existing != nil {
+		if existing.GetExternalID() == priceID {
+			return nil
+		}
+		if err := s.store.ProviderReferenceDeleteByID(ctx, existing.GetID()); err != nil {
+			return err
+		}
+	}
+
+	reference := NewProviderReference().
+		SetProvider(s.catalog.Name()).
+		SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).
+		SetLocalID(plan.GetID()).
+		SetExternalID(priceID)
+	if err := s.store.ProviderReferenceCreate(ctx, reference); err != nil {
+		return err
+	}
+
+	if s.catalog.Name() == PAYMENT_PROVIDER_STRIPE && plan.GetStripePriceID() != priceID {
+		plan.SetStripePriceID(priceID)
+		return s.store.PlanUpdate(ctx, plan)
+	}
+	return nil
+}
+
+// planLink is a local plan and its catalog price. Either may be nil.
+type planLink struct {
+	plan      PlanInterface
+	reference ProviderReferenceInterface
+	price     *CatalogPrice
+}
+
+// link pairs the local plans with the catalog prices
+func (s *PlanSync) link(ctx context.Context) ([]planLink, error) {
+	plans, err := s.store.PlanList(ctx, PlanQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	catalogPrices, err := s.catalog.PriceList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	references, err := s.store.ProviderReferenceList(ctx, ProviderReferenceQuery().
+		SetProvider(s.catalog.Name()).
+		SetObjectType(PROVIDER_OBJECT_TYPE_PRICE))
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]*CatalogPrice{}
+	for i := range catalogPrices {
+		prices[catalogPrices[i].ID] = &catalogPrices[i]
+	}
+
+	planReferences := map[string]ProviderReferenceInterface{}
+	for _, reference := range references {
+		planReferences[reference.GetLocalID()] = reference
+	}
+
+	// Superseded prices, deactivated by an earlier push, are not missing locally
+	linked := map[string]bool{}
+
+	links := []planLink{}
+	for _, plan := range plans {
+		l := planLink{plan: plan, reference: planReferences[plan.GetID()]}
+
+		priceID := ""
+		if l.reference != nil {
+			priceID = l.reference.GetExternalID()
+		} else if s.catalog.Name() == PAYMENT_PROVIDER_STRIPE {
+			priceID = plan.GetStripePriceID()
+		}
+
+		if priceID != "" {
+			l.price = prices[priceID]
+			linked[priceID] = true
+		}
+
+		links = append(links, l)
+	}
+
+	for _, reference := range references {
+		linked[reference.GetExternalID()] = true
+	}
+
+	for i := range catalogPrices {
+		if !linked[catalogPrices[i].ID] {
+			links = append(links, planLink{price: &catalogPrices[i]})
+		}
+	}
+
+	return links, nil
+}
+
+// drift returns the drift of the link, and false if it is in sync
+func (l planLink) drift() (PlanDrift, bool) {
+	drift := PlanDrift{Plan: l.plan, Price: l.price}
+
+	if l.plan == nil {
+		// Inactive prices nobody refers to are archived, not missing
+		if !l.price.Active {
+			return drift, false
+		}
+		drift.Kind = PLAN_DRIFT_MISSING_LOCAL
+		return drift, true
+	}
+
+	if l.price == nil {
+		drift.Kind = PLAN_DRIFT_MISSING_REMOTE
+		return drift, true
+	}
+
+	if !amountsEqual(l.plan.GetPrice(), l.price.Price) {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_PRICE)
+	}
+	if !strings.EqualFold(l.plan.GetCurrency(), l.price.Currency) {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_CURRENCY)
+	}
+	if l.plan.GetInterval() != l.price.Interval {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_INTERVAL)
+	}
+	if pricingModel(l.plan.GetPricingModel()) != pricingModel(l.price.PricingModel) {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_PRICING_MODEL)
+	}
+	if tiers, err := l.plan.GetPriceTiers(); err != nil || !priceTiersEqual(tiers, l.price.Tiers) {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_PRICE_TIERS)
+	}
+	if planActive(l.plan) != l.price.Active {
+		drift.Fields = append(drift.Fields, PLAN_DRIFT_FIELD_ACTIVE)
+	}
+
+	if len(drift.Fields) == 0 {
+		return drift, false
+	}
+
+	drift.Kind = PLAN_DRIFT_CHANGED
+	return drift, true
+}
+
+func pushAction(drift PlanDrift) string {
+	switch drift.Kind {
+	case PLAN_DRIFT_MISSING_REMOTE:
+		return PLAN_SYNC_ACTION_REMOTE_CREATE
+	case PLAN_DRIFT_CHANGED:
+		if len(drift.Fields) == 1 && drift.Fields[0] == PLAN_DRIFT_FIELD_ACTIVE {
+			return PLAN_SYNC_ACTION_REMOTE_ACTIVATE
+		}
+		return PLAN_SYNC_ACTION_REMOTE_REPLACE
+	}
+	return PLAN_SYNC_ACTION_NONE
+}
+
+func pullAction(drift PlanDrift) string {
+	switch drift.Kind {
+	case PLAN_DRIFT_MISSING_LOCAL:
+		return PLAN_SYNC_ACTION_LOCAL_CREATE
+	case PLAN_DRIFT_CHANGED:
+		return PLAN_SYNC_ACTION_LOCAL_UPDATE
+	}
+	return PLAN_SYNC_ACTION_NONE
+}
+
+func planActive(plan PlanInterface) bool {
+	return plan.GetStatus() == PLAN_STATUS_ACTIVE
+}
+
+// applyCatalogPrice copies the price, currency, interval, pricing and
+// active flag of the catalog price to the plan
+func applyCatalogPrice(plan PlanInterface, price CatalogPrice) error {
+	plan.SetPrice(price.Price).
+		SetCurrency(strings.ToUpper(price.Currency)).
+		SetInterval(price.Interval).
+		SetPricingModel(pricingModel(price.PricingModel))
+
+	if _, err := plan.SetPriceTiers(price.Tiers); err != nil {
+		return err
+	}
+
+	if price.Active {
+		plan.SetStatus(PLAN_STATUS_ACTIVE)
+	} else {
+		plan.SetStatus(PLAN_STATUS_INACTIVE)
+	}
+	return nil
+}
+
+// pricingModel returns the pricing model, defaulting to per unit
+func pricingModel(model string) string {
+	if model == "" {
+		return PLAN_PRICING_MODEL_PER_UNIT
+	}
+	return model
+}
+
+// amountsEqual returns true if the decimal amounts are the same number of
+// cents, i.e. "9.99" and "9.990". Amounts which are not numbers are only
+// equal to themselves.
+func amountsEqual(a string, b string) bool {
+	aCents, aErr := amountCents(a)
+	bCents, bErr := amountCents(b)
+	if aErr != nil || bErr != nil {
+		return a == b
+	}
+	return aCents == bCents
+}
+
+// priceTiersEqual returns true if the tiers have the same bounds and amounts
+func priceTiersEqual(a []PriceTier, b []PriceTier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].UpTo != b[i].UpTo ||
+			!amountsEqual(a[i].UnitPrice, b[i].UnitPrice) ||
+			!amountsEqual(a[i].FlatPrice, b[i].FlatPrice) {
+			return false
+		}
+	}
+	return true
+}
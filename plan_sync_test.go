@@ -0,0 +1,368 @@
+package subscriptionstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/subscriptionstore/providertest"
+)
+
+func createPlan(t *testing.T, store subscriptionstore.StoreInterface, title string, price string) subscriptionstore.PlanInterface {
+	plan := subscriptionstore.NewPlan().
+		SetTitle(title).
+		SetPrice(price).
+		SetCurrency(subscriptionstore.CURRENCY_USD).
+		SetInterval(subscriptionstore.PLAN_INTERVAL_MONTHLY).
+		SetStatus(subscriptionstore.PLAN_STATUS_ACTIVE)
+	if err := store.PlanCreate(context.Background(), plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return plan
+}
+
+func linkPlan(t *testing.T, store subscriptionstore.StoreInterface, catalog *providertest.FakeCatalog, plan subscriptionstore.PlanInterface, priceID string) {
+	reference := subscriptionstore.NewProviderReference().
+		SetProvider(catalog.Name()).
+		SetObjectType(subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE).
+		SetLocalID(plan.GetID()).
+		SetExternalID(priceID)
+	if err := store.ProviderReferenceCreate(context.Background(), reference); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func monthlyPrice(title string, price string) subscriptionstore.CatalogPrice {
+	return subscriptionstore.CatalogPrice{
+		Title:    title,
+		Price:    price,
+		Currency: "usd",
+		Interval: subscriptionstore.PLAN_INTERVAL_MONTHLY,
+		Active:   true,
+	}
+}
+
+func driftByKind(drifts []subscriptionstore.PlanDrift) map[string][]subscriptionstore.PlanDrift {
+	byKind := map[string][]subscriptionstore.PlanDrift{}
+	for _, drift := range drifts {
+		byKind[drift.Kind] = append(byKind[drift.Kind], drift)
+	}
+	return byKind
+}
+
+func TestNewPlanSyncRequiresStoreAndCatalog(t *testing.T) {
+	if _, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Catalog: providertest.NewFakeCatalog("fake")}); err == nil {
+		t.Error("expected error without a store")
+	}
+	if _, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: initProviderStore(t)}); err == nil {
+		t.Error("expected error without a catalog")
+	}
+}
+
+func TestPlanSyncDiff(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+	catalog := providertest.NewFakeCatalog(subscriptionstore.PAYMENT_PROVIDER_PADDLE)
+
+	inSync := createPlan(t, store, "Basic", "9.99")
+	linkPlan(t, store, catalog, inSync, catalog.Put(monthlyPrice("Basic", "9.990")))
+
+	changed := createPlan(t, store, "Pro", "29.00")
+	changedPrice := monthlyPrice("Pro", "19.00")
+	changedPrice.Active = false
+	linkPlan(t, store, catalog, changed, catalog.Put(changedPrice))
+
+	createPlan(t, store, "Enterprise", "99.00")
+
+	catalog.Put(monthlyPrice("Team", "49.00"))
+
+	archived := monthlyPrice("Legacy", "5.00")
+	archived.Active = false
+	catalog.Put(archived)
+
+	sync, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: store, Catalog: catalog})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	drifts, err := sync.Diff(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(drifts) != 3 {
+		t.Fatalf("expected 3 drifts, got %d: %+v", len(drifts), drifts)
+	}
+
+	byKind := driftByKind(drifts)
+
+	changedDrifts := byKind[subscriptionstore.PLAN_DRIFT_CHANGED]
+	if len(changedDrifts) != 1 || changedDrifts[0].Plan.GetID() != changed.GetID() {
+		t.Fatalf("expected plan %s to be changed, got %+v", changed.GetID(), changedDrifts)
+	}
+	fields := changedDrifts[0].Fields
+	if len(fields) != 2 || fields[0] != subscriptionstore.PLAN_DRIFT_FIELD_PRICE || fields[1] != subscriptionstore.PLAN_DRIFT_FIELD_ACTIVE {
+		t.Errorf("expected price and active drift, got %v", fields)
+	}
+
+	missingRemote := byKind[subscriptionstore.PLAN_DRIFT_MISSING_REMOTE]
+	if len(missingRemote) != 1 || missingRemote[0].Plan.GetTitle() != "Enterprise" {
+		t.Errorf("expected Enterprise to be missing at the provider, got %+v", missingRemote)
+	}
+
+	missingLocal := byKind[subscriptionstore.PLAN_DRIFT_MISSING_LOCAL]
+	if len(missingLocal) != 1 || missingLocal[0].Price.Title != "Team" {
+		t.Errorf("expected Team to be missing locally, got %+v", missingLocal)
+	}
+
+	for _, drift := range drifts {
+		if drift.Action != subscriptionstore.PLAN_SYNC_ACTION_NONE {
+			t.Errorf("expected no action on a diff, got %s", drift.Action)
+		}
+	}
+}
+
+func TestPlanSyncPush(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+	catalog := providertest.NewFakeCatalog(subscriptionstore.PAYMENT_PROVIDER_PADDLE)
+
+	changed := createPlan(t, store, "Pro", "29.00")
+	oldPriceID := catalog.Put(monthlyPrice("Pro", "19.00"))
+	linkPlan(t, store, catalog, changed, oldPriceID)
+
+	deactivated := createPlan(t, store, "Starter", "5.00")
+	deactivated.SetStatus(subscriptionstore.PLAN_STATUS_INACTIVE)
+	if err := store.PlanUpdate(ctx, deactivated); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	starterPriceID := catalog.Put(monthlyPrice("Starter", "5.00"))
+	linkPlan(t, store, catalog, deactivated, starterPriceID)
+
+	missing := createPlan(t, store, "Enterprise", "99.00")
+
+	sync, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: store, Catalog: catalog})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	report, err := sync.Push(ctx, true)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !report.DryRun || report.Direction != subscriptionstore.PLAN_SYNC_DIRECTION_PUSH || len(report.Drifts) != 3 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+
+	actions := map[string]string{}
+	for _, drift := range report.Drifts {
+		actions[drift.Plan.GetID()] = drift.Action
+	}
+	if actions[changed.GetID()] != subscriptionstore.PLAN_SYNC_ACTION_REMOTE_REPLACE {
+		t.Errorf("expected the changed price to be replaced, got %s", actions[changed.GetID()])
+	}
+	if actions[deactivated.GetID()] != subscriptionstore.PLAN_SYNC_ACTION_REMOTE_ACTIVATE {
+		t.Errorf("expected the starter price to be deactivated, got %s", actions[deactivated.GetID()])
+	}
+	if actions[missing.GetID()] != subscriptionstore.PLAN_SYNC_ACTION_REMOTE_CREATE {
+		t.Errorf("expected the enterprise price to be created, got %s", actions[missing.GetID()])
+	}
+
+	// The dry run changed nothing
+	if prices, _ := catalog.PriceList(ctx); len(prices) != 2 {
+		t.Fatalf("expected the catalog to be unchanged on a dry run, got %d prices", len(prices))
+	}
+
+	if _, err := sync.Push(ctx, false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if old, _ := catalog.Price(oldPriceID); old.Active {
+		t.Error("expected the replaced price to be deactivated")
+	}
+	if starter, _ := catalog.Price(starterPriceID); starter.Active {
+		t.Error("expected the starter price to be deactivated")
+	}
+
+	reference, err := store.ProviderReferenceFindByLocalID(ctx, catalog.Name(), subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE, changed.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	newPrice, ok := catalog.Price(reference.GetExternalID())
+	if !ok || reference.GetExternalID() == oldPriceID || newPrice.Price != "29.00" || !newPrice.Active {
+		t.Fatalf("expected the plan to reference a new active price of 29.00, got %+v", newPrice)
+	}
+
+	drifts, err := sync.Diff(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("expected no drift after a push, got %+v", drifts)
+	}
+}
+
+func TestPlanSyncPull(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+	catalog := providertest.NewFakeCatalog(subscriptionstore.PAYMENT_PROVIDER_STRIPE)
+
+	// Linked by the stripe price id only, as before provider references
+	changed := createPlan(t, store, "Pro", "29.00")
+	changedPrice := monthlyPrice("Pro", "24.00")
+	changedPrice.Interval = subscriptionstore.PLAN_INTERVAL_YEARLY
+	changed.SetStripePriceID(catalog.Put(changedPrice))
+	if err := store.PlanUpdate(ctx, changed); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	teamPriceID := catalog.Put(monthlyPrice("Team", "49.00"))
+
+	sync, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: store, Catalog: catalog})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	report, err := sync.Pull(ctx, true)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(report.Drifts) != 2 {
+		t.Fatalf("expected 2 drifts, got %+v", report.Drifts)
+	}
+	if count, _ := store.PlanCount(ctx, subscriptionstore.PlanQuery()); count != 1 {
+		t.Fatalf("expected no plan to be created on a dry run, got %d plans", count)
+	}
+
+	if _, err := sync.Pull(ctx, false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	updated, err := store.PlanFindByID(ctx, changed.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if updated.GetPrice() != "24.00" || updated.GetInterval() != subscriptionstore.PLAN_INTERVAL_YEARLY {
+		t.Errorf("expected the plan to be 24.00 yearly, got %s %s", updated.GetPrice(), updated.GetInterval())
+	}
+
+	reference, err := store.ProviderReferenceFindByExternalID(ctx, catalog.Name(), subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE, teamPriceID)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if reference == nil {
+		t.Fatal("expected the Team price to be referenced by a new plan")
+	}
+	team, err := store.PlanFindByID(ctx, reference.GetLocalID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if team.GetTitle() != "Team" || team.GetCurrency() != subscriptionstore.CURRENCY_USD || team.GetStatus() != subscriptionstore.PLAN_STATUS_ACTIVE || team.GetStripePriceID() != teamPriceID {
+		t.Errorf("unexpected pulled plan: %s %s %s %s", team.GetTitle(), team.GetCurrency(), team.GetStatus(), team.GetStripePriceID())
+	}
+
+	drifts, err := sync.Diff(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("expected no drift after a pull, got %+v", drifts)
+	}
+}
+
+func TestPlanSyncCatalogFailure(t *testing.T) {
+	store := initProviderStore(t)
+	catalog := providertest.NewFakeCatalog(subscriptionstore.PAYMENT_PROVIDER_PADDLE)
+	catalog.FailWith(errors.New("catalog unavailable"))
+
+	sync, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: store, Catalog: catalog})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := sync.Push(context.Background(), false); err == nil {
+		t.Fatal("expected the catalog error")
+	}
+}
+
+func TestPlanSyncDiffPricing(t *testing.T) {
+	store := initProviderStore(t)
+	ctx := context.Background()
+	catalog := providertest.NewFakeCatalog(subscriptionstore.PAYMENT_PROVIDER_PADDLE)
+
+	tiers := []subscriptionstore.PriceTier{
+		{UpTo: 10, UnitPrice: "10.00"},
+		{UpTo: 0, UnitPrice: "8.00"},
+	}
+
+	plan := subscriptionstore.NewPlan().
+		SetTitle("Seats").
+		SetCurrency(subscriptionstore.CURRENCY_USD).
+		SetInterval(subscriptionstore.PLAN_INTERVAL_MONTHLY).
+		SetPricingModel(subscriptionstore.PLAN_PRICING_MODEL_GRADUATED).
+		SetStatus(subscriptionstore.PLAN_STATUS_ACTIVE)
+	if _, err := plan.SetPriceTiers(tiers); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// Same amounts written differently are in sync
+	price := monthlyPrice("Seats", "")
+	price.PricingModel = subscriptionstore.PLAN_PRICING_MODEL_GRADUATED
+	price.Tiers = []subscriptionstore.PriceTier{
+		{UpTo: 10, UnitPrice: "10"},
+		{UpTo: 0, UnitPrice: "8.000"},
+	}
+	price.ID = catalog.Put(price)
+	linkPlan(t, store, catalog, plan, price.ID)
+
+	sync, err := subscriptionstore.NewPlanSync(subscriptionstore.NewPlanSyncOptions{Store: store, Catalog: catalog})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	drifts, err := sync.Diff(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("expected no drift, got %+v", drifts)
+	}
+
+	price.PricingModel = subscriptionstore.PLAN_PRICING_MODEL_VOLUME
+	price.Tiers = []subscriptionstore.PriceTier{
+		{UpTo: 10, UnitPrice: "10.00"},
+		{UpTo: 0, UnitPrice: "7.50"},
+	}
+	catalog.Put(price)
+
+	drifts, err = sync.Diff(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(drifts) != 1 {
+		t.Fatalf("expected 1 drift, got %+v", drifts)
+	}
+	fields := drifts[0].Fields
+	if len(fields) != 2 || fields[0] != subscriptionstore.PLAN_DRIFT_FIELD_PRICING_MODEL || fields[1] != subscriptionstore.PLAN_DRIFT_FIELD_PRICE_TIERS {
+		t.Errorf("expected pricing model and tiers drift, got %v", fields)
+	}
+
+	if _, err := sync.Pull(ctx, false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	updated, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	updatedTiers, err := updated.GetPriceTiers()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if updated.GetPricingModel() != subscriptionstore.PLAN_PRICING_MODEL_VOLUME || len(updatedTiers) != 2 || updatedTiers[1].UnitPrice != "7.50" {
+		t.Errorf("expected the volume tiers to be pulled, got %s %+v", updated.GetPricingModel(), updatedTiers)
+	}
+}
@@ -25,6 +25,12 @@ func TestNewPlanDefaults(t *testing.T) {
 	if plan.GetFeatures() != "" {
 		t.Fatalf("expected empty features, got %s", plan.GetFeatures())
 	}
+	if plan.GetPricingModel() != PLAN_PRICING_MODEL_PER_UNIT {
+		t.Fatalf("expected pricing model %s, got %s", PLAN_PRICING_MODEL_PER_UNIT, plan.GetPricingModel())
+	}
+	if tiers, err := plan.GetPriceTiers(); err != nil || len(tiers) != 0 {
+		t.Fatalf("expected no price tiers, got %v (%v)", tiers, err)
+	}
 	if plan.GetMemo() != "" {
 		t.Fatalf("expected empty memo, got %s", plan.GetMemo())
 	}
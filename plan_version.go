@@ -0,0 +1,227 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+// PlanVersionInterface defines the methods for a PlanVersion entity.
+// A version is an immutable copy of the billed fields of a plan. Subscriptions
+// are pinned to the version they signed up on, so changing the price or the
+// features of a plan does not change them.
+type PlanVersionInterface interface {
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) PlanVersionInterface
+
+	GetCurrency() string
+	SetCurrency(currency string) PlanVersionInterface
+
+	GetFeatures() string
+	SetFeatures(features string) PlanVersionInterface
+
+	GetID() string
+	SetID(id string) PlanVersionInterface
+
+	GetInterval() string
+	SetInterval(interval string) PlanVersionInterface
+
+	GetPlanID() string
+	SetPlanID(planID string) PlanVersionInterface
+
+	GetPrice() string
+	GetPriceFloat() float64
+	SetPrice(price string) PlanVersionInterface
+
+	GetPriceTiers() ([]PriceTier, error)
+	SetPriceTiers(tiers []PriceTier) (PlanVersionInterface, error)
+
+	GetPricingModel() string
+	SetPricingModel(pricingModel string) PlanVersionInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) PlanVersionInterface
+
+	GetVersion() int
+	SetVersion(version int) PlanVersionInterface
+}
+
+var _ PlanVersionInterface = (*planVersionImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type planVersionImplementation struct {
+	orm.ShortID
+
+	PlanIDField       string `db:"plan_id"`
+	VersionField      int    `db:"version"`
+	IntervalField     string `db:"interval"`
+	CurrencyField     string `db:"currency"`
+	PriceField        string `db:"price"`
+	PricingModelField string `db:"pricing_model"`
+	PriceTiersField   string `db:"price_tiers"`
+	FeaturesField     string `db:"features"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewPlanVersion() PlanVersionInterface {
+	o := &planVersionImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetPlanID("")
+	o.SetVersion(1)
+	o.SetInterval("")
+	o.SetCurrency("")
+	o.SetPrice("")
+	o.SetPricingModel(PLAN_PRICING_MODEL_PER_UNIT)
+	o.PriceTiersField = ""
+	o.SetFeatures("")
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *planVersionImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *planVersionImplementation) SetID(id string) PlanVersionInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *planVersionImplementation) GetPlanID() string {
+	return o.PlanIDField
+}
+
+func (o *planVersionImplementation) SetPlanID(planID string) PlanVersionInterface {
+	o.PlanIDField = planID
+	return o
+}
+
+func (o *planVersionImplementation) GetVersion() int {
+	return o.VersionField
+}
+
+func (o *planVersionImplementation) SetVersion(version int) PlanVersionInterface {
+	o.VersionField = version
+	return o
+}
+
+func (o *planVersionImplementation) GetInterval() string {
+	return o.IntervalField
+}
+
+func (o *planVersionImplementation) SetInterval(interval string) PlanVersionInterface {
+	o.IntervalField = interval
+	return o
+}
+
+func (o *planVersionImplementation) GetCurrency() string {
+	return o.CurrencyField
+}
+
+func (o *planVersionImplementation) SetCurrency(currency string) PlanVersionInterface {
+	o.CurrencyField = currency
+	return o
+}
+
+func (o *planVersionImplementation) GetPrice() string {
+	return o.PriceField
+}
+
+func (o *planVersionImplementation) GetPriceFloat() float64 {
+	return cast.ToFloat64(o.PriceField)
+}
+
+func (o *planVersionImplementation) SetPrice(price string) PlanVersionInterface {
+	o.PriceField = price
+	return o
+}
+
+func (o *planVersionImplementation) GetPriceTiers() ([]PriceTier, error) {
+	if o.PriceTiersField == "" {
+		return nil, nil
+	}
+	var tiers []PriceTier
+	err := json.Unmarshal([]byte(o.PriceTiersField), &tiers)
+	if err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+func (o *planVersionImplementation) SetPriceTiers(tiers []PriceTier) (PlanVersionInterface, error) {
+	tiersJSON, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+	o.PriceTiersField = tiersJSON
+	return o, nil
+}
+
+func (o *planVersionImplementation) GetPricingModel() string {
+	return o.PricingModelField
+}
+
+func (o *planVersionImplementation) SetPricingModel(pricingModel string) PlanVersionInterface {
+	o.PricingModelField = pricingModel
+	return o
+}
+
+func (o *planVersionImplementation) GetFeatures() string {
+	return o.FeaturesField
+}
+
+func (o *planVersionImplementation) SetFeatures(features string) PlanVersionInterface {
+	o.FeaturesField = features
+	return o
+}
+
+func (o *planVersionImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *planVersionImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *planVersionImplementation) SetCreatedAt(createdAt string) PlanVersionInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *planVersionImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *planVersionImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *planVersionImplementation) SetUpdatedAt(updatedAt string) PlanVersionInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
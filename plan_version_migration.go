@@ -0,0 +1,221 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// PlanVersionMigrationInterface defines the methods for a PlanVersionMigration entity.
+// A migration moves a cohort of the subscriptions of a plan to a newer
+// version of the plan, once it is due.
+type PlanVersionMigrationInterface interface {
+	GetCompletedAt() string
+	GetCompletedAtCarbon() *carbon.Carbon
+	SetCompletedAt(completedAt string) PlanVersionMigrationInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) PlanVersionMigrationInterface
+
+	GetFromVersionID() string
+	SetFromVersionID(fromVersionID string) PlanVersionMigrationInterface
+
+	GetID() string
+	SetID(id string) PlanVersionMigrationInterface
+
+	GetMigratedCount() int
+	SetMigratedCount(migratedCount int) PlanVersionMigrationInterface
+
+	GetPlanID() string
+	SetPlanID(planID string) PlanVersionMigrationInterface
+
+	GetScheduledAt() string
+	GetScheduledAtCarbon() *carbon.Carbon
+	SetScheduledAt(scheduledAt string) PlanVersionMigrationInterface
+
+	GetStatus() string
+	SetStatus(status string) PlanVersionMigrationInterface
+
+	GetSubscribedBefore() string
+	GetSubscribedBeforeCarbon() *carbon.Carbon
+	SetSubscribedBefore(subscribedBefore string) PlanVersionMigrationInterface
+
+	GetToVersionID() string
+	SetToVersionID(toVersionID string) PlanVersionMigrationInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) PlanVersionMigrationInterface
+}
+
+var _ PlanVersionMigrationInterface = (*planVersionMigrationImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type planVersionMigrationImplementation struct {
+	orm.ShortID
+
+	PlanIDField           string `db:"plan_id"`
+	FromVersionIDField    string `db:"from_version_id"`
+	ToVersionIDField      string `db:"to_version_id"`
+	SubscribedBeforeField string `db:"subscribed_before"`
+	ScheduledAtField      string `db:"scheduled_at"`
+	StatusField           string `db:"status"`
+	MigratedCountField    int    `db:"migrated_count"`
+	CompletedAtField      string `db:"completed_at"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewPlanVersionMigration() PlanVersionMigrationInterface {
+	o := &planVersionMigrationImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetPlanID("")
+	o.SetFromVersionID("")
+	o.SetToVersionID("")
+	o.SetSubscribedBefore(MAX_DATETIME)
+	o.SetScheduledAt(MAX_DATETIME)
+	o.SetStatus(PLAN_VERSION_MIGRATION_STATUS_SCHEDULED)
+	o.SetMigratedCount(0)
+	o.SetCompletedAt(MAX_DATETIME)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *planVersionMigrationImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *planVersionMigrationImplementation) SetID(id string) PlanVersionMigrationInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetPlanID() string {
+	return o.PlanIDField
+}
+
+func (o *planVersionMigrationImplementation) SetPlanID(planID string) PlanVersionMigrationInterface {
+	o.PlanIDField = planID
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetFromVersionID() string {
+	return o.FromVersionIDField
+}
+
+func (o *planVersionMigrationImplementation) SetFromVersionID(fromVersionID string) PlanVersionMigrationInterface {
+	o.FromVersionIDField = fromVersionID
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetToVersionID() string {
+	return o.ToVersionIDField
+}
+
+func (o *planVersionMigrationImplementation) SetToVersionID(toVersionID string) PlanVersionMigrationInterface {
+	o.ToVersionIDField = toVersionID
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetSubscribedBefore() string {
+	return o.SubscribedBeforeField
+}
+
+func (o *planVersionMigrationImplementation) GetSubscribedBeforeCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetSubscribedBefore(), carbon.UTC)
+}
+
+func (o *planVersionMigrationImplementation) SetSubscribedBefore(subscribedBefore string) PlanVersionMigrationInterface {
+	o.SubscribedBeforeField = subscribedBefore
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetScheduledAt() string {
+	return o.ScheduledAtField
+}
+
+func (o *planVersionMigrationImplementation) GetScheduledAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetScheduledAt(), carbon.UTC)
+}
+
+func (o *planVersionMigrationImplementation) SetScheduledAt(scheduledAt string) PlanVersionMigrationInterface {
+	o.ScheduledAtField = scheduledAt
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetStatus() string {
+	return o.StatusField
+}
+
+func (o *planVersionMigrationImplementation) SetStatus(status string) PlanVersionMigrationInterface {
+	o.StatusField = status
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetMigratedCount() int {
+	return o.MigratedCountField
+}
+
+func (o *planVersionMigrationImplementation) SetMigratedCount(migratedCount int) PlanVersionMigrationInterface {
+	o.MigratedCountField = migratedCount
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetCompletedAt() string {
+	return o.CompletedAtField
+}
+
+func (o *planVersionMigrationImplementation) GetCompletedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetCompletedAt(), carbon.UTC)
+}
+
+func (o *planVersionMigrationImplementation) SetCompletedAt(completedAt string) PlanVersionMigrationInterface {
+	o.CompletedAtField = completedAt
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *planVersionMigrationImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *planVersionMigrationImplementation) SetCreatedAt(createdAt string) PlanVersionMigrationInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *planVersionMigrationImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *planVersionMigrationImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *planVersionMigrationImplementation) SetUpdatedAt(updatedAt string) PlanVersionMigrationInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
@@ -0,0 +1,189 @@
+package subscriptionstore
+
+import "errors"
+
+// PlanVersionMigrationQueryInterface defines the interface for querying plan version migrations.
+type PlanVersionMigrationQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) PlanVersionMigrationQueryInterface
+
+	HasPlanID() bool
+	PlanID() string
+	SetPlanID(planID string) PlanVersionMigrationQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) PlanVersionMigrationQueryInterface
+
+	HasScheduledAtLte() bool
+	ScheduledAtLte() string
+	SetScheduledAtLte(scheduledAtLte string) PlanVersionMigrationQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) PlanVersionMigrationQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) PlanVersionMigrationQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) PlanVersionMigrationQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) PlanVersionMigrationQueryInterface
+}
+
+// PlanVersionMigrationQuery is a shortcut alias for NewPlanVersionMigrationQuery
+func PlanVersionMigrationQuery() PlanVersionMigrationQueryInterface {
+	return NewPlanVersionMigrationQuery()
+}
+
+// NewPlanVersionMigrationQuery creates a new plan version migration query
+func NewPlanVersionMigrationQuery() PlanVersionMigrationQueryInterface {
+	return &planVersionMigrationQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ PlanVersionMigrationQueryInterface = (*planVersionMigrationQueryImplementation)(nil)
+
+type planVersionMigrationQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *planVersionMigrationQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("plan version migration query. id cannot be empty")
+	}
+	if q.HasPlanID() && q.PlanID() == "" {
+		return errors.New("plan version migration query. plan_id cannot be empty")
+	}
+	if q.HasStatus() && q.Status() == "" {
+		return errors.New("plan version migration query. status cannot be empty")
+	}
+	if q.HasScheduledAtLte() && q.ScheduledAtLte() == "" {
+		return errors.New("plan version migration query. scheduled_at_lte cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("plan version migration query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("plan version migration query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *planVersionMigrationQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *planVersionMigrationQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetID(id string) PlanVersionMigrationQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasPlanID() bool {
+	return q.hasProperty("plan_id")
+}
+
+func (q *planVersionMigrationQueryImplementation) PlanID() string {
+	return q.properties["plan_id"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetPlanID(planID string) PlanVersionMigrationQueryInterface {
+	q.properties["plan_id"] = planID
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasStatus() bool {
+	return q.hasProperty("status")
+}
+
+func (q *planVersionMigrationQueryImplementation) Status() string {
+	return q.properties["status"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetStatus(status string) PlanVersionMigrationQueryInterface {
+	q.properties["status"] = status
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasScheduledAtLte() bool {
+	return q.hasProperty("scheduled_at_lte")
+}
+
+func (q *planVersionMigrationQueryImplementation) ScheduledAtLte() string {
+	return q.properties["scheduled_at_lte"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetScheduledAtLte(scheduledAtLte string) PlanVersionMigrationQueryInterface {
+	q.properties["scheduled_at_lte"] = scheduledAtLte
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *planVersionMigrationQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetOffset(offset int) PlanVersionMigrationQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *planVersionMigrationQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetLimit(limit int) PlanVersionMigrationQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *planVersionMigrationQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetOrderBy(orderBy string) PlanVersionMigrationQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *planVersionMigrationQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *planVersionMigrationQueryImplementation) SetSortOrder(sortOrder string) PlanVersionMigrationQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *planVersionMigrationQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
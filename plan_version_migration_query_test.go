@@ -0,0 +1,49 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanVersionMigrationQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(PlanVersionMigrationQueryInterface)
+		contains string
+	}{
+		{
+			name:     "plan_id empty",
+			setup:    func(q PlanVersionMigrationQueryInterface) { q.SetPlanID("") },
+			contains: "plan_id cannot be empty",
+		},
+		{
+			name:     "status empty",
+			setup:    func(q PlanVersionMigrationQueryInterface) { q.SetStatus("") },
+			contains: "status cannot be empty",
+		},
+		{
+			name:     "scheduled_at_lte empty",
+			setup:    func(q PlanVersionMigrationQueryInterface) { q.SetScheduledAtLte("") },
+			contains: "scheduled_at_lte cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q PlanVersionMigrationQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewPlanVersionMigrationQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewPlanVersionMigrationDefaults(t *testing.T) {
+	migration := NewPlanVersionMigration()
+
+	if migration.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if migration.GetStatus() != PLAN_VERSION_MIGRATION_STATUS_SCHEDULED {
+		t.Fatalf("expected status %s, got %s", PLAN_VERSION_MIGRATION_STATUS_SCHEDULED, migration.GetStatus())
+	}
+	if migration.GetSubscribedBefore() != MAX_DATETIME || migration.GetScheduledAt() != MAX_DATETIME || migration.GetCompletedAt() != MAX_DATETIME {
+		t.Fatal("dates should default to MAX_DATETIME")
+	}
+	if migration.GetMigratedCount() != 0 {
+		t.Fatalf("expected migrated count 0, got %d", migration.GetMigratedCount())
+	}
+}
+
+func TestPlanVersionMigrationSettersAndGetters(t *testing.T) {
+	migration := NewPlanVersionMigration().
+		SetPlanID("plan_1").
+		SetFromVersionID("v1").
+		SetToVersionID("v2").
+		SetSubscribedBefore("2025-01-01 00:00:00").
+		SetScheduledAt("2025-06-01 00:00:00").
+		SetMigratedCount(5)
+
+	if migration.GetPlanID() != "plan_1" || migration.GetFromVersionID() != "v1" || migration.GetToVersionID() != "v2" {
+		t.Fatalf("unexpected plan %s / from %s / to %s", migration.GetPlanID(), migration.GetFromVersionID(), migration.GetToVersionID())
+	}
+	if migration.GetSubscribedBefore() != "2025-01-01 00:00:00" || migration.GetScheduledAt() != "2025-06-01 00:00:00" {
+		t.Fatalf("unexpected dates %s / %s", migration.GetSubscribedBefore(), migration.GetScheduledAt())
+	}
+	if migration.GetMigratedCount() != 5 {
+		t.Fatalf("expected migrated count 5, got %d", migration.GetMigratedCount())
+	}
+}
@@ -0,0 +1,149 @@
+package subscriptionstore
+
+import "errors"
+
+// PlanVersionQueryInterface defines the interface for querying plan versions.
+type PlanVersionQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) PlanVersionQueryInterface
+
+	HasPlanID() bool
+	PlanID() string
+	SetPlanID(planID string) PlanVersionQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) PlanVersionQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) PlanVersionQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) PlanVersionQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) PlanVersionQueryInterface
+}
+
+// PlanVersionQuery is a shortcut alias for NewPlanVersionQuery
+func PlanVersionQuery() PlanVersionQueryInterface {
+	return NewPlanVersionQuery()
+}
+
+// NewPlanVersionQuery creates a new plan version query
+func NewPlanVersionQuery() PlanVersionQueryInterface {
+	return &planVersionQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ PlanVersionQueryInterface = (*planVersionQueryImplementation)(nil)
+
+type planVersionQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *planVersionQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("plan version query. id cannot be empty")
+	}
+	if q.HasPlanID() && q.PlanID() == "" {
+		return errors.New("plan version query. plan_id cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("plan version query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("plan version query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *planVersionQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *planVersionQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *planVersionQueryImplementation) SetID(id string) PlanVersionQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *planVersionQueryImplementation) HasPlanID() bool {
+	return q.hasProperty("plan_id")
+}
+
+func (q *planVersionQueryImplementation) PlanID() string {
+	return q.properties["plan_id"].(string)
+}
+
+func (q *planVersionQueryImplementation) SetPlanID(planID string) PlanVersionQueryInterface {
+	q.properties["plan_id"] = planID
+	return q
+}
+
+func (q *planVersionQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *planVersionQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *planVersionQueryImplementation) SetOffset(offset int) PlanVersionQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *planVersionQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *planVersionQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *planVersionQueryImplementation) SetLimit(limit int) PlanVersionQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *planVersionQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *planVersionQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *planVersionQueryImplementation) SetOrderBy(orderBy string) PlanVersionQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *planVersionQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *planVersionQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *planVersionQueryImplementation) SetSortOrder(sortOrder string) PlanVersionQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *planVersionQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
@@ -0,0 +1,39 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanVersionQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(PlanVersionQueryInterface)
+		contains string
+	}{
+		{
+			name:     "plan_id empty",
+			setup:    func(q PlanVersionQueryInterface) { q.SetPlanID("") },
+			contains: "plan_id cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q PlanVersionQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewPlanVersionQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewPlanVersionDefaults(t *testing.T) {
+	version := NewPlanVersion()
+
+	if version.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if version.GetVersion() != 1 {
+		t.Fatalf("expected version 1, got %d", version.GetVersion())
+	}
+	if version.GetPricingModel() != PLAN_PRICING_MODEL_PER_UNIT {
+		t.Fatalf("expected pricing model %s, got %s", PLAN_PRICING_MODEL_PER_UNIT, version.GetPricingModel())
+	}
+	if version.GetCreatedAt() == "" || version.GetUpdatedAt() == "" {
+		t.Fatal("timestamps should not be empty")
+	}
+}
+
+func TestPlanVersionSettersAndGetters(t *testing.T) {
+	version := NewPlanVersion().
+		SetPlanID("plan_1").
+		SetVersion(3).
+		SetInterval(PLAN_INTERVAL_YEARLY).
+		SetCurrency(CURRENCY_EUR).
+		SetPrice("99.00").
+		SetPricingModel(PLAN_PRICING_MODEL_VOLUME).
+		SetFeatures("sso")
+
+	if version.GetPlanID() != "plan_1" || version.GetVersion() != 3 {
+		t.Fatalf("unexpected plan id %s / version %d", version.GetPlanID(), version.GetVersion())
+	}
+	if version.GetInterval() != PLAN_INTERVAL_YEARLY || version.GetCurrency() != CURRENCY_EUR || version.GetPrice() != "99.00" {
+		t.Fatalf("unexpected billing %s %s %s", version.GetInterval(), version.GetCurrency(), version.GetPrice())
+	}
+	if version.GetPricingModel() != PLAN_PRICING_MODEL_VOLUME || version.GetFeatures() != "sso" {
+		t.Fatalf("unexpected pricing model %s / features %s", version.GetPricingModel(), version.GetFeatures())
+	}
+
+	if _, err := version.SetPriceTiers([]PriceTier{{UpTo: 10, UnitPrice: "5.00"}, {UnitPrice: "4.00"}}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	tiers, err := version.GetPriceTiers()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(tiers) != 2 || tiers[0].UpTo != 10 || tiers[1].UnitPrice != "4.00" {
+		t.Fatalf("unexpected tiers %+v", tiers)
+	}
+}
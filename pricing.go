@@ -0,0 +1,191 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// PriceTier is a tier of the graduated or volume pricing of a plan
+type PriceTier struct {
+	// UpTo is the last quantity of the tier, zero for the last tier, which
+	// has no upper bound
+	UpTo int `json:"up_to"`
+	// UnitPrice is charged for each unit in the tier
+	UnitPrice string `json:"unit_price"`
+	// FlatPrice is charged once when the tier is reached
+	FlatPrice string `json:"flat_price"`
+}
+
+// CalculateAmount returns the amount charged for the quantity of the plan,
+// before discounts and taxes, according to its pricing model:
+//
+//   - PLAN_PRICING_MODEL_FLAT charges the price, whatever the quantity
+//   - PLAN_PRICING_MODEL_PER_UNIT charges the price for each unit
+//   - PLAN_PRICING_MODEL_GRADUATED charges each unit at the tier it falls in,
+//     i.e. the first 10 seats at 10.00 and the next ones at 8.00
+//   - PLAN_PRICING_MODEL_VOLUME charges all units at the tier the total
+//     quantity falls in, i.e. 8.00 for every seat once there are 11
+//
+// Plans without a pricing model are priced per unit.
+func CalculateAmount(plan PlanInterface, quantity int) (string, error) {
+	cents, err := planAmountCents(plan, quantity)
+	if err != nil {
+		return "", err
+	}
+	return centsAmount(cents), nil
+}
+
+// planAmountCents returns the amount charged for the quantity of the plan,
+// in cents
+func planAmountCents(plan PlanInterface, quantity int) (int64, error) {
+	if plan == nil {
+		return 0, errors.New("calculate amount. plan cannot be nil")
+	}
+	if quantity < 0 {
+		return 0, errors.New("calculate amount. quantity cannot be negative")
+	}
+
+	if err := planPricingValidate(plan); err != nil {
+		return 0, errors.New("calculate amount. " + err.Error())
+	}
+
+	switch plan.GetPricingModel() {
+	case PLAN_PRICING_MODEL_FLAT:
+		return amountCents(plan.GetPrice())
+	case PLAN_PRICING_MODEL_GRADUATED:
+		tiers, err := plan.GetPriceTiers()
+		if err != nil {
+			return 0, err
+		}
+		return graduatedAmountCents(tiers, quantity)
+	case PLAN_PRICING_MODEL_VOLUME:
+		tiers, err := plan.GetPriceTiers()
+		if err != nil {
+			return 0, err
+		}
+		return volumeAmountCents(tiers, quantity)
+	}
+
+	price, err := amountCents(plan.GetPrice())
+	if err != nil {
+		return 0, err
+	}
+	return price * int64(quantity), nil
+}
+
+// graduatedAmountCents charges each unit at the tier it falls in
+func graduatedAmountCents(tiers []PriceTier, quantity int) (int64, error) {
+	total := int64(0)
+	previousUpTo := 0
+
+	for _, tier := range tiers {
+		upTo := tier.UpTo
+		if upTo == 0 || upTo > quantity {
+			upTo = quantity
+		}
+
+		units := upTo - previousUpTo
+		if units <= 0 {
+			break
+		}
+
+		cents, err := tierAmountCents(tier, units)
+		if err != nil {
+			return 0, err
+		}
+
+		total += cents
+		previousUpTo = upTo
+	}
+
+	return total, nil
+}
+
+// volumeAmountCents charges all units at the tier the quantity falls in
+func volumeAmountCents(tiers []PriceTier, quantity int) (int64, error) {
+	for _, tier := range tiers {
+		if tier.UpTo == 0 || quantity <= tier.UpTo {
+			return tierAmountCents(tier, quantity)
+		}
+	}
+	return 0, errors.New("quantity exceeds the last price tier")
+}
+
+// tierAmountCents returns the flat price of the tier plus its unit price for
+// each of the units
+func tierAmountCents(tier PriceTier, units int) (int64, error) {
+	unitPrice, err := amountCents(tier.UnitPrice)
+	if err != nil {
+		return 0, errors.New("price tier unit price " + err.Error())
+	}
+	flatPrice, err := amountCents(tier.FlatPrice)
+	if err != nil {
+		return 0, errors.New("price tier flat price " + err.Error())
+	}
+	return flatPrice + unitPrice*int64(units), nil
+}
+
+// planPricingValidate checks that the pricing model of the plan is known,
+// and that tiered models have valid tiers
+func planPricingValidate(plan PlanInterface) error {
+	switch plan.GetPricingModel() {
+	case "", PLAN_PRICING_MODEL_FLAT, PLAN_PRICING_MODEL_PER_UNIT:
+		return nil
+	case PLAN_PRICING_MODEL_GRADUATED, PLAN_PRICING_MODEL_VOLUME:
+		tiers, err := plan.GetPriceTiers()
+		if err != nil {
+			return errors.New("price tiers are not valid json")
+		}
+		return priceTiersValidate(tiers)
+	}
+	return errors.New("unknown pricing model " + plan.GetPricingModel())
+}
+
+// priceTiersValidate checks that the tiers are in increasing order, with
+// the last one unbounded, and have valid prices
+func priceTiersValidate(tiers []PriceTier) error {
+	if len(tiers) == 0 {
+		return errors.New("price tiers cannot be empty")
+	}
+
+	previousUpTo := 0
+	for i, tier := range tiers {
+		position := strconv.Itoa(i + 1)
+		last := i == len(tiers)-1
+
+		if last && tier.UpTo != 0 {
+			return errors.New("price tier " + position + " is the last and must have no upper bound")
+		}
+		if !last && tier.UpTo <= previousUpTo {
+			return errors.New("price tier " + position + " must end after the previous tier")
+		}
+
+		for _, amount := range []string{tier.UnitPrice, tier.FlatPrice} {
+			cents, err := amountCents(amount)
+			if err != nil {
+				return errors.New("price tier " + position + " " + err.Error())
+			}
+			if cents < 0 {
+				return errors.New("price tier " + position + " prices cannot be negative")
+			}
+		}
+
+		previousUpTo = tier.UpTo
+	}
+
+	return nil
+}
+
+// priceTiersJSON encodes the tiers as stored in the plan table, empty if
+// there are none
+func priceTiersJSON(tiers []PriceTier) (string, error) {
+	if len(tiers) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(tiers)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,117 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCalculateAmount(t *testing.T) {
+	tiers := []PriceTier{
+		{UpTo: 10, UnitPrice: "10.00"},
+		{UpTo: 50, UnitPrice: "8.00"},
+		{UnitPrice: "5.00", FlatPrice: "20.00"},
+	}
+
+	testCases := []struct {
+		name     string
+		model    string
+		quantity int
+		expected string
+	}{
+		{name: "flat ignores the quantity", model: PLAN_PRICING_MODEL_FLAT, quantity: 7, expected: "12.00"},
+		{name: "per unit", model: PLAN_PRICING_MODEL_PER_UNIT, quantity: 7, expected: "84.00"},
+		{name: "no model is per unit", model: "", quantity: 3, expected: "36.00"},
+		{name: "per unit zero quantity", model: PLAN_PRICING_MODEL_PER_UNIT, quantity: 0, expected: "0.00"},
+		{name: "graduated first tier", model: PLAN_PRICING_MODEL_GRADUATED, quantity: 10, expected: "100.00"},
+		{name: "graduated second tier", model: PLAN_PRICING_MODEL_GRADUATED, quantity: 15, expected: "140.00"},
+		{name: "graduated last tier", model: PLAN_PRICING_MODEL_GRADUATED, quantity: 52, expected: "450.00"},
+		{name: "graduated zero quantity", model: PLAN_PRICING_MODEL_GRADUATED, quantity: 0, expected: "0.00"},
+		{name: "volume first tier", model: PLAN_PRICING_MODEL_VOLUME, quantity: 10, expected: "100.00"},
+		{name: "volume second tier", model: PLAN_PRICING_MODEL_VOLUME, quantity: 15, expected: "120.00"},
+		{name: "volume last tier", model: PLAN_PRICING_MODEL_VOLUME, quantity: 52, expected: "280.00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := NewPlan().SetPrice("12.00").SetPricingModel(tc.model)
+			if _, err := plan.SetPriceTiers(tiers); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			amount, err := CalculateAmount(plan, tc.quantity)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if amount != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, amount)
+			}
+		})
+	}
+}
+
+func TestCalculateAmountErrors(t *testing.T) {
+	if _, err := CalculateAmount(nil, 1); err == nil {
+		t.Error("expected error for a nil plan")
+	}
+	if _, err := CalculateAmount(NewPlan().SetPrice("1.00"), -1); err == nil {
+		t.Error("expected error for a negative quantity")
+	}
+	if _, err := CalculateAmount(NewPlan().SetPricingModel("custom"), 1); err == nil {
+		t.Error("expected error for an unknown pricing model")
+	}
+}
+
+func TestPriceTiersValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tiers    []PriceTier
+		contains string
+	}{
+		{
+			name:     "empty",
+			tiers:    nil,
+			contains: "cannot be empty",
+		},
+		{
+			name:     "bounded last tier",
+			tiers:    []PriceTier{{UpTo: 10, UnitPrice: "1.00"}},
+			contains: "must have no upper bound",
+		},
+		{
+			name:     "decreasing tiers",
+			tiers:    []PriceTier{{UpTo: 10, UnitPrice: "1.00"}, {UpTo: 5, UnitPrice: "0.50"}, {UnitPrice: "0.25"}},
+			contains: "must end after the previous tier",
+		},
+		{
+			name:     "unbounded tier before the last",
+			tiers:    []PriceTier{{UnitPrice: "1.00"}, {UnitPrice: "0.50"}},
+			contains: "must end after the previous tier",
+		},
+		{
+			name:     "negative price",
+			tiers:    []PriceTier{{UnitPrice: "-1.00"}},
+			contains: "cannot be negative",
+		},
+		{
+			name:     "price not a number",
+			tiers:    []PriceTier{{UnitPrice: "1.00", FlatPrice: "free"}},
+			contains: "not a number",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := priceTiersValidate(tc.tiers)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("expected error containing %q, got %q", tc.contains, err.Error())
+			}
+		})
+	}
+
+	if err := priceTiersValidate([]PriceTier{{UpTo: 10, UnitPrice: "1.00"}, {UnitPrice: "0.50"}}); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,183 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// PromotionCodeInterface defines the methods for a PromotionCode entity.
+// A promotion code is a code customers enter to redeem a coupon. A coupon
+// may have several codes, each with its own redemption limit and expiry.
+type PromotionCodeInterface interface {
+	GetCode() string
+	SetCode(code string) PromotionCodeInterface
+
+	GetCouponID() string
+	SetCouponID(couponID string) PromotionCodeInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) PromotionCodeInterface
+
+	GetExpiresAt() string
+	GetExpiresAtCarbon() *carbon.Carbon
+	SetExpiresAt(expiresAt string) PromotionCodeInterface
+
+	GetID() string
+	SetID(id string) PromotionCodeInterface
+
+	GetMaxRedemptions() int
+	SetMaxRedemptions(maxRedemptions int) PromotionCodeInterface
+
+	GetStatus() string
+	SetStatus(status string) PromotionCodeInterface
+
+	GetTimesRedeemed() int
+	SetTimesRedeemed(timesRedeemed int) PromotionCodeInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) PromotionCodeInterface
+}
+
+var _ PromotionCodeInterface = (*promotionCodeImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type promotionCodeImplementation struct {
+	orm.ShortID
+
+	CouponIDField       string `db:"coupon_id"`
+	CodeField           string `db:"code"`
+	StatusField         string `db:"status"`
+	MaxRedemptionsField int    `db:"max_redemptions"`
+	TimesRedeemedField  int    `db:"times_redeemed"`
+	ExpiresAtField      string `db:"expires_at"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewPromotionCode() PromotionCodeInterface {
+	o := &promotionCodeImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetCouponID("")
+	o.SetCode("")
+	o.SetStatus(PROMOTION_CODE_STATUS_ACTIVE)
+	o.SetMaxRedemptions(0)
+	o.SetTimesRedeemed(0)
+	o.SetExpiresAt(MAX_DATETIME)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *promotionCodeImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *promotionCodeImplementation) SetID(id string) PromotionCodeInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *promotionCodeImplementation) GetCouponID() string {
+	return o.CouponIDField
+}
+
+func (o *promotionCodeImplementation) SetCouponID(couponID string) PromotionCodeInterface {
+	o.CouponIDField = couponID
+	return o
+}
+
+func (o *promotionCodeImplementation) GetCode() string {
+	return o.CodeField
+}
+
+func (o *promotionCodeImplementation) SetCode(code string) PromotionCodeInterface {
+	o.CodeField = code
+	return o
+}
+
+func (o *promotionCodeImplementation) GetStatus() string {
+	return o.StatusField
+}
+
+func (o *promotionCodeImplementation) SetStatus(status string) PromotionCodeInterface {
+	o.StatusField = status
+	return o
+}
+
+func (o *promotionCodeImplementation) GetMaxRedemptions() int {
+	return o.MaxRedemptionsField
+}
+
+func (o *promotionCodeImplementation) SetMaxRedemptions(maxRedemptions int) PromotionCodeInterface {
+	o.MaxRedemptionsField = maxRedemptions
+	return o
+}
+
+func (o *promotionCodeImplementation) GetTimesRedeemed() int {
+	return o.TimesRedeemedField
+}
+
+func (o *promotionCodeImplementation) SetTimesRedeemed(timesRedeemed int) PromotionCodeInterface {
+	o.TimesRedeemedField = timesRedeemed
+	return o
+}
+
+func (o *promotionCodeImplementation) GetExpiresAt() string {
+	return o.ExpiresAtField
+}
+
+func (o *promotionCodeImplementation) GetExpiresAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetExpiresAt(), carbon.UTC)
+}
+
+func (o *promotionCodeImplementation) SetExpiresAt(expiresAt string) PromotionCodeInterface {
+	o.ExpiresAtField = expiresAt
+	return o
+}
+
+func (o *promotionCodeImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *promotionCodeImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *promotionCodeImplementation) SetCreatedAt(createdAt string) PromotionCodeInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *promotionCodeImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *promotionCodeImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *promotionCodeImplementation) SetUpdatedAt(updatedAt string) PromotionCodeInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
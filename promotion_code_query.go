@@ -0,0 +1,189 @@
+package subscriptionstore
+
+import "errors"
+
+// PromotionCodeQueryInterface defines the interface for querying promotion codes.
+type PromotionCodeQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) PromotionCodeQueryInterface
+
+	HasCouponID() bool
+	CouponID() string
+	SetCouponID(couponID string) PromotionCodeQueryInterface
+
+	HasCode() bool
+	Code() string
+	SetCode(code string) PromotionCodeQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) PromotionCodeQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) PromotionCodeQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) PromotionCodeQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) PromotionCodeQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) PromotionCodeQueryInterface
+}
+
+// PromotionCodeQuery is a shortcut alias for NewPromotionCodeQuery
+func PromotionCodeQuery() PromotionCodeQueryInterface {
+	return NewPromotionCodeQuery()
+}
+
+// NewPromotionCodeQuery creates a new promotion code query
+func NewPromotionCodeQuery() PromotionCodeQueryInterface {
+	return &promotionCodeQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ PromotionCodeQueryInterface = (*promotionCodeQueryImplementation)(nil)
+
+type promotionCodeQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *promotionCodeQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("promotion code query. id cannot be empty")
+	}
+	if q.HasCouponID() && q.CouponID() == "" {
+		return errors.New("promotion code query. coupon_id cannot be empty")
+	}
+	if q.HasCode() && q.Code() == "" {
+		return errors.New("promotion code query. code cannot be empty")
+	}
+	if q.HasStatus() && q.Status() == "" {
+		return errors.New("promotion code query. status cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("promotion code query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("promotion code query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *promotionCodeQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *promotionCodeQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetID(id string) PromotionCodeQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasCouponID() bool {
+	return q.hasProperty("coupon_id")
+}
+
+func (q *promotionCodeQueryImplementation) CouponID() string {
+	return q.properties["coupon_id"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetCouponID(couponID string) PromotionCodeQueryInterface {
+	q.properties["coupon_id"] = couponID
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasCode() bool {
+	return q.hasProperty("code")
+}
+
+func (q *promotionCodeQueryImplementation) Code() string {
+	return q.properties["code"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetCode(code string) PromotionCodeQueryInterface {
+	q.properties["code"] = code
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasStatus() bool {
+	return q.hasProperty("status")
+}
+
+func (q *promotionCodeQueryImplementation) Status() string {
+	return q.properties["status"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetStatus(status string) PromotionCodeQueryInterface {
+	q.properties["status"] = status
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *promotionCodeQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *promotionCodeQueryImplementation) SetOffset(offset int) PromotionCodeQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *promotionCodeQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *promotionCodeQueryImplementation) SetLimit(limit int) PromotionCodeQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *promotionCodeQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetOrderBy(orderBy string) PromotionCodeQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *promotionCodeQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *promotionCodeQueryImplementation) SetSortOrder(sortOrder string) PromotionCodeQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *promotionCodeQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
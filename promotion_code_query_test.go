@@ -0,0 +1,49 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromotionCodeQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(PromotionCodeQueryInterface)
+		contains string
+	}{
+		{
+			name:     "coupon_id empty",
+			setup:    func(q PromotionCodeQueryInterface) { q.SetCouponID("") },
+			contains: "coupon_id cannot be empty",
+		},
+		{
+			name:     "code empty",
+			setup:    func(q PromotionCodeQueryInterface) { q.SetCode("") },
+			contains: "code cannot be empty",
+		},
+		{
+			name:     "status empty",
+			setup:    func(q PromotionCodeQueryInterface) { q.SetStatus("") },
+			contains: "status cannot be empty",
+		},
+		{
+			name:     "limit negative",
+			setup:    func(q PromotionCodeQueryInterface) { q.SetLimit(-1) },
+			contains: "limit cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewPromotionCodeQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewPromotionCodeDefaults(t *testing.T) {
+	promotionCode := NewPromotionCode()
+
+	if promotionCode.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if promotionCode.GetStatus() != PROMOTION_CODE_STATUS_ACTIVE {
+		t.Fatalf("expected status %s, got %s", PROMOTION_CODE_STATUS_ACTIVE, promotionCode.GetStatus())
+	}
+	if promotionCode.GetExpiresAt() != MAX_DATETIME {
+		t.Fatalf("expected expires at %s, got %s", MAX_DATETIME, promotionCode.GetExpiresAt())
+	}
+}
+
+func TestPromotionCodeSettersAndGetters(t *testing.T) {
+	promotionCode := NewPromotionCode().
+		SetCouponID("coupon_1").
+		SetCode("SPRING20").
+		SetStatus(PROMOTION_CODE_STATUS_INACTIVE).
+		SetMaxRedemptions(10).
+		SetTimesRedeemed(2).
+		SetExpiresAt("2025-06-01 00:00:00")
+
+	if promotionCode.GetCouponID() != "coupon_1" {
+		t.Fatalf("expected coupon id coupon_1, got %s", promotionCode.GetCouponID())
+	}
+	if promotionCode.GetCode() != "SPRING20" {
+		t.Fatalf("expected code SPRING20, got %s", promotionCode.GetCode())
+	}
+	if promotionCode.GetStatus() != PROMOTION_CODE_STATUS_INACTIVE {
+		t.Fatalf("expected status %s, got %s", PROMOTION_CODE_STATUS_INACTIVE, promotionCode.GetStatus())
+	}
+	if promotionCode.GetMaxRedemptions() != 10 || promotionCode.GetTimesRedeemed() != 2 {
+		t.Fatalf("expected 2 of 10 redemptions, got %d of %d", promotionCode.GetTimesRedeemed(), promotionCode.GetMaxRedemptions())
+	}
+	if promotionCode.GetExpiresAtCarbon().ToDateTimeString() != "2025-06-01 00:00:00" {
+		t.Fatalf("expected expires at 2025-06-01 00:00:00, got %s", promotionCode.GetExpiresAt())
+	}
+}
@@ -0,0 +1,42 @@
+package subscriptionstore
+
+import (
+	"math"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// Proration is what a change to a subscription costs for the rest of its
+// current billing period. It is not billed by the store; charge it with the
+// payment provider, or record it with InvoiceCreate.
+type Proration struct {
+	SubscriptionID string
+	FromQuantity   int
+	ToQuantity     int
+	// ProratedAt is when the change took effect
+	ProratedAt string
+	PeriodEnd  string
+	Currency   string
+	// Amount is positive for a charge, and negative for a credit
+	Amount string
+}
+
+// prorateCents returns the share of the amount for the part of the period
+// from at until its end. Nothing is prorated outside the period, or for a
+// period which does not end.
+func prorateCents(cents int64, periodStart *carbon.Carbon, periodEnd *carbon.Carbon, at *carbon.Carbon) int64 {
+	if periodStart.IsInvalid() || periodEnd.IsInvalid() || periodEnd.ToDateTimeString(carbon.UTC) == MAX_DATETIME {
+		return 0
+	}
+	if at.Lt(periodStart) || !at.Lt(periodEnd) {
+		return 0
+	}
+
+	periodSeconds := periodStart.DiffInSeconds(periodEnd)
+	remainingSeconds := at.DiffInSeconds(periodEnd)
+	if periodSeconds <= 0 {
+		return 0
+	}
+
+	return int64(math.Round(float64(cents) * float64(remainingSeconds) / float64(periodSeconds)))
+}
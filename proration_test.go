@@ -0,0 +1,36 @@
+package subscriptionstore
+
+import (
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestProrateCents(t *testing.T) {
+	periodStart := carbon.Parse("2025-04-01 00:00:00", carbon.UTC)
+	periodEnd := carbon.Parse("2025-05-01 00:00:00", carbon.UTC)
+
+	testCases := []struct {
+		name     string
+		cents    int64
+		end      *carbon.Carbon
+		at       string
+		expected int64
+	}{
+		{name: "start of period", cents: 3000, end: periodEnd, at: "2025-04-01 00:00:00", expected: 3000},
+		{name: "two thirds left", cents: 3000, end: periodEnd, at: "2025-04-11 00:00:00", expected: 2000},
+		{name: "credit", cents: -3000, end: periodEnd, at: "2025-04-21 00:00:00", expected: -1000},
+		{name: "end of period", cents: 3000, end: periodEnd, at: "2025-05-01 00:00:00", expected: 0},
+		{name: "before period", cents: 3000, end: periodEnd, at: "2025-03-31 00:00:00", expected: 0},
+		{name: "period without end", cents: 3000, end: carbon.Parse(MAX_DATETIME, carbon.UTC), at: "2025-04-11 00:00:00", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cents := prorateCents(tc.cents, periodStart, tc.end, carbon.Parse(tc.at, carbon.UTC))
+			if cents != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, cents)
+			}
+		})
+	}
+}
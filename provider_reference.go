@@ -0,0 +1,146 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// ProviderReferenceInterface defines the methods for a ProviderReference entity.
+// A provider reference maps a local object (a plan, a subscription or a
+// subscriber) to the object representing it at a payment provider.
+type ProviderReferenceInterface interface {
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) ProviderReferenceInterface
+
+	GetExternalID() string
+	SetExternalID(externalID string) ProviderReferenceInterface
+
+	GetID() string
+	SetID(id string) ProviderReferenceInterface
+
+	GetLocalID() string
+	SetLocalID(localID string) ProviderReferenceInterface
+
+	GetObjectType() string
+	SetObjectType(objectType string) ProviderReferenceInterface
+
+	GetProvider() string
+	SetProvider(provider string) ProviderReferenceInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) ProviderReferenceInterface
+}
+
+var _ ProviderReferenceInterface = (*providerReferenceImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type providerReferenceImplementation struct {
+	orm.ShortID
+
+	ProviderField   string `db:"provider"`
+	ObjectTypeField string `db:"object_type"`
+	LocalIDField    string `db:"local_id"`
+	ExternalIDField string `db:"external_id"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewProviderReference() ProviderReferenceInterface {
+	o := &providerReferenceImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *providerReferenceImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *providerReferenceImplementation) SetID(id string) ProviderReferenceInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *providerReferenceImplementation) GetProvider() string {
+	return o.ProviderField
+}
+
+func (o *providerReferenceImplementation) SetProvider(provider string) ProviderReferenceInterface {
+	o.ProviderField = provider
+	return o
+}
+
+func (o *providerReferenceImplementation) GetObjectType() string {
+	return o.ObjectTypeField
+}
+
+func (o *providerReferenceImplementation) SetObjectType(objectType string) ProviderReferenceInterface {
+	o.ObjectTypeField = objectType
+	return o
+}
+
+func (o *providerReferenceImplementation) GetLocalID() string {
+	return o.LocalIDField
+}
+
+func (o *providerReferenceImplementation) SetLocalID(localID string) ProviderReferenceInterface {
+	o.LocalIDField = localID
+	return o
+}
+
+func (o *providerReferenceImplementation) GetExternalID() string {
+	return o.ExternalIDField
+}
+
+func (o *providerReferenceImplementation) SetExternalID(externalID string) ProviderReferenceInterface {
+	o.ExternalIDField = externalID
+	return o
+}
+
+func (o *providerReferenceImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *providerReferenceImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *providerReferenceImplementation) SetCreatedAt(createdAt string) ProviderReferenceInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *providerReferenceImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *providerReferenceImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *providerReferenceImplementation) SetUpdatedAt(updatedAt string) ProviderReferenceInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
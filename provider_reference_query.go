@@ -0,0 +1,209 @@
+package subscriptionstore
+
+import "errors"
+
+// ProviderReferenceQueryInterface defines the interface for querying provider references.
+type ProviderReferenceQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) ProviderReferenceQueryInterface
+
+	HasProvider() bool
+	Provider() string
+	SetProvider(provider string) ProviderReferenceQueryInterface
+
+	HasObjectType() bool
+	ObjectType() string
+	SetObjectType(objectType string) ProviderReferenceQueryInterface
+
+	HasLocalID() bool
+	LocalID() string
+	SetLocalID(localID string) ProviderReferenceQueryInterface
+
+	HasExternalID() bool
+	ExternalID() string
+	SetExternalID(externalID string) ProviderReferenceQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) ProviderReferenceQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) ProviderReferenceQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) ProviderReferenceQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) ProviderReferenceQueryInterface
+}
+
+// ProviderReferenceQuery is a shortcut alias for NewProviderReferenceQuery
+func ProviderReferenceQuery() ProviderReferenceQueryInterface {
+	return NewProviderReferenceQuery()
+}
+
+// NewProviderReferenceQuery creates a new provider reference query
+func NewProviderReferenceQuery() ProviderReferenceQueryInterface {
+	return &providerReferenceQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ ProviderReferenceQueryInterface = (*providerReferenceQueryImplementation)(nil)
+
+type providerReferenceQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *providerReferenceQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("provider reference query. id cannot be empty")
+	}
+	if q.HasProvider() && q.Provider() == "" {
+		return errors.New("provider reference query. provider cannot be empty")
+	}
+	if q.HasObjectType() && q.ObjectType() == "" {
+		return errors.New("provider reference query. object_type cannot be empty")
+	}
+	if q.HasLocalID() && q.LocalID() == "" {
+		return errors.New("provider reference query. local_id cannot be empty")
+	}
+	if q.HasExternalID() && q.ExternalID() == "" {
+		return errors.New("provider reference query. external_id cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("provider reference query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("provider reference query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *providerReferenceQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *providerReferenceQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetID(id string) ProviderReferenceQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasProvider() bool {
+	return q.hasProperty("provider")
+}
+
+func (q *providerReferenceQueryImplementation) Provider() string {
+	return q.properties["provider"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetProvider(provider string) ProviderReferenceQueryInterface {
+	q.properties["provider"] = provider
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasObjectType() bool {
+	return q.hasProperty("object_type")
+}
+
+func (q *providerReferenceQueryImplementation) ObjectType() string {
+	return q.properties["object_type"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetObjectType(objectType string) ProviderReferenceQueryInterface {
+	q.properties["object_type"] = objectType
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasLocalID() bool {
+	return q.hasProperty("local_id")
+}
+
+func (q *providerReferenceQueryImplementation) LocalID() string {
+	return q.properties["local_id"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetLocalID(localID string) ProviderReferenceQueryInterface {
+	q.properties["local_id"] = localID
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasExternalID() bool {
+	return q.hasProperty("external_id")
+}
+
+func (q *providerReferenceQueryImplementation) ExternalID() string {
+	return q.properties["external_id"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetExternalID(externalID string) ProviderReferenceQueryInterface {
+	q.properties["external_id"] = externalID
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *providerReferenceQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *providerReferenceQueryImplementation) SetOffset(offset int) ProviderReferenceQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *providerReferenceQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *providerReferenceQueryImplementation) SetLimit(limit int) ProviderReferenceQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *providerReferenceQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetOrderBy(orderBy string) ProviderReferenceQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *providerReferenceQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *providerReferenceQueryImplementation) SetSortOrder(sortOrder string) ProviderReferenceQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *providerReferenceQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
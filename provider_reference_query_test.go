@@ -0,0 +1,54 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProviderReferenceQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(ProviderReferenceQueryInterface)
+		contains string
+	}{
+		{
+			name:     "provider empty",
+			setup:    func(q ProviderReferenceQueryInterface) { q.SetProvider("") },
+			contains: "provider cannot be empty",
+		},
+		{
+			name:     "object_type empty",
+			setup:    func(q ProviderReferenceQueryInterface) { q.SetObjectType("") },
+			contains: "object_type cannot be empty",
+		},
+		{
+			name:     "local_id empty",
+			setup:    func(q ProviderReferenceQueryInterface) { q.SetLocalID("") },
+			contains: "local_id cannot be empty",
+		},
+		{
+			name:     "external_id empty",
+			setup:    func(q ProviderReferenceQueryInterface) { q.SetExternalID("") },
+			contains: "external_id cannot be empty",
+		},
+		{
+			name:     "limit negative",
+			setup:    func(q ProviderReferenceQueryInterface) { q.SetLimit(-1) },
+			contains: "limit cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewProviderReferenceQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
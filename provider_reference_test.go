@@ -0,0 +1,30 @@
+package subscriptionstore
+
+import "testing"
+
+func TestProviderReferenceSettersAndGetters(t *testing.T) {
+	reference := NewProviderReference().
+		SetProvider(PAYMENT_PROVIDER_PADDLE).
+		SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).
+		SetLocalID("plan_1").
+		SetExternalID("pri_01h")
+
+	if reference.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if reference.GetProvider() != PAYMENT_PROVIDER_PADDLE {
+		t.Fatalf("expected provider %s, got %s", PAYMENT_PROVIDER_PADDLE, reference.GetProvider())
+	}
+	if reference.GetObjectType() != PROVIDER_OBJECT_TYPE_PRICE {
+		t.Fatalf("expected object type %s, got %s", PROVIDER_OBJECT_TYPE_PRICE, reference.GetObjectType())
+	}
+	if reference.GetLocalID() != "plan_1" {
+		t.Fatalf("expected local id plan_1, got %s", reference.GetLocalID())
+	}
+	if reference.GetExternalID() != "pri_01h" {
+		t.Fatalf("expected external id pri_01h, got %s", reference.GetExternalID())
+	}
+	if reference.GetCreatedAt() == "" || reference.GetUpdatedAt() == "" {
+		t.Fatal("created at and updated at should not be empty")
+	}
+}
@@ -0,0 +1,127 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dracory/subscriptionstore"
+)
+
+// FakeCatalog is an in-memory PlanCatalog. It is safe for concurrent use.
+type FakeCatalog struct {
+	name string
+
+	mu       sync.Mutex
+	sequence int
+	err      error
+	prices   map[string]subscriptionstore.CatalogPrice
+}
+
+var _ subscriptionstore.PlanCatalog = (*FakeCatalog)(nil)
+
+// NewFakeCatalog creates a fake catalog with the given provider name
+func NewFakeCatalog(name string) *FakeCatalog {
+	return &FakeCatalog{
+		name:   name,
+		prices: map[string]subscriptionstore.CatalogPrice{},
+	}
+}
+
+// FailWith makes every following call fail with the given error,
+// until it is called again with nil
+func (c *FakeCatalog) FailWith(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Put adds or replaces a price, as if it was edited at the provider.
+// Prices without an id get a new one, which is returned.
+func (c *FakeCatalog) Put(price subscriptionstore.CatalogPrice) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if price.ID == "" {
+		price.ID = c.nextID()
+	}
+	c.prices[price.ID] = price
+	return price.ID
+}
+
+// Price returns the price with the given id
+func (c *FakeCatalog) Price(id string) (subscriptionstore.CatalogPrice, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	price, ok := c.prices[id]
+	return price, ok
+}
+
+func (c *FakeCatalog) Name() string {
+	return c.name
+}
+
+func (c *FakeCatalog) PriceList(ctx context.Context) ([]subscriptionstore.CatalogPrice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	prices := make([]subscriptionstore.CatalogPrice, 0, len(c.prices))
+	for _, price := range c.prices {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].ID < prices[j].ID })
+	return prices, nil
+}
+
+func (c *FakeCatalog) PriceCreate(ctx context.Context, plan subscriptionstore.PlanInterface) (subscriptionstore.CatalogPrice, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return subscriptionstore.CatalogPrice{}, c.err
+	}
+
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return subscriptionstore.CatalogPrice{}, err
+	}
+
+	price := subscriptionstore.CatalogPrice{
+		ID:           c.nextID(),
+		Title:        plan.GetTitle(),
+		Price:        plan.GetPrice(),
+		Currency:     strings.ToLower(plan.GetCurrency()),
+		Interval:     plan.GetInterval(),
+		PricingModel: plan.GetPricingModel(),
+		Tiers:        tiers,
+		Active:       plan.GetStatus() == subscriptionstore.PLAN_STATUS_ACTIVE,
+	}
+	c.prices[price.ID] = price
+	return price, nil
+}
+
+func (c *FakeCatalog) PriceSetActive(ctx context.Context, priceID string, active bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+
+	price, ok := c.prices[priceID]
+	if !ok {
+		return errors.New("fake catalog: no such price " + priceID)
+	}
+	price.Active = active
+	c.prices[priceID] = price
+	return nil
+}
+
+// nextID returns a new price id. The caller must hold the lock.
+func (c *FakeCatalog) nextID() string {
+	c.sequence++
+	return "price_" + c.name + "_" + strconv.Itoa(c.sequence)
+}
@@ -0,0 +1,151 @@
+// Package providertest provides an in-memory payment provider and plan
+// catalog, to test code using a subscriptionstore.PaymentProvider or
+// subscriptionstore.PlanCatalog without a network.
+package providertest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/dracory/subscriptionstore"
+)
+
+// FakeSubscription is a subscription created at the fake provider
+type FakeSubscription struct {
+	ID          string
+	CustomerID  string
+	PriceID     string
+	Cancelled   bool
+	AtPeriodEnd bool
+}
+
+// FakeProvider is an in-memory PaymentProvider. It is safe for concurrent use.
+type FakeProvider struct {
+	name string
+
+	mu            sync.Mutex
+	sequence      int
+	err           error
+	customers     map[string]string
+	plans         map[string]string
+	subscriptions map[string]FakeSubscription
+}
+
+var _ subscriptionstore.PaymentProvider = (*FakeProvider)(nil)
+
+// NewFakeProvider creates a fake provider with the given name
+func NewFakeProvider(name string) *FakeProvider {
+	return &FakeProvider{
+		name:          name,
+		customers:     map[string]string{},
+		plans:         map[string]string{},
+		subscriptions: map[string]FakeSubscription{},
+	}
+}
+
+// FailWith makes every following call fail with the given error,
+// until it is called again with nil
+func (p *FakeProvider) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Customers returns the created customers, keyed by id,
+// with the subscriber id they were created for
+func (p *FakeProvider) Customers() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return copyMap(p.customers)
+}
+
+// Plans returns the created plans, keyed by id, with the local plan id
+func (p *FakeProvider) Plans() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return copyMap(p.plans)
+}
+
+// Subscription returns the subscription with the given id
+func (p *FakeProvider) Subscription(id string) (FakeSubscription, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	subscription, ok := p.subscriptions[id]
+	return subscription, ok
+}
+
+func (p *FakeProvider) Name() string {
+	return p.name
+}
+
+func (p *FakeProvider) CustomerCreate(ctx context.Context, subscriberID string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", p.err
+	}
+
+	id := p.nextID("cus")
+	p.customers[id] = subscriberID
+	return id, nil
+}
+
+func (p *FakeProvider) PlanCreate(ctx context.Context, plan subscriptionstore.PlanInterface) (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", "", p.err
+	}
+
+	planID := p.nextID("prod")
+	p.plans[planID] = plan.GetID()
+	return planID, p.nextID("price"), nil
+}
+
+func (p *FakeProvider) SubscriptionCreate(ctx context.Context, customerID string, priceID string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", p.err
+	}
+	if _, ok := p.customers[customerID]; !ok {
+		return "", errors.New("fake provider: no such customer " + customerID)
+	}
+
+	id := p.nextID("sub")
+	p.subscriptions[id] = FakeSubscription{ID: id, CustomerID: customerID, PriceID: priceID}
+	return id, nil
+}
+
+func (p *FakeProvider) SubscriptionCancel(ctx context.Context, subscriptionID string, atPeriodEnd bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+
+	subscription, ok := p.subscriptions[subscriptionID]
+	if !ok {
+		return errors.New("fake provider: no such subscription " + subscriptionID)
+	}
+	subscription.Cancelled = true
+	subscription.AtPeriodEnd = atPeriodEnd
+	p.subscriptions[subscriptionID] = subscription
+	return nil
+}
+
+// nextID returns a new id with the given prefix. The caller must hold the lock.
+func (p *FakeProvider) nextID(prefix string) string {
+	p.sequence++
+	return prefix + "_" + p.name + "_" + strconv.Itoa(p.sequence)
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
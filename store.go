@@ -12,17 +12,23 @@ import (
 	"github.com/dracory/neat"
 	contractsorm "github.com/dracory/neat/contracts/database/orm"
 	contractsschema "github.com/dracory/neat/contracts/database/schema"
+	"github.com/dracory/neat/database/schema/grammars"
 	"github.com/dromara/carbon/v2"
 	"github.com/samber/lo"
 )
 
-// StoreInterface defines the interface for the subscription store.
-type StoreInterface interface {
-	MigrateDown(ctx context.Context, tx ...*sql.Tx) error
-	MigrateUp(ctx context.Context, tx ...*sql.Tx) error
-	EnableDebug(debug bool)
+// PlanSubscriptionStore defines the plans and the subscriptions part of the
+// store, which both the SQL store and the memory store implement.
+type PlanSubscriptionStore interface {
+	PlanStore
+	SubscriptionStore
+}
 
+// PlanStore defines the methods of the store for the plans and their
+// versions.
+type PlanStore interface {
 	PlanCount(ctx context.Context, query PlanQueryInterface) (int64, error)
+	PlanCountBy(ctx context.Context, query PlanQueryInterface, column string) (map[string]int64, error)
 	PlanCreate(ctx context.Context, plan PlanInterface) error
 	PlanDelete(ctx context.Context, plan PlanInterface) error
 	PlanDeleteByID(ctx context.Context, id string) error
@@ -31,122 +37,166 @@ type StoreInterface interface {
 	PlanList(ctx context.Context, query PlanQueryInterface) ([]PlanInterface, error)
 	PlanSoftDelete(ctx context.Context, plan PlanInterface) error
 	PlanSoftDeleteByID(ctx context.Context, id string) error
-	PlanTableName() string
 	PlanUpdate(ctx context.Context, plan PlanInterface) error
+	PlanVersionFindByID(ctx context.Context, id string) (PlanVersionInterface, error)
+	PlanVersionList(ctx context.Context, query PlanVersionQueryInterface) ([]PlanVersionInterface, error)
+}
 
+// SubscriptionStore defines the methods of the store for the subscriptions
+// and their lifecycle.
+type SubscriptionStore interface {
+	SubscriptionCancel(ctx context.Context, subscriptionID string, options SubscriptionCancelOptions) error
+	SubscriptionCancellationsByReason(ctx context.Context, query SubscriptionQueryInterface) (map[string]int64, error)
 	SubscriptionCount(ctx context.Context, query SubscriptionQueryInterface) (int64, error)
+	SubscriptionCountBy(ctx context.Context, query SubscriptionQueryInterface, column string) (map[string]int64, error)
 	SubscriptionCreate(ctx context.Context, subscription SubscriptionInterface) error
 	SubscriptionDelete(ctx context.Context, subscription SubscriptionInterface) error
 	SubscriptionDeleteByID(ctx context.Context, id string) error
 	SubscriptionExists(ctx context.Context, subscriptionID string) (bool, error)
+	SubscriptionFindActiveBySubscriberID(ctx context.Context, subscriberID string) (SubscriptionInterface, error)
 	SubscriptionFindByID(ctx context.Context, id string) (SubscriptionInterface, error)
 	SubscriptionList(ctx context.Context, query SubscriptionQueryInterface) ([]SubscriptionInterface, error)
+	SubscriptionPause(ctx context.Context, id string, resumeAt string) error
+	SubscriptionPlan(ctx context.Context, subscriptionID string) (PlanInterface, error)
+	SubscriptionResume(ctx context.Context, id string) error
+	SubscriptionResumeDue(ctx context.Context, now string) ([]SubscriptionInterface, error)
 	SubscriptionSoftDelete(ctx context.Context, subscription SubscriptionInterface) error
 	SubscriptionSoftDeleteByID(ctx context.Context, id string) error
-	SubscriptionTableName() string
+	SubscriptionUncancel(ctx context.Context, subscriptionID string) error
 	SubscriptionUpdate(ctx context.Context, subscription SubscriptionInterface) error
 }
 
-var _ StoreInterface = (*storeImplementation)(nil)
+// StoreInterface defines the interface for the subscription store.
+type StoreInterface interface {
+	PlanStore
+	SubscriptionStore
 
-// == TYPE =====================================================================
+	MigrateDown(ctx context.Context, tx ...*sql.Tx) error
+	MigrateUp(ctx context.Context, tx ...*sql.Tx) error
+	MigrationStatus(ctx context.Context) ([]MigrationState, error)
+	MigrationTableName() string
+	EnableDebug(debug bool)
+	Transaction(ctx context.Context, fn func(txStore StoreInterface) error) error
+
+	CouponCreate(ctx context.Context, coupon CouponInterface) error
+	CouponFindByID(ctx context.Context, id string) (CouponInterface, error)
+	CouponList(ctx context.Context, query CouponQueryInterface) ([]CouponInterface, error)
+	CouponTableName() string
+	CouponUpdate(ctx context.Context, coupon CouponInterface) error
+
+	DunningAttemptCreate(ctx context.Context, attempt DunningAttemptInterface) error
+	DunningAttemptList(ctx context.Context, query DunningAttemptQueryInterface) ([]DunningAttemptInterface, error)
+	DunningAttemptTableName() string
+	DunningAttemptUpdate(ctx context.Context, attempt DunningAttemptInterface) error
+	DunningDue(ctx context.Context, now string) ([]DunningAttemptInterface, error)
+	DunningRecordFailure(ctx context.Context, subscriptionID string, reason string) (DunningAttemptInterface, error)
+	DunningRecordSuccess(ctx context.Context, subscriptionID string) error
+
+	InvoiceCreate(ctx context.Context, invoice InvoiceInterface) error
+	InvoiceCreateForPeriod(ctx context.Context, subscriptionID string, periodStart string) (InvoiceInterface, error)
+	InvoiceFindByID(ctx context.Context, id string) (InvoiceInterface, error)
+	InvoiceList(ctx context.Context, query InvoiceQueryInterface) ([]InvoiceInterface, error)
+	InvoiceTableName() string
+	InvoiceUpdate(ctx context.Context, invoice InvoiceInterface) error
+
+	PaymentMethodCreate(ctx context.Context, paymentMethod PaymentMethodInterface) error
+	PaymentMethodDeleteByID(ctx context.Context, id string) error
+	PaymentMethodExpiring(ctx context.Context, from string, to string) ([]PaymentMethodInterface, error)
+	PaymentMethodFindByID(ctx context.Context, id string) (PaymentMethodInterface, error)
+	PaymentMethodList(ctx context.Context, query PaymentMethodQueryInterface) ([]PaymentMethodInterface, error)
+	PaymentMethodTableName() string
+	PaymentMethodUpdate(ctx context.Context, paymentMethod PaymentMethodInterface) error
 
-type storeImplementation struct {
-	planTableName         string
-	subscriptionTableName string
-	db                    *neat.Database
-	automigrateEnabled    bool
-	debugEnabled          bool
-	sqlLogger             *slog.Logger
+	PlanTableName() string
+	PlanVersionMigrationCancel(ctx context.Context, id string) error
+	PlanVersionMigrationCreate(ctx context.Context, migration PlanVersionMigrationInterface) error
+	PlanVersionMigrationList(ctx context.Context, query PlanVersionMigrationQueryInterface) ([]PlanVersionMigrationInterface, error)
+	PlanVersionMigrationRunDue(ctx context.Context, now string) ([]PlanVersionMigrationInterface, error)
+	PlanVersionMigrationTableName() string
+	PlanVersionTableName() string
+
+	PromotionCodeCreate(ctx context.Context, promotionCode PromotionCodeInterface) error
+	PromotionCodeFindByCode(ctx context.Context, code string) (PromotionCodeInterface, error)
+	PromotionCodeList(ctx context.Context, query PromotionCodeQueryInterface) ([]PromotionCodeInterface, error)
+	PromotionCodeTableName() string
+	PromotionCodeUpdate(ctx context.Context, promotionCode PromotionCodeInterface) error
+
+	ProviderReferenceCreate(ctx context.Context, reference ProviderReferenceInterface) error
+	ProviderReferenceDeleteByID(ctx context.Context, id string) error
+	ProviderReferenceFindByExternalID(ctx context.Context, provider string, objectType string, externalID string) (ProviderReferenceInterface, error)
+	ProviderReferenceFindByLocalID(ctx context.Context, provider string, objectType string, localID string) (ProviderReferenceInterface, error)
+	ProviderReferenceList(ctx context.Context, query ProviderReferenceQueryInterface) ([]ProviderReferenceInterface, error)
+	ProviderReferenceTableName() string
+
+	QuotePrice(ctx context.Context, planID string, subscriber SubscriberContext) (PriceQuote, error)
+
+	SubscriberCreate(ctx context.Context, subscriber SubscriberInterface) error
+	SubscriberFindByID(ctx context.Context, id string) (SubscriberInterface, error)
+	SubscriberList(ctx context.Context, query SubscriberQueryInterface) ([]SubscriberInterface, error)
+	SubscriberTableName() string
+	SubscriberUpdate(ctx context.Context, subscriber SubscriberInterface) error
+
+	SubscriptionApplyCoupon(ctx context.Context, subscriptionID string, couponID string) (SubscriptionDiscountInterface, error)
+	SubscriptionApplyPromotionCode(ctx context.Context, subscriptionID string, code string) (SubscriptionDiscountInterface, error)
+	SubscriptionChurn(ctx context.Context, from string, to string) (ChurnReport, error)
+	SubscriptionCounts(ctx context.Context, query SubscriptionQueryInterface) (SubscriptionCountReport, error)
+	SubscriptionDiscountList(ctx context.Context, query SubscriptionDiscountQueryInterface) ([]SubscriptionDiscountInterface, error)
+	SubscriptionDiscountTableName() string
+	SubscriptionHasSeats(ctx context.Context, subscriptionID string, seats int) (bool, error)
+	SubscriptionItemCreate(ctx context.Context, item SubscriptionItemInterface) error
+	SubscriptionItemList(ctx context.Context, query SubscriptionItemQueryInterface) ([]SubscriptionItemInterface, error)
+	SubscriptionItemRemove(ctx context.Context, id string) error
+	SubscriptionItemTableName() string
+	SubscriptionMRR(ctx context.Context, at string) (map[string]string, error)
+	SubscriptionMRRMovement(ctx context.Context, from string, to string) ([]MRRMovement, error)
+	SubscriptionPriceForPeriod(ctx context.Context, subscriptionID string, periodStart string) (PeriodPrice, error)
+	SubscriptionScheduleCancel(ctx context.Context, id string) error
+	SubscriptionScheduleCreate(ctx context.Context, schedule SubscriptionScheduleInterface) error
+	SubscriptionScheduleFindByID(ctx context.Context, id string) (SubscriptionScheduleInterface, error)
+	SubscriptionScheduleList(ctx context.Context, query SubscriptionScheduleQueryInterface) ([]SubscriptionScheduleInterface, error)
+	SubscriptionScheduleRunDue(ctx context.Context, now string) ([]SubscriptionScheduleInterface, error)
+	SubscriptionScheduleTableName() string
+	SubscriptionScheduleUpcoming(ctx context.Context, from string, until string) ([]ScheduledChange, error)
+	SubscriptionSetQuantity(ctx context.Context, subscriptionID string, quantity int) (Proration, error)
+	SubscriptionSignupCohorts(ctx context.Context, from string, to string) ([]SignupCohort, error)
+	SubscriptionTableName() string
 }
 
-// PUBLIC METHODS ==============================================================
-
-// MigrateUp creates the plans and subscriptions tables if they do not exist
-func (st *storeImplementation) MigrateUp(ctx context.Context, tx ...*sql.Tx) error {
-	if st.db.Schema().HasTable(st.planTableName) {
-		if st.debugEnabled {
-			st.sqlLogger.Info("MigrateUp: plan table already exists", "table", st.planTableName)
-		}
-	} else {
-		err := st.db.Schema().Create(st.planTableName, func(table contractsschema.Blueprint) {
-			table.String(COLUMN_ID, 40)
-			table.Primary(COLUMN_ID)
-			table.String(COLUMN_TYPE, 50)
-			table.String(COLUMN_STATUS, 40)
-			table.String(COLUMN_TITLE, 100)
-			table.Text(COLUMN_DESCRIPTION)
-			table.String(COLUMN_INTERVAL, 40)
-			table.String(COLUMN_CURRENCY, 40)
-			table.String(COLUMN_PRICE, 40)
-			table.String(COLUMN_STRIPE_PRICE_ID, 100)
-			table.Text(COLUMN_FEATURES)
-			table.Text(COLUMN_MEMO)
-			table.Text(COLUMN_METAS)
-			table.DateTime(COLUMN_CREATED_AT)
-			table.DateTime(COLUMN_UPDATED_AT)
-			table.DateTime(COLUMN_SOFT_DELETED_AT)
-		})
-		if err != nil {
-			if st.debugEnabled {
-				st.sqlLogger.Error("MigrateUp: plan table failed", "error", err)
-			}
-			return err
-		}
-	}
+var _ StoreInterface = (*storeImplementation)(nil)
 
-	if st.db.Schema().HasTable(st.subscriptionTableName) {
-		if st.debugEnabled {
-			st.sqlLogger.Info("MigrateUp: subscription table already exists", "table", st.subscriptionTableName)
-		}
-	} else {
-		err := st.db.Schema().Create(st.subscriptionTableName, func(table contractsschema.Blueprint) {
-			table.String(COLUMN_ID, 40)
-			table.Primary(COLUMN_ID)
-			table.String(COLUMN_STATUS, 40)
-			table.String(COLUMN_SUBSCRIBER_ID, 50)
-			table.String(COLUMN_PLAN_ID, 50)
-			table.DateTime(COLUMN_PERIOD_START)
-			table.DateTime(COLUMN_PERIOD_END)
-			table.String(COLUMN_CANCEL_AT_PERIOD_END, 3)
-			table.String(COLUMN_PAYMENT_METHOD_ID, 40)
-			table.Text(COLUMN_MEMO)
-			table.Text(COLUMN_METAS)
-			table.DateTime(COLUMN_CREATED_AT)
-			table.DateTime(COLUMN_UPDATED_AT)
-			table.DateTime(COLUMN_SOFT_DELETED_AT)
-		})
-		if err != nil {
-			if st.debugEnabled {
-				st.sqlLogger.Error("MigrateUp: subscription table failed", "error", err)
-			}
-			return err
-		}
-	}
+// == TYPE =====================================================================
 
-	return nil
+type storeImplementation struct {
+	planTableName                 string
+	subscriptionTableName         string
+	migrationTableName            string
+	dunningAttemptTableName       string
+	dunningRetryDays              []int
+	invoiceTableName              string
+	providerReferenceTableName    string
+	couponTableName               string
+	promotionCodeTableName        string
+	subscriptionDiscountTableName string
+	subscriptionItemTableName     string
+	subscriptionScheduleTableName string
+	subscriberTableName           string
+	paymentMethodTableName        string
+	planVersionTableName          string
+	planVersionMigrationTableName string
+	planMetaColumns               []string
+	subscriptionMetaColumns       []string
+	taxRateResolver               TaxRateResolver
+	db                            *neat.Database
+	automigrateEnabled            bool
+	debugEnabled                  bool
+	sqlLogger                     *slog.Logger
+
+	// tx is the transaction the queries of the store run in, nil outside
+	// of transactions
+	tx contractsorm.QueryWithContext
 }
 
-// MigrateDown drops the plans and subscriptions tables
-func (st *storeImplementation) MigrateDown(ctx context.Context, tx ...*sql.Tx) error {
-	if st.db.Schema().HasTable(st.planTableName) {
-		if err := st.db.Schema().Drop(st.planTableName); err != nil {
-			if st.debugEnabled {
-				st.sqlLogger.Error("MigrateDown: plan table failed", "error", err)
-			}
-			return err
-		}
-	}
-	if st.db.Schema().HasTable(st.subscriptionTableName) {
-		if err := st.db.Schema().Drop(st.subscriptionTableName); err != nil {
-			if st.debugEnabled {
-				st.sqlLogger.Error("MigrateDown: subscription table failed", "error", err)
-			}
-			return err
-		}
-	}
-	return nil
-}
+// PUBLIC METHODS ==============================================================
 
 // EnableDebug enables the debug option
 func (st *storeImplementation) EnableDebug(debug bool) {
@@ -160,11 +210,90 @@ func (st *storeImplementation) EnableDebug(debug bool) {
 	}
 }
 
+// Transaction runs fn with a store whose calls run in a single transaction
+// on the primary DB, committed if fn returns nil and rolled back otherwise.
+// Reads made with the store see the writes made before them in fn, even when
+// read DBs are set. Migrations do not run in the transaction.
+func (st *storeImplementation) Transaction(ctx context.Context, fn func(txStore StoreInterface) error) error {
+	if fn == nil {
+		return errors.New("subscriptionstore > transaction. fn cannot be nil")
+	}
+
+	return st.transaction(func(txStore *storeImplementation) error {
+		return fn(txStore)
+	})
+}
+
+// CouponTableName returns the coupon table name
+func (st *storeImplementation) CouponTableName() string {
+	return st.couponTableName
+}
+
+// DunningAttemptTableName returns the dunning attempt table name
+func (st *storeImplementation) DunningAttemptTableName() string {
+	return st.dunningAttemptTableName
+}
+
+// InvoiceTableName returns the invoice table name
+func (st *storeImplementation) InvoiceTableName() string {
+	return st.invoiceTableName
+}
+
+// MigrationTableName returns the migration table name
+func (st *storeImplementation) MigrationTableName() string {
+	return st.migrationTableName
+}
+
+// PaymentMethodTableName returns the payment method table name
+func (st *storeImplementation) PaymentMethodTableName() string {
+	return st.paymentMethodTableName
+}
+
 // PlanTableName returns the plan table name
 func (st *storeImplementation) PlanTableName() string {
 	return st.planTableName
 }
 
+// PlanVersionMigrationTableName returns the plan version migration table name
+func (st *storeImplementation) PlanVersionMigrationTableName() string {
+	return st.planVersionMigrationTableName
+}
+
+// PlanVersionTableName returns the plan version table name
+func (st *storeImplementation) PlanVersionTableName() string {
+	return st.planVersionTableName
+}
+
+// PromotionCodeTableName returns the promotion code table name
+func (st *storeImplementation) PromotionCodeTableName() string {
+	return st.promotionCodeTableName
+}
+
+// ProviderReferenceTableName returns the provider reference table name
+func (st *storeImplementation) ProviderReferenceTableName() string {
+	return st.providerReferenceTableName
+}
+
+// SubscriptionDiscountTableName returns the subscription discount table name
+func (st *storeImplementation) SubscriptionDiscountTableName() string {
+	return st.subscriptionDiscountTableName
+}
+
+// SubscriptionItemTableName returns the subscription item table name
+func (st *storeImplementation) SubscriptionItemTableName() string {
+	return st.subscriptionItemTableName
+}
+
+// SubscriberTableName returns the subscriber table name
+func (st *storeImplementation) SubscriberTableName() string {
+	return st.subscriberTableName
+}
+
+// SubscriptionScheduleTableName returns the subscription schedule table name
+func (st *storeImplementation) SubscriptionScheduleTableName() string {
+	return st.subscriptionScheduleTableName
+}
+
 // SubscriptionTableName returns the subscription table name
 func (st *storeImplementation) SubscriptionTableName() string {
 	return st.subscriptionTableName
@@ -188,22 +317,47 @@ func (st *storeImplementation) PlanCount(ctx context.Context, query PlanQueryInt
 	return count, err
 }
 
+// PlanCountBy counts the plans matching the query by the value of the
+// column, one of COLUMN_TYPE, COLUMN_INTERVAL, COLUMN_STATUS,
+// COLUMN_CURRENCY or COLUMN_PRICING_MODEL
+func (st *storeImplementation) PlanCountBy(ctx context.Context, query PlanQueryInterface, column string) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("plan query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !lo.Contains(planCountByColumns, column) {
+		return nil, errors.New("subscriptionstore > plan count by. unsupported column: " + column)
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > plan count by. query cannot be paginated or ordered")
+	}
+
+	q, err := st.buildPlanCountByQuery(query, column)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []countByRow
+	if err := q.Get(&rows); err != nil {
+		return nil, err
+	}
+
+	return countByRowsMap(rows), nil
+}
+
 // PlanCreate creates a new plan
 func (st *storeImplementation) PlanCreate(ctx context.Context, plan PlanInterface) error {
 	if plan == nil {
 		return errors.New("subscriptionstore > plan create. plan cannot be nil")
 	}
-
-	if plan.GetCreatedAt() == "" {
-		plan.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
-	}
-	if plan.GetUpdatedAt() == "" {
-		plan.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
-	}
-	if plan.GetSoftDeletedAt() == "" {
-		plan.SetSoftDeletedAt(MAX_DATETIME)
+	if err := planPricingValidate(plan); err != nil {
+		return errors.New("subscriptionstore > plan create. " + err.Error())
 	}
 
+	planCreateDefaults(plan)
+
 	metasMap, err := plan.GetMetas()
 	if err != nil {
 		return err
@@ -217,6 +371,15 @@ func (st *storeImplementation) PlanCreate(ctx context.Context, plan PlanInterfac
 		metasStr = string(b)
 	}
 
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return err
+	}
+	tiersStr, err := priceTiersJSON(tiers)
+	if err != nil {
+		return err
+	}
+
 	row := map[string]any{
 		COLUMN_ID:              plan.GetID(),
 		COLUMN_TYPE:            plan.GetType(),
@@ -226,16 +389,30 @@ func (st *storeImplementation) PlanCreate(ctx context.Context, plan PlanInterfac
 		COLUMN_INTERVAL:        plan.GetInterval(),
 		COLUMN_CURRENCY:        plan.GetCurrency(),
 		COLUMN_PRICE:           plan.GetPrice(),
+		COLUMN_PRICING_MODEL:   plan.GetPricingModel(),
+		COLUMN_PRICE_TIERS:     tiersStr,
 		COLUMN_STRIPE_PRICE_ID: plan.GetStripePriceID(),
 		COLUMN_FEATURES:        plan.GetFeatures(),
 		COLUMN_MEMO:            plan.GetMemo(),
 		COLUMN_METAS:           metasStr,
-		COLUMN_CREATED_AT:      plan.GetCreatedAtCarbon().StdTime(),
-		COLUMN_UPDATED_AT:      plan.GetUpdatedAtCarbon().StdTime(),
-		COLUMN_SOFT_DELETED_AT: plan.GetSoftDeletedAtCarbon().StdTime(),
+		COLUMN_CREATED_AT:      dateTimeValue(plan.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(plan.GetUpdatedAtCarbon()),
+		COLUMN_SOFT_DELETED_AT: dateTimeValue(plan.GetSoftDeletedAtCarbon()),
+	}
+
+	if err := metaColumnsRow(row, st.planMetaColumns, metasMap); err != nil {
+		return errors.New("subscriptionstore > plan create. " + err.Error())
 	}
 
-	return st.db.Query().Table(st.planTableName).Create(row)
+	// The plan is written with its version, so it never goes without one
+	return st.transaction(func(txStore *storeImplementation) error {
+		if err := txStore.query().Table(txStore.planTableName).Create(row); err != nil {
+			return err
+		}
+
+		_, err := txStore.planVersionSync(ctx, plan)
+		return err
+	})
 }
 
 // PlanDelete deletes a plan
@@ -251,7 +428,7 @@ func (st *storeImplementation) PlanDeleteByID(ctx context.Context, id string) er
 	if id == "" {
 		return errors.New("plan id is empty")
 	}
-	_, err := st.db.Query().Table(st.planTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	_, err := st.query().Table(st.planTableName).Where(COLUMN_ID+" = ?", id).Delete()
 	return err
 }
 
@@ -302,6 +479,8 @@ func (st *storeImplementation) PlanList(ctx context.Context, query PlanQueryInte
 		Interval      string    `db:"interval"`
 		Currency      string    `db:"currency"`
 		Price         string    `db:"price"`
+		PricingModel  string    `db:"pricing_model"`
+		PriceTiers    string    `db:"price_tiers"`
 		StripePriceID string    `db:"stripe_price_id"`
 		Features      string    `db:"features"`
 		Memo          string    `db:"memo"`
@@ -327,13 +506,15 @@ func (st *storeImplementation) PlanList(ctx context.Context, query PlanQueryInte
 		p.SetInterval(r.Interval)
 		p.SetCurrency(r.Currency)
 		p.SetPrice(r.Price)
+		p.SetPricingModel(r.PricingModel)
+		p.PriceTiersField = r.PriceTiers
 		p.SetStripePriceID(r.StripePriceID)
 		p.SetFeatures(r.Features)
 		p.SetMemo(r.Memo)
 		p.MetasField = r.Metas
-		p.CreatedAtField.CreatedAt = r.CreatedAt
-		p.UpdatedAtField.UpdatedAt = r.UpdatedAt
-		p.SoftDeletesMaxDate.SoftDeletedAt = r.SoftDeletedAt
+		p.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		p.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		p.SoftDeletesMaxDate.SoftDeletedAt = r.SoftDeletedAt.UTC()
 		list = append(list, p)
 	}
 
@@ -363,6 +544,9 @@ func (st *storeImplementation) PlanUpdate(ctx context.Context, plan PlanInterfac
 	if plan == nil {
 		return errors.New("subscriptionstore > plan update. plan cannot be nil")
 	}
+	if err := planPricingValidate(plan); err != nil {
+		return errors.New("subscriptionstore > plan update. " + err.Error())
+	}
 
 	plan.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
@@ -379,6 +563,15 @@ func (st *storeImplementation) PlanUpdate(ctx context.Context, plan PlanInterfac
 		metasStr = string(b)
 	}
 
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return err
+	}
+	tiersStr, err := priceTiersJSON(tiers)
+	if err != nil {
+		return err
+	}
+
 	row := map[string]any{
 		COLUMN_TYPE:            plan.GetType(),
 		COLUMN_STATUS:          plan.GetStatus(),
@@ -387,16 +580,31 @@ func (st *storeImplementation) PlanUpdate(ctx context.Context, plan PlanInterfac
 		COLUMN_INTERVAL:        plan.GetInterval(),
 		COLUMN_CURRENCY:        plan.GetCurrency(),
 		COLUMN_PRICE:           plan.GetPrice(),
+		COLUMN_PRICING_MODEL:   plan.GetPricingModel(),
+		COLUMN_PRICE_TIERS:     tiersStr,
 		COLUMN_STRIPE_PRICE_ID: plan.GetStripePriceID(),
 		COLUMN_FEATURES:        plan.GetFeatures(),
 		COLUMN_MEMO:            plan.GetMemo(),
 		COLUMN_METAS:           metasStr,
-		COLUMN_UPDATED_AT:      plan.GetUpdatedAtCarbon().StdTime(),
-		COLUMN_SOFT_DELETED_AT: plan.GetSoftDeletedAtCarbon().StdTime(),
+		COLUMN_UPDATED_AT:      dateTimeValue(plan.GetUpdatedAtCarbon()),
+		COLUMN_SOFT_DELETED_AT: dateTimeValue(plan.GetSoftDeletedAtCarbon()),
 	}
 
-	_, err = st.db.Query().Table(st.planTableName).Where(COLUMN_ID+" = ?", plan.GetID()).Update(row)
-	return err
+	if err := metaColumnsRow(row, st.planMetaColumns, metasMap); err != nil {
+		return errors.New("subscriptionstore > plan update. " + err.Error())
+	}
+
+	// The plan is written with its version, so it never goes without one.
+	// Of two concurrent updates, the unique index on the plan and the version
+	// rolls back the one creating the same version second.
+	return st.transaction(func(txStore *storeImplementation) error {
+		if _, err := txStore.query().Table(txStore.planTableName).Where(COLUMN_ID+" = ?", plan.GetID()).Update(row); err != nil {
+			return err
+		}
+
+		_, err := txStore.planVersionSync(ctx, plan)
+		return err
+	})
 }
 
 // == SUBSCRIPTION METHODS ======================================================
@@ -417,26 +625,52 @@ func (st *storeImplementation) SubscriptionCount(ctx context.Context, query Subs
 	return count, err
 }
 
+// SubscriptionCountBy counts the subscriptions matching the query by the
+// value of the column, one of COLUMN_STATUS, COLUMN_PLAN_ID,
+// COLUMN_PLAN_VERSION_ID or COLUMN_SUBSCRIBER_ID
+func (st *storeImplementation) SubscriptionCountBy(ctx context.Context, query SubscriptionQueryInterface, column string) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("subscription query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !lo.Contains(subscriptionCountByColumns, column) {
+		return nil, errors.New("subscriptionstore > subscription count by. unsupported column: " + column)
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > subscription count by. query cannot be paginated or ordered")
+	}
+
+	q, err := st.buildSubscriptionCountByQuery(query, column)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []countByRow
+	if err := q.Get(&rows); err != nil {
+		return nil, err
+	}
+
+	return countByRowsMap(rows), nil
+}
+
 // SubscriptionCreate creates a new subscription
 func (st *storeImplementation) SubscriptionCreate(ctx context.Context, subscription SubscriptionInterface) error {
 	if subscription == nil {
 		return errors.New("subscriptionstore > subscription create. subscription cannot be nil")
 	}
-
-	if subscription.GetPeriodStart() == "" {
-		subscription.SetPeriodStart(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
-	}
-	if subscription.GetPeriodEnd() == "" {
-		subscription.SetPeriodEnd(MAX_DATETIME)
+	if err := subscriptionCreateDefaults(subscription); err != nil {
+		return err
 	}
-	if subscription.GetCreatedAt() == "" {
-		subscription.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	if err := st.subscriptionPinPlanVersion(ctx, subscription); err != nil {
+		return err
 	}
-	if subscription.GetUpdatedAt() == "" {
-		subscription.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	if err := st.subscriptionSubscriberDefaults(ctx, subscription); err != nil {
+		return errors.New("subscriptionstore > subscription create. " + err.Error())
 	}
-	if subscription.GetSoftDeletedAt() == "" {
-		subscription.SetSoftDeletedAt(MAX_DATETIME)
+	if err := st.paymentMethodCheckOwner(ctx, subscription.GetSubscriberID(), subscription.GetPaymentMethodID()); err != nil {
+		return errors.New("subscriptionstore > subscription create. " + err.Error())
 	}
 
 	metasMap, err := subscription.GetMetas()
@@ -453,22 +687,36 @@ func (st *storeImplementation) SubscriptionCreate(ctx context.Context, subscript
 	}
 
 	row := map[string]any{
-		COLUMN_ID:                   subscription.GetID(),
-		COLUMN_STATUS:               subscription.GetStatus(),
-		COLUMN_SUBSCRIBER_ID:        subscription.GetSubscriberID(),
-		COLUMN_PLAN_ID:              subscription.GetPlanID(),
-		COLUMN_PERIOD_START:         subscription.GetPeriodStartCarbon().StdTime(),
-		COLUMN_PERIOD_END:           subscription.GetPeriodEndCarbon().StdTime(),
-		COLUMN_CANCEL_AT_PERIOD_END: lo.Ternary(subscription.GetCancelAtPeriodEnd(), YES, NO),
-		COLUMN_PAYMENT_METHOD_ID:    subscription.GetPaymentMethodID(),
-		COLUMN_MEMO:                 subscription.GetMemo(),
-		COLUMN_METAS:                metasStr,
-		COLUMN_CREATED_AT:           subscription.GetCreatedAtCarbon().StdTime(),
-		COLUMN_UPDATED_AT:           subscription.GetUpdatedAtCarbon().StdTime(),
-		COLUMN_SOFT_DELETED_AT:      subscription.GetSoftDeletedAtCarbon().StdTime(),
-	}
-
-	return st.db.Query().Table(st.subscriptionTableName).Create(row)
+		COLUMN_ID:                        subscription.GetID(),
+		COLUMN_STATUS:                    subscription.GetStatus(),
+		COLUMN_SUBSCRIBER_ID:             subscription.GetSubscriberID(),
+		COLUMN_PLAN_ID:                   subscription.GetPlanID(),
+		COLUMN_PLAN_VERSION_ID:           subscription.GetPlanVersionID(),
+		COLUMN_QUANTITY:                  subscription.GetQuantity(),
+		COLUMN_PERIOD_START:              dateTimeValue(subscription.GetPeriodStartCarbon()),
+		COLUMN_PERIOD_END:                dateTimeValue(subscription.GetPeriodEndCarbon()),
+		COLUMN_CANCEL_AT_PERIOD_END:      lo.Ternary(subscription.GetCancelAtPeriodEnd(), YES, NO),
+		COLUMN_CANCELLATION_REQUESTED_AT: dateTimeValue(subscription.GetCancellationRequestedAtCarbon()),
+		COLUMN_CANCELLATION_EFFECTIVE_AT: dateTimeValue(subscription.GetCancellationEffectiveAtCarbon()),
+		COLUMN_CANCELLATION_REASON:       subscription.GetCancellationReason(),
+		COLUMN_CANCELLATION_FEEDBACK:     subscription.GetCancellationFeedback(),
+		COLUMN_CANCELLED_BY:              subscription.GetCancelledBy(),
+		COLUMN_PAYMENT_METHOD_ID:         subscription.GetPaymentMethodID(),
+		COLUMN_CURRENCY:                  subscription.GetCurrency(),
+		COLUMN_PAUSED_AT:                 dateTimeValue(subscription.GetPausedAtCarbon()),
+		COLUMN_RESUME_AT:                 dateTimeValue(subscription.GetResumeAtCarbon()),
+		COLUMN_MEMO:                      subscription.GetMemo(),
+		COLUMN_METAS:                     metasStr,
+		COLUMN_CREATED_AT:                dateTimeValue(subscription.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:                dateTimeValue(subscription.GetUpdatedAtCarbon()),
+		COLUMN_SOFT_DELETED_AT:           dateTimeValue(subscription.GetSoftDeletedAtCarbon()),
+	}
+
+	if err := metaColumnsRow(row, st.subscriptionMetaColumns, metasMap); err != nil {
+		return errors.New("subscriptionstore > subscription create. " + err.Error())
+	}
+
+	return st.query().Table(st.subscriptionTableName).Create(row)
 }
 
 // SubscriptionDelete deletes a subscription
@@ -484,7 +732,7 @@ func (st *storeImplementation) SubscriptionDeleteByID(ctx context.Context, id st
 	if id == "" {
 		return errors.New("subscription id is empty")
 	}
-	_, err := st.db.Query().Table(st.subscriptionTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	_, err := st.query().Table(st.subscriptionTableName).Where(COLUMN_ID+" = ?", id).Delete()
 	return err
 }
 
@@ -515,6 +763,34 @@ func (st *storeImplementation) SubscriptionFindByID(ctx context.Context, id stri
 	return nil, nil
 }
 
+// SubscriptionFindActiveBySubscriberID finds the latest active subscription
+// of the subscriber, or nil if it has none. Past due subscriptions are still
+// active while their renewal is retried.
+func (st *storeImplementation) SubscriptionFindActiveBySubscriberID(ctx context.Context, subscriberID string) (SubscriptionInterface, error) {
+	return findActiveSubscription(ctx, st, subscriberID)
+}
+
+// findActiveSubscription finds the latest active subscription of the
+// subscriber in the store, or nil if it has none
+func findActiveSubscription(ctx context.Context, st subscriptionWorkflowStore, subscriberID string) (SubscriptionInterface, error) {
+	if subscriberID == "" {
+		return nil, errors.New("subscriber id is empty")
+	}
+	list, err := st.SubscriptionList(ctx, SubscriptionQuery().
+		SetSubscriberID(subscriberID).
+		SetStatusIn([]string{SUBSCRIPTION_STATUS_ACTIVE, SUBSCRIPTION_STATUS_PAST_DUE}).
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortOrder("desc").
+		SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
 // SubscriptionList retrieves a list of subscriptions
 func (st *storeImplementation) SubscriptionList(ctx context.Context, query SubscriptionQueryInterface) ([]SubscriptionInterface, error) {
 	if query == nil {
@@ -527,19 +803,29 @@ func (st *storeImplementation) SubscriptionList(ctx context.Context, query Subsc
 	q := st.buildSubscriptionQuery(query)
 
 	type subscriptionRow struct {
-		ID                string    `db:"id"`
-		Status            string    `db:"status"`
-		SubscriberID      string    `db:"subscriber_id"`
-		PlanID            string    `db:"plan_id"`
-		PeriodStart       time.Time `db:"period_start"`
-		PeriodEnd         time.Time `db:"period_end"`
-		CancelAtPeriodEnd string    `db:"cancel_at_period_end"`
-		PaymentMethodID   string    `db:"payment_method_id"`
-		Memo              string    `db:"memo"`
-		Metas             string    `db:"metas"`
-		CreatedAt         time.Time `db:"created_at"`
-		UpdatedAt         time.Time `db:"updated_at"`
-		SoftDeletedAt     time.Time `db:"soft_deleted_at"`
+		ID                      string    `db:"id"`
+		Status                  string    `db:"status"`
+		SubscriberID            string    `db:"subscriber_id"`
+		PlanID                  string    `db:"plan_id"`
+		PlanVersionID           string    `db:"plan_version_id"`
+		Quantity                int       `db:"quantity"`
+		PeriodStart             time.Time `db:"period_start"`
+		PeriodEnd               time.Time `db:"period_end"`
+		CancelAtPeriodEnd       string    `db:"cancel_at_period_end"`
+		CancellationRequestedAt time.Time `db:"cancellation_requested_at"`
+		CancellationEffectiveAt time.Time `db:"cancellation_effective_at"`
+		CancellationReason      string    `db:"cancellation_reason"`
+		CancellationFeedback    string    `db:"cancellation_feedback"`
+		CancelledBy             string    `db:"cancelled_by"`
+		PaymentMethodID         string    `db:"payment_method_id"`
+		Currency                string    `db:"currency"`
+		PausedAt                time.Time `db:"paused_at"`
+		ResumeAt                time.Time `db:"resume_at"`
+		Memo                    string    `db:"memo"`
+		Metas                   string    `db:"metas"`
+		CreatedAt               time.Time `db:"created_at"`
+		UpdatedAt               time.Time `db:"updated_at"`
+		SoftDeletedAt           time.Time `db:"soft_deleted_at"`
 	}
 
 	var rows []subscriptionRow
@@ -554,15 +840,25 @@ func (st *storeImplementation) SubscriptionList(ctx context.Context, query Subsc
 		s.SetStatus(r.Status)
 		s.SetSubscriberID(r.SubscriberID)
 		s.SetPlanID(r.PlanID)
-		s.SetPeriodStart(carbon.CreateFromStdTime(r.PeriodStart).ToDateTimeString())
-		s.SetPeriodEnd(carbon.CreateFromStdTime(r.PeriodEnd).ToDateTimeString())
+		s.SetPlanVersionID(r.PlanVersionID)
+		s.SetQuantity(r.Quantity)
+		s.SetPeriodStart(carbon.CreateFromStdTime(r.PeriodStart, carbon.UTC).ToDateTimeString(carbon.UTC))
+		s.SetPeriodEnd(carbon.CreateFromStdTime(r.PeriodEnd, carbon.UTC).ToDateTimeString(carbon.UTC))
 		s.SetCancelAtPeriodEnd(r.CancelAtPeriodEnd == YES)
+		s.SetCancellationRequestedAt(carbon.CreateFromStdTime(r.CancellationRequestedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		s.SetCancellationEffectiveAt(carbon.CreateFromStdTime(r.CancellationEffectiveAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		s.SetCancellationReason(r.CancellationReason)
+		s.SetCancellationFeedback(r.CancellationFeedback)
+		s.SetCancelledBy(r.CancelledBy)
 		s.SetPaymentMethodID(r.PaymentMethodID)
+		s.SetCurrency(r.Currency)
+		s.SetPausedAt(carbon.CreateFromStdTime(r.PausedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		s.SetResumeAt(carbon.CreateFromStdTime(r.ResumeAt, carbon.UTC).ToDateTimeString(carbon.UTC))
 		s.SetMemo(r.Memo)
 		s.MetasField = r.Metas
-		s.CreatedAtField.CreatedAt = r.CreatedAt
-		s.UpdatedAtField.UpdatedAt = r.UpdatedAt
-		s.SoftDeletesMaxDate.SoftDeletedAt = r.SoftDeletedAt
+		s.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		s.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		s.SoftDeletesMaxDate.SoftDeletedAt = r.SoftDeletedAt.UTC()
 		list = append(list, s)
 	}
 
@@ -595,6 +891,16 @@ func (st *storeImplementation) SubscriptionUpdate(ctx context.Context, subscript
 
 	subscription.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
+	if err := st.subscriptionPinPlanVersion(ctx, subscription); err != nil {
+		return err
+	}
+	if err := st.subscriptionPlanChangeCurrency(ctx, subscription); err != nil {
+		return errors.New("subscriptionstore > subscription update. " + err.Error())
+	}
+	if err := st.paymentMethodCheckOwner(ctx, subscription.GetSubscriberID(), subscription.GetPaymentMethodID()); err != nil {
+		return errors.New("subscriptionstore > subscription update. " + err.Error())
+	}
+
 	metasMap, err := subscription.GetMetas()
 	if err != nil {
 		return err
@@ -609,32 +915,101 @@ func (st *storeImplementation) SubscriptionUpdate(ctx context.Context, subscript
 	}
 
 	row := map[string]any{
-		COLUMN_STATUS:               subscription.GetStatus(),
-		COLUMN_SUBSCRIBER_ID:        subscription.GetSubscriberID(),
-		COLUMN_PLAN_ID:              subscription.GetPlanID(),
-		COLUMN_PERIOD_START:         subscription.GetPeriodStartCarbon().StdTime(),
-		COLUMN_PERIOD_END:           subscription.GetPeriodEndCarbon().StdTime(),
-		COLUMN_CANCEL_AT_PERIOD_END: lo.Ternary(subscription.GetCancelAtPeriodEnd(), YES, NO),
-		COLUMN_PAYMENT_METHOD_ID:    subscription.GetPaymentMethodID(),
-		COLUMN_MEMO:                 subscription.GetMemo(),
-		COLUMN_METAS:                metasStr,
-		COLUMN_UPDATED_AT:           subscription.GetUpdatedAtCarbon().StdTime(),
-		COLUMN_SOFT_DELETED_AT:      subscription.GetSoftDeletedAtCarbon().StdTime(),
-	}
-
-	_, err = st.db.Query().Table(st.subscriptionTableName).Where(COLUMN_ID+" = ?", subscription.GetID()).Update(row)
+		COLUMN_STATUS:                    subscription.GetStatus(),
+		COLUMN_SUBSCRIBER_ID:             subscription.GetSubscriberID(),
+		COLUMN_PLAN_ID:                   subscription.GetPlanID(),
+		COLUMN_PLAN_VERSION_ID:           subscription.GetPlanVersionID(),
+		COLUMN_QUANTITY:                  subscription.GetQuantity(),
+		COLUMN_PERIOD_START:              dateTimeValue(subscription.GetPeriodStartCarbon()),
+		COLUMN_PERIOD_END:                dateTimeValue(subscription.GetPeriodEndCarbon()),
+		COLUMN_CANCEL_AT_PERIOD_END:      lo.Ternary(subscription.GetCancelAtPeriodEnd(), YES, NO),
+		COLUMN_CANCELLATION_REQUESTED_AT: dateTimeValue(subscription.GetCancellationRequestedAtCarbon()),
+		COLUMN_CANCELLATION_EFFECTIVE_AT: dateTimeValue(subscription.GetCancellationEffectiveAtCarbon()),
+		COLUMN_CANCELLATION_REASON:       subscription.GetCancellationReason(),
+		COLUMN_CANCELLATION_FEEDBACK:     subscription.GetCancellationFeedback(),
+		COLUMN_CANCELLED_BY:              subscription.GetCancelledBy(),
+		COLUMN_PAYMENT_METHOD_ID:         subscription.GetPaymentMethodID(),
+		COLUMN_CURRENCY:                  subscription.GetCurrency(),
+		COLUMN_PAUSED_AT:                 dateTimeValue(subscription.GetPausedAtCarbon()),
+		COLUMN_RESUME_AT:                 dateTimeValue(subscription.GetResumeAtCarbon()),
+		COLUMN_MEMO:                      subscription.GetMemo(),
+		COLUMN_METAS:                     metasStr,
+		COLUMN_UPDATED_AT:                dateTimeValue(subscription.GetUpdatedAtCarbon()),
+		COLUMN_SOFT_DELETED_AT:           dateTimeValue(subscription.GetSoftDeletedAtCarbon()),
+	}
+
+	if err := metaColumnsRow(row, st.subscriptionMetaColumns, metasMap); err != nil {
+		return errors.New("subscriptionstore > subscription update. " + err.Error())
+	}
+
+	_, err = st.query().Table(st.subscriptionTableName).Where(COLUMN_ID+" = ?", subscription.GetID()).Update(row)
 	return err
 }
 
+// planCreateDefaults sets the timestamps of a plan being created which are
+// not set yet
+func planCreateDefaults(plan PlanInterface) {
+	if plan.GetCreatedAt() == "" {
+		plan.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	}
+	if plan.GetUpdatedAt() == "" {
+		plan.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	}
+	if plan.GetSoftDeletedAt() == "" {
+		plan.SetSoftDeletedAt(MAX_DATETIME)
+	}
+}
+
+// subscriptionCreateDefaults checks the quantity of a subscription being
+// created, and sets its quantity, period and timestamps which are not set yet
+func subscriptionCreateDefaults(subscription SubscriptionInterface) error {
+	if subscription.GetQuantity() < 0 {
+		return errors.New("subscriptionstore > subscription create. quantity cannot be negative")
+	}
+
+	if subscription.GetQuantity() == 0 {
+		subscription.SetQuantity(1)
+	}
+
+	if subscription.GetPeriodStart() == "" {
+		subscription.SetPeriodStart(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if subscription.GetPeriodEnd() == "" {
+		subscription.SetPeriodEnd(MAX_DATETIME)
+	}
+	if subscription.GetPausedAt() == "" {
+		subscription.SetPausedAt(MAX_DATETIME)
+	}
+	if subscription.GetResumeAt() == "" {
+		subscription.SetResumeAt(MAX_DATETIME)
+	}
+	if subscription.GetCancellationRequestedAt() == "" {
+		subscription.SetCancellationRequestedAt(MAX_DATETIME)
+	}
+	if subscription.GetCancellationEffectiveAt() == "" {
+		subscription.SetCancellationEffectiveAt(MAX_DATETIME)
+	}
+	if subscription.GetCreatedAt() == "" {
+		subscription.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	}
+	if subscription.GetUpdatedAt() == "" {
+		subscription.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString())
+	}
+	if subscription.GetSoftDeletedAt() == "" {
+		subscription.SetSoftDeletedAt(MAX_DATETIME)
+	}
+
+	return nil
+}
+
 // == QUERY BUILDERS ===========================================================
 
 // buildPlanQuery builds a neat query from the plan query interface.
 func (st *storeImplementation) buildPlanQuery(query PlanQueryInterface) contractsorm.Query {
-	// Use Model() to enable neat's automatic soft delete handling via SoftDeletesMaxDate
-	q := st.db.Query().Model(&planImplementation{})
+	q := st.query()
 
 	if query == nil {
-		return q
+		return q.Where(COLUMN_SOFT_DELETED_AT+" > ?", dateTimeValue(carbon.Now(carbon.UTC)))
 	}
 
 	if query.HasID() && query.ID() != "" {
@@ -667,9 +1042,18 @@ func (st *storeImplementation) buildPlanQuery(query PlanQueryInterface) contract
 		}
 		q = q.WhereIn(COLUMN_INTERVAL, args)
 	}
+	if query.HasStripePriceID() && query.StripePriceID() != "" {
+		q = q.Where(COLUMN_STRIPE_PRICE_ID+" = ?", query.StripePriceID())
+	}
 	if query.HasType() && query.Type() != "" {
 		q = q.Where(COLUMN_TYPE+" = ?", query.Type())
 	}
+	if query.HasMetaEquals() {
+		q = st.whereMetaEquals(q, st.planMetaColumns, query.MetaEquals())
+	}
+	if query.HasMetaHasKey() {
+		q = st.whereMetaHasKey(q, st.planMetaColumns, query.MetaHasKey())
+	}
 	if query.HasLimit() && query.Limit() > 0 {
 		q = q.Limit(query.Limit())
 	}
@@ -684,9 +1068,11 @@ func (st *storeImplementation) buildPlanQuery(query PlanQueryInterface) contract
 		q = q.OrderBy(query.OrderBy(), sortOrder)
 	}
 
-	// Handle soft delete filtering via neat's automatic handling (SoftDeletesMaxDate)
-	if query.HasSoftDeletedIncluded() && query.SoftDeletedIncluded() {
-		q = q.WithSoftDeleted()
+	// Soft deleted rows are filtered explicitly, rather than via neat's
+	// SoftDeletesMaxDate model scope, so the comparison is made in UTC and
+	// the placeholder is rebound for every dialect (i.e. $n on PostgreSQL)
+	if !query.SoftDeletedIncluded() {
+		q = q.Where(COLUMN_SOFT_DELETED_AT+" > ?", dateTimeValue(carbon.Now(carbon.UTC)))
 	}
 
 	return q
@@ -694,11 +1080,10 @@ func (st *storeImplementation) buildPlanQuery(query PlanQueryInterface) contract
 
 // buildSubscriptionQuery builds a neat query from the subscription query interface.
 func (st *storeImplementation) buildSubscriptionQuery(query SubscriptionQueryInterface) contractsorm.Query {
-	// Use Model() to enable neat's automatic soft delete handling via SoftDeletesMaxDate
-	q := st.db.Query().Model(&subscriptionImplementation{})
+	q := st.query()
 
 	if query == nil {
-		return q
+		return q.Where(COLUMN_SOFT_DELETED_AT+" > ?", dateTimeValue(carbon.Now(carbon.UTC)))
 	}
 
 	if query.HasID() && query.ID() != "" {
@@ -727,6 +1112,24 @@ func (st *storeImplementation) buildSubscriptionQuery(query SubscriptionQueryInt
 	if query.HasPlanID() && query.PlanID() != "" {
 		q = q.Where(COLUMN_PLAN_ID+" = ?", query.PlanID())
 	}
+	if query.HasResumeAtLte() && query.ResumeAtLte() != "" {
+		q = q.Where(COLUMN_RESUME_AT+" <= ?", dateTimeValue(carbon.Parse(query.ResumeAtLte(), carbon.UTC)))
+	}
+	if query.HasCancellationReason() && query.CancellationReason() != "" {
+		q = q.Where(COLUMN_CANCELLATION_REASON+" = ?", query.CancellationReason())
+	}
+	if query.HasCancellationRequestedAtGte() && query.CancellationRequestedAtGte() != "" {
+		q = q.Where(COLUMN_CANCELLATION_REQUESTED_AT+" >= ?", dateTimeValue(carbon.Parse(query.CancellationRequestedAtGte(), carbon.UTC)))
+	}
+	if query.HasCancellationRequestedAtLte() && query.CancellationRequestedAtLte() != "" {
+		q = q.Where(COLUMN_CANCELLATION_REQUESTED_AT+" <= ?", dateTimeValue(carbon.Parse(query.CancellationRequestedAtLte(), carbon.UTC)))
+	}
+	if query.HasMetaEquals() {
+		q = st.whereMetaEquals(q, st.subscriptionMetaColumns, query.MetaEquals())
+	}
+	if query.HasMetaHasKey() {
+		q = st.whereMetaHasKey(q, st.subscriptionMetaColumns, query.MetaHasKey())
+	}
 	if query.HasLimit() && query.Limit() > 0 {
 		q = q.Limit(query.Limit())
 	}
@@ -741,10 +1144,143 @@ func (st *storeImplementation) buildSubscriptionQuery(query SubscriptionQueryInt
 		q = q.OrderBy(query.OrderBy(), sortOrder)
 	}
 
-	// Handle soft delete filtering via neat's automatic handling (SoftDeletesMaxDate)
-	if query.HasSoftDeletedIncluded() && query.SoftDeletedIncluded() {
-		q = q.WithSoftDeleted()
+	// Soft deleted rows are filtered explicitly, rather than via neat's
+	// SoftDeletesMaxDate model scope, so the comparison is made in UTC and
+	// the placeholder is rebound for every dialect (i.e. $n on PostgreSQL)
+	if !query.SoftDeletedIncluded() {
+		q = q.Where(COLUMN_SOFT_DELETED_AT+" > ?", dateTimeValue(carbon.Now(carbon.UTC)))
 	}
 
 	return q
 }
+
+// planCountByColumns are the plan columns which PlanCountBy groups by
+var planCountByColumns = []string{
+	COLUMN_CURRENCY,
+	COLUMN_INTERVAL,
+	COLUMN_PRICING_MODEL,
+	COLUMN_STATUS,
+	COLUMN_TYPE,
+}
+
+// subscriptionCountByColumns are the subscription columns which
+// SubscriptionCountBy groups by
+var subscriptionCountByColumns = []string{
+	COLUMN_PLAN_ID,
+	COLUMN_PLAN_VERSION_ID,
+	COLUMN_STATUS,
+	COLUMN_SUBSCRIBER_ID,
+}
+
+// query returns a new query, run in the transaction of the store if it is
+// in one
+func (st *storeImplementation) query() contractsorm.Query {
+	if st.tx != nil {
+		// WithContext returns a copy of the transaction query, without the
+		// clauses of the previous queries
+		return st.tx.WithContext(context.Background())
+	}
+	return st.db.Query()
+}
+
+// schema returns the schema builder of the store, whose statements run in
+// the transaction of the store, if any
+func (st *storeImplementation) schema() contractsschema.Schema {
+	if st.tx != nil {
+		return st.db.Schema().WithTransaction(st.query())
+	}
+	return st.db.Schema()
+}
+
+// wrapColumn quotes the column as an identifier of the database, for the
+// raw expressions neat does not quote, such as selects
+func (st *storeImplementation) wrapColumn(column string) (string, error) {
+	return grammars.NewWrap(st.query().Driver(), "").Column(column)
+}
+
+// transaction runs fn with a copy of the store whose queries run in a
+// single transaction, committed if fn returns nil and rolled back otherwise.
+// A store already in a transaction runs fn in it.
+func (st *storeImplementation) transaction(fn func(txStore *storeImplementation) error) error {
+	if st.tx != nil {
+		return fn(st)
+	}
+
+	return st.db.Transaction(func(tx contractsorm.Query) error {
+		txQuery, ok := tx.(contractsorm.QueryWithContext)
+		if !ok {
+			return errors.New("subscriptionstore > transaction. query cannot be copied")
+		}
+		txStore := *st
+		txStore.tx = txQuery
+		return fn(&txStore)
+	})
+}
+
+// subscriptionLock locks the row of the subscription until the end of the
+// transaction of the store, so the writes depending on what the transaction
+// read of the subscription are not interleaved with those of another
+// transaction. SQLite, which runs one write transaction at a time, takes no
+// row lock.
+func (st *storeImplementation) subscriptionLock(id string) error {
+	var rows []struct {
+		ID string `db:"id"`
+	}
+	return st.query().Table(st.subscriptionTableName).
+		Select(COLUMN_ID).
+		Where(COLUMN_ID+" = ?", id).
+		LockForUpdate().
+		Get(&rows)
+}
+
+// dateTimeValue returns the value written to, and compared with, the date
+// time columns. Dates are passed as "2006-01-02 15:04:05" text rather than
+// time.Time: the rows are created with that text, while the SQLite driver
+// binds time.Time in the updates and the filters as "... +0000 UTC", and the
+// text columns then no longer compare as dates.
+func dateTimeValue(value *carbon.Carbon) string {
+	return value.ToDateTimeString(carbon.UTC)
+}
+
+// countByRow is the count of the rows sharing a value of the grouped column
+type countByRow struct {
+	Value string `db:"value"`
+	Total int64  `db:"total"`
+}
+
+// countByRowsMap maps the grouped values to their counts
+func countByRowsMap(rows []countByRow) map[string]int64 {
+	counts := map[string]int64{}
+	for _, r := range rows {
+		counts[r.Value] = r.Total
+	}
+	return counts
+}
+
+// buildPlanCountByQuery builds the query counting the plans matching the
+// query by the value of the column
+func (st *storeImplementation) buildPlanCountByQuery(query PlanQueryInterface, column string) (contractsorm.Query, error) {
+	wrapped, err := st.wrapColumn(column)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.buildPlanQuery(query).
+		Table(st.planTableName).
+		Select(wrapped + " AS value, COUNT(*) AS total").
+		Group(column), nil
+}
+
+// buildSubscriptionCountByQuery builds the query counting the subscriptions
+// matching the query by the value of the column
+func (st *storeImplementation) buildSubscriptionCountByQuery(query SubscriptionQueryInterface, column string) (contractsorm.Query, error) {
+	wrapped, err := st.wrapColumn(column)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.buildSubscriptionQuery(query).
+		Table(st.subscriptionTableName).
+		Select(wrapped + " AS value, COUNT(*) AS total").
+		Group(column), nil
+}
@@ -0,0 +1,419 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// subscriptionMRR is the monthly recurring revenue of one subscription
+type subscriptionMRR struct {
+	currency string
+	cents    int64
+}
+
+// SubscriptionMRR returns the monthly recurring revenue by currency at the
+// given date. A subscription contributes the amount of its invoice covering
+// the date, normalized to a month. Without such an invoice, subscriptions
+// live at the date and not paused contribute their current price.
+func (st *storeImplementation) SubscriptionMRR(ctx context.Context, at string) (map[string]string, error) {
+	atCarbon := carbon.Parse(at, carbon.UTC)
+	if atCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > subscription mrr. at is not a valid date")
+	}
+
+	data, err := st.analyticsLoad(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]int64{}
+	for _, subscription := range data.subscriptions {
+		mrr, err := data.subscriptionMRRAt(subscription, atCarbon)
+		if err != nil {
+			return nil, errors.New("subscriptionstore > subscription mrr. " + err.Error())
+		}
+		if mrr.cents != 0 {
+			totals[mrr.currency] += mrr.cents
+		}
+	}
+
+	amounts := map[string]string{}
+	for currency, cents := range totals {
+		amounts[currency] = centsAmount(cents)
+	}
+
+	return amounts, nil
+}
+
+// SubscriptionMRRMovement breaks down the change of the monthly recurring
+// revenue between two dates into new, expansion, contraction and churned
+// revenue, one movement per currency ordered by currency. Each subscription
+// is compared with itself at both dates, valued as by SubscriptionMRR.
+func (st *storeImplementation) SubscriptionMRRMovement(ctx context.Context, from string, to string) ([]MRRMovement, error) {
+	fromCarbon, toCarbon, err := analyticsRange(from, to)
+	if err != nil {
+		return nil, errors.New("subscriptionstore > subscription mrr movement. " + err.Error())
+	}
+
+	data, err := st.analyticsLoad(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type movementCents struct {
+		start, new, expansion, contraction, churned int64
+	}
+	movements := map[string]*movementCents{}
+	movement := func(currency string) *movementCents {
+		if movements[currency] == nil {
+			movements[currency] = &movementCents{}
+		}
+		return movements[currency]
+	}
+
+	for _, subscription := range data.subscriptions {
+		start, err := data.subscriptionMRRAt(subscription, fromCarbon)
+		if err != nil {
+			return nil, errors.New("subscriptionstore > subscription mrr movement. " + err.Error())
+		}
+		end, err := data.subscriptionMRRAt(subscription, toCarbon)
+		if err != nil {
+			return nil, errors.New("subscriptionstore > subscription mrr movement. " + err.Error())
+		}
+
+		if start.cents != 0 {
+			movement(start.currency).start += start.cents
+		}
+
+		// A change of currency churns the old revenue and adds the new one
+		switch {
+		case start.cents == 0 && end.cents == 0:
+		case start.cents == 0:
+			movement(end.currency).new += end.cents
+		case end.cents == 0 || start.currency != end.currency:
+			movement(start.currency).churned += start.cents
+			if end.cents != 0 {
+				movement(end.currency).new += end.cents
+			}
+		case end.cents > start.cents:
+			movement(start.currency).expansion += end.cents - start.cents
+		case end.cents < start.cents:
+			movement(start.currency).contraction += start.cents - end.cents
+		}
+	}
+
+	currencies := lo.Keys(movements)
+	sort.Strings(currencies)
+
+	result := make([]MRRMovement, 0, len(currencies))
+	for _, currency := range currencies {
+		m := movements[currency]
+		result = append(result, MRRMovement{
+			Currency:    currency,
+			StartMRR:    centsAmount(m.start),
+			New:         centsAmount(m.new),
+			Expansion:   centsAmount(m.expansion),
+			Contraction: centsAmount(m.contraction),
+			Churned:     centsAmount(m.churned),
+			EndMRR:      centsAmount(m.start + m.new + m.expansion - m.contraction - m.churned),
+		})
+	}
+
+	return result, nil
+}
+
+// SubscriptionChurn counts the subscriptions live at the start of the date
+// range, those of them cancelled by its end, and the signups during it
+func (st *storeImplementation) SubscriptionChurn(ctx context.Context, from string, to string) (ChurnReport, error) {
+	fromCarbon, toCarbon, err := analyticsRange(from, to)
+	if err != nil {
+		return ChurnReport{}, errors.New("subscriptionstore > subscription churn. " + err.Error())
+	}
+
+	subscriptions, err := st.SubscriptionList(ctx, SubscriptionQuery())
+	if err != nil {
+		return ChurnReport{}, err
+	}
+
+	report := ChurnReport{
+		From: fromCarbon.ToDateTimeString(carbon.UTC),
+		To:   toCarbon.ToDateTimeString(carbon.UTC),
+	}
+
+	for _, subscription := range subscriptions {
+		if subscriptionLiveAt(subscription, fromCarbon) {
+			report.ActiveAtStart++
+			if !subscriptionLiveAt(subscription, toCarbon) {
+				report.Churned++
+			}
+			continue
+		}
+
+		createdAt := subscription.GetCreatedAtCarbon()
+		if subscription.GetStatus() != SUBSCRIPTION_STATUS_INACTIVE && createdAt.Gt(fromCarbon) && createdAt.Lte(toCarbon) {
+			report.New++
+		}
+	}
+
+	if report.ActiveAtStart > 0 {
+		report.Rate = float64(report.Churned) / float64(report.ActiveAtStart)
+	}
+
+	return report, nil
+}
+
+// SubscriptionSignupCohorts groups the subscriptions signed up during the
+// date range by month, and counts how many of each cohort were still live at
+// the end of every month up to the end of the range. Months without signups
+// are left out.
+func (st *storeImplementation) SubscriptionSignupCohorts(ctx context.Context, from string, to string) ([]SignupCohort, error) {
+	fromCarbon, toCarbon, err := analyticsRange(from, to)
+	if err != nil {
+		return nil, errors.New("subscriptionstore > subscription signup cohorts. " + err.Error())
+	}
+
+	subscriptions, err := st.SubscriptionList(ctx, SubscriptionQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	cohorts := map[string][]SubscriptionInterface{}
+	for _, subscription := range subscriptions {
+		createdAt := subscription.GetCreatedAtCarbon()
+		if subscription.GetStatus() == SUBSCRIPTION_STATUS_INACTIVE || createdAt.Lt(fromCarbon) || createdAt.Gt(toCarbon) {
+			continue
+		}
+		month := createdAt.StdTime().Format("2006-01")
+		cohorts[month] = append(cohorts[month], subscription)
+	}
+
+	months := lo.Keys(cohorts)
+	sort.Strings(months)
+
+	result := make([]SignupCohort, 0, len(months))
+	for _, month := range months {
+		members := cohorts[month]
+		cohort := SignupCohort{
+			Month: month,
+			Size:  int64(len(members)),
+		}
+
+		monthStart := members[0].GetCreatedAtCarbon().StartOfMonth()
+		for ; monthStart.Lte(toCarbon); monthStart = monthStart.AddMonthNoOverflow() {
+			at := monthStart.EndOfMonth()
+			if at.Gt(toCarbon) {
+				at = toCarbon
+			}
+
+			retained := lo.CountBy(members, func(subscription SubscriptionInterface) bool {
+				return subscriptionLiveAt(subscription, at)
+			})
+			cohort.Retained = append(cohort.Retained, int64(retained))
+		}
+
+		result = append(result, cohort)
+	}
+
+	return result, nil
+}
+
+// SubscriptionCounts counts the subscriptions matching the query by status
+// and by plan
+func (st *storeImplementation) SubscriptionCounts(ctx context.Context, query SubscriptionQueryInterface) (SubscriptionCountReport, error) {
+	byStatus, err := st.SubscriptionCountBy(ctx, query, COLUMN_STATUS)
+	if err != nil {
+		return SubscriptionCountReport{}, err
+	}
+
+	byPlan, err := st.SubscriptionCountBy(ctx, query, COLUMN_PLAN_ID)
+	if err != nil {
+		return SubscriptionCountReport{}, err
+	}
+
+	return SubscriptionCountReport{
+		ByStatus: byStatus,
+		ByPlan:   byPlan,
+	}, nil
+}
+
+// analyticsData is what the revenue reports read, loaded once per report
+// rather than once per subscription
+type analyticsData struct {
+	subscriptions []SubscriptionInterface
+	// invoices, discounts and items are by subscription ID, the discounts
+	// latest first and the items oldest first
+	invoices  map[string][]InvoiceInterface
+	discounts map[string][]SubscriptionDiscountInterface
+	items     map[string][]SubscriptionItemInterface
+	// plans, versions and coupons are by ID
+	plans    map[string]PlanInterface
+	versions map[string]PlanVersionInterface
+	coupons  map[string]CouponInterface
+}
+
+// analyticsLoad loads the subscriptions, with their invoices, discounts,
+// items, plans and coupons
+func (st *storeImplementation) analyticsLoad(ctx context.Context) (analyticsData, error) {
+	subscriptions, err := st.SubscriptionList(ctx, SubscriptionQuery())
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	invoices, err := st.InvoiceList(ctx, InvoiceQuery())
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	discounts, err := st.SubscriptionDiscountList(ctx, SubscriptionDiscountQuery().
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortOrder("desc"))
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	items, err := st.SubscriptionItemList(ctx, SubscriptionItemQuery().
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortOrder("asc"))
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	plans, err := st.PlanList(ctx, PlanQuery())
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	versions, err := st.PlanVersionList(ctx, PlanVersionQuery())
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	coupons, err := st.CouponList(ctx, CouponQuery())
+	if err != nil {
+		return analyticsData{}, err
+	}
+
+	return analyticsData{
+		subscriptions: subscriptions,
+		invoices: lo.GroupBy(invoices, func(invoice InvoiceInterface) string {
+			return invoice.GetSubscriptionID()
+		}),
+		discounts: lo.GroupBy(discounts, func(discount SubscriptionDiscountInterface) string {
+			return discount.GetSubscriptionID()
+		}),
+		items: lo.GroupBy(items, func(item SubscriptionItemInterface) string {
+			return item.GetSubscriptionID()
+		}),
+		plans: lo.KeyBy(plans, func(plan PlanInterface) string {
+			return plan.GetID()
+		}),
+		versions: lo.KeyBy(versions, func(version PlanVersionInterface) string {
+			return version.GetID()
+		}),
+		coupons: lo.KeyBy(coupons, func(coupon CouponInterface) string {
+			return coupon.GetID()
+		}),
+	}, nil
+}
+
+// subscriptionMRRAt returns the monthly recurring revenue of the
+// subscription at the given time, from its invoice covering the time or else
+// from its current price
+func (data analyticsData) subscriptionMRRAt(subscription SubscriptionInterface, at *carbon.Carbon) (subscriptionMRR, error) {
+	if !subscriptionLiveAt(subscription, at) {
+		return subscriptionMRR{}, nil
+	}
+
+	var covering InvoiceInterface
+	for _, invoice := range data.invoices[subscription.GetID()] {
+		if invoice.GetStatus() == INVOICE_STATUS_VOID {
+			continue
+		}
+		if invoice.GetPeriodStartCarbon().Gt(at) || invoice.GetPeriodEndCarbon().Lte(at) {
+			continue
+		}
+		if covering == nil || invoice.GetPeriodStartCarbon().Gt(covering.GetPeriodStartCarbon()) {
+			covering = invoice
+		}
+	}
+
+	if covering != nil {
+		snapshot, err := covering.GetPlanSnapshot()
+		if err != nil {
+			return subscriptionMRR{}, err
+		}
+		cents, err := amountCents(covering.GetAmount())
+		if err != nil {
+			return subscriptionMRR{}, err
+		}
+		monthly, err := monthlyCents(cents, snapshot[COLUMN_INTERVAL])
+		if err != nil {
+			return subscriptionMRR{}, err
+		}
+		return subscriptionMRR{currency: covering.GetCurrency(), cents: monthly}, nil
+	}
+
+	// Cancelled subscriptions were still paying while live, unlike paused ones
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_PAUSED {
+		return subscriptionMRR{}, nil
+	}
+
+	plan, err := planAtPinnedVersion(data.plans[subscription.GetPlanID()], data.versions[subscription.GetPlanVersionID()])
+	if err != nil {
+		return subscriptionMRR{}, err
+	}
+	if plan == nil {
+		return subscriptionMRR{}, nil
+	}
+
+	itemLines, err := itemPriceLines(data.items[subscription.GetID()], data.plans, plan)
+	if err != nil {
+		return subscriptionMRR{}, err
+	}
+
+	price, err := calculatePeriodPrice(subscription, plan, itemLines, data.couponAt(subscription, at), at)
+	if err != nil {
+		return subscriptionMRR{}, err
+	}
+	cents, err := amountCents(price.Total)
+	if err != nil {
+		return subscriptionMRR{}, err
+	}
+	monthly, err := monthlyCents(cents, plan.GetInterval())
+	if err != nil {
+		return subscriptionMRR{}, err
+	}
+
+	return subscriptionMRR{currency: price.Currency, cents: monthly}, nil
+}
+
+// couponAt returns the coupon of the latest discount of the subscription
+// active at the given time, or nil if there is none
+func (data analyticsData) couponAt(subscription SubscriptionInterface, at *carbon.Carbon) CouponInterface {
+	for _, discount := range data.discounts[subscription.GetID()] {
+		if discount.GetStartsAtCarbon().Lte(at) && discount.GetEndsAtCarbon().Gt(at) {
+			return data.coupons[discount.GetCouponID()]
+		}
+	}
+	return nil
+}
+
+// analyticsRange parses the dates of a report range
+func analyticsRange(from string, to string) (*carbon.Carbon, *carbon.Carbon, error) {
+	fromCarbon := carbon.Parse(from, carbon.UTC)
+	if fromCarbon.IsInvalid() {
+		return nil, nil, errors.New("from is not a valid date")
+	}
+	toCarbon := carbon.Parse(to, carbon.UTC)
+	if toCarbon.IsInvalid() {
+		return nil, nil, errors.New("to is not a valid date")
+	}
+	if toCarbon.Lt(fromCarbon) {
+		return nil, nil, errors.New("to cannot be before from")
+	}
+	return fromCarbon, toCarbon, nil
+}
@@ -0,0 +1,256 @@
+package subscriptionstore
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// analyticsFixture creates subscriptions covering new, expansion,
+// contraction and churned revenue between 2025-01-20 and 2025-02-20
+func analyticsFixture(t *testing.T) StoreInterface {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	monthly := NewPlan().
+		SetTitle("Monthly").
+		SetStatus(PLAN_STATUS_ACTIVE).
+		SetInterval(PLAN_INTERVAL_MONTHLY).
+		SetCurrency(CURRENCY_USD).
+		SetPrice("10.00")
+	yearly := NewPlan().
+		SetTitle("Yearly").
+		SetStatus(PLAN_STATUS_ACTIVE).
+		SetInterval(PLAN_INTERVAL_YEARLY).
+		SetCurrency(CURRENCY_USD).
+		SetPrice("120.00")
+	for _, plan := range []PlanInterface{monthly, yearly} {
+		if err := store.PlanCreate(ctx, plan); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	create := func(id string, plan PlanInterface, quantity int, createdAt string) SubscriptionInterface {
+		subscription := NewSubscription().
+			SetID(id).
+			SetSubscriberID("user_" + id).
+			SetPlanID(plan.GetID()).
+			SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+			SetQuantity(quantity).
+			SetPeriodStart(createdAt).
+			SetCreatedAt(createdAt)
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		return subscription
+	}
+
+	invoice := func(subscription SubscriptionInterface, periodStart string, periodEnd string, amount string) {
+		invoice := NewInvoice().
+			SetSubscriptionID(subscription.GetID()).
+			SetSubscriberID(subscription.GetSubscriberID()).
+			SetPlanID(subscription.GetPlanID()).
+			SetPeriodStart(periodStart).
+			SetPeriodEnd(periodEnd).
+			SetAmount(amount).
+			SetCurrency(CURRENCY_USD)
+		if _, err := invoice.SetPlanSnapshot(map[string]string{COLUMN_INTERVAL: PLAN_INTERVAL_MONTHLY}); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if err := store.InvoiceCreate(ctx, invoice); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// Billed one seat in January, three seats since
+	expanded := create("expanded", monthly, 3, "2025-01-05 00:00:00")
+	invoice(expanded, "2025-01-05 00:00:00", "2025-02-05 00:00:00", "10.00")
+
+	create("yearly", yearly, 1, "2025-01-10 00:00:00")
+
+	churned := create("churned", monthly, 1, "2024-12-01 00:00:00")
+	st := store.(*storeImplementation)
+	err = st.subscriptionCancel(ctx, churned.GetID(), SubscriptionCancelOptions{
+		Reason: CANCELLATION_REASON_TOO_EXPENSIVE,
+	}, carbon.Parse("2025-02-10 00:00:00", carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	create("new", monthly, 1, "2025-02-15 00:00:00")
+
+	// Billed two seats in January, one seat since
+	contracted := create("contracted", monthly, 1, "2024-12-15 00:00:00")
+	invoice(contracted, "2025-01-15 00:00:00", "2025-02-15 00:00:00", "20.00")
+
+	return store
+}
+
+func TestStoreSubscriptionMRR(t *testing.T) {
+	store := analyticsFixture(t)
+	ctx := context.Background()
+
+	mrr, err := store.SubscriptionMRR(ctx, "2025-01-20 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(mrr) != 1 || mrr[CURRENCY_USD] != "50.00" {
+		t.Errorf("expected MRR of 50.00 USD, got %v", mrr)
+	}
+
+	mrr, err = store.SubscriptionMRR(ctx, "2025-02-20 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(mrr) != 1 || mrr[CURRENCY_USD] != "60.00" {
+		t.Errorf("expected MRR of 60.00 USD, got %v", mrr)
+	}
+
+	if _, err := store.SubscriptionMRR(ctx, "not a date"); err == nil {
+		t.Error("expected error for an invalid date")
+	}
+}
+
+func TestStoreSubscriptionMRRWithoutInvoice(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	pro := NewPlan().SetTitle("Pro").SetInterval(PLAN_INTERVAL_MONTHLY).SetCurrency(CURRENCY_USD).SetPrice("10.00")
+	addOn := NewPlan().SetTitle("Add-on").SetInterval(PLAN_INTERVAL_MONTHLY).SetCurrency(CURRENCY_USD).SetPrice("5.00")
+	for _, plan := range []PlanInterface{pro, addOn} {
+		if err := store.PlanCreate(ctx, plan); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(pro.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetQuantity(2).
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetCreatedAt("2025-01-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionItemCreate(ctx, NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	coupon := NewCoupon().SetType(COUPON_TYPE_PERCENT).SetPercentOff("50").SetDuration(COUPON_DURATION_FOREVER)
+	if err := store.CouponCreate(ctx, coupon); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), coupon.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The subscription stays on the version of the plan it is pinned to
+	if err := store.PlanUpdate(ctx, pro.SetPrice("20.00")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	mrr, err := store.SubscriptionMRR(ctx, "2025-01-15 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(mrr) != 1 || mrr[CURRENCY_USD] != "12.50" {
+		t.Errorf("expected MRR of 12.50 USD, got %v", mrr)
+	}
+}
+
+func TestStoreSubscriptionMRRMovement(t *testing.T) {
+	store := analyticsFixture(t)
+	ctx := context.Background()
+
+	movements, err := store.SubscriptionMRRMovement(ctx, "2025-01-20 00:00:00", "2025-02-20 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(movements) != 1 {
+		t.Fatalf("expected 1 movement, got %d", len(movements))
+	}
+
+	expected := MRRMovement{
+		Currency:    CURRENCY_USD,
+		StartMRR:    "50.00",
+		New:         "10.00",
+		Expansion:   "20.00",
+		Contraction: "10.00",
+		Churned:     "10.00",
+		EndMRR:      "60.00",
+	}
+	if movements[0] != expected {
+		t.Errorf("expected movement %+v, got %+v", expected, movements[0])
+	}
+
+	if _, err := store.SubscriptionMRRMovement(ctx, "2025-02-20 00:00:00", "2025-01-20 00:00:00"); err == nil {
+		t.Error("expected error for a range ending before it starts")
+	}
+}
+
+func TestStoreSubscriptionChurn(t *testing.T) {
+	store := analyticsFixture(t)
+
+	report, err := store.SubscriptionChurn(context.Background(), "2025-01-20 00:00:00", "2025-02-20 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if report.ActiveAtStart != 4 || report.Churned != 1 || report.New != 1 {
+		t.Errorf("expected 4 active, 1 churned and 1 new, got %d / %d / %d", report.ActiveAtStart, report.Churned, report.New)
+	}
+	if report.Rate != 0.25 {
+		t.Errorf("expected churn rate 0.25, got %v", report.Rate)
+	}
+}
+
+func TestStoreSubscriptionSignupCohorts(t *testing.T) {
+	store := analyticsFixture(t)
+
+	cohorts, err := store.SubscriptionSignupCohorts(context.Background(), "2024-12-01 00:00:00", "2025-02-28 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []SignupCohort{
+		{Month: "2024-12", Size: 2, Retained: []int64{2, 2, 1}},
+		{Month: "2025-01", Size: 2, Retained: []int64{2, 2}},
+		{Month: "2025-02", Size: 1, Retained: []int64{1}},
+	}
+	if len(cohorts) != len(expected) {
+		t.Fatalf("expected %d cohorts, got %d", len(expected), len(cohorts))
+	}
+	for i, cohort := range cohorts {
+		if cohort.Month != expected[i].Month || cohort.Size != expected[i].Size || !slices.Equal(cohort.Retained, expected[i].Retained) {
+			t.Errorf("expected cohort %+v, got %+v", expected[i], cohort)
+		}
+	}
+}
+
+func TestStoreSubscriptionCounts(t *testing.T) {
+	store := analyticsFixture(t)
+	ctx := context.Background()
+
+	counts, err := store.SubscriptionCounts(ctx, SubscriptionQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(counts.ByStatus) != 2 || counts.ByStatus[SUBSCRIPTION_STATUS_ACTIVE] != 4 || counts.ByStatus[SUBSCRIPTION_STATUS_CANCELLED] != 1 {
+		t.Errorf("unexpected counts by status %v", counts.ByStatus)
+	}
+	if len(counts.ByPlan) != 2 {
+		t.Errorf("expected counts for 2 plans, got %v", counts.ByPlan)
+	}
+
+	if _, err := store.SubscriptionCounts(ctx, SubscriptionQuery().SetLimit(10)); err == nil {
+		t.Error("expected error for a paginated query")
+	}
+}
@@ -0,0 +1,270 @@
+package subscriptionstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// NewCachedStoreOptions define the options for creating a new cached store
+type NewCachedStoreOptions struct {
+	Store StoreInterface
+	// Cache is the backend of the cached values. Defaults to an LRU cache of
+	// 1000 values.
+	Cache Cache
+	// TTL is how long the values are cached. Writes made through other
+	// stores, i.e. by other processes, are seen once it has passed.
+	// Defaults to one minute.
+	TTL time.Duration
+}
+
+// NewCachedStore wraps a store with a cache of the plans found by ID and of
+// the active subscription of each subscriber, the lookups made to check the
+// entitlements of a subscriber.
+//
+// Writes to the plans or the subscriptions made through the cached store
+// invalidate the cached values. Each read returns new entities, which the
+// callers are free to change.
+func NewCachedStore(opts NewCachedStoreOptions) (StoreInterface, error) {
+	if opts.Store == nil {
+		return nil, errors.New("cached store: Store is required")
+	}
+	if opts.TTL < 0 {
+		return nil, errors.New("cached store: TTL cannot be negative")
+	}
+	if opts.Cache == nil {
+		opts.Cache = NewLRUCache(1000)
+	}
+	if opts.TTL == 0 {
+		opts.TTL = time.Minute
+	}
+
+	return &cachedStore{
+		StoreInterface: opts.Store,
+		cache:          opts.Cache,
+		ttl:            opts.TTL,
+	}, nil
+}
+
+var _ StoreInterface = (*cachedStore)(nil)
+
+// cachedStore passes the calls to the wrapped store, caching the plan and
+// active subscription lookups.
+//
+// Instead of deleting the cached values, writes move on the generation of the
+// plans or of the subscriptions, which is part of the cache keys. Values read
+// before a write are then never found again, even when the read finishes
+// after the write.
+type cachedStore struct {
+	StoreInterface
+	cache                  Cache
+	ttl                    time.Duration
+	planGeneration         atomic.Uint64
+	subscriptionGeneration atomic.Uint64
+}
+
+// == CACHED READS =============================================================
+
+// PlanFindByID finds a plan by id, from the cache if it was found before
+func (st *cachedStore) PlanFindByID(ctx context.Context, id string) (PlanInterface, error) {
+	key := "plan:" + strconv.FormatUint(st.planGeneration.Load(), 10) + ":" + id
+	if value, found := st.cache.Get(key); found {
+		if data, ok := value.(map[string]string); ok {
+			return NewPlanFromExistingData(data), nil
+		}
+	}
+
+	plan, err := st.StoreInterface.PlanFindByID(ctx, id)
+	if err != nil || plan == nil {
+		return plan, err
+	}
+
+	data, err := planExistingData(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	st.cache.Set(key, data, st.ttl)
+	return plan, nil
+}
+
+// SubscriptionFindActiveBySubscriberID finds the latest active subscription
+// of the subscriber, from the cache if it was looked up before. Subscribers
+// without an active subscription are cached too.
+func (st *cachedStore) SubscriptionFindActiveBySubscriberID(ctx context.Context, subscriberID string) (SubscriptionInterface, error) {
+	key := "subscription_active:" + strconv.FormatUint(st.subscriptionGeneration.Load(), 10) + ":" + subscriberID
+	if value, found := st.cache.Get(key); found {
+		if data, ok := value.(map[string]string); ok && data != nil {
+			return NewSubscriptionFromExistingData(data), nil
+		}
+		return nil, nil
+	}
+
+	subscription, err := st.StoreInterface.SubscriptionFindActiveBySubscriberID(ctx, subscriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]string
+	if subscription != nil {
+		data, err = subscriptionExistingData(subscription)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	st.cache.Set(key, data, st.ttl)
+	return subscription, nil
+}
+
+// == INVALIDATING WRITES ======================================================
+
+// invalidatePlans stops the plans cached so far from being found
+func (st *cachedStore) invalidatePlans() {
+	st.planGeneration.Add(1)
+}
+
+// invalidateSubscriptions stops the active subscriptions cached so far from
+// being found
+func (st *cachedStore) invalidateSubscriptions() {
+	st.subscriptionGeneration.Add(1)
+}
+
+func (st *cachedStore) MigrateDown(ctx context.Context, tx ...*sql.Tx) error {
+	defer st.invalidatePlans()
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.MigrateDown(ctx, tx...)
+}
+
+// Transaction runs fn with the transaction store of the wrapped store, which
+// reads through no cache, so fn sees its own writes. The cached values are
+// invalidated once the transaction ends.
+func (st *cachedStore) Transaction(ctx context.Context, fn func(txStore StoreInterface) error) error {
+	defer st.invalidatePlans()
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.Transaction(ctx, fn)
+}
+
+func (st *cachedStore) MigrateUp(ctx context.Context, tx ...*sql.Tx) error {
+	defer st.invalidatePlans()
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.MigrateUp(ctx, tx...)
+}
+
+func (st *cachedStore) DunningRecordFailure(ctx context.Context, subscriptionID string, reason string) (DunningAttemptInterface, error) {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.DunningRecordFailure(ctx, subscriptionID, reason)
+}
+
+func (st *cachedStore) DunningRecordSuccess(ctx context.Context, subscriptionID string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.DunningRecordSuccess(ctx, subscriptionID)
+}
+
+func (st *cachedStore) PlanCreate(ctx context.Context, plan PlanInterface) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanCreate(ctx, plan)
+}
+
+func (st *cachedStore) PlanDelete(ctx context.Context, plan PlanInterface) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanDelete(ctx, plan)
+}
+
+func (st *cachedStore) PlanDeleteByID(ctx context.Context, id string) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanDeleteByID(ctx, id)
+}
+
+func (st *cachedStore) PlanSoftDelete(ctx context.Context, plan PlanInterface) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanSoftDelete(ctx, plan)
+}
+
+func (st *cachedStore) PlanSoftDeleteByID(ctx context.Context, id string) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanSoftDeleteByID(ctx, id)
+}
+
+func (st *cachedStore) PlanUpdate(ctx context.Context, plan PlanInterface) error {
+	defer st.invalidatePlans()
+	return st.StoreInterface.PlanUpdate(ctx, plan)
+}
+
+func (st *cachedStore) PlanVersionMigrationRunDue(ctx context.Context, now string) ([]PlanVersionMigrationInterface, error) {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.PlanVersionMigrationRunDue(ctx, now)
+}
+
+func (st *cachedStore) SubscriptionCancel(ctx context.Context, subscriptionID string, options SubscriptionCancelOptions) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionCancel(ctx, subscriptionID, options)
+}
+
+func (st *cachedStore) SubscriptionCreate(ctx context.Context, subscription SubscriptionInterface) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionCreate(ctx, subscription)
+}
+
+func (st *cachedStore) SubscriptionDelete(ctx context.Context, subscription SubscriptionInterface) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionDelete(ctx, subscription)
+}
+
+func (st *cachedStore) SubscriptionDeleteByID(ctx context.Context, id string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionDeleteByID(ctx, id)
+}
+
+func (st *cachedStore) SubscriptionPause(ctx context.Context, id string, resumeAt string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionPause(ctx, id, resumeAt)
+}
+
+func (st *cachedStore) SubscriptionResume(ctx context.Context, id string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionResume(ctx, id)
+}
+
+func (st *cachedStore) SubscriptionResumeDue(ctx context.Context, now string) ([]SubscriptionInterface, error) {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionResumeDue(ctx, now)
+}
+
+func (st *cachedStore) SubscriptionScheduleCreate(ctx context.Context, schedule SubscriptionScheduleInterface) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionScheduleCreate(ctx, schedule)
+}
+
+func (st *cachedStore) SubscriptionScheduleRunDue(ctx context.Context, now string) ([]SubscriptionScheduleInterface, error) {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionScheduleRunDue(ctx, now)
+}
+
+func (st *cachedStore) SubscriptionSetQuantity(ctx context.Context, subscriptionID string, quantity int) (Proration, error) {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionSetQuantity(ctx, subscriptionID, quantity)
+}
+
+func (st *cachedStore) SubscriptionSoftDelete(ctx context.Context, subscription SubscriptionInterface) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionSoftDelete(ctx, subscription)
+}
+
+func (st *cachedStore) SubscriptionSoftDeleteByID(ctx context.Context, id string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionSoftDeleteByID(ctx, id)
+}
+
+func (st *cachedStore) SubscriptionUncancel(ctx context.Context, subscriptionID string) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionUncancel(ctx, subscriptionID)
+}
+
+func (st *cachedStore) SubscriptionUpdate(ctx context.Context, subscription SubscriptionInterface) error {
+	defer st.invalidateSubscriptions()
+	return st.StoreInterface.SubscriptionUpdate(ctx, subscription)
+}
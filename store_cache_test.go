@@ -0,0 +1,206 @@
+package subscriptionstore
+
+import (
+	"context"
+	"maps"
+	"testing"
+)
+
+// countingStore counts the lookups reaching the wrapped store
+type countingStore struct {
+	StoreInterface
+	planFinds               int
+	activeSubscriptionFinds int
+}
+
+func (st *countingStore) PlanFindByID(ctx context.Context, id string) (PlanInterface, error) {
+	st.planFinds++
+	return st.StoreInterface.PlanFindByID(ctx, id)
+}
+
+func (st *countingStore) SubscriptionFindActiveBySubscriberID(ctx context.Context, subscriberID string) (SubscriptionInterface, error) {
+	st.activeSubscriptionFinds++
+	return st.StoreInterface.SubscriptionFindActiveBySubscriberID(ctx, subscriberID)
+}
+
+func initCachedStore(t *testing.T) (StoreInterface, *countingStore) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	counting := &countingStore{StoreInterface: store}
+	cached, err := NewCachedStore(NewCachedStoreOptions{Store: counting})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return cached, counting
+}
+
+func TestNewCachedStoreRequiresStore(t *testing.T) {
+	if _, err := NewCachedStore(NewCachedStoreOptions{}); err == nil {
+		t.Error("expected error without a store")
+	}
+}
+
+func TestCachedStorePlanFindByID(t *testing.T) {
+	store, counting := initCachedStore(t)
+	ctx := context.Background()
+
+	plan := NewPlan().SetTitle("Gold").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for range 3 {
+		found, err := store.PlanFindByID(ctx, plan.GetID())
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if found == nil || found.GetTitle() != "Gold" {
+			t.Fatalf("expected plan Gold, got %v", found)
+		}
+	}
+	if counting.planFinds != 1 {
+		t.Errorf("expected 1 lookup of the store, got %d", counting.planFinds)
+	}
+
+	plan.SetTitle("Platinum")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetTitle() != "Platinum" || counting.planFinds != 2 {
+		t.Errorf("expected the updated plan from the store, got %s after %d lookups", found.GetTitle(), counting.planFinds)
+	}
+
+	// Plans not found are not cached
+	for range 2 {
+		if _, err := store.PlanFindByID(ctx, "missing"); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	if counting.planFinds != 4 {
+		t.Errorf("expected missing plans to be looked up every time, got %d lookups", counting.planFinds)
+	}
+}
+
+func TestCachedStoreSubscriptionFindActiveBySubscriberID(t *testing.T) {
+	store, counting := initCachedStore(t)
+	ctx := context.Background()
+
+	plan := NewPlan().SetTitle("Gold").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// Subscribers without an active subscription are cached too
+	for range 2 {
+		active, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if active != nil {
+			t.Fatal("expected no active subscription")
+		}
+	}
+	if counting.activeSubscriptionFinds != 1 {
+		t.Errorf("expected 1 lookup of the store, got %d", counting.activeSubscriptionFinds)
+	}
+
+	subscription := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for range 2 {
+		active, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if active == nil || active.GetID() != subscription.GetID() {
+			t.Fatalf("expected the created subscription, got %v", active)
+		}
+	}
+	if counting.activeSubscriptionFinds != 2 {
+		t.Errorf("expected the create to invalidate the cache, got %d lookups", counting.activeSubscriptionFinds)
+	}
+
+	if err := store.SubscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	active, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if active != nil {
+		t.Errorf("expected no active subscription after the cancellation, got %s", active.GetID())
+	}
+}
+
+func TestCachedStoreReturnsCopies(t *testing.T) {
+	store, counting := initCachedStore(t)
+	ctx := context.Background()
+
+	plan := NewPlan().SetTitle("Gold").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	subscription := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if _, err := subscription.SetMeta("tenant_id", "acme"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	first, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	first.SetTitle("Changed")
+
+	second, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if second.GetTitle() != "Gold" || counting.planFinds != 1 {
+		t.Errorf("expected an unchanged cached plan, got %s after %d lookups", second.GetTitle(), counting.planFinds)
+	}
+
+	uncached, err := counting.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for range 2 {
+		active, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if active == uncached {
+			t.Fatal("expected a copy of the subscription")
+		}
+
+		expected, err := subscriptionExistingData(uncached)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		actual, err := subscriptionExistingData(active)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if !maps.Equal(expected, actual) {
+			t.Errorf("expected the cached subscription %v, got %v", expected, actual)
+		}
+
+		if _, err := active.SetMeta("tenant_id", "globex"); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+}
@@ -0,0 +1,832 @@
+package subscriptionstore
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// conformanceStores are the stores which must behave the same on the
+// conformance tests. The SQL store promotes the tenant_id meta key to a
+// column, so the queries on promoted columns are compared too.
+var conformanceStores = map[string]func(t *testing.T) PlanSubscriptionStore{
+	"sql": func(t *testing.T) PlanSubscriptionStore {
+		store, err := NewStore(NewStoreOptions{
+			DB:                      initDB(":memory:"),
+			PlanTableName:           "plan_table",
+			SubscriptionTableName:   "subscription_table",
+			PlanMetaColumns:         []string{"tenant_id"},
+			SubscriptionMetaColumns: []string{"tenant_id"},
+			AutomigrateEnabled:      true,
+		})
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		return store
+	},
+	"memory": func(t *testing.T) PlanSubscriptionStore {
+		return NewMemoryStore()
+	},
+}
+
+// conformanceTests are run on a new store of each of the conformance stores
+var conformanceTests = map[string]func(t *testing.T, store PlanSubscriptionStore){
+	"PlanFilters":                        conformancePlanFilters,
+	"PlanOrderAndPagination":             conformancePlanOrderAndPagination,
+	"PlanSoftDelete":                     conformancePlanSoftDelete,
+	"PlanCountBy":                        conformancePlanCountBy,
+	"PlanUpdateAndDelete":                conformancePlanUpdateAndDelete,
+	"SubscriptionFilters":                conformanceSubscriptionFilters,
+	"SubscriptionOrderAndPagination":     conformanceSubscriptionOrderAndPagination,
+	"SubscriptionSoftDelete":             conformanceSubscriptionSoftDelete,
+	"SubscriptionCountBy":                conformanceSubscriptionCountBy,
+	"SubscriptionCreateDefaults":         conformanceSubscriptionCreateDefaults,
+	"SubscriptionCancelAndUncancel":      conformanceSubscriptionCancelAndUncancel,
+	"SubscriptionPauseAndResume":         conformanceSubscriptionPauseAndResume,
+	"SubscriptionFindActiveBySubscriber": conformanceSubscriptionFindActiveBySubscriber,
+	"PlanVersions":                       conformancePlanVersions,
+	"SubscriptionPlanVersion":            conformanceSubscriptionPlanVersion,
+	"SubscriptionUpdatePlanCurrency":     conformanceSubscriptionUpdatePlanCurrency,
+}
+
+func TestStoreConformance(t *testing.T) {
+	for storeName, newStore := range conformanceStores {
+		for testName, test := range conformanceTests {
+			t.Run(storeName+"/"+testName, func(t *testing.T) {
+				test(t, newStore(t))
+			})
+		}
+	}
+}
+
+// == HELPERS ==================================================================
+
+// conformancePlan returns a monthly active plan with the id and title
+func conformancePlan(id string, title string) PlanInterface {
+	return NewPlan().
+		SetID(id).
+		SetTitle(title).
+		SetPrice("1.00").
+		SetCurrency("usd").
+		SetStatus(PLAN_STATUS_ACTIVE).
+		SetType(PLAN_TYPE_SILVER).
+		SetInterval(PLAN_INTERVAL_MONTHLY)
+}
+
+// conformanceSubscription returns an active subscription with the id, of a
+// plan which is not stored
+func conformanceSubscription(id string, subscriberID string) SubscriptionInterface {
+	return NewSubscription().
+		SetID(id).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetSubscriberID(subscriberID).
+		SetPlanID("plan_unknown")
+}
+
+// conformanceCreatePlans creates the plans, failing the test on errors
+func conformanceCreatePlans(t *testing.T, store PlanSubscriptionStore, plans ...PlanInterface) {
+	t.Helper()
+	for _, plan := range plans {
+		if err := store.PlanCreate(context.Background(), plan); err != nil {
+			t.Fatal("unexpected error creating plan:", err)
+		}
+	}
+}
+
+// conformanceCreateSubscriptions creates the subscriptions, failing the test
+// on errors
+func conformanceCreateSubscriptions(t *testing.T, store PlanSubscriptionStore, subscriptions ...SubscriptionInterface) {
+	t.Helper()
+	for _, subscription := range subscriptions {
+		if err := store.SubscriptionCreate(context.Background(), subscription); err != nil {
+			t.Fatal("unexpected error creating subscription:", err)
+		}
+	}
+}
+
+// conformancePlanIDs lists the plans of the query, returning their ids
+func conformancePlanIDs(t *testing.T, store PlanSubscriptionStore, query PlanQueryInterface) []string {
+	t.Helper()
+	list, err := store.PlanList(context.Background(), query)
+	if err != nil {
+		t.Fatal("unexpected error listing plans:", err)
+	}
+	return lo.Map(list, func(plan PlanInterface, _ int) string {
+		return plan.GetID()
+	})
+}
+
+// conformanceSubscriptionIDs lists the subscriptions of the query, returning
+// their ids
+func conformanceSubscriptionIDs(t *testing.T, store PlanSubscriptionStore, query SubscriptionQueryInterface) []string {
+	t.Helper()
+	list, err := store.SubscriptionList(context.Background(), query)
+	if err != nil {
+		t.Fatal("unexpected error listing subscriptions:", err)
+	}
+	return lo.Map(list, func(subscription SubscriptionInterface, _ int) string {
+		return subscription.GetID()
+	})
+}
+
+// conformanceSorted returns the ids sorted, for the queries without an order
+func conformanceSorted(ids []string) []string {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// == PLAN TESTS ===============================================================
+
+func conformancePlanFilters(t *testing.T, store PlanSubscriptionStore) {
+	a := conformancePlan("plan_a", "A").SetStripePriceID("price_a")
+	a.SetMetas(map[string]string{"tier": "pro", "region": "eu"})
+	b := conformancePlan("plan_b", "B").SetInterval(PLAN_INTERVAL_YEARLY).SetType(PLAN_TYPE_GOLD)
+	b.SetMetas(map[string]string{"tier": "pro"})
+	c := conformancePlan("plan_c", "C").SetStatus(PLAN_STATUS_INACTIVE)
+	conformanceCreatePlans(t, store, a, b, c)
+
+	cases := []struct {
+		name     string
+		query    PlanQueryInterface
+		expected []string
+	}{
+		{"all", PlanQuery(), []string{"plan_a", "plan_b", "plan_c"}},
+		{"id", PlanQuery().SetID("plan_b"), []string{"plan_b"}},
+		{"id in", PlanQuery().SetIDIn([]string{"plan_a", "plan_c", "plan_x"}), []string{"plan_a", "plan_c"}},
+		{"status", PlanQuery().SetStatus(PLAN_STATUS_INACTIVE), []string{"plan_c"}},
+		{"status in", PlanQuery().SetStatusIn([]string{PLAN_STATUS_ACTIVE}), []string{"plan_a", "plan_b"}},
+		{"interval", PlanQuery().SetInterval(PLAN_INTERVAL_YEARLY), []string{"plan_b"}},
+		{"interval in", PlanQuery().SetIntervalIn([]string{PLAN_INTERVAL_MONTHLY}), []string{"plan_a", "plan_c"}},
+		{"stripe price id", PlanQuery().SetStripePriceID("price_a"), []string{"plan_a"}},
+		{"type", PlanQuery().SetType(PLAN_TYPE_GOLD), []string{"plan_b"}},
+		{"meta equals", PlanQuery().SetMetaEquals("tier", "pro"), []string{"plan_a", "plan_b"}},
+		{"meta equals all", PlanQuery().SetMetaEquals("tier", "pro").SetMetaEquals("region", "eu"), []string{"plan_a"}},
+		{"meta has key", PlanQuery().SetMetaHasKey("region"), []string{"plan_a"}},
+		{"combined", PlanQuery().SetStatus(PLAN_STATUS_ACTIVE).SetInterval(PLAN_INTERVAL_MONTHLY), []string{"plan_a"}},
+		{"none", PlanQuery().SetType(PLAN_TYPE_TRIAL), []string{}},
+	}
+
+	for _, c := range cases {
+		ids := conformanceSorted(conformancePlanIDs(t, store, c.query))
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+
+		count, err := store.PlanCount(context.Background(), c.query)
+		if err != nil {
+			t.Fatal("unexpected error counting plans:", err)
+		}
+		if count != int64(len(c.expected)) {
+			t.Fatalf("%s: expected count %d, got %d", c.name, len(c.expected), count)
+		}
+	}
+
+	if _, err := store.PlanList(context.Background(), PlanQuery().SetID("")); err == nil {
+		t.Fatal("expected error for an invalid query")
+	}
+}
+
+func conformancePlanOrderAndPagination(t *testing.T, store PlanSubscriptionStore) {
+	plans := []PlanInterface{
+		conformancePlan("plan_1", "Bravo"),
+		conformancePlan("plan_2", "Delta"),
+		conformancePlan("plan_3", "Alpha"),
+		conformancePlan("plan_4", "Charlie"),
+	}
+	for i, tenantID := range []string{"acme", "", "globex", "initech"} {
+		if tenantID == "" {
+			continue
+		}
+		if _, err := plans[i].SetMeta("tenant_id", tenantID); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	conformanceCreatePlans(t, store, plans...)
+
+	cases := []struct {
+		name     string
+		query    PlanQueryInterface
+		expected []string
+	}{
+		{"asc", PlanQuery().SetOrderBy(COLUMN_TITLE).SetSortOrder("asc"), []string{"plan_3", "plan_1", "plan_4", "plan_2"}},
+		{"desc", PlanQuery().SetOrderBy(COLUMN_TITLE).SetSortOrder("desc"), []string{"plan_2", "plan_4", "plan_1", "plan_3"}},
+		{"default desc", PlanQuery().SetOrderBy(COLUMN_TITLE), []string{"plan_2", "plan_4", "plan_1", "plan_3"}},
+		{"limit", PlanQuery().SetOrderBy(COLUMN_TITLE).SetSortOrder("asc").SetLimit(2), []string{"plan_3", "plan_1"}},
+		{"limit and offset", PlanQuery().SetOrderBy(COLUMN_TITLE).SetSortOrder("asc").SetLimit(2).SetOffset(1), []string{"plan_1", "plan_4"}},
+		{"offset past end", PlanQuery().SetOrderBy(COLUMN_TITLE).SetLimit(2).SetOffset(10), []string{}},
+		{"promoted meta asc", PlanQuery().SetOrderBy(metaColumnName("tenant_id")).SetSortOrder("asc"), []string{"plan_2", "plan_1", "plan_3", "plan_4"}},
+		{"promoted meta desc", PlanQuery().SetOrderBy(metaColumnName("tenant_id")), []string{"plan_4", "plan_3", "plan_1", "plan_2"}},
+	}
+
+	for _, c := range cases {
+		ids := conformancePlanIDs(t, store, c.query)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+	}
+}
+
+func conformancePlanSoftDelete(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreatePlans(t, store, conformancePlan("plan_a", "A"), conformancePlan("plan_b", "B"))
+
+	if err := store.PlanSoftDeleteByID(ctx, "plan_a"); err != nil {
+		t.Fatal("unexpected error soft deleting plan:", err)
+	}
+
+	if ids := conformancePlanIDs(t, store, PlanQuery()); !reflect.DeepEqual(ids, []string{"plan_b"}) {
+		t.Fatal("expected only plan_b, got:", ids)
+	}
+
+	found, err := store.PlanFindByID(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found != nil {
+		t.Fatal("expected soft deleted plan not to be found")
+	}
+
+	exists, err := store.PlanExists(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if exists {
+		t.Fatal("expected soft deleted plan not to exist")
+	}
+
+	ids := conformanceSorted(conformancePlanIDs(t, store, PlanQuery().SetSoftDeletedIncluded(true)))
+	if !reflect.DeepEqual(ids, []string{"plan_a", "plan_b"}) {
+		t.Fatal("expected soft deleted plan to be included, got:", ids)
+	}
+
+	list, err := store.PlanList(ctx, PlanQuery().SetID("plan_a").SetSoftDeletedIncluded(true))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 1 || !list[0].IsSoftDeleted() {
+		t.Fatal("expected the included plan to be soft deleted")
+	}
+}
+
+func conformancePlanCountBy(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreatePlans(t, store,
+		conformancePlan("plan_a", "A"),
+		conformancePlan("plan_b", "B").SetInterval(PLAN_INTERVAL_YEARLY),
+		conformancePlan("plan_c", "C").SetStatus(PLAN_STATUS_INACTIVE),
+	)
+
+	counts, err := store.PlanCountBy(ctx, PlanQuery(), COLUMN_INTERVAL)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := map[string]int64{PLAN_INTERVAL_MONTHLY: 2, PLAN_INTERVAL_YEARLY: 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+
+	counts, err = store.PlanCountBy(ctx, PlanQuery().SetStatus(PLAN_STATUS_ACTIVE), COLUMN_STATUS)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !reflect.DeepEqual(counts, map[string]int64{PLAN_STATUS_ACTIVE: 2}) {
+		t.Fatal("expected 2 active plans, got:", counts)
+	}
+
+	if _, err := store.PlanCountBy(ctx, PlanQuery(), COLUMN_TITLE); err == nil {
+		t.Fatal("expected error for an unsupported column")
+	}
+	if _, err := store.PlanCountBy(ctx, PlanQuery().SetLimit(1), COLUMN_STATUS); err == nil {
+		t.Fatal("expected error for a paginated query")
+	}
+}
+
+func conformancePlanUpdateAndDelete(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	plan := conformancePlan("plan_a", "Before")
+	plan.SetMetas(map[string]string{"color": "red"})
+	conformanceCreatePlans(t, store, plan)
+
+	found, err := store.PlanFindByID(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Fatal("expected plan to be found")
+	}
+	if found.GetTitle() != "Before" || found.GetPrice() != "1.00" {
+		t.Fatal("unexpected plan:", found.GetTitle(), found.GetPrice())
+	}
+	if metas, _ := found.GetMetas(); metas["color"] != "red" {
+		t.Fatal("expected meta color red, got:", metas)
+	}
+
+	found.SetTitle("Unsaved")
+	again, err := store.PlanFindByID(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if again.GetTitle() != "Before" {
+		t.Fatal("expected changes which are not saved not to be stored, got:", again.GetTitle())
+	}
+
+	found.SetTitle("After")
+	if err := store.PlanUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error updating plan:", err)
+	}
+	again, err = store.PlanFindByID(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if again.GetTitle() != "After" {
+		t.Fatal("expected updated title, got:", again.GetTitle())
+	}
+
+	if err := store.PlanCreate(ctx, conformancePlan("plan_a", "Duplicate")); err == nil {
+		t.Fatal("expected error creating a plan with an existing id")
+	}
+
+	if err := store.PlanDeleteByID(ctx, "plan_a"); err != nil {
+		t.Fatal("unexpected error deleting plan:", err)
+	}
+	exists, err := store.PlanExists(ctx, "plan_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if exists {
+		t.Fatal("expected deleted plan not to exist")
+	}
+	count, err := store.PlanCount(ctx, PlanQuery().SetSoftDeletedIncluded(true))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 0 {
+		t.Fatal("expected no plans, got:", count)
+	}
+}
+
+// == SUBSCRIPTION TESTS =======================================================
+
+func conformanceSubscriptionFilters(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+
+	a := conformanceSubscription("sub_a", "user_1").SetResumeAt("2025-01-10 00:00:00")
+	a.SetMetas(map[string]string{"seat": "team"})
+	b := conformanceSubscription("sub_b", "user_2").SetPlanID("plan_other").SetStatus(SUBSCRIPTION_STATUS_PAST_DUE)
+	c := conformanceSubscription("sub_c", "user_1").SetResumeAt("2025-02-10 00:00:00")
+	d := conformanceSubscription("sub_d", "user_3").SetPeriodEnd("2030-01-01 00:00:00")
+	conformanceCreateSubscriptions(t, store, a, b, c, d)
+
+	if err := store.SubscriptionCancel(ctx, "sub_c", SubscriptionCancelOptions{
+		Reason: CANCELLATION_REASON_TOO_EXPENSIVE,
+	}); err != nil {
+		t.Fatal("unexpected error cancelling subscription:", err)
+	}
+	if err := store.SubscriptionCancel(ctx, "sub_d", SubscriptionCancelOptions{
+		AtPeriodEnd: true,
+		Reason:      CANCELLATION_REASON_UNUSED,
+	}); err != nil {
+		t.Fatal("unexpected error cancelling subscription:", err)
+	}
+
+	yesterday := carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC)
+	tomorrow := carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC)
+
+	cases := []struct {
+		name     string
+		query    SubscriptionQueryInterface
+		expected []string
+	}{
+		{"all", SubscriptionQuery(), []string{"sub_a", "sub_b", "sub_c", "sub_d"}},
+		{"id", SubscriptionQuery().SetID("sub_b"), []string{"sub_b"}},
+		{"id in", SubscriptionQuery().SetIDIn([]string{"sub_a", "sub_d"}), []string{"sub_a", "sub_d"}},
+		{"status", SubscriptionQuery().SetStatus(SUBSCRIPTION_STATUS_CANCELLED), []string{"sub_c"}},
+		{"status in", SubscriptionQuery().SetStatusIn([]string{SUBSCRIPTION_STATUS_ACTIVE, SUBSCRIPTION_STATUS_PAST_DUE}), []string{"sub_a", "sub_b", "sub_d"}},
+		{"subscriber id", SubscriptionQuery().SetSubscriberID("user_1"), []string{"sub_a", "sub_c"}},
+		{"plan id", SubscriptionQuery().SetPlanID("plan_other"), []string{"sub_b"}},
+		{"resume at lte", SubscriptionQuery().SetResumeAtLte("2025-01-31"), []string{"sub_a"}},
+		{"cancellation reason", SubscriptionQuery().SetCancellationReason(CANCELLATION_REASON_UNUSED), []string{"sub_d"}},
+		{"cancellation requested in range", SubscriptionQuery().SetCancellationRequestedAtGte(yesterday).SetCancellationRequestedAtLte(tomorrow), []string{"sub_c", "sub_d"}},
+		{"cancellation requested before", SubscriptionQuery().SetCancellationRequestedAtLte(yesterday), []string{}},
+		{"meta equals", SubscriptionQuery().SetMetaEquals("seat", "team"), []string{"sub_a"}},
+		{"meta has key", SubscriptionQuery().SetMetaHasKey("seat"), []string{"sub_a"}},
+		{"meta has missing key", SubscriptionQuery().SetMetaHasKey("other"), []string{}},
+	}
+
+	for _, c := range cases {
+		ids := conformanceSorted(conformanceSubscriptionIDs(t, store, c.query))
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+
+		count, err := store.SubscriptionCount(ctx, c.query)
+		if err != nil {
+			t.Fatal("unexpected error counting subscriptions:", err)
+		}
+		if count != int64(len(c.expected)) {
+			t.Fatalf("%s: expected count %d, got %d", c.name, len(c.expected), count)
+		}
+	}
+
+	reasons, err := store.SubscriptionCancellationsByReason(ctx, SubscriptionQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := map[string]int64{CANCELLATION_REASON_TOO_EXPENSIVE: 1, CANCELLATION_REASON_UNUSED: 1}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Fatalf("expected %v, got %v", expected, reasons)
+	}
+}
+
+func conformanceSubscriptionOrderAndPagination(t *testing.T, store PlanSubscriptionStore) {
+	subscriptions := []SubscriptionInterface{
+		conformanceSubscription("sub_1", "user_1").SetQuantity(2),
+		conformanceSubscription("sub_2", "user_1").SetQuantity(10),
+		conformanceSubscription("sub_3", "user_1").SetQuantity(1),
+	}
+	for i, tenantID := range []string{"globex", "", "acme"} {
+		if tenantID == "" {
+			continue
+		}
+		if _, err := subscriptions[i].SetMeta("tenant_id", tenantID); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	conformanceCreateSubscriptions(t, store, subscriptions...)
+
+	cases := []struct {
+		name     string
+		query    SubscriptionQueryInterface
+		expected []string
+	}{
+		{"numeric asc", SubscriptionQuery().SetOrderBy(COLUMN_QUANTITY).SetSortOrder("asc"), []string{"sub_3", "sub_1", "sub_2"}},
+		{"numeric desc", SubscriptionQuery().SetOrderBy(COLUMN_QUANTITY), []string{"sub_2", "sub_1", "sub_3"}},
+		{"string asc", SubscriptionQuery().SetOrderBy(COLUMN_ID).SetSortOrder("asc"), []string{"sub_1", "sub_2", "sub_3"}},
+		{"limit and offset", SubscriptionQuery().SetOrderBy(COLUMN_QUANTITY).SetSortOrder("asc").SetLimit(1).SetOffset(1), []string{"sub_1"}},
+		{"promoted meta asc", SubscriptionQuery().SetOrderBy(metaColumnName("tenant_id")).SetSortOrder("asc"), []string{"sub_2", "sub_3", "sub_1"}},
+	}
+
+	for _, c := range cases {
+		ids := conformanceSubscriptionIDs(t, store, c.query)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+	}
+}
+
+func conformanceSubscriptionSoftDelete(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreateSubscriptions(t, store,
+		conformanceSubscription("sub_a", "user_1"),
+		conformanceSubscription("sub_b", "user_1"),
+	)
+
+	if err := store.SubscriptionSoftDeleteByID(ctx, "sub_a"); err != nil {
+		t.Fatal("unexpected error soft deleting subscription:", err)
+	}
+
+	if ids := conformanceSubscriptionIDs(t, store, SubscriptionQuery()); !reflect.DeepEqual(ids, []string{"sub_b"}) {
+		t.Fatal("expected only sub_b, got:", ids)
+	}
+
+	exists, err := store.SubscriptionExists(ctx, "sub_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if exists {
+		t.Fatal("expected soft deleted subscription not to exist")
+	}
+
+	count, err := store.SubscriptionCount(ctx, SubscriptionQuery().SetSoftDeletedIncluded(true))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 2 {
+		t.Fatal("expected 2 subscriptions including soft deleted, got:", count)
+	}
+
+	if err := store.SubscriptionDeleteByID(ctx, "sub_a"); err != nil {
+		t.Fatal("unexpected error deleting subscription:", err)
+	}
+	count, err = store.SubscriptionCount(ctx, SubscriptionQuery().SetSoftDeletedIncluded(true))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 1 {
+		t.Fatal("expected 1 subscription after delete, got:", count)
+	}
+}
+
+func conformanceSubscriptionCountBy(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreateSubscriptions(t, store,
+		conformanceSubscription("sub_a", "user_1"),
+		conformanceSubscription("sub_b", "user_1").SetStatus(SUBSCRIPTION_STATUS_PAUSED),
+		conformanceSubscription("sub_c", "user_2"),
+	)
+
+	counts, err := store.SubscriptionCountBy(ctx, SubscriptionQuery(), COLUMN_SUBSCRIBER_ID)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := map[string]int64{"user_1": 2, "user_2": 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+
+	counts, err = store.SubscriptionCountBy(ctx, SubscriptionQuery().SetSubscriberID("user_1"), COLUMN_STATUS)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected = map[string]int64{SUBSCRIPTION_STATUS_ACTIVE: 1, SUBSCRIPTION_STATUS_PAUSED: 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+
+	if _, err := store.SubscriptionCountBy(ctx, SubscriptionQuery(), COLUMN_MEMO); err == nil {
+		t.Fatal("expected error for an unsupported column")
+	}
+	if _, err := store.SubscriptionCountBy(ctx, SubscriptionQuery().SetOrderBy(COLUMN_ID), COLUMN_STATUS); err == nil {
+		t.Fatal("expected error for an ordered query")
+	}
+}
+
+func conformanceSubscriptionCreateDefaults(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreatePlans(t, store, conformancePlan("plan_eur", "Euro").SetCurrency("eur"))
+
+	subscription := NewSubscription().
+		SetID("sub_a").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPlanID("plan_eur").
+		SetQuantity(0).
+		SetPeriodStart("2025-01-01")
+	conformanceCreateSubscriptions(t, store, subscription)
+
+	found, err := store.SubscriptionFindByID(ctx, "sub_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Fatal("expected subscription to be found")
+	}
+	if found.GetQuantity() != 1 {
+		t.Fatal("expected default quantity 1, got:", found.GetQuantity())
+	}
+	if found.GetPeriodStart() != "2025-01-01 00:00:00" {
+		t.Fatal("expected period start as a date time, got:", found.GetPeriodStart())
+	}
+	if found.GetPeriodEnd() != MAX_DATETIME || found.GetCancellationRequestedAt() != MAX_DATETIME {
+		t.Fatal("expected open ended period and no cancellation")
+	}
+	if found.GetCurrency() != "eur" {
+		t.Fatal("expected the currency of the plan, got:", found.GetCurrency())
+	}
+
+	mismatch := conformanceSubscription("sub_b", "user_1").SetPlanID("plan_eur").SetCurrency("usd")
+	if err := store.SubscriptionCreate(ctx, mismatch); err == nil {
+		t.Fatal("expected error for a currency other than the plan's")
+	}
+
+	if err := store.SubscriptionCreate(ctx, conformanceSubscription("sub_c", "user_1").SetQuantity(-1)); err == nil {
+		t.Fatal("expected error for a negative quantity")
+	}
+}
+
+func conformanceSubscriptionCancelAndUncancel(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreateSubscriptions(t, store,
+		conformanceSubscription("sub_now", "user_1"),
+		conformanceSubscription("sub_end", "user_1").SetPeriodEnd("2030-01-01 00:00:00"),
+	)
+
+	if err := store.SubscriptionCancel(ctx, "sub_now", SubscriptionCancelOptions{Reason: "bogus"}); err == nil {
+		t.Fatal("expected error for an unsupported reason")
+	}
+
+	if err := store.SubscriptionCancel(ctx, "sub_now", SubscriptionCancelOptions{
+		Reason:      CANCELLATION_REASON_OTHER,
+		Feedback:    "moving on",
+		CancelledBy: CANCELLED_BY_SUBSCRIBER,
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	now, err := store.SubscriptionFindByID(ctx, "sub_now")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if now.GetStatus() != SUBSCRIPTION_STATUS_CANCELLED || now.GetCancellationFeedback() != "moving on" {
+		t.Fatal("expected cancelled subscription with feedback, got:", now.GetStatus(), now.GetCancellationFeedback())
+	}
+	if err := store.SubscriptionCancel(ctx, "sub_now", SubscriptionCancelOptions{}); err == nil {
+		t.Fatal("expected error cancelling a cancelled subscription")
+	}
+
+	if err := store.SubscriptionCancel(ctx, "sub_end", SubscriptionCancelOptions{AtPeriodEnd: true}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	end, err := store.SubscriptionFindByID(ctx, "sub_end")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if end.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE || !end.GetCancelAtPeriodEnd() {
+		t.Fatal("expected active subscription pending cancellation")
+	}
+	if end.GetCancellationEffectiveAt() != "2030-01-01 00:00:00" {
+		t.Fatal("expected cancellation at the period end, got:", end.GetCancellationEffectiveAt())
+	}
+
+	if err := store.SubscriptionUncancel(ctx, "sub_end"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	end, err = store.SubscriptionFindByID(ctx, "sub_end")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if end.GetCancelAtPeriodEnd() || end.GetCancellationRequestedAt() != MAX_DATETIME {
+		t.Fatal("expected cancellation to be withdrawn")
+	}
+	if err := store.SubscriptionUncancel(ctx, "sub_end"); err == nil {
+		t.Fatal("expected error without a pending cancellation")
+	}
+}
+
+func conformanceSubscriptionPauseAndResume(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreateSubscriptions(t, store, conformanceSubscription("sub_a", "user_1"))
+
+	resumeAt := carbon.Now(carbon.UTC).AddDays(10).ToDateTimeString(carbon.UTC)
+	if err := store.SubscriptionPause(ctx, "sub_a", resumeAt); err != nil {
+		t.Fatal("unexpected error pausing subscription:", err)
+	}
+
+	resumed, err := store.SubscriptionResumeDue(ctx, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(resumed) != 0 {
+		t.Fatal("expected no subscription due, got:", len(resumed))
+	}
+
+	resumed, err = store.SubscriptionResumeDue(ctx, carbon.Now(carbon.UTC).AddDays(11).ToDateTimeString(carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(resumed) != 1 || resumed[0].GetID() != "sub_a" {
+		t.Fatal("expected sub_a to be resumed")
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, "sub_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE || found.GetResumeAt() != MAX_DATETIME {
+		t.Fatal("expected active subscription, got:", found.GetStatus(), found.GetResumeAt())
+	}
+}
+
+func conformanceSubscriptionFindActiveBySubscriber(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreateSubscriptions(t, store,
+		conformanceSubscription("sub_old", "user_1").SetCreatedAt("2024-01-01 00:00:00"),
+		conformanceSubscription("sub_new", "user_1").SetCreatedAt("2025-01-01 00:00:00").SetStatus(SUBSCRIPTION_STATUS_PAST_DUE),
+		conformanceSubscription("sub_paused", "user_1").SetCreatedAt("2026-01-01 00:00:00").SetStatus(SUBSCRIPTION_STATUS_PAUSED),
+	)
+
+	found, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetID() != "sub_new" {
+		t.Fatal("expected latest active subscription sub_new")
+	}
+
+	found, err = store.SubscriptionFindActiveBySubscriberID(ctx, "user_2")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found != nil {
+		t.Fatal("expected no active subscription")
+	}
+}
+
+func conformancePlanVersions(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	plan := conformancePlan("plan_a", "A")
+	conformanceCreatePlans(t, store, plan)
+
+	// Updates of the descriptive fields keep the version
+	plan.SetTitle("A renamed")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	plan.SetPrice("2.00")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	versions, err := store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID("plan_a").SetOrderBy(COLUMN_VERSION).SetSortOrder("asc"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 2 {
+		t.Fatal("expected 2 versions, got:", len(versions))
+	}
+	if versions[0].GetVersion() != 1 || versions[0].GetPrice() != "1.00" {
+		t.Fatal("expected version 1 at 1.00, got:", versions[0].GetVersion(), versions[0].GetPrice())
+	}
+	if versions[1].GetVersion() != 2 || versions[1].GetPrice() != "2.00" {
+		t.Fatal("expected version 2 at 2.00, got:", versions[1].GetVersion(), versions[1].GetPrice())
+	}
+
+	found, err := store.PlanVersionFindByID(ctx, versions[1].GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetPlanID() != "plan_a" || found.GetCurrency() != "usd" {
+		t.Fatal("expected to find version 2 of plan_a")
+	}
+}
+
+func conformanceSubscriptionPlanVersion(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	plan := conformancePlan("plan_a", "A")
+	conformanceCreatePlans(t, store, plan)
+
+	before := conformanceSubscription("sub_before", "user_1").SetPlanID("plan_a")
+	conformanceCreateSubscriptions(t, store, before)
+	if before.GetPlanVersionID() == "" {
+		t.Fatal("expected the subscription to be pinned to a plan version")
+	}
+
+	plan.SetPrice("2.00")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	after := conformanceSubscription("sub_after", "user_2").SetPlanID("plan_a")
+	conformanceCreateSubscriptions(t, store, after)
+
+	for id, price := range map[string]string{"sub_before": "1.00", "sub_after": "2.00"} {
+		subscriptionPlan, err := store.SubscriptionPlan(ctx, id)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if subscriptionPlan.GetPrice() != price || subscriptionPlan.GetTitle() != "A" {
+			t.Fatalf("expected %s on plan A at %s, got %s at %s", id, price, subscriptionPlan.GetTitle(), subscriptionPlan.GetPrice())
+		}
+	}
+
+	// Updates keep the pinned version of the plan, and pin another plan to
+	// its latest version
+	conformanceCreatePlans(t, store, conformancePlan("plan_b", "B"))
+	if err := store.SubscriptionUpdate(ctx, before.SetMemo("kept")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	subscriptionPlan, err := store.SubscriptionPlan(ctx, "sub_before")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscriptionPlan.GetPrice() != "1.00" {
+		t.Fatal("expected the pinned price 1.00, got:", subscriptionPlan.GetPrice())
+	}
+
+	if err := store.SubscriptionUpdate(ctx, before.SetPlanID("plan_b")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	versions, err := store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID("plan_b"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 1 || before.GetPlanVersionID() != versions[0].GetID() {
+		t.Fatal("expected the subscription to be pinned to the version of plan_b")
+	}
+}
+
+func conformanceSubscriptionUpdatePlanCurrency(t *testing.T, store PlanSubscriptionStore) {
+	ctx := context.Background()
+	conformanceCreatePlans(t, store,
+		conformancePlan("plan_usd", "Dollar"),
+		conformancePlan("plan_eur", "Euro").SetCurrency("eur"),
+	)
+
+	subscription := conformanceSubscription("sub_a", "user_1").SetPlanID("plan_usd")
+	conformanceCreateSubscriptions(t, store, subscription)
+
+	if err := store.SubscriptionUpdate(ctx, subscription.SetPlanID("plan_eur")); err == nil {
+		t.Fatal("expected error for a plan priced in another currency")
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, "sub_a")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetPlanID() != "plan_usd" {
+		t.Fatal("expected the subscription to keep plan_usd")
+	}
+}
@@ -0,0 +1,380 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// == COUPON METHODS ===========================================================
+
+// CouponCreate creates a new coupon
+func (st *storeImplementation) CouponCreate(ctx context.Context, coupon CouponInterface) error {
+	if coupon == nil {
+		return errors.New("subscriptionstore > coupon create. coupon cannot be nil")
+	}
+	if err := couponValidate(coupon); err != nil {
+		return errors.New("subscriptionstore > coupon create. " + err.Error())
+	}
+
+	if coupon.GetCreatedAt() == "" {
+		coupon.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if coupon.GetUpdatedAt() == "" {
+		coupon.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := st.couponRow(coupon)
+	row[COLUMN_ID] = coupon.GetID()
+	row[COLUMN_TIMES_REDEEMED] = coupon.GetTimesRedeemed()
+	row[COLUMN_CREATED_AT] = dateTimeValue(coupon.GetCreatedAtCarbon())
+
+	return st.query().Table(st.couponTableName).Create(row)
+}
+
+// CouponFindByID finds a coupon by id
+func (st *storeImplementation) CouponFindByID(ctx context.Context, id string) (CouponInterface, error) {
+	if id == "" {
+		return nil, errors.New("coupon id is empty")
+	}
+	list, err := st.CouponList(ctx, CouponQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// CouponList retrieves a list of coupons
+func (st *storeImplementation) CouponList(ctx context.Context, query CouponQueryInterface) ([]CouponInterface, error) {
+	if query == nil {
+		return []CouponInterface{}, errors.New("at coupon list > coupon query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []CouponInterface{}, err
+	}
+
+	q := st.buildCouponQuery(query)
+
+	type couponRow struct {
+		ID              string    `db:"id"`
+		Name            string    `db:"name"`
+		Type            string    `db:"type"`
+		PercentOff      string    `db:"percent_off"`
+		AmountOff       string    `db:"amount_off"`
+		Currency        string    `db:"currency"`
+		Duration        string    `db:"duration"`
+		DurationPeriods int       `db:"duration_periods"`
+		MaxRedemptions  int       `db:"max_redemptions"`
+		TimesRedeemed   int       `db:"times_redeemed"`
+		RedeemBy        time.Time `db:"redeem_by"`
+		Memo            string    `db:"memo"`
+		CreatedAt       time.Time `db:"created_at"`
+		UpdatedAt       time.Time `db:"updated_at"`
+	}
+
+	var rows []couponRow
+	if err := q.Table(st.couponTableName).Get(&rows); err != nil {
+		return []CouponInterface{}, err
+	}
+
+	list := make([]CouponInterface, 0, len(rows))
+	for _, r := range rows {
+		c := &couponImplementation{}
+		c.SetID(r.ID)
+		c.SetName(r.Name)
+		c.SetType(r.Type)
+		c.SetPercentOff(r.PercentOff)
+		c.SetAmountOff(r.AmountOff)
+		c.SetCurrency(r.Currency)
+		c.SetDuration(r.Duration)
+		c.SetDurationPeriods(r.DurationPeriods)
+		c.SetMaxRedemptions(r.MaxRedemptions)
+		c.SetTimesRedeemed(r.TimesRedeemed)
+		c.SetRedeemBy(carbon.CreateFromStdTime(r.RedeemBy, carbon.UTC).ToDateTimeString(carbon.UTC))
+		c.SetMemo(r.Memo)
+		c.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		c.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, c)
+	}
+
+	return list, nil
+}
+
+// CouponUpdate updates a coupon
+func (st *storeImplementation) CouponUpdate(ctx context.Context, coupon CouponInterface) error {
+	if coupon == nil {
+		return errors.New("subscriptionstore > coupon update. coupon cannot be nil")
+	}
+	if err := couponValidate(coupon); err != nil {
+		return errors.New("subscriptionstore > coupon update. " + err.Error())
+	}
+
+	coupon.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	_, err := st.query().Table(st.couponTableName).Where(COLUMN_ID+" = ?", coupon.GetID()).Update(st.couponRow(coupon))
+	return err
+}
+
+// couponRow returns the columns of the coupon, which are written on both
+// create and update. The times redeemed are only written on create, and
+// then only change with redemptionCount, so saving a coupon read before a
+// redemption keeps the redemption.
+func (st *storeImplementation) couponRow(coupon CouponInterface) map[string]any {
+	return map[string]any{
+		COLUMN_NAME:             coupon.GetName(),
+		COLUMN_TYPE:             coupon.GetType(),
+		COLUMN_PERCENT_OFF:      coupon.GetPercentOff(),
+		COLUMN_AMOUNT_OFF:       coupon.GetAmountOff(),
+		COLUMN_CURRENCY:         coupon.GetCurrency(),
+		COLUMN_DURATION:         coupon.GetDuration(),
+		COLUMN_DURATION_PERIODS: coupon.GetDurationPeriods(),
+		COLUMN_MAX_REDEMPTIONS:  coupon.GetMaxRedemptions(),
+		COLUMN_REDEEM_BY:        dateTimeValue(coupon.GetRedeemByCarbon()),
+		COLUMN_MEMO:             coupon.GetMemo(),
+		COLUMN_UPDATED_AT:       dateTimeValue(coupon.GetUpdatedAtCarbon()),
+	}
+}
+
+// couponRedeemable checks that the coupon can be redeemed once more at now
+func couponRedeemable(coupon CouponInterface, now *carbon.Carbon) error {
+	if !now.Lt(coupon.GetRedeemByCarbon()) {
+		return errors.New("coupon expired")
+	}
+	if coupon.GetMaxRedemptions() > 0 && coupon.GetTimesRedeemed() >= coupon.GetMaxRedemptions() {
+		return errors.New("coupon fully redeemed")
+	}
+	return nil
+}
+
+// redemptionCount counts one more redemption of the coupon or promotion
+// code with the id in the table. The count is raised with one conditional
+// update, so concurrent redemptions cannot go over max_redemptions, a
+// max_redemptions of 0 meaning no limit. It returns false if the row is
+// fully redeemed.
+func (st *storeImplementation) redemptionCount(table string, id string) (bool, error) {
+	result, err := st.query().Table(table).
+		Where(COLUMN_ID+" = ?", id).
+		Where("("+COLUMN_MAX_REDEMPTIONS+" = ? OR "+COLUMN_TIMES_REDEEMED+" < "+COLUMN_MAX_REDEMPTIONS+")", 0).
+		Increment(COLUMN_TIMES_REDEEMED)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// buildCouponQuery builds a neat query from the coupon query interface.
+func (st *storeImplementation) buildCouponQuery(query CouponQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
+
+// == PROMOTION CODE METHODS ===================================================
+
+// PromotionCodeCreate creates a new promotion code. Codes are case
+// insensitive, and are stored in upper case.
+func (st *storeImplementation) PromotionCodeCreate(ctx context.Context, promotionCode PromotionCodeInterface) error {
+	if promotionCode == nil {
+		return errors.New("subscriptionstore > promotion code create. promotion code cannot be nil")
+	}
+	if promotionCode.GetCode() == "" {
+		return errors.New("subscriptionstore > promotion code create. code cannot be empty")
+	}
+	if promotionCode.GetCouponID() == "" {
+		return errors.New("subscriptionstore > promotion code create. coupon id cannot be empty")
+	}
+
+	promotionCode.SetCode(strings.ToUpper(promotionCode.GetCode()))
+
+	// The unique index is checked up front too, to return a clear error
+	// rather than the error of the driver
+	existing, err := st.PromotionCodeFindByCode(ctx, promotionCode.GetCode())
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("subscriptionstore > promotion code create. code already exists")
+	}
+
+	coupon, err := st.CouponFindByID(ctx, promotionCode.GetCouponID())
+	if err != nil {
+		return err
+	}
+	if coupon == nil {
+		return errors.New("subscriptionstore > promotion code create. coupon not found")
+	}
+
+	if promotionCode.GetCreatedAt() == "" {
+		promotionCode.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if promotionCode.GetUpdatedAt() == "" {
+		promotionCode.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := st.promotionCodeRow(promotionCode)
+	row[COLUMN_ID] = promotionCode.GetID()
+	row[COLUMN_CODE] = promotionCode.GetCode()
+	row[COLUMN_TIMES_REDEEMED] = promotionCode.GetTimesRedeemed()
+	row[COLUMN_CREATED_AT] = dateTimeValue(promotionCode.GetCreatedAtCarbon())
+
+	return st.query().Table(st.promotionCodeTableName).Create(row)
+}
+
+// PromotionCodeFindByCode finds a promotion code by its code, ignoring case
+func (st *storeImplementation) PromotionCodeFindByCode(ctx context.Context, code string) (PromotionCodeInterface, error) {
+	if code == "" {
+		return nil, errors.New("promotion code is empty")
+	}
+	list, err := st.PromotionCodeList(ctx, PromotionCodeQuery().SetCode(strings.ToUpper(code)).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// PromotionCodeList retrieves a list of promotion codes
+func (st *storeImplementation) PromotionCodeList(ctx context.Context, query PromotionCodeQueryInterface) ([]PromotionCodeInterface, error) {
+	if query == nil {
+		return []PromotionCodeInterface{}, errors.New("at promotion code list > promotion code query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PromotionCodeInterface{}, err
+	}
+
+	q := st.buildPromotionCodeQuery(query)
+
+	type promotionCodeRow struct {
+		ID             string    `db:"id"`
+		CouponID       string    `db:"coupon_id"`
+		Code           string    `db:"code"`
+		Status         string    `db:"status"`
+		MaxRedemptions int       `db:"max_redemptions"`
+		TimesRedeemed  int       `db:"times_redeemed"`
+		ExpiresAt      time.Time `db:"expires_at"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}
+
+	var rows []promotionCodeRow
+	if err := q.Table(st.promotionCodeTableName).Get(&rows); err != nil {
+		return []PromotionCodeInterface{}, err
+	}
+
+	list := make([]PromotionCodeInterface, 0, len(rows))
+	for _, r := range rows {
+		p := &promotionCodeImplementation{}
+		p.SetID(r.ID)
+		p.SetCouponID(r.CouponID)
+		p.SetCode(r.Code)
+		p.SetStatus(r.Status)
+		p.SetMaxRedemptions(r.MaxRedemptions)
+		p.SetTimesRedeemed(r.TimesRedeemed)
+		p.SetExpiresAt(carbon.CreateFromStdTime(r.ExpiresAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		p.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		p.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, p)
+	}
+
+	return list, nil
+}
+
+// PromotionCodeUpdate updates a promotion code. The code itself is not updated.
+func (st *storeImplementation) PromotionCodeUpdate(ctx context.Context, promotionCode PromotionCodeInterface) error {
+	if promotionCode == nil {
+		return errors.New("subscriptionstore > promotion code update. promotion code cannot be nil")
+	}
+
+	promotionCode.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	_, err := st.query().Table(st.promotionCodeTableName).Where(COLUMN_ID+" = ?", promotionCode.GetID()).Update(st.promotionCodeRow(promotionCode))
+	return err
+}
+
+// promotionCodeRow returns the columns of the promotion code, which are
+// written on both create and update. As for coupons, the times redeemed
+// are only written on create.
+func (st *storeImplementation) promotionCodeRow(promotionCode PromotionCodeInterface) map[string]any {
+	return map[string]any{
+		COLUMN_COUPON_ID:       promotionCode.GetCouponID(),
+		COLUMN_STATUS:          promotionCode.GetStatus(),
+		COLUMN_MAX_REDEMPTIONS: promotionCode.GetMaxRedemptions(),
+		COLUMN_EXPIRES_AT:      dateTimeValue(promotionCode.GetExpiresAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(promotionCode.GetUpdatedAtCarbon()),
+	}
+}
+
+// promotionCodeRedeemable checks that the promotion code can be redeemed
+// once more at now
+func promotionCodeRedeemable(promotionCode PromotionCodeInterface, now *carbon.Carbon) error {
+	if promotionCode.GetStatus() != PROMOTION_CODE_STATUS_ACTIVE {
+		return errors.New("promotion code is not active")
+	}
+	if !now.Lt(promotionCode.GetExpiresAtCarbon()) {
+		return errors.New("promotion code expired")
+	}
+	if promotionCode.GetMaxRedemptions() > 0 && promotionCode.GetTimesRedeemed() >= promotionCode.GetMaxRedemptions() {
+		return errors.New("promotion code fully redeemed")
+	}
+	return nil
+}
+
+// buildPromotionCodeQuery builds a neat query from the promotion code query interface.
+func (st *storeImplementation) buildPromotionCodeQuery(query PromotionCodeQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasCouponID() && query.CouponID() != "" {
+		q = q.Where(COLUMN_COUPON_ID+" = ?", query.CouponID())
+	}
+	if query.HasCode() && query.Code() != "" {
+		q = q.Where(COLUMN_CODE+" = ?", query.Code())
+	}
+	if query.HasStatus() && query.Status() != "" {
+		q = q.Where(COLUMN_STATUS+" = ?", query.Status())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,111 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreCouponCreateAndUpdate(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	coupon := NewCoupon().
+		SetName("20% off for 3 months").
+		SetPercentOff("20").
+		SetDuration(COUPON_DURATION_REPEATING).
+		SetDurationPeriods(3).
+		SetMaxRedemptions(50).
+		SetRedeemBy("2030-01-01 00:00:00")
+	if err := store.CouponCreate(ctx, coupon); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.CouponFindByID(ctx, coupon.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Fatal("expected coupon to be found")
+	}
+	if found.GetPercentOff() != "20" || found.GetDurationPeriods() != 3 || found.GetMaxRedemptions() != 50 {
+		t.Errorf("unexpected coupon: %s%% for %d periods, %d redemptions", found.GetPercentOff(), found.GetDurationPeriods(), found.GetMaxRedemptions())
+	}
+	if found.GetRedeemBy() != "2030-01-01 00:00:00" {
+		t.Errorf("expected redeem by 2030-01-01 00:00:00, got %s", found.GetRedeemBy())
+	}
+
+	// Updates keep the redemptions counted since the coupon was read
+	if _, err := store.(*storeImplementation).redemptionCount(store.CouponTableName(), coupon.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found.SetName("25% off for 3 months").SetTimesRedeemed(0)
+	if err := store.CouponUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.CouponList(ctx, CouponQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 1 || list[0].GetTimesRedeemed() != 1 || list[0].GetName() != "25% off for 3 months" {
+		t.Fatalf("expected 1 renamed coupon redeemed once, got %d", len(list))
+	}
+
+	if err := store.CouponCreate(ctx, NewCoupon()); err == nil {
+		t.Error("expected error for a coupon without a discount")
+	}
+}
+
+func TestStorePromotionCodeCreate(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	coupon := NewCoupon().SetPercentOff("10")
+	if err := store.CouponCreate(ctx, coupon); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	promotionCode := NewPromotionCode().SetCouponID(coupon.GetID()).SetCode("spring10")
+	if err := store.PromotionCodeCreate(ctx, promotionCode); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if promotionCode.GetCode() != "SPRING10" {
+		t.Errorf("expected the code to be stored in upper case, got %s", promotionCode.GetCode())
+	}
+
+	found, err := store.PromotionCodeFindByCode(ctx, "Spring10")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetCouponID() != coupon.GetID() {
+		t.Fatal("expected the code to be found ignoring case")
+	}
+
+	found.SetStatus(PROMOTION_CODE_STATUS_INACTIVE)
+	if err := store.PromotionCodeUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	list, err := store.PromotionCodeList(ctx, PromotionCodeQuery().SetCouponID(coupon.GetID()).SetStatus(PROMOTION_CODE_STATUS_INACTIVE))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected 1 inactive code, got %d", len(list))
+	}
+
+	duplicate := NewPromotionCode().SetCouponID(coupon.GetID()).SetCode("SPRING10")
+	if err := store.PromotionCodeCreate(ctx, duplicate); err == nil {
+		t.Error("expected error for a duplicate code")
+	}
+
+	orphan := NewPromotionCode().SetCouponID("missing").SetCode("ORPHAN")
+	if err := store.PromotionCodeCreate(ctx, orphan); err == nil {
+		t.Error("expected error for a code of a missing coupon")
+	}
+}
@@ -0,0 +1,334 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// DunningAttemptCreate creates a new dunning attempt
+func (st *storeImplementation) DunningAttemptCreate(ctx context.Context, attempt DunningAttemptInterface) error {
+	if attempt == nil {
+		return errors.New("subscriptionstore > dunning attempt create. attempt cannot be nil")
+	}
+	if attempt.GetSubscriptionID() == "" {
+		return errors.New("subscriptionstore > dunning attempt create. subscription id cannot be empty")
+	}
+
+	if attempt.GetCreatedAt() == "" {
+		attempt.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if attempt.GetUpdatedAt() == "" {
+		attempt.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := map[string]any{
+		COLUMN_ID:              attempt.GetID(),
+		COLUMN_SUBSCRIPTION_ID: attempt.GetSubscriptionID(),
+		COLUMN_PERIOD_START:    dateTimeValue(attempt.GetPeriodStartCarbon()),
+		COLUMN_PERIOD_END:      dateTimeValue(attempt.GetPeriodEndCarbon()),
+		COLUMN_ATTEMPT:         attempt.GetAttempt(),
+		COLUMN_STATUS:          attempt.GetStatus(),
+		COLUMN_SCHEDULED_AT:    dateTimeValue(attempt.GetScheduledAtCarbon()),
+		COLUMN_ATTEMPTED_AT:    dateTimeValue(attempt.GetAttemptedAtCarbon()),
+		COLUMN_FAILURE_REASON:  attempt.GetFailureReason(),
+		COLUMN_CREATED_AT:      dateTimeValue(attempt.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(attempt.GetUpdatedAtCarbon()),
+	}
+
+	return st.query().Table(st.dunningAttemptTableName).Create(row)
+}
+
+// DunningAttemptList retrieves a list of dunning attempts
+func (st *storeImplementation) DunningAttemptList(ctx context.Context, query DunningAttemptQueryInterface) ([]DunningAttemptInterface, error) {
+	if query == nil {
+		return []DunningAttemptInterface{}, errors.New("at dunning attempt list > dunning attempt query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []DunningAttemptInterface{}, err
+	}
+
+	q := st.buildDunningAttemptQuery(query)
+
+	type dunningAttemptRow struct {
+		ID             string    `db:"id"`
+		SubscriptionID string    `db:"subscription_id"`
+		PeriodStart    time.Time `db:"period_start"`
+		PeriodEnd      time.Time `db:"period_end"`
+		Attempt        int       `db:"attempt"`
+		Status         string    `db:"status"`
+		ScheduledAt    time.Time `db:"scheduled_at"`
+		AttemptedAt    time.Time `db:"attempted_at"`
+		FailureReason  string    `db:"failure_reason"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}
+
+	var rows []dunningAttemptRow
+	if err := q.Table(st.dunningAttemptTableName).Get(&rows); err != nil {
+		return []DunningAttemptInterface{}, err
+	}
+
+	list := make([]DunningAttemptInterface, 0, len(rows))
+	for _, r := range rows {
+		a := &dunningAttemptImplementation{}
+		a.SetID(r.ID)
+		a.SetSubscriptionID(r.SubscriptionID)
+		a.SetPeriodStart(carbon.CreateFromStdTime(r.PeriodStart, carbon.UTC).ToDateTimeString(carbon.UTC))
+		a.SetPeriodEnd(carbon.CreateFromStdTime(r.PeriodEnd, carbon.UTC).ToDateTimeString(carbon.UTC))
+		a.SetAttempt(r.Attempt)
+		a.SetStatus(r.Status)
+		a.SetScheduledAt(carbon.CreateFromStdTime(r.ScheduledAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		a.SetAttemptedAt(carbon.CreateFromStdTime(r.AttemptedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		a.SetFailureReason(r.FailureReason)
+		a.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		a.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, a)
+	}
+
+	return list, nil
+}
+
+// DunningAttemptUpdate updates a dunning attempt
+func (st *storeImplementation) DunningAttemptUpdate(ctx context.Context, attempt DunningAttemptInterface) error {
+	if attempt == nil {
+		return errors.New("subscriptionstore > dunning attempt update. attempt cannot be nil")
+	}
+
+	attempt.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	row := map[string]any{
+		COLUMN_SUBSCRIPTION_ID: attempt.GetSubscriptionID(),
+		COLUMN_PERIOD_START:    dateTimeValue(attempt.GetPeriodStartCarbon()),
+		COLUMN_PERIOD_END:      dateTimeValue(attempt.GetPeriodEndCarbon()),
+		COLUMN_ATTEMPT:         attempt.GetAttempt(),
+		COLUMN_STATUS:          attempt.GetStatus(),
+		COLUMN_SCHEDULED_AT:    dateTimeValue(attempt.GetScheduledAtCarbon()),
+		COLUMN_ATTEMPTED_AT:    dateTimeValue(attempt.GetAttemptedAtCarbon()),
+		COLUMN_FAILURE_REASON:  attempt.GetFailureReason(),
+		COLUMN_UPDATED_AT:      dateTimeValue(attempt.GetUpdatedAtCarbon()),
+	}
+
+	_, err := st.query().Table(st.dunningAttemptTableName).Where(COLUMN_ID+" = ?", attempt.GetID()).Update(row)
+	return err
+}
+
+// DunningDue returns the scheduled dunning attempts which are due at or
+// before now, oldest first. It is meant to be polled by the payment worker,
+// which charges each subscription and reports the outcome with
+// DunningRecordSuccess or DunningRecordFailure.
+func (st *storeImplementation) DunningDue(ctx context.Context, now string) ([]DunningAttemptInterface, error) {
+	nowCarbon := carbon.Parse(now, carbon.UTC)
+	if nowCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > dunning due. now is not a valid date")
+	}
+
+	return st.DunningAttemptList(ctx, DunningAttemptQuery().
+		SetStatus(DUNNING_ATTEMPT_STATUS_SCHEDULED).
+		SetScheduledAtLte(nowCarbon.ToDateTimeString(carbon.UTC)).
+		SetOrderBy(COLUMN_SCHEDULED_AT).
+		SetSortOrder("asc"))
+}
+
+// DunningRecordFailure records a failed charge of the subscription's current
+// billing period, and marks the subscription as past due.
+//
+// The next retry is scheduled according to the dunning retry days, counted
+// from the first failed charge, and returned. Once the retries are exhausted
+// the subscription is cancelled, and nil is returned.
+//
+// The attempts and the subscription are written in one transaction, with
+// the subscription locked, so concurrent reports for the subscription are
+// recorded one after the other.
+func (st *storeImplementation) DunningRecordFailure(ctx context.Context, subscriptionID string, reason string) (DunningAttemptInterface, error) {
+	var retry DunningAttemptInterface
+	err := st.transaction(func(txStore *storeImplementation) error {
+		var err error
+		retry, err = txStore.dunningRecordFailure(ctx, subscriptionID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retry, nil
+}
+
+// dunningRecordFailure records the failed charge, within the transaction of
+// DunningRecordFailure
+func (st *storeImplementation) dunningRecordFailure(ctx context.Context, subscriptionID string, reason string) (DunningAttemptInterface, error) {
+	if err := st.subscriptionLock(subscriptionID); err != nil {
+		return nil, err
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > dunning record failure. subscription not found")
+	}
+	if subscription.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE && subscription.GetStatus() != SUBSCRIPTION_STATUS_PAST_DUE {
+		return nil, errors.New("subscriptionstore > dunning record failure. only active or past due subscriptions can be dunned")
+	}
+
+	attempts, err := st.dunningAttemptsForPeriod(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	now := carbon.Now(carbon.UTC)
+
+	failed := st.dunningAttemptScheduled(attempts)
+	if failed == nil {
+		failed = NewDunningAttempt().
+			SetSubscriptionID(subscription.GetID()).
+			SetPeriodStart(subscription.GetPeriodStart()).
+			SetPeriodEnd(subscription.GetPeriodEnd()).
+			SetAttempt(len(attempts) + 1).
+			SetScheduledAt(now.ToDateTimeString(carbon.UTC))
+		if err := st.DunningAttemptCreate(ctx, failed); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, failed)
+	}
+
+	failed.SetStatus(DUNNING_ATTEMPT_STATUS_FAILED)
+	failed.SetAttemptedAt(now.ToDateTimeString(carbon.UTC))
+	failed.SetFailureReason(reason)
+	if err := st.DunningAttemptUpdate(ctx, failed); err != nil {
+		return nil, err
+	}
+
+	failures := 0
+	for _, attempt := range attempts {
+		if attempt.GetStatus() == DUNNING_ATTEMPT_STATUS_FAILED {
+			failures++
+		}
+	}
+
+	if failures > len(st.dunningRetryDays) {
+		return nil, st.subscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{CancelledBy: CANCELLED_BY_SYSTEM}, now)
+	}
+
+	firstFailedAt := attempts[0].GetAttemptedAtCarbon()
+	retry := NewDunningAttempt().
+		SetSubscriptionID(subscription.GetID()).
+		SetPeriodStart(subscription.GetPeriodStart()).
+		SetPeriodEnd(subscription.GetPeriodEnd()).
+		SetAttempt(failed.GetAttempt() + 1).
+		SetScheduledAt(firstFailedAt.AddDays(st.dunningRetryDays[failures-1]).ToDateTimeString(carbon.UTC))
+	if err := st.DunningAttemptCreate(ctx, retry); err != nil {
+		return nil, err
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_PAST_DUE)
+	if err := st.SubscriptionUpdate(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return retry, nil
+}
+
+// DunningRecordSuccess records a successful charge of a past due subscription,
+// and makes the subscription active again. As with DunningRecordFailure,
+// the attempt and the subscription are written in one transaction.
+func (st *storeImplementation) DunningRecordSuccess(ctx context.Context, subscriptionID string) error {
+	return st.transaction(func(txStore *storeImplementation) error {
+		return txStore.dunningRecordSuccess(ctx, subscriptionID)
+	})
+}
+
+// dunningRecordSuccess records the successful charge, within the
+// transaction of DunningRecordSuccess
+func (st *storeImplementation) dunningRecordSuccess(ctx context.Context, subscriptionID string) error {
+	if err := st.subscriptionLock(subscriptionID); err != nil {
+		return err
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > dunning record success. subscription not found")
+	}
+	if subscription.GetStatus() != SUBSCRIPTION_STATUS_PAST_DUE {
+		return errors.New("subscriptionstore > dunning record success. subscription is not past due")
+	}
+
+	attempts, err := st.dunningAttemptsForPeriod(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	if scheduled := st.dunningAttemptScheduled(attempts); scheduled != nil {
+		scheduled.SetStatus(DUNNING_ATTEMPT_STATUS_SUCCEEDED)
+		scheduled.SetAttemptedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		if err := st.DunningAttemptUpdate(ctx, scheduled); err != nil {
+			return err
+		}
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	return st.SubscriptionUpdate(ctx, subscription)
+}
+
+// dunningAttemptsForPeriod returns the dunning attempts of the subscription's
+// current billing period, in attempt order
+func (st *storeImplementation) dunningAttemptsForPeriod(ctx context.Context, subscription SubscriptionInterface) ([]DunningAttemptInterface, error) {
+	return st.DunningAttemptList(ctx, DunningAttemptQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetPeriodEnd(subscription.GetPeriodEnd()).
+		SetOrderBy(COLUMN_ATTEMPT).
+		SetSortOrder("asc"))
+}
+
+// dunningAttemptScheduled returns the attempt still waiting to be charged, if any
+func (st *storeImplementation) dunningAttemptScheduled(attempts []DunningAttemptInterface) DunningAttemptInterface {
+	for _, attempt := range attempts {
+		if attempt.GetStatus() == DUNNING_ATTEMPT_STATUS_SCHEDULED {
+			return attempt
+		}
+	}
+	return nil
+}
+
+// buildDunningAttemptQuery builds a neat query from the dunning attempt query interface.
+func (st *storeImplementation) buildDunningAttemptQuery(query DunningAttemptQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriptionID() && query.SubscriptionID() != "" {
+		q = q.Where(COLUMN_SUBSCRIPTION_ID+" = ?", query.SubscriptionID())
+	}
+	if query.HasStatus() && query.Status() != "" {
+		q = q.Where(COLUMN_STATUS+" = ?", query.Status())
+	}
+	if query.HasPeriodEnd() && query.PeriodEnd() != "" {
+		q = q.Where(COLUMN_PERIOD_END+" = ?", dateTimeValue(carbon.Parse(query.PeriodEnd(), carbon.UTC)))
+	}
+	if query.HasScheduledAtLte() && query.ScheduledAtLte() != "" {
+		q = q.Where(COLUMN_SCHEDULED_AT+" <= ?", dateTimeValue(carbon.Parse(query.ScheduledAtLte(), carbon.UTC)))
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,260 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreDunningRetryScheduleThenCancel(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart(carbon.Now(carbon.UTC).SubMonth().ToDateTimeString(carbon.UTC)).
+		SetPeriodEnd(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	retry, err := store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if retry == nil {
+		t.Fatal("expected a retry to be scheduled")
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_PAST_DUE {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_PAST_DUE, found.GetStatus())
+	}
+
+	attempts, err := store.DunningAttemptList(ctx, DunningAttemptQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetOrderBy(COLUMN_ATTEMPT).
+		SetSortOrder("asc"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+	if attempts[0].GetStatus() != DUNNING_ATTEMPT_STATUS_FAILED || attempts[0].GetFailureReason() != "card_declined" {
+		t.Errorf("expected first attempt failed with card_declined, got %s %s", attempts[0].GetStatus(), attempts[0].GetFailureReason())
+	}
+	if attempts[1].GetStatus() != DUNNING_ATTEMPT_STATUS_SCHEDULED || attempts[1].GetAttempt() != 2 {
+		t.Errorf("expected second attempt scheduled, got %s #%d", attempts[1].GetStatus(), attempts[1].GetAttempt())
+	}
+
+	firstFailedAt := attempts[0].GetAttemptedAtCarbon()
+	for i, days := range []int{1, 3, 7} {
+		expected := firstFailedAt.AddDays(days).ToDateTimeString(carbon.UTC)
+		if retry.GetScheduledAt() != expected {
+			t.Fatalf("retry %d: expected scheduled at %s, got %s", i+1, expected, retry.GetScheduledAt())
+		}
+
+		retry, err = store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined")
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if retry != nil {
+		t.Fatal("expected no retry once the schedule is exhausted")
+	}
+
+	found, err = store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_CANCELLED {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_CANCELLED, found.GetStatus())
+	}
+	if found.GetCancelledBy() != CANCELLED_BY_SYSTEM || found.GetCancellationRequestedAt() == MAX_DATETIME {
+		t.Errorf("expected a system cancellation, got %s requested at %s", found.GetCancelledBy(), found.GetCancellationRequestedAt())
+	}
+
+	if _, err := store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined"); err == nil {
+		t.Error("expected error dunning a cancelled subscription")
+	}
+}
+
+func TestStoreDunningRecordFailureRollsBack(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetPaymentMethodID("pm_other").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart(carbon.Now(carbon.UTC).SubMonth().ToDateTimeString(carbon.UTC)).
+		SetPeriodEnd(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The payment method of the subscription now belongs to another
+	// subscriber, so the subscription can no longer be updated
+	if err := store.PaymentMethodCreate(ctx, NewPaymentMethod().SetID("pm_other").SetSubscriberID("user_2")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined"); err == nil {
+		t.Fatal("expected the error of the subscription update")
+	}
+
+	attempts, err := store.DunningAttemptList(ctx, DunningAttemptQuery().SetSubscriptionID(subscription.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("expected the attempts to be rolled back, got %d", len(attempts))
+	}
+}
+
+func TestStoreDunningRecordSuccess(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.DunningRecordSuccess(ctx, subscription.GetID()); err == nil {
+		t.Error("expected error for a subscription which is not past due")
+	}
+
+	retry, err := store.DunningRecordFailure(ctx, subscription.GetID(), "insufficient_funds")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.DunningRecordSuccess(ctx, subscription.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_ACTIVE, found.GetStatus())
+	}
+
+	attempts, err := store.DunningAttemptList(ctx, DunningAttemptQuery().SetID(retry.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(attempts) != 1 || attempts[0].GetStatus() != DUNNING_ATTEMPT_STATUS_SUCCEEDED {
+		t.Fatalf("expected the scheduled retry to have succeeded, got %v", attempts)
+	}
+}
+
+func TestStoreDunningAttemptListByPeriodEndAfterUpdate(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart("2030-01-01 00:00:00").
+		SetPeriodEnd("2030-02-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	retry, err := store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// An updated row must keep matching the date filters of the created rows
+	retry.SetFailureReason("expired_card")
+	if err := store.DunningAttemptUpdate(ctx, retry); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	attempts, err := store.DunningAttemptList(ctx, DunningAttemptQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetPeriodEnd("2030-02-01 00:00:00"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected the failed attempt and the retry of the period, got %d", len(attempts))
+	}
+}
+
+func TestStoreDunningDue(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	retry, err := store.DunningRecordFailure(ctx, subscription.GetID(), "card_declined")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	due, err := store.DunningDue(ctx, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no due attempts before the retry date, got %d", len(due))
+	}
+
+	due, err = store.DunningDue(ctx, retry.GetScheduledAt())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 1 || due[0].GetID() != retry.GetID() {
+		t.Fatalf("expected the retry to be due, got %v", due)
+	}
+	if due[0].GetSubscriptionID() != subscription.GetID() {
+		t.Errorf("expected subscription id %s, got %s", subscription.GetID(), due[0].GetSubscriptionID())
+	}
+
+	if _, err := store.DunningDue(ctx, "not a date"); err == nil {
+		t.Error("expected error for an invalid date")
+	}
+}
+
+func TestNewStoreDunningRetryDaysValidation(t *testing.T) {
+	_, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		DunningRetryDays:      []int{3, 1},
+	})
+	if err == nil {
+		t.Fatal("expected error for retry days which are not ascending")
+	}
+}
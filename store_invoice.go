@@ -0,0 +1,319 @@
+package subscriptionstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// InvoiceCreate creates a new invoice
+func (st *storeImplementation) InvoiceCreate(ctx context.Context, invoice InvoiceInterface) error {
+	if invoice == nil {
+		return errors.New("subscriptionstore > invoice create. invoice cannot be nil")
+	}
+	if invoice.GetSubscriptionID() == "" {
+		return errors.New("subscriptionstore > invoice create. subscription id cannot be empty")
+	}
+
+	if invoice.GetCreatedAt() == "" {
+		invoice.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if invoice.GetUpdatedAt() == "" {
+		invoice.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row, err := st.invoiceRow(invoice)
+	if err != nil {
+		return err
+	}
+	row[COLUMN_ID] = invoice.GetID()
+	row[COLUMN_CREATED_AT] = dateTimeValue(invoice.GetCreatedAtCarbon())
+
+	return st.query().Table(st.invoiceTableName).Create(row)
+}
+
+// InvoiceCreateForPeriod issues a draft invoice for the billing period of the
+// subscription which starts at periodStart. The period lasts one interval of
+// the subscription's plan, and the plan is snapshotted on the invoice, so
+// later changes to the plan do not alter what was billed. The amount is the
+// price of the plan times the quantity of the subscription, less the
+// discount applying to the period, if any.
+//
+// If a non void invoice already exists for the period, it is returned instead,
+// so generation can safely be retried. A unique index on the period backs
+// the check, so concurrent runs create a single invoice.
+func (st *storeImplementation) InvoiceCreateForPeriod(ctx context.Context, subscriptionID string, periodStart string) (InvoiceInterface, error) {
+	periodStartCarbon := carbon.Parse(periodStart, carbon.UTC)
+	if periodStartCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > invoice create for period. period start is not a valid date")
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > invoice create for period. subscription not found")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > invoice create for period. plan not found")
+	}
+
+	price, err := st.periodPrice(ctx, subscription, plan, periodStartCarbon)
+	if err != nil {
+		return nil, errors.New("subscriptionstore > invoice create for period. " + err.Error())
+	}
+
+	existing, err := st.invoiceForPeriod(ctx, subscription.GetID(), periodStartCarbon)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	invoice := NewInvoice().
+		SetSubscriptionID(subscription.GetID()).
+		SetSubscriberID(subscription.GetSubscriberID()).
+		SetPlanID(plan.GetID()).
+		SetPeriodStart(price.PeriodStart).
+		SetPeriodEnd(price.PeriodEnd).
+		SetAmount(price.Total).
+		SetCurrency(price.Currency)
+
+	snapshot, err := planSnapshot(plan)
+	if err != nil {
+		return nil, err
+	}
+	snapshot[COLUMN_QUANTITY] = strconv.Itoa(price.Quantity)
+	if subscription.GetPlanVersionID() != "" {
+		snapshot[COLUMN_PLAN_VERSION_ID] = subscription.GetPlanVersionID()
+	}
+	if price.CouponID != "" {
+		snapshot[COLUMN_COUPON_ID] = price.CouponID
+	}
+
+	if _, err := invoice.SetPlanSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	// An invoice created for the period since the check above, by a
+	// concurrent run, fails the unique index on the period and is returned
+	if err := st.InvoiceCreate(ctx, invoice); err != nil {
+		existing, findErr := st.invoiceForPeriod(ctx, subscription.GetID(), periodStartCarbon)
+		if findErr != nil || existing == nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	return invoice, nil
+}
+
+// invoiceForPeriod returns the invoice of the subscription for the period
+// starting at periodStart which is not void, or nil if there is none
+func (st *storeImplementation) invoiceForPeriod(ctx context.Context, subscriptionID string, periodStart *carbon.Carbon) (InvoiceInterface, error) {
+	invoices, err := st.InvoiceList(ctx, InvoiceQuery().
+		SetSubscriptionID(subscriptionID).
+		SetPeriodStart(periodStart.ToDateTimeString(carbon.UTC)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, invoice := range invoices {
+		if invoice.GetStatus() != INVOICE_STATUS_VOID {
+			return invoice, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// InvoiceFindByID finds an invoice by id
+func (st *storeImplementation) InvoiceFindByID(ctx context.Context, id string) (InvoiceInterface, error) {
+	if id == "" {
+		return nil, errors.New("invoice id is empty")
+	}
+	list, err := st.InvoiceList(ctx, InvoiceQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// InvoiceList retrieves a list of invoices
+func (st *storeImplementation) InvoiceList(ctx context.Context, query InvoiceQueryInterface) ([]InvoiceInterface, error) {
+	if query == nil {
+		return []InvoiceInterface{}, errors.New("at invoice list > invoice query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []InvoiceInterface{}, err
+	}
+
+	q := st.buildInvoiceQuery(query)
+
+	type invoiceRow struct {
+		ID             string    `db:"id"`
+		SubscriptionID string    `db:"subscription_id"`
+		SubscriberID   string    `db:"subscriber_id"`
+		PlanID         string    `db:"plan_id"`
+		PlanSnapshot   string    `db:"plan_snapshot"`
+		PeriodStart    time.Time `db:"period_start"`
+		PeriodEnd      time.Time `db:"period_end"`
+		Amount         string    `db:"amount"`
+		Currency       string    `db:"currency"`
+		Status         string    `db:"status"`
+		Memo           string    `db:"memo"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}
+
+	var rows []invoiceRow
+	if err := q.Table(st.invoiceTableName).Get(&rows); err != nil {
+		return []InvoiceInterface{}, err
+	}
+
+	list := make([]InvoiceInterface, 0, len(rows))
+	for _, r := range rows {
+		i := &invoiceImplementation{}
+		i.SetID(r.ID)
+		i.SetSubscriptionID(r.SubscriptionID)
+		i.SetSubscriberID(r.SubscriberID)
+		i.SetPlanID(r.PlanID)
+		i.PlanSnapshotField = r.PlanSnapshot
+		i.SetPeriodStart(carbon.CreateFromStdTime(r.PeriodStart, carbon.UTC).ToDateTimeString(carbon.UTC))
+		i.SetPeriodEnd(carbon.CreateFromStdTime(r.PeriodEnd, carbon.UTC).ToDateTimeString(carbon.UTC))
+		i.SetAmount(r.Amount)
+		i.SetCurrency(r.Currency)
+		i.SetStatus(r.Status)
+		i.SetMemo(r.Memo)
+		i.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		i.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, i)
+	}
+
+	return list, nil
+}
+
+// InvoiceUpdate updates an invoice
+func (st *storeImplementation) InvoiceUpdate(ctx context.Context, invoice InvoiceInterface) error {
+	if invoice == nil {
+		return errors.New("subscriptionstore > invoice update. invoice cannot be nil")
+	}
+
+	invoice.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	row, err := st.invoiceRow(invoice)
+	if err != nil {
+		return err
+	}
+
+	_, err = st.query().Table(st.invoiceTableName).Where(COLUMN_ID+" = ?", invoice.GetID()).Update(row)
+	return err
+}
+
+// invoiceRow returns the columns of the invoice, which are written on both
+// create and update
+func (st *storeImplementation) invoiceRow(invoice InvoiceInterface) (map[string]any, error) {
+	snapshotMap, err := invoice.GetPlanSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	var snapshotStr string
+	if snapshotMap != nil {
+		b, err := json.Marshal(snapshotMap)
+		if err != nil {
+			return nil, err
+		}
+		snapshotStr = string(b)
+	}
+
+	return map[string]any{
+		COLUMN_SUBSCRIPTION_ID: invoice.GetSubscriptionID(),
+		COLUMN_SUBSCRIBER_ID:   invoice.GetSubscriberID(),
+		COLUMN_PLAN_ID:         invoice.GetPlanID(),
+		COLUMN_PLAN_SNAPSHOT:   snapshotStr,
+		COLUMN_PERIOD_START:    dateTimeValue(invoice.GetPeriodStartCarbon()),
+		COLUMN_PERIOD_END:      dateTimeValue(invoice.GetPeriodEndCarbon()),
+		COLUMN_AMOUNT:          invoice.GetAmount(),
+		COLUMN_CURRENCY:        invoice.GetCurrency(),
+		COLUMN_STATUS:          invoice.GetStatus(),
+		COLUMN_VOID_ID:         lo.Ternary(invoice.GetStatus() == INVOICE_STATUS_VOID, invoice.GetID(), ""),
+		COLUMN_MEMO:            invoice.GetMemo(),
+		COLUMN_UPDATED_AT:      dateTimeValue(invoice.GetUpdatedAtCarbon()),
+	}, nil
+}
+
+// planSnapshot returns the plan fields which determine what is billed
+func planSnapshot(plan PlanInterface) (map[string]string, error) {
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+	tiersJSON, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		COLUMN_ID:              plan.GetID(),
+		COLUMN_TYPE:            plan.GetType(),
+		COLUMN_TITLE:           plan.GetTitle(),
+		COLUMN_INTERVAL:        plan.GetInterval(),
+		COLUMN_CURRENCY:        plan.GetCurrency(),
+		COLUMN_PRICE:           plan.GetPrice(),
+		COLUMN_PRICING_MODEL:   plan.GetPricingModel(),
+		COLUMN_PRICE_TIERS:     tiersJSON,
+		COLUMN_STRIPE_PRICE_ID: plan.GetStripePriceID(),
+	}, nil
+}
+
+// buildInvoiceQuery builds a neat query from the invoice query interface.
+func (st *storeImplementation) buildInvoiceQuery(query InvoiceQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriptionID() && query.SubscriptionID() != "" {
+		q = q.Where(COLUMN_SUBSCRIPTION_ID+" = ?", query.SubscriptionID())
+	}
+	if query.HasSubscriberID() && query.SubscriberID() != "" {
+		q = q.Where(COLUMN_SUBSCRIBER_ID+" = ?", query.SubscriberID())
+	}
+	if query.HasStatus() && query.Status() != "" {
+		q = q.Where(COLUMN_STATUS+" = ?", query.Status())
+	}
+	if query.HasPeriodStart() && query.PeriodStart() != "" {
+		q = q.Where(COLUMN_PERIOD_START+" = ?", dateTimeValue(carbon.Parse(query.PeriodStart(), carbon.UTC)))
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,168 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreInvoiceCreateForPeriod(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().
+		SetTitle("Pro").
+		SetPrice("19.99").
+		SetCurrency(CURRENCY_USD).
+		SetInterval(PLAN_INTERVAL_MONTHLY).
+		SetStatus(PLAN_STATUS_ACTIVE)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(plan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	invoice, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-01-31 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if invoice.GetStatus() != INVOICE_STATUS_DRAFT {
+		t.Errorf("expected status %s, got %s", INVOICE_STATUS_DRAFT, invoice.GetStatus())
+	}
+	if invoice.GetPeriodEnd() != "2025-02-28 00:00:00" {
+		t.Errorf("expected period end 2025-02-28 00:00:00, got %s", invoice.GetPeriodEnd())
+	}
+	if invoice.GetAmount() != "19.99" || invoice.GetCurrency() != CURRENCY_USD {
+		t.Errorf("expected 19.99 USD, got %s %s", invoice.GetAmount(), invoice.GetCurrency())
+	}
+
+	// Later price changes do not alter what was billed
+	plan.SetPrice("29.99")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.InvoiceFindByID(ctx, invoice.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Fatal("expected invoice to be found")
+	}
+	if found.GetSubscriberID() != "user_1" || found.GetPlanID() != plan.GetID() {
+		t.Errorf("unexpected invoice owner %s / plan %s", found.GetSubscriberID(), found.GetPlanID())
+	}
+	snapshot, err := found.GetPlanSnapshot()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if snapshot[COLUMN_PRICE] != "19.99" || snapshot[COLUMN_TITLE] != "Pro" || snapshot[COLUMN_INTERVAL] != PLAN_INTERVAL_MONTHLY {
+		t.Errorf("unexpected plan snapshot: %v", snapshot)
+	}
+
+	// Generation is idempotent per period
+	again, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-01-31 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if again.GetID() != invoice.GetID() {
+		t.Errorf("expected the existing invoice %s, got %s", invoice.GetID(), again.GetID())
+	}
+
+	// A voided invoice is reissued
+	found.SetStatus(INVOICE_STATUS_VOID)
+	if err := store.InvoiceUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	reissued, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-01-31 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if reissued.GetID() == invoice.GetID() {
+		t.Error("expected a new invoice after voiding")
+	}
+	if reissued.GetAmount() != "19.99" {
+		t.Errorf("expected the reissued invoice to bill the pinned plan version, got %s", reissued.GetAmount())
+	}
+
+	// An updated invoice is still found for its period
+	reissued.SetMemo("sent")
+	if err := store.InvoiceUpdate(ctx, reissued); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	again, err = store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-01-31 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if again.GetID() != reissued.GetID() {
+		t.Errorf("expected the updated invoice %s, got %s", reissued.GetID(), again.GetID())
+	}
+
+	// A unique index keeps a single invoice per period which is not void,
+	// so concurrent runs cannot both create one
+	duplicate := NewInvoice().SetSubscriptionID(subscription.GetID()).SetPeriodStart(reissued.GetPeriodStart())
+	if err := store.InvoiceCreate(ctx, duplicate); err == nil {
+		t.Error("expected error for a second invoice of the period")
+	}
+
+	if _, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "not a date"); err == nil {
+		t.Error("expected error for an invalid period start")
+	}
+	if _, err := store.InvoiceCreateForPeriod(ctx, "missing", "2025-01-31 00:00:00"); err == nil {
+		t.Error("expected error for a missing subscription")
+	}
+}
+
+func TestStoreInvoiceList(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	for _, invoice := range []InvoiceInterface{
+		NewInvoice().SetSubscriptionID("sub_1").SetSubscriberID("user_1").SetStatus(INVOICE_STATUS_PAID).SetPeriodStart("2025-01-01 00:00:00"),
+		NewInvoice().SetSubscriptionID("sub_1").SetSubscriberID("user_1").SetStatus(INVOICE_STATUS_OPEN).SetPeriodStart("2025-02-01 00:00:00"),
+		NewInvoice().SetSubscriptionID("sub_2").SetSubscriberID("user_1").SetStatus(INVOICE_STATUS_OPEN),
+		NewInvoice().SetSubscriptionID("sub_3").SetSubscriberID("user_2").SetStatus(INVOICE_STATUS_OPEN),
+	} {
+		if err := store.InvoiceCreate(ctx, invoice); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    InvoiceQueryInterface
+		expected int
+	}{
+		{"by subscriber", InvoiceQuery().SetSubscriberID("user_1"), 3},
+		{"by subscription", InvoiceQuery().SetSubscriptionID("sub_1"), 2},
+		{"by subscriber and status", InvoiceQuery().SetSubscriberID("user_1").SetStatus(INVOICE_STATUS_OPEN), 2},
+		{"with limit", InvoiceQuery().SetLimit(1), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := store.InvoiceList(ctx, tc.query)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if len(list) != tc.expected {
+				t.Fatalf("expected %d invoices, got %d", tc.expected, len(list))
+			}
+		})
+	}
+
+	if err := store.InvoiceCreate(ctx, NewInvoice()); err == nil {
+		t.Error("expected error creating an invoice without a subscription")
+	}
+}
@@ -0,0 +1,1136 @@
+package subscriptionstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// NewMemoryStore creates a store keeping the plans, their versions and the
+// subscriptions in memory, i.e. for the tests of the applications using the
+// store, which then need no database.
+//
+// Plans and subscriptions honor every filter, ordering, pagination and soft
+// delete of their queries, as the SQL store does. Plans are versioned when
+// their billed fields change, subscriptions are pinned to the latest version
+// of their plan, and can be cancelled, uncancelled, paused and resumed. The
+// memory store keeps no subscribers or payment methods, so subscriptions
+// behave as those of subscribers without a record in the SQL store.
+//
+// The memory store implements PlanSubscriptionStore, the plans and the
+// subscriptions part of StoreInterface, and not StoreInterface: coupons,
+// invoices, dunning, schedules, items, subscribers, payment methods, the
+// reports and the migrations are left to the SQL store. Services using only
+// plans and subscriptions depend on PlanSubscriptionStore, or on PlanStore
+// or SubscriptionStore, which the SQL store implements as well, to be tested
+// with the memory store.
+func NewMemoryStore() PlanSubscriptionStore {
+	return &memoryStore{}
+}
+
+var _ PlanSubscriptionStore = (*memoryStore)(nil)
+
+// memoryStore keeps the plans, the plan versions and the subscriptions as
+// rows of their column values, in the order they were created. Entities are
+// copied in and out of the rows, so changing a returned entity does not
+// change the store.
+type memoryStore struct {
+	mutex         sync.RWMutex
+	plans         []map[string]string
+	planVersions  []map[string]string
+	subscriptions []map[string]string
+}
+
+// memoryPlanColumns are the columns of the plan rows
+var memoryPlanColumns = []string{
+	COLUMN_ID,
+	COLUMN_TYPE,
+	COLUMN_STATUS,
+	COLUMN_TITLE,
+	COLUMN_DESCRIPTION,
+	COLUMN_INTERVAL,
+	COLUMN_CURRENCY,
+	COLUMN_PRICE,
+	COLUMN_PRICING_MODEL,
+	COLUMN_PRICE_TIERS,
+	COLUMN_STRIPE_PRICE_ID,
+	COLUMN_FEATURES,
+	COLUMN_MEMO,
+	COLUMN_METAS,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// memoryPlanVersionColumns are the columns of the plan version rows
+var memoryPlanVersionColumns = []string{
+	COLUMN_ID,
+	COLUMN_PLAN_ID,
+	COLUMN_VERSION,
+	COLUMN_INTERVAL,
+	COLUMN_CURRENCY,
+	COLUMN_PRICE,
+	COLUMN_PRICING_MODEL,
+	COLUMN_PRICE_TIERS,
+	COLUMN_FEATURES,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+}
+
+// memorySubscriptionColumns are the columns of the subscription rows
+var memorySubscriptionColumns = []string{
+	COLUMN_ID,
+	COLUMN_STATUS,
+	COLUMN_SUBSCRIBER_ID,
+	COLUMN_PLAN_ID,
+	COLUMN_PLAN_VERSION_ID,
+	COLUMN_QUANTITY,
+	COLUMN_PERIOD_START,
+	COLUMN_PERIOD_END,
+	COLUMN_CANCEL_AT_PERIOD_END,
+	COLUMN_CANCELLATION_REQUESTED_AT,
+	COLUMN_CANCELLATION_EFFECTIVE_AT,
+	COLUMN_CANCELLATION_REASON,
+	COLUMN_CANCELLATION_FEEDBACK,
+	COLUMN_CANCELLED_BY,
+	COLUMN_PAYMENT_METHOD_ID,
+	COLUMN_CURRENCY,
+	COLUMN_PAUSED_AT,
+	COLUMN_RESUME_AT,
+	COLUMN_MEMO,
+	COLUMN_METAS,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// memoryIntegerColumns are the columns ordered as numbers rather than as
+// strings, as the integer columns of the SQL tables are
+var memoryIntegerColumns = []string{
+	COLUMN_QUANTITY,
+	COLUMN_VERSION,
+}
+
+// == PLAN METHODS =============================================================
+
+// PlanCount returns the number of plans based on the given query options
+func (st *memoryStore) PlanCount(ctx context.Context, query PlanQueryInterface) (int64, error) {
+	if query == nil {
+		return 0, errors.New("plan query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return 0, err
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	return int64(len(memoryPlanRows(st.plans, query))), nil
+}
+
+// PlanCountBy counts the plans matching the query by the value of the
+// column, one of COLUMN_TYPE, COLUMN_INTERVAL, COLUMN_STATUS,
+// COLUMN_CURRENCY or COLUMN_PRICING_MODEL
+func (st *memoryStore) PlanCountBy(ctx context.Context, query PlanQueryInterface, column string) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("plan query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !lo.Contains(planCountByColumns, column) {
+		return nil, errors.New("subscriptionstore > plan count by. unsupported column: " + column)
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > plan count by. query cannot be paginated or ordered")
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	return memoryRowsCountBy(memoryPlanRows(st.plans, query), column), nil
+}
+
+// PlanCreate creates a new plan
+func (st *memoryStore) PlanCreate(ctx context.Context, plan PlanInterface) error {
+	if plan == nil {
+		return errors.New("subscriptionstore > plan create. plan cannot be nil")
+	}
+	if err := planPricingValidate(plan); err != nil {
+		return errors.New("subscriptionstore > plan create. " + err.Error())
+	}
+
+	planCreateDefaults(plan)
+
+	row, err := planExistingData(plan)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if memoryRowIndex(st.plans, plan.GetID()) >= 0 {
+		return errors.New("subscriptionstore > plan create. plan already exists: " + plan.GetID())
+	}
+
+	// The plan is written with its version, so it never goes without one
+	versionRow, err := st.planVersionNextRow(plan)
+	if err != nil {
+		return err
+	}
+
+	st.plans = append(st.plans, row)
+	if versionRow != nil {
+		st.planVersions = append(st.planVersions, versionRow)
+	}
+	return nil
+}
+
+// PlanDelete deletes a plan
+func (st *memoryStore) PlanDelete(ctx context.Context, plan PlanInterface) error {
+	if plan == nil {
+		return errors.New("plan is nil")
+	}
+	return st.PlanDeleteByID(ctx, plan.GetID())
+}
+
+// PlanDeleteByID deletes a plan by id
+func (st *memoryStore) PlanDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("plan id is empty")
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.plans = memoryRowsDelete(st.plans, id)
+	return nil
+}
+
+// PlanExists returns true if a plan exists
+func (st *memoryStore) PlanExists(ctx context.Context, planID string) (bool, error) {
+	if planID == "" {
+		return false, errors.New("plan id is empty")
+	}
+	count, err := st.PlanCount(ctx, PlanQuery().SetID(planID))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PlanFindByID finds a plan by id
+func (st *memoryStore) PlanFindByID(ctx context.Context, id string) (PlanInterface, error) {
+	if id == "" {
+		return nil, errors.New("plan id is empty")
+	}
+	list, err := st.PlanList(ctx, PlanQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// PlanList retrieves a list of plans
+func (st *memoryStore) PlanList(ctx context.Context, query PlanQueryInterface) ([]PlanInterface, error) {
+	if query == nil {
+		return []PlanInterface{}, errors.New("at plan list > plan query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PlanInterface{}, err
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	rows := memoryPlanRows(st.plans, query)
+
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		if err := memoryRowsSort(rows, memoryPlanColumns, query.OrderBy(), sortOrder); err != nil {
+			return []PlanInterface{}, err
+		}
+	}
+
+	if query.HasOffset() {
+		rows = memoryRowsPage(rows, 0, query.Offset())
+	}
+	if query.HasLimit() {
+		rows = memoryRowsPage(rows, query.Limit(), 0)
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) PlanInterface {
+		return NewPlanFromExistingData(row)
+	}), nil
+}
+
+// PlanSoftDelete soft deletes a plan
+func (st *memoryStore) PlanSoftDelete(ctx context.Context, plan PlanInterface) error {
+	if plan == nil {
+		return errors.New("plan is nil")
+	}
+	plan.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return st.PlanUpdate(ctx, plan)
+}
+
+// PlanSoftDeleteByID soft deletes a plan by id
+func (st *memoryStore) PlanSoftDeleteByID(ctx context.Context, id string) error {
+	plan, err := st.PlanFindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return st.PlanSoftDelete(ctx, plan)
+}
+
+// PlanUpdate updates a plan. Plans which do not exist are left alone.
+func (st *memoryStore) PlanUpdate(ctx context.Context, plan PlanInterface) error {
+	if plan == nil {
+		return errors.New("subscriptionstore > plan update. plan cannot be nil")
+	}
+	if err := planPricingValidate(plan); err != nil {
+		return errors.New("subscriptionstore > plan update. " + err.Error())
+	}
+
+	plan.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	row, err := planExistingData(plan)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if memoryRowIndex(st.plans, plan.GetID()) < 0 {
+		return nil
+	}
+
+	versionRow, err := st.planVersionNextRow(plan)
+	if err != nil {
+		return err
+	}
+
+	memoryRowsUpdate(st.plans, row)
+	if versionRow != nil {
+		st.planVersions = append(st.planVersions, versionRow)
+	}
+	return nil
+}
+
+// PlanVersionFindByID finds a plan version by id
+func (st *memoryStore) PlanVersionFindByID(ctx context.Context, id string) (PlanVersionInterface, error) {
+	if id == "" {
+		return nil, errors.New("plan version id is empty")
+	}
+	list, err := st.PlanVersionList(ctx, PlanVersionQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// PlanVersionList retrieves a list of plan versions
+func (st *memoryStore) PlanVersionList(ctx context.Context, query PlanVersionQueryInterface) ([]PlanVersionInterface, error) {
+	if query == nil {
+		return []PlanVersionInterface{}, errors.New("at plan version list > plan version query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PlanVersionInterface{}, err
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	rows := memoryRowsFilter(st.planVersions, func(row map[string]string) bool {
+		if query.HasID() && query.ID() != "" && row[COLUMN_ID] != query.ID() {
+			return false
+		}
+		return !query.HasPlanID() || query.PlanID() == "" || row[COLUMN_PLAN_ID] == query.PlanID()
+	})
+
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		if err := memoryRowsSort(rows, memoryPlanVersionColumns, query.OrderBy(), sortOrder); err != nil {
+			return []PlanVersionInterface{}, err
+		}
+	}
+
+	if query.HasOffset() {
+		rows = memoryRowsPage(rows, 0, query.Offset())
+	}
+	if query.HasLimit() {
+		rows = memoryRowsPage(rows, query.Limit(), 0)
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) PlanVersionInterface {
+		return memoryPlanVersion(row)
+	}), nil
+}
+
+// planVersionNextRow returns the row of the version following the latest
+// version of the plan, or nil if the latest version matches the plan. The
+// caller holds the lock of the store.
+func (st *memoryStore) planVersionNextRow(plan PlanInterface) (map[string]string, error) {
+	var latest PlanVersionInterface
+	if row := memoryPlanVersionLatest(st.planVersions, plan.GetID()); row != nil {
+		latest = memoryPlanVersion(row)
+	}
+
+	version, err := planVersionNext(plan, latest)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, nil
+	}
+
+	return planVersionExistingData(version)
+}
+
+// == SUBSCRIPTION METHODS ======================================================
+
+// SubscriptionCancel cancels the subscription, immediately or at the end of
+// its current period
+func (st *memoryStore) SubscriptionCancel(ctx context.Context, subscriptionID string, options SubscriptionCancelOptions) error {
+	return cancelSubscription(ctx, st, subscriptionID, options, carbon.Now(carbon.UTC))
+}
+
+// SubscriptionCancellationsByReason counts the cancellations of the
+// subscriptions matching the query by reason, including those pending at
+// the end of the period
+func (st *memoryStore) SubscriptionCancellationsByReason(ctx context.Context, query SubscriptionQueryInterface) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("subscriptionstore > subscription cancellations by reason. query cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > subscription cancellations by reason. query cannot be paginated or ordered")
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	cancelled := lo.Filter(memorySubscriptionRows(st.subscriptions, query), func(row map[string]string, _ int) bool {
+		return row[COLUMN_CANCELLATION_REQUESTED_AT] < memoryDateTime(MAX_DATETIME)
+	})
+
+	return memoryRowsCountBy(cancelled, COLUMN_CANCELLATION_REASON), nil
+}
+
+// SubscriptionCount returns the number of subscriptions based on the given query options
+func (st *memoryStore) SubscriptionCount(ctx context.Context, query SubscriptionQueryInterface) (int64, error) {
+	if query == nil {
+		return 0, errors.New("subscription query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return 0, err
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	return int64(len(memorySubscriptionRows(st.subscriptions, query))), nil
+}
+
+// SubscriptionCountBy counts the subscriptions matching the query by the
+// value of the column, one of COLUMN_STATUS, COLUMN_PLAN_ID,
+// COLUMN_PLAN_VERSION_ID or COLUMN_SUBSCRIBER_ID
+func (st *memoryStore) SubscriptionCountBy(ctx context.Context, query SubscriptionQueryInterface, column string) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("subscription query: cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if !lo.Contains(subscriptionCountByColumns, column) {
+		return nil, errors.New("subscriptionstore > subscription count by. unsupported column: " + column)
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > subscription count by. query cannot be paginated or ordered")
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	return memoryRowsCountBy(memorySubscriptionRows(st.subscriptions, query), column), nil
+}
+
+// SubscriptionCreate creates a new subscription
+func (st *memoryStore) SubscriptionCreate(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription create. subscription cannot be nil")
+	}
+	if err := subscriptionCreateDefaults(subscription); err != nil {
+		return err
+	}
+	st.subscriptionPinPlanVersion(subscription)
+	if subscription.GetPlanID() != "" {
+		plan, err := st.subscriptionPlan(ctx, subscription)
+		if err != nil {
+			return err
+		}
+		if err := subscriptionPlanCurrency(subscription, plan); err != nil {
+			return errors.New("subscriptionstore > subscription create. " + err.Error())
+		}
+	}
+
+	row, err := subscriptionExistingData(subscription)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if memoryRowIndex(st.subscriptions, subscription.GetID()) >= 0 {
+		return errors.New("subscriptionstore > subscription create. subscription already exists: " + subscription.GetID())
+	}
+
+	st.subscriptions = append(st.subscriptions, row)
+	return nil
+}
+
+// SubscriptionDelete deletes a subscription
+func (st *memoryStore) SubscriptionDelete(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription == nil {
+		return errors.New("subscription is nil")
+	}
+	return st.SubscriptionDeleteByID(ctx, subscription.GetID())
+}
+
+// SubscriptionDeleteByID deletes a subscription by id
+func (st *memoryStore) SubscriptionDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("subscription id is empty")
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.subscriptions = memoryRowsDelete(st.subscriptions, id)
+	return nil
+}
+
+// SubscriptionExists returns true if a subscription exists
+func (st *memoryStore) SubscriptionExists(ctx context.Context, subscriptionID string) (bool, error) {
+	if subscriptionID == "" {
+		return false, errors.New("subscription id is empty")
+	}
+	count, err := st.SubscriptionCount(ctx, SubscriptionQuery().SetID(subscriptionID))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SubscriptionFindActiveBySubscriberID finds the latest active subscription
+// of the subscriber, or nil if it has none
+func (st *memoryStore) SubscriptionFindActiveBySubscriberID(ctx context.Context, subscriberID string) (SubscriptionInterface, error) {
+	return findActiveSubscription(ctx, st, subscriberID)
+}
+
+// SubscriptionFindByID finds a subscription by id
+func (st *memoryStore) SubscriptionFindByID(ctx context.Context, id string) (SubscriptionInterface, error) {
+	if id == "" {
+		return nil, errors.New("subscription id is empty")
+	}
+	list, err := st.SubscriptionList(ctx, SubscriptionQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// SubscriptionList retrieves a list of subscriptions
+func (st *memoryStore) SubscriptionList(ctx context.Context, query SubscriptionQueryInterface) ([]SubscriptionInterface, error) {
+	if query == nil {
+		return []SubscriptionInterface{}, errors.New("at subscription list > subscription query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []SubscriptionInterface{}, err
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	rows := memorySubscriptionRows(st.subscriptions, query)
+
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		if err := memoryRowsSort(rows, memorySubscriptionColumns, query.OrderBy(), sortOrder); err != nil {
+			return []SubscriptionInterface{}, err
+		}
+	}
+
+	if query.HasOffset() {
+		rows = memoryRowsPage(rows, 0, query.Offset())
+	}
+	if query.HasLimit() {
+		rows = memoryRowsPage(rows, query.Limit(), 0)
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) SubscriptionInterface {
+		return NewSubscriptionFromExistingData(row)
+	}), nil
+}
+
+// SubscriptionPause pauses an active subscription until the given resume date
+func (st *memoryStore) SubscriptionPause(ctx context.Context, id string, resumeAt string) error {
+	return pauseSubscription(ctx, st, id, resumeAt)
+}
+
+// SubscriptionPlan returns the plan of the subscription as of the version
+// the subscription is pinned to
+func (st *memoryStore) SubscriptionPlan(ctx context.Context, subscriptionID string) (PlanInterface, error) {
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > subscription plan. subscription not found")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > subscription plan. plan not found")
+	}
+
+	return plan, nil
+}
+
+// SubscriptionResume reactivates a paused subscription
+func (st *memoryStore) SubscriptionResume(ctx context.Context, id string) error {
+	return resumeSubscription(ctx, st, id)
+}
+
+// SubscriptionResumeDue resumes all paused subscriptions whose resume date
+// is at or before now, and returns them, skipping those failing to resume
+func (st *memoryStore) SubscriptionResumeDue(ctx context.Context, now string) ([]SubscriptionInterface, error) {
+	return resumeDueSubscriptions(ctx, st, now)
+}
+
+// SubscriptionSoftDelete soft deletes a subscription
+func (st *memoryStore) SubscriptionSoftDelete(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription == nil {
+		return errors.New("subscription is nil")
+	}
+	subscription.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return st.SubscriptionUpdate(ctx, subscription)
+}
+
+// SubscriptionSoftDeleteByID soft deletes a subscription by id
+func (st *memoryStore) SubscriptionSoftDeleteByID(ctx context.Context, id string) error {
+	subscription, err := st.SubscriptionFindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return st.SubscriptionSoftDelete(ctx, subscription)
+}
+
+// SubscriptionUncancel withdraws the pending cancellation at the end of the
+// period of the subscription
+func (st *memoryStore) SubscriptionUncancel(ctx context.Context, subscriptionID string) error {
+	return uncancelSubscription(ctx, st, subscriptionID)
+}
+
+// SubscriptionUpdate updates a subscription. Subscriptions which do not
+// exist are left alone.
+func (st *memoryStore) SubscriptionUpdate(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription update. subscription cannot be nil")
+	}
+
+	subscription.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	st.subscriptionPinPlanVersion(subscription)
+	if err := st.subscriptionPlanChangeCurrency(ctx, subscription); err != nil {
+		return errors.New("subscriptionstore > subscription update. " + err.Error())
+	}
+
+	row, err := subscriptionExistingData(subscription)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	memoryRowsUpdate(st.subscriptions, row)
+	return nil
+}
+
+// subscriptionPlan returns the plan of the subscription as of its pinned
+// version, or nil if the plan is not found
+func (st *memoryStore) subscriptionPlan(ctx context.Context, subscription SubscriptionInterface) (PlanInterface, error) {
+	plan, err := st.PlanFindByID(ctx, subscription.GetPlanID())
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil || subscription.GetPlanVersionID() == "" {
+		return plan, nil
+	}
+
+	version, err := st.PlanVersionFindByID(ctx, subscription.GetPlanVersionID())
+	if err != nil {
+		return nil, err
+	}
+
+	return planAtPinnedVersion(plan, version)
+}
+
+// subscriptionPinPlanVersion pins the subscription to the latest version of
+// its plan, unless it is already pinned to a version of the plan
+func (st *memoryStore) subscriptionPinPlanVersion(subscription SubscriptionInterface) {
+	if subscription.GetPlanID() == "" {
+		return
+	}
+
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	if index := memoryRowIndex(st.planVersions, subscription.GetPlanVersionID()); index >= 0 {
+		if st.planVersions[index][COLUMN_PLAN_ID] == subscription.GetPlanID() {
+			return
+		}
+	}
+
+	latest := memoryPlanVersionLatest(st.planVersions, subscription.GetPlanID())
+	subscription.SetPlanVersionID(latest[COLUMN_ID])
+}
+
+// subscriptionPlanChangeCurrency checks that the plan of the subscription is
+// priced in its currency, when the plan differs from the stored one
+func (st *memoryStore) subscriptionPlanChangeCurrency(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription.GetPlanID() == "" {
+		return nil
+	}
+
+	stored, err := st.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.GetPlanID() == subscription.GetPlanID() {
+		return nil
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	return subscriptionPlanCurrency(subscription, plan)
+}
+
+// == ROWS =====================================================================
+
+// planExistingData returns the column values of the plan, as the SQL store
+// writes them and NewPlanFromExistingData reads them
+func planExistingData(plan PlanInterface) (map[string]string, error) {
+	metas, err := plan.GetMetas()
+	if err != nil {
+		return nil, err
+	}
+	metasStr, err := memoryMetasJSON(metas)
+	if err != nil {
+		return nil, err
+	}
+
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+	tiersStr, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		COLUMN_ID:              plan.GetID(),
+		COLUMN_TYPE:            plan.GetType(),
+		COLUMN_STATUS:          plan.GetStatus(),
+		COLUMN_TITLE:           plan.GetTitle(),
+		COLUMN_DESCRIPTION:     plan.GetDescription(),
+		COLUMN_INTERVAL:        plan.GetInterval(),
+		COLUMN_CURRENCY:        plan.GetCurrency(),
+		COLUMN_PRICE:           plan.GetPrice(),
+		COLUMN_PRICING_MODEL:   plan.GetPricingModel(),
+		COLUMN_PRICE_TIERS:     tiersStr,
+		COLUMN_STRIPE_PRICE_ID: plan.GetStripePriceID(),
+		COLUMN_FEATURES:        plan.GetFeatures(),
+		COLUMN_MEMO:            plan.GetMemo(),
+		COLUMN_METAS:           metasStr,
+		COLUMN_CREATED_AT:      plan.GetCreatedAtCarbon().ToDateTimeString(carbon.UTC),
+		COLUMN_UPDATED_AT:      plan.GetUpdatedAtCarbon().ToDateTimeString(carbon.UTC),
+		COLUMN_SOFT_DELETED_AT: plan.GetSoftDeletedAtCarbon().ToDateTimeString(carbon.UTC),
+	}, nil
+}
+
+// planVersionExistingData returns the column values of the plan version, as
+// the SQL store writes them and memoryPlanVersion reads them
+func planVersionExistingData(version PlanVersionInterface) (map[string]string, error) {
+	tiers, err := version.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+	tiersStr, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		COLUMN_ID:            version.GetID(),
+		COLUMN_PLAN_ID:       version.GetPlanID(),
+		COLUMN_VERSION:       strconv.Itoa(version.GetVersion()),
+		COLUMN_INTERVAL:      version.GetInterval(),
+		COLUMN_CURRENCY:      version.GetCurrency(),
+		COLUMN_PRICE:         version.GetPrice(),
+		COLUMN_PRICING_MODEL: version.GetPricingModel(),
+		COLUMN_PRICE_TIERS:   tiersStr,
+		COLUMN_FEATURES:      version.GetFeatures(),
+		COLUMN_CREATED_AT:    version.GetCreatedAtCarbon().ToDateTimeString(carbon.UTC),
+		COLUMN_UPDATED_AT:    version.GetUpdatedAtCarbon().ToDateTimeString(carbon.UTC),
+	}, nil
+}
+
+// memoryPlanVersion returns the plan version of the row, as the SQL store
+// reads the plan version rows
+func memoryPlanVersion(row map[string]string) PlanVersionInterface {
+	version := &planVersionImplementation{}
+	version.SetID(row[COLUMN_ID])
+	version.SetPlanID(row[COLUMN_PLAN_ID])
+	version.SetVersion(cast.ToInt(row[COLUMN_VERSION]))
+	version.SetInterval(row[COLUMN_INTERVAL])
+	version.SetCurrency(row[COLUMN_CURRENCY])
+	version.SetPrice(row[COLUMN_PRICE])
+	version.SetPricingModel(row[COLUMN_PRICING_MODEL])
+	version.PriceTiersField = row[COLUMN_PRICE_TIERS]
+	version.SetFeatures(row[COLUMN_FEATURES])
+	version.SetCreatedAt(row[COLUMN_CREATED_AT])
+	version.SetUpdatedAt(row[COLUMN_UPDATED_AT])
+	return version
+}
+
+// memoryPlanVersionLatest returns the row of the latest version of the plan,
+// or nil if the plan has no versions
+func memoryPlanVersionLatest(rows []map[string]string, planID string) map[string]string {
+	var latest map[string]string
+	for _, row := range rows {
+		if row[COLUMN_PLAN_ID] != planID {
+			continue
+		}
+		if latest == nil || cast.ToInt(row[COLUMN_VERSION]) > cast.ToInt(latest[COLUMN_VERSION]) {
+			latest = row
+		}
+	}
+	return latest
+}
+
+// subscriptionExistingData returns the column values of the subscription, as
+// the SQL store writes them and NewSubscriptionFromExistingData reads them
+func subscriptionExistingData(subscription SubscriptionInterface) (map[string]string, error) {
+	metas, err := subscription.GetMetas()
+	if err != nil {
+		return nil, err
+	}
+	metasStr, err := memoryMetasJSON(metas)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		COLUMN_ID:                        subscription.GetID(),
+		COLUMN_STATUS:                    subscription.GetStatus(),
+		COLUMN_SUBSCRIBER_ID:             subscription.GetSubscriberID(),
+		COLUMN_PLAN_ID:                   subscription.GetPlanID(),
+		COLUMN_PLAN_VERSION_ID:           subscription.GetPlanVersionID(),
+		COLUMN_QUANTITY:                  strconv.Itoa(subscription.GetQuantity()),
+		COLUMN_PERIOD_START:              memoryDateTime(subscription.GetPeriodStart()),
+		COLUMN_PERIOD_END:                memoryDateTime(subscription.GetPeriodEnd()),
+		COLUMN_CANCEL_AT_PERIOD_END:      lo.Ternary(subscription.GetCancelAtPeriodEnd(), YES, NO),
+		COLUMN_CANCELLATION_REQUESTED_AT: memoryDateTime(subscription.GetCancellationRequestedAt()),
+		COLUMN_CANCELLATION_EFFECTIVE_AT: memoryDateTime(subscription.GetCancellationEffectiveAt()),
+		COLUMN_CANCELLATION_REASON:       subscription.GetCancellationReason(),
+		COLUMN_CANCELLATION_FEEDBACK:     subscription.GetCancellationFeedback(),
+		COLUMN_CANCELLED_BY:              subscription.GetCancelledBy(),
+		COLUMN_PAYMENT_METHOD_ID:         subscription.GetPaymentMethodID(),
+		COLUMN_CURRENCY:                  subscription.GetCurrency(),
+		COLUMN_PAUSED_AT:                 memoryDateTime(subscription.GetPausedAt()),
+		COLUMN_RESUME_AT:                 memoryDateTime(subscription.GetResumeAt()),
+		COLUMN_MEMO:                      subscription.GetMemo(),
+		COLUMN_METAS:                     metasStr,
+		COLUMN_CREATED_AT:                subscription.GetCreatedAtCarbon().ToDateTimeString(carbon.UTC),
+		COLUMN_UPDATED_AT:                subscription.GetUpdatedAtCarbon().ToDateTimeString(carbon.UTC),
+		COLUMN_SOFT_DELETED_AT:           subscription.GetSoftDeletedAtCarbon().ToDateTimeString(carbon.UTC),
+	}, nil
+}
+
+// memoryDateTime returns the date time in UTC, as the SQL store reads the
+// date columns back, so the rows compare and order as dates
+func memoryDateTime(value string) string {
+	return carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+}
+
+// memoryMetasJSON returns the JSON of the metas, or an empty string when
+// there are none, as the SQL store writes the metas column
+func memoryMetasJSON(metas map[string]string) (string, error) {
+	if metas == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(metas)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// memoryPlanRows returns copies of the plan rows matching the filters of
+// the query, in the order they were created
+func memoryPlanRows(rows []map[string]string, query PlanQueryInterface) []map[string]string {
+	matches := func(row map[string]string) bool {
+		if query.HasID() && row[COLUMN_ID] != query.ID() {
+			return false
+		}
+		if query.HasIDIn() && !lo.Contains(query.IDIn(), row[COLUMN_ID]) {
+			return false
+		}
+		if query.HasStatus() && row[COLUMN_STATUS] != query.Status() {
+			return false
+		}
+		if query.HasStatusIn() && !lo.Contains(query.StatusIn(), row[COLUMN_STATUS]) {
+			return false
+		}
+		if query.HasInterval() && row[COLUMN_INTERVAL] != query.Interval() {
+			return false
+		}
+		if query.HasIntervalIn() && !lo.Contains(query.IntervalIn(), row[COLUMN_INTERVAL]) {
+			return false
+		}
+		if query.HasStripePriceID() && row[COLUMN_STRIPE_PRICE_ID] != query.StripePriceID() {
+			return false
+		}
+		if query.HasType() && row[COLUMN_TYPE] != query.Type() {
+			return false
+		}
+		if query.HasMetaEquals() && !memoryRowMetaEquals(row, query.MetaEquals()) {
+			return false
+		}
+		if query.HasMetaHasKey() && !memoryRowMetaHasKey(row, query.MetaHasKey()) {
+			return false
+		}
+		return query.SoftDeletedIncluded() || !memoryRowSoftDeleted(row)
+	}
+
+	return memoryRowsFilter(rows, matches)
+}
+
+// memorySubscriptionRows returns copies of the subscription rows matching
+// the filters of the query, in the order they were created
+func memorySubscriptionRows(rows []map[string]string, query SubscriptionQueryInterface) []map[string]string {
+	matches := func(row map[string]string) bool {
+		if query.HasID() && row[COLUMN_ID] != query.ID() {
+			return false
+		}
+		if query.HasIDIn() && !lo.Contains(query.IDIn(), row[COLUMN_ID]) {
+			return false
+		}
+		if query.HasStatus() && row[COLUMN_STATUS] != query.Status() {
+			return false
+		}
+		if query.HasStatusIn() && !lo.Contains(query.StatusIn(), row[COLUMN_STATUS]) {
+			return false
+		}
+		if query.HasSubscriberID() && query.SubscriberID() != "" && row[COLUMN_SUBSCRIBER_ID] != query.SubscriberID() {
+			return false
+		}
+		if query.HasPlanID() && query.PlanID() != "" && row[COLUMN_PLAN_ID] != query.PlanID() {
+			return false
+		}
+		if query.HasResumeAtLte() && query.ResumeAtLte() != "" && row[COLUMN_RESUME_AT] > memoryDateTime(query.ResumeAtLte()) {
+			return false
+		}
+		if query.HasCancellationReason() && query.CancellationReason() != "" && row[COLUMN_CANCELLATION_REASON] != query.CancellationReason() {
+			return false
+		}
+		if query.HasCancellationRequestedAtGte() && query.CancellationRequestedAtGte() != "" && row[COLUMN_CANCELLATION_REQUESTED_AT] < memoryDateTime(query.CancellationRequestedAtGte()) {
+			return false
+		}
+		if query.HasCancellationRequestedAtLte() && query.CancellationRequestedAtLte() != "" && row[COLUMN_CANCELLATION_REQUESTED_AT] > memoryDateTime(query.CancellationRequestedAtLte()) {
+			return false
+		}
+		if query.HasMetaEquals() && !memoryRowMetaEquals(row, query.MetaEquals()) {
+			return false
+		}
+		if query.HasMetaHasKey() && !memoryRowMetaHasKey(row, query.MetaHasKey()) {
+			return false
+		}
+		return query.SoftDeletedIncluded() || !memoryRowSoftDeleted(row)
+	}
+
+	return memoryRowsFilter(rows, matches)
+}
+
+// memoryRowsFilter returns copies of the rows matching the filter, so the
+// rows can be ordered and read without the lock of the store
+func memoryRowsFilter(rows []map[string]string, matches func(row map[string]string) bool) []map[string]string {
+	filtered := []map[string]string{}
+	for _, row := range rows {
+		if matches(row) {
+			filtered = append(filtered, lo.Assign(row))
+		}
+	}
+	return filtered
+}
+
+// memoryRowSoftDeleted returns whether the row is soft deleted
+func memoryRowSoftDeleted(row map[string]string) bool {
+	return row[COLUMN_SOFT_DELETED_AT] <= carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+}
+
+// memoryRowMetas returns the metas of the row
+func memoryRowMetas(row map[string]string) map[string]string {
+	metas := map[string]string{}
+	if row[COLUMN_METAS] != "" {
+		_ = json.Unmarshal([]byte(row[COLUMN_METAS]), &metas)
+	}
+	return metas
+}
+
+// memoryRowMetaEquals returns whether the meta keys of the row have the values
+func memoryRowMetaEquals(row map[string]string, equals map[string]string) bool {
+	metas := memoryRowMetas(row)
+	for key, value := range equals {
+		if current, ok := metas[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryRowMetaHasKey returns whether the meta keys of the row are set
+func memoryRowMetaHasKey(row map[string]string, keys []string) bool {
+	metas := memoryRowMetas(row)
+	for _, key := range keys {
+		if _, ok := metas[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryRowsSort orders the rows by the column, descending unless the sort
+// order is asc. Integer columns are ordered as numbers. The columns of the
+// promoted meta keys, such as meta_tenant_id, order the rows with metas by
+// the value of the key, empty when it is not set, as the promoted columns of
+// the SQL store hold.
+func memoryRowsSort(rows []map[string]string, columns []string, orderBy string, sortOrder string) error {
+	value := func(row map[string]string) string {
+		return row[orderBy]
+	}
+
+	key, found := strings.CutPrefix(orderBy, metaColumnName(""))
+	if found && lo.Contains(columns, COLUMN_METAS) && metaColumnKeyPattern.MatchString(key) {
+		value = func(row map[string]string) string {
+			return memoryRowMetas(row)[key]
+		}
+	} else if !lo.Contains(columns, orderBy) {
+		return errors.New("subscriptionstore > memory store. unsupported order by column: " + orderBy)
+	}
+
+	descending := !strings.EqualFold(sortOrder, "asc")
+	numeric := lo.Contains(memoryIntegerColumns, orderBy)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := value(rows[i]), value(rows[j])
+		if descending {
+			a, b = b, a
+		}
+		if numeric {
+			return cast.ToInt(a) < cast.ToInt(b)
+		}
+		return a < b
+	})
+
+	return nil
+}
+
+// memoryRowsPage returns the rows skipping the offset, and at most limit
+// rows when the limit is set
+func memoryRowsPage(rows []map[string]string, limit int, offset int) []map[string]string {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return []map[string]string{}
+		}
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// memoryRowsCountBy counts the rows by the value of the column
+func memoryRowsCountBy(rows []map[string]string, column string) map[string]int64 {
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row[column]]++
+	}
+	return counts
+}
+
+// memoryRowIndex returns the index of the row with the id, or -1
+func memoryRowIndex(rows []map[string]string, id string) int {
+	_, index, _ := lo.FindIndexOf(rows, func(row map[string]string) bool {
+		return row[COLUMN_ID] == id
+	})
+	return index
+}
+
+// memoryRowsDelete returns the rows without the row with the id
+func memoryRowsDelete(rows []map[string]string, id string) []map[string]string {
+	return lo.Reject(rows, func(row map[string]string, _ int) bool {
+		return row[COLUMN_ID] == id
+	})
+}
+
+// memoryRowsUpdate replaces the row with the same id, keeping when it was
+// created, as the SQL store does not update the created_at column
+func memoryRowsUpdate(rows []map[string]string, row map[string]string) {
+	index := memoryRowIndex(rows, row[COLUMN_ID])
+	if index < 0 {
+		return
+	}
+	row[COLUMN_CREATED_AT] = rows[index][COLUMN_CREATED_AT]
+	rows[index] = row
+}
@@ -0,0 +1,174 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dracory/neat/contracts/database"
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	contractsschema "github.com/dracory/neat/contracts/database/schema"
+	"github.com/samber/lo"
+)
+
+// metaColumnKeyPattern matches the meta keys which can be promoted to
+// columns, so the column names need no quoting on any database
+var metaColumnKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// metaColumnName returns the column holding the promoted meta key
+func metaColumnName(key string) string {
+	return "meta_" + key
+}
+
+// metaColumnLength is the length of the promoted meta columns, which is as
+// long as MySQL can index with utf8mb4
+const metaColumnLength = 191
+
+// metaColumnsRow copies the values of the promoted meta keys to the row.
+// Missing keys are stored as empty values. Values longer than the columns
+// are rejected, rather than truncated or failing on some databases only.
+func metaColumnsRow(row map[string]any, keys []string, metas map[string]string) error {
+	for _, key := range keys {
+		if utf8.RuneCountInString(metas[key]) > metaColumnLength {
+			return errors.New("meta " + key + " is longer than " + strconv.Itoa(metaColumnLength) + " characters, the length of its column")
+		}
+		row[metaColumnName(key)] = metas[key]
+	}
+	return nil
+}
+
+// whereMetaEquals filters on the meta keys having the values, reading the
+// promoted columns, or else the JSON of the metas column
+func (st *storeImplementation) whereMetaEquals(q contractsorm.Query, promoted []string, equals map[string]string) contractsorm.Query {
+	keys := lo.Keys(equals)
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if lo.Contains(promoted, key) {
+			q = q.Where(metaColumnName(key)+" = ?", equals[key])
+			continue
+		}
+		expression, arg := st.metaValueExpression(key)
+		q = q.Where(expression+" = ?", arg, equals[key])
+	}
+
+	return q
+}
+
+// whereMetaHasKey filters on the meta keys being set, reading the promoted
+// columns, or else the JSON of the metas column. Promoted keys set to an
+// empty value count as not set.
+func (st *storeImplementation) whereMetaHasKey(q contractsorm.Query, promoted []string, keys []string) contractsorm.Query {
+	for _, key := range keys {
+		if lo.Contains(promoted, key) {
+			q = q.Where(metaColumnName(key)+" <> ?", "")
+			continue
+		}
+		expression, arg := st.metaValueExpression(key)
+		q = q.Where(expression+" IS NOT NULL", arg)
+	}
+
+	return q
+}
+
+// metaValueExpression returns the SQL expression reading the value of the
+// meta key from the metas column, and the argument of its placeholder. The
+// value is NULL when the key is not set. Rows without metas are stored with
+// an empty string, which is not valid JSON, so they are skipped explicitly.
+func (st *storeImplementation) metaValueExpression(key string) (string, string) {
+	switch st.query().Driver() {
+	case database.DriverPostgres:
+		return "(CASE WHEN " + COLUMN_METAS + " = '' THEN NULL ELSE CAST(" + COLUMN_METAS + " AS jsonb) ->> CAST(? AS TEXT) END)", key
+	case database.DriverMysql:
+		return "(CASE WHEN " + COLUMN_METAS + " = '' THEN NULL ELSE JSON_UNQUOTE(JSON_EXTRACT(" + COLUMN_METAS + ", ?)) END)", metaJSONPath(key)
+	case database.DriverSqlserver:
+		return "(CASE WHEN " + COLUMN_METAS + " = '' THEN NULL ELSE JSON_VALUE(" + COLUMN_METAS + ", ?) END)", metaJSONPath(key)
+	}
+	return "(CASE WHEN " + COLUMN_METAS + " = '' THEN NULL ELSE json_extract(" + COLUMN_METAS + ", ?) END)", metaJSONPath(key)
+}
+
+// metaJSONPath returns the JSON path of the meta key, quoted so keys with
+// dots or spaces address a single member
+func metaJSONPath(key string) string {
+	key = strings.ReplaceAll(key, `\`, `\\`)
+	key = strings.ReplaceAll(key, `"`, `\"`)
+	return `$."` + key + `"`
+}
+
+// metaColumnMigrations returns the migrations adding the columns of the
+// promoted meta keys of the table, one per key in the order of the options.
+// Promoted keys depend on the options of the store, so these migrations are
+// listed after the ones of every store, and a key promoted later is migrated
+// by the next MigrateUp. Demoting a key leaves its column in place.
+func metaColumnMigrations(prefix string, tableName string, keys []string) []migration {
+	return lo.Map(keys, func(key string, _ int) migration {
+		column := metaColumnName(key)
+		indexes := [][]string{{column}}
+
+		return migration{
+			id: prefix + "_" + key,
+			schema: func(st *storeImplementation) []migrationSchema {
+				return []migrationSchema{
+					{
+						table:   tableName,
+						columns: []string{column},
+						define: func(table contractsschema.Blueprint) {
+							table.String(column, metaColumnLength).Default("")
+						},
+						indexes: indexes,
+					},
+				}
+			},
+			data: func(st *storeImplementation) error {
+				return st.metaColumnsBackfill(tableName, []string{key})
+			},
+			down: func(st *storeImplementation) error {
+				if err := st.dropIndexes(tableName, indexes); err != nil {
+					return err
+				}
+				return st.dropColumns(tableName, []string{column})
+			},
+		}
+	})
+}
+
+// metaColumnsBackfill copies the values of the meta keys of the existing
+// rows to their newly added columns
+func (st *storeImplementation) metaColumnsBackfill(tableName string, keys []string) error {
+	type metasRow struct {
+		ID    string `db:"id"`
+		Metas string `db:"metas"`
+	}
+
+	var rows []metasRow
+	err := st.query().
+		Table(tableName).
+		Select(COLUMN_ID+", "+COLUMN_METAS).
+		Where(COLUMN_METAS+" <> ?", "").
+		Get(&rows)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		var metas map[string]string
+		if err := json.Unmarshal([]byte(r.Metas), &metas); err != nil {
+			return err
+		}
+
+		update := map[string]any{}
+		if err := metaColumnsRow(update, keys, metas); err != nil {
+			return errors.New(tableName + " " + r.ID + ": " + err.Error())
+		}
+
+		if _, err := st.query().Table(tableName).Where(COLUMN_ID+" = ?", r.ID).Update(update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package subscriptionstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStoreMetaFilters(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	create := func(subscriberID string, metas map[string]string) {
+		subscription := NewSubscription().
+			SetSubscriberID(subscriberID).
+			SetPlanID("plan_1").
+			SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+		if _, err := subscription.SetMetas(metas); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	create("user_1", map[string]string{"tenant_id": "acme", "crm_id": "c1"})
+	create("user_2", map[string]string{"tenant_id": "acme"})
+	create("user_3", map[string]string{"tenant_id": "globex", "crm.id": "c3"})
+	create("user_4", nil)
+
+	// Rows written before metas defaulted to an object
+	st := store.(*storeImplementation)
+	if _, err := st.db.Query().Table(st.subscriptionTableName).Where(COLUMN_SUBSCRIBER_ID+" = ?", "user_4").Update(map[string]any{COLUMN_METAS: ""}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		name     string
+		query    SubscriptionQueryInterface
+		expected int64
+	}{
+		{"equals", SubscriptionQuery().SetMetaEquals("tenant_id", "acme"), 2},
+		{"equals both", SubscriptionQuery().SetMetaEquals("tenant_id", "acme").SetMetaEquals("crm_id", "c1"), 1},
+		{"equals no match", SubscriptionQuery().SetMetaEquals("tenant_id", "initech"), 0},
+		{"has key", SubscriptionQuery().SetMetaHasKey("crm_id"), 1},
+		{"has key with dot", SubscriptionQuery().SetMetaHasKey("crm.id"), 1},
+		{"has key and equals", SubscriptionQuery().SetMetaHasKey("tenant_id").SetMetaEquals("tenant_id", "globex"), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := store.SubscriptionCount(ctx, tc.query)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if count != tc.expected {
+				t.Fatalf("expected %d subscriptions, got %d", tc.expected, count)
+			}
+		})
+	}
+
+	plan := NewPlan().SetTitle("Tenant Plan").SetStatus(PLAN_STATUS_ACTIVE)
+	if _, err := plan.SetMeta("tenant_id", "acme"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	plans, err := store.PlanList(ctx, PlanQuery().SetMetaEquals("tenant_id", "acme"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(plans) != 1 || plans[0].GetID() != plan.GetID() {
+		t.Fatalf("expected the tenant plan, got %d plans", len(plans))
+	}
+}
+
+func TestStoreMetaColumns(t *testing.T) {
+	db := initDB(":memory:")
+	store, err := NewStore(NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	existing := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if _, err := existing.SetMeta("tenant_id", "acme"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCreate(ctx, existing); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := NewStore(NewStoreOptions{
+		DB:                      db,
+		PlanTableName:           "plan_table",
+		SubscriptionTableName:   "subscription_table",
+		SubscriptionMetaColumns: []string{"Tenant-ID"},
+	}); err == nil {
+		t.Fatal("expected error for an invalid meta column key")
+	}
+
+	// Promoting the key later fills the column in for the existing rows
+	store, err = NewStore(NewStoreOptions{
+		DB:                      db,
+		PlanTableName:           "plan_table",
+		SubscriptionTableName:   "subscription_table",
+		SubscriptionMetaColumns: []string{"tenant_id"},
+		AutomigrateEnabled:      true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+	if !st.db.Schema().HasIndex(st.subscriptionTableName, indexName(st.subscriptionTableName, []string{"meta_tenant_id"})) {
+		t.Error("expected the promoted meta column to be indexed")
+	}
+
+	states, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if last := states[len(states)-1]; last.ID != "subscription_meta_column_tenant_id" || !last.Applied {
+		t.Errorf("expected the promoted meta column migration applied last, got %+v", last)
+	}
+
+	long := NewSubscription().
+		SetSubscriberID("user_3").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if _, err := long.SetMeta("tenant_id", strings.Repeat("a", metaColumnLength+1)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCreate(ctx, long); err == nil {
+		t.Error("expected error for a meta value longer than its column")
+	}
+
+	created := NewSubscription().
+		SetSubscriberID("user_2").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if _, err := created.SetMeta("tenant_id", "globex"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCreate(ctx, created); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for tenant, id := range map[string]string{"acme": existing.GetID(), "globex": created.GetID()} {
+		list, err := store.SubscriptionList(ctx, SubscriptionQuery().SetMetaEquals("tenant_id", tenant))
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if len(list) != 1 || list[0].GetID() != id {
+			t.Errorf("expected subscription %s for tenant %s, got %d", id, tenant, len(list))
+		}
+	}
+
+	// Updates keep the column in step with the metas
+	if _, err := created.DeleteMeta("tenant_id"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionUpdate(ctx, created); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	count, err := store.SubscriptionCount(ctx, SubscriptionQuery().SetMetaHasKey("tenant_id"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 subscription with a tenant, got %d", count)
+	}
+}
+
+func TestNewStoreMetaColumnsKeepsOptions(t *testing.T) {
+	// The plan keys have room for another key, which validating the keys of
+	// both tables must not write to
+	planMetaColumns := make([]string, 1, 2)
+	planMetaColumns[0] = "tenant_id"
+
+	_, err := NewStore(NewStoreOptions{
+		DB:                      initDB(":memory:"),
+		PlanTableName:           "plan_table",
+		SubscriptionTableName:   "subscription_table",
+		PlanMetaColumns:         planMetaColumns,
+		SubscriptionMetaColumns: []string{"crm_id"},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if spare := planMetaColumns[:2][1]; spare != "" {
+		t.Errorf("expected the plan meta columns left unchanged, got %q appended", spare)
+	}
+}
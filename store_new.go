@@ -6,17 +6,80 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"slices"
 
 	"github.com/dracory/neat"
+	"github.com/samber/lo"
 )
 
 // NewStoreOptions define the options for creating a new subscription store
 type NewStoreOptions struct {
 	PlanTableName         string
 	SubscriptionTableName string
-	DB                    *sql.DB
-	AutomigrateEnabled    bool
-	DebugEnabled          bool
+	// MigrationTableName is the table recording the applied schema migrations.
+	// Defaults to SubscriptionTableName + "_migrations".
+	MigrationTableName string
+	// DunningAttemptTableName is the table recording the retries of failed
+	// renewal charges. Defaults to SubscriptionTableName + "_dunning_attempts".
+	DunningAttemptTableName string
+	// DunningRetryDays are the days, counted from the first failed charge,
+	// on which a past due subscription is retried before it is cancelled.
+	// Defaults to 1, 3 and 7 days.
+	DunningRetryDays []int
+	// InvoiceTableName is the table of the invoices issued for past billing
+	// periods. Defaults to SubscriptionTableName + "_invoices".
+	InvoiceTableName string
+	// ProviderReferenceTableName is the table mapping plans, subscriptions and
+	// subscribers to the objects of the payment providers.
+	// Defaults to SubscriptionTableName + "_provider_references".
+	ProviderReferenceTableName string
+	// CouponTableName is the table of the coupons.
+	// Defaults to SubscriptionTableName + "_coupons".
+	CouponTableName string
+	// PromotionCodeTableName is the table of the codes redeeming the coupons.
+	// Defaults to SubscriptionTableName + "_promotion_codes".
+	PromotionCodeTableName string
+	// SubscriptionDiscountTableName is the table of the coupons applied to
+	// subscriptions. Defaults to SubscriptionTableName + "_discounts".
+	SubscriptionDiscountTableName string
+	// SubscriptionItemTableName is the table of the add-on plans of the
+	// subscriptions. Defaults to SubscriptionTableName + "_items".
+	SubscriptionItemTableName string
+	// SubscriptionScheduleTableName is the table of the scheduled phases of
+	// the subscriptions. Defaults to SubscriptionTableName + "_schedules".
+	SubscriptionScheduleTableName string
+	// SubscriberTableName is the table of the subscribers, the customers
+	// owning the subscriptions. Defaults to SubscriptionTableName + "_subscribers".
+	SubscriberTableName string
+	// PaymentMethodTableName is the table of the payment methods of the
+	// subscribers. Defaults to SubscriptionTableName + "_payment_methods".
+	PaymentMethodTableName string
+	// PlanVersionTableName is the table of the versions of the plans.
+	// Defaults to PlanTableName + "_versions".
+	PlanVersionTableName string
+	// PlanVersionMigrationTableName is the table of the scheduled moves of
+	// subscriptions to newer plan versions.
+	// Defaults to PlanTableName + "_version_migrations".
+	PlanVersionMigrationTableName string
+	// PlanMetaColumns are the meta keys of the plans copied to their own
+	// indexed columns, named "meta_" + key, so filtering on them does not
+	// read the JSON of every row. Keys are lowercase letters, digits and
+	// underscores, and their values up to 191 characters.
+	PlanMetaColumns []string
+	// SubscriptionMetaColumns are the meta keys of the subscriptions copied
+	// to their own indexed columns, as for PlanMetaColumns.
+	SubscriptionMetaColumns []string
+	// TaxRateResolver resolves the tax rate of the price quotes.
+	// Defaults to no tax.
+	TaxRateResolver TaxRateResolver
+	DB              *sql.DB
+	// ReadDBs are read replicas of DB. When set, PlanList, SubscriptionList
+	// and the plan and subscription counts and exists checks read from them
+	// in turn. Writes, and the reads made while writing, use DB. Use
+	// WithPrimaryDB to read from DB what was just written.
+	ReadDBs            []*sql.DB
+	AutomigrateEnabled bool
+	DebugEnabled       bool
 }
 
 // NewStore creates a new subscription store
@@ -33,6 +96,78 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		return nil, errors.New("subscription store: DB is required")
 	}
 
+	if lo.Contains(opts.ReadDBs, nil) {
+		return nil, errors.New("subscription store: ReadDBs cannot contain nil")
+	}
+
+	if opts.MigrationTableName == "" {
+		opts.MigrationTableName = opts.SubscriptionTableName + "_migrations"
+	}
+
+	if opts.DunningAttemptTableName == "" {
+		opts.DunningAttemptTableName = opts.SubscriptionTableName + "_dunning_attempts"
+	}
+
+	if opts.InvoiceTableName == "" {
+		opts.InvoiceTableName = opts.SubscriptionTableName + "_invoices"
+	}
+
+	if opts.ProviderReferenceTableName == "" {
+		opts.ProviderReferenceTableName = opts.SubscriptionTableName + "_provider_references"
+	}
+
+	if opts.CouponTableName == "" {
+		opts.CouponTableName = opts.SubscriptionTableName + "_coupons"
+	}
+
+	if opts.PromotionCodeTableName == "" {
+		opts.PromotionCodeTableName = opts.SubscriptionTableName + "_promotion_codes"
+	}
+
+	if opts.SubscriptionDiscountTableName == "" {
+		opts.SubscriptionDiscountTableName = opts.SubscriptionTableName + "_discounts"
+	}
+
+	if opts.SubscriptionItemTableName == "" {
+		opts.SubscriptionItemTableName = opts.SubscriptionTableName + "_items"
+	}
+
+	if opts.SubscriptionScheduleTableName == "" {
+		opts.SubscriptionScheduleTableName = opts.SubscriptionTableName + "_schedules"
+	}
+
+	if opts.SubscriberTableName == "" {
+		opts.SubscriberTableName = opts.SubscriptionTableName + "_subscribers"
+	}
+
+	if opts.PaymentMethodTableName == "" {
+		opts.PaymentMethodTableName = opts.SubscriptionTableName + "_payment_methods"
+	}
+
+	if opts.PlanVersionTableName == "" {
+		opts.PlanVersionTableName = opts.PlanTableName + "_versions"
+	}
+
+	if opts.PlanVersionMigrationTableName == "" {
+		opts.PlanVersionMigrationTableName = opts.PlanTableName + "_version_migrations"
+	}
+
+	if opts.DunningRetryDays == nil {
+		opts.DunningRetryDays = []int{1, 3, 7}
+	}
+
+	for i, days := range opts.DunningRetryDays {
+		if days <= 0 || (i > 0 && days <= opts.DunningRetryDays[i-1]) {
+			return nil, errors.New("subscription store: DunningRetryDays must be positive and ascending")
+		}
+	}
+
+	for _, key := range slices.Concat(opts.PlanMetaColumns, opts.SubscriptionMetaColumns) {
+		if !metaColumnKeyPattern.MatchString(key) {
+			return nil, errors.New("subscription store: meta column key is not valid: " + key)
+		}
+	}
+
 	neatDB, err := neat.NewFromSQLDB(opts.DB)
 	if err != nil {
 		return nil, err
@@ -40,12 +175,29 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	store := &storeImplementation{
-		planTableName:         opts.PlanTableName,
-		subscriptionTableName: opts.SubscriptionTableName,
-		db:                    neatDB,
-		automigrateEnabled:    opts.AutomigrateEnabled,
-		debugEnabled:          opts.DebugEnabled,
-		sqlLogger:             logger,
+		planTableName:                 opts.PlanTableName,
+		subscriptionTableName:         opts.SubscriptionTableName,
+		migrationTableName:            opts.MigrationTableName,
+		dunningAttemptTableName:       opts.DunningAttemptTableName,
+		dunningRetryDays:              opts.DunningRetryDays,
+		invoiceTableName:              opts.InvoiceTableName,
+		providerReferenceTableName:    opts.ProviderReferenceTableName,
+		couponTableName:               opts.CouponTableName,
+		promotionCodeTableName:        opts.PromotionCodeTableName,
+		subscriptionDiscountTableName: opts.SubscriptionDiscountTableName,
+		subscriptionItemTableName:     opts.SubscriptionItemTableName,
+		subscriptionScheduleTableName: opts.SubscriptionScheduleTableName,
+		subscriberTableName:           opts.SubscriberTableName,
+		paymentMethodTableName:        opts.PaymentMethodTableName,
+		planVersionTableName:          opts.PlanVersionTableName,
+		planVersionMigrationTableName: opts.PlanVersionMigrationTableName,
+		planMetaColumns:               opts.PlanMetaColumns,
+		subscriptionMetaColumns:       opts.SubscriptionMetaColumns,
+		taxRateResolver:               opts.TaxRateResolver,
+		db:                            neatDB,
+		automigrateEnabled:            opts.AutomigrateEnabled,
+		debugEnabled:                  opts.DebugEnabled,
+		sqlLogger:                     logger,
 	}
 
 	if store.automigrateEnabled {
@@ -54,5 +206,23 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		}
 	}
 
-	return store, nil
+	if len(opts.ReadDBs) == 0 {
+		return store, nil
+	}
+
+	readers := make([]*storeImplementation, 0, len(opts.ReadDBs))
+	for _, readDB := range opts.ReadDBs {
+		neatReadDB, err := neat.NewFromSQLDB(readDB)
+		if err != nil {
+			return nil, err
+		}
+		reader := *store
+		reader.db = neatReadDB
+		readers = append(readers, &reader)
+	}
+
+	return &replicaStore{
+		storeImplementation: store,
+		readers:             readers,
+	}, nil
 }
@@ -0,0 +1,328 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// last4Pattern matches the last four digits of a card or account number
+var last4Pattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// PaymentMethodCreate registers a payment method of a subscriber. Set its ID
+// to the ID of the payment method at the provider, the one stored on the
+// subscriptions charged to it.
+//
+// Marking it as the default unmarks the other payment methods of the
+// subscriber, in one transaction with the create.
+func (st *storeImplementation) PaymentMethodCreate(ctx context.Context, paymentMethod PaymentMethodInterface) error {
+	if paymentMethod == nil {
+		return errors.New("subscriptionstore > payment method create. payment method cannot be nil")
+	}
+	if paymentMethod.GetID() == "" {
+		return errors.New("subscriptionstore > payment method create. id cannot be empty")
+	}
+	if err := paymentMethodValidate(paymentMethod); err != nil {
+		return errors.New("subscriptionstore > payment method create. " + err.Error())
+	}
+
+	if paymentMethod.GetCreatedAt() == "" {
+		paymentMethod.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if paymentMethod.GetUpdatedAt() == "" {
+		paymentMethod.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	return st.transaction(func(txStore *storeImplementation) error {
+		return txStore.paymentMethodCreate(ctx, paymentMethod)
+	})
+}
+
+// paymentMethodCreate writes the payment method, within the transaction of
+// PaymentMethodCreate, so the default is unmarked only if it is written
+func (st *storeImplementation) paymentMethodCreate(ctx context.Context, paymentMethod PaymentMethodInterface) error {
+	existing, err := st.PaymentMethodFindByID(ctx, paymentMethod.GetID())
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("subscriptionstore > payment method create. payment method already exists")
+	}
+
+	if paymentMethod.GetDefault() {
+		if err := st.paymentMethodClearDefault(paymentMethod); err != nil {
+			return err
+		}
+	}
+
+	row := st.paymentMethodRow(paymentMethod)
+	row[COLUMN_ID] = paymentMethod.GetID()
+	row[COLUMN_CREATED_AT] = dateTimeValue(paymentMethod.GetCreatedAtCarbon())
+
+	return st.query().Table(st.paymentMethodTableName).Create(row)
+}
+
+// PaymentMethodDeleteByID removes a payment method from the registry. The
+// subscriptions charged to it keep its ID.
+func (st *storeImplementation) PaymentMethodDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("payment method id is empty")
+	}
+	_, err := st.query().Table(st.paymentMethodTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	return err
+}
+
+// PaymentMethodExpiring returns the payment methods expiring between the
+// given dates, i.e. with an expiry month ending in the range, soonest first.
+// Payment methods without an expiry date are left out.
+func (st *storeImplementation) PaymentMethodExpiring(ctx context.Context, from string, to string) ([]PaymentMethodInterface, error) {
+	fromCarbon, toCarbon, err := analyticsRange(from, to)
+	if err != nil {
+		return nil, errors.New("subscriptionstore > payment method expiring. " + err.Error())
+	}
+
+	return st.PaymentMethodList(ctx, PaymentMethodQuery().
+		SetExpiresAtGte(fromCarbon.ToDateTimeString(carbon.UTC)).
+		SetExpiresAtLte(toCarbon.ToDateTimeString(carbon.UTC)).
+		SetOrderBy(COLUMN_EXPIRES_AT).
+		SetSortOrder("asc"))
+}
+
+// PaymentMethodFindByID finds a payment method by id
+func (st *storeImplementation) PaymentMethodFindByID(ctx context.Context, id string) (PaymentMethodInterface, error) {
+	if id == "" {
+		return nil, errors.New("payment method id is empty")
+	}
+	list, err := st.PaymentMethodList(ctx, PaymentMethodQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// PaymentMethodList retrieves a list of payment methods
+func (st *storeImplementation) PaymentMethodList(ctx context.Context, query PaymentMethodQueryInterface) ([]PaymentMethodInterface, error) {
+	if query == nil {
+		return []PaymentMethodInterface{}, errors.New("at payment method list > payment method query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PaymentMethodInterface{}, err
+	}
+
+	q := st.buildPaymentMethodQuery(query)
+
+	type paymentMethodRow struct {
+		ID           string    `db:"id"`
+		SubscriberID string    `db:"subscriber_id"`
+		Provider     string    `db:"provider"`
+		Type         string    `db:"type"`
+		Brand        string    `db:"brand"`
+		Last4        string    `db:"last4"`
+		ExpMonth     int       `db:"exp_month"`
+		ExpYear      int       `db:"exp_year"`
+		IsDefault    string    `db:"is_default"`
+		CreatedAt    time.Time `db:"created_at"`
+		UpdatedAt    time.Time `db:"updated_at"`
+	}
+
+	var rows []paymentMethodRow
+	if err := q.Table(st.paymentMethodTableName).Get(&rows); err != nil {
+		return []PaymentMethodInterface{}, err
+	}
+
+	list := make([]PaymentMethodInterface, 0, len(rows))
+	for _, r := range rows {
+		p := &paymentMethodImplementation{}
+		p.SetID(r.ID)
+		p.SetSubscriberID(r.SubscriberID)
+		p.SetProvider(r.Provider)
+		p.SetType(r.Type)
+		p.SetBrand(r.Brand)
+		p.SetLast4(r.Last4)
+		p.SetExpMonth(r.ExpMonth)
+		p.SetExpYear(r.ExpYear)
+		p.SetDefault(r.IsDefault == YES)
+		p.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		p.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, p)
+	}
+
+	return list, nil
+}
+
+// PaymentMethodUpdate updates a payment method, i.e. its expiry date after
+// the card was renewed. Marking it as the default unmarks the other payment
+// methods of the subscriber. A payment method stays with its subscriber, so
+// changes to the subscriber id are rejected.
+func (st *storeImplementation) PaymentMethodUpdate(ctx context.Context, paymentMethod PaymentMethodInterface) error {
+	if paymentMethod == nil {
+		return errors.New("subscriptionstore > payment method update. payment method cannot be nil")
+	}
+	if err := paymentMethodValidate(paymentMethod); err != nil {
+		return errors.New("subscriptionstore > payment method update. " + err.Error())
+	}
+
+	paymentMethod.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return st.transaction(func(txStore *storeImplementation) error {
+		return txStore.paymentMethodUpdate(ctx, paymentMethod)
+	})
+}
+
+// paymentMethodUpdate writes the payment method, within the transaction of
+// PaymentMethodUpdate
+func (st *storeImplementation) paymentMethodUpdate(ctx context.Context, paymentMethod PaymentMethodInterface) error {
+	existing, err := st.PaymentMethodFindByID(ctx, paymentMethod.GetID())
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return errors.New("subscriptionstore > payment method update. payment method not found")
+	}
+	if existing.GetSubscriberID() != paymentMethod.GetSubscriberID() {
+		return errors.New("subscriptionstore > payment method update. subscriber id cannot be changed")
+	}
+
+	if paymentMethod.GetDefault() {
+		if err := st.paymentMethodClearDefault(paymentMethod); err != nil {
+			return err
+		}
+	}
+
+	_, err = st.query().Table(st.paymentMethodTableName).Where(COLUMN_ID+" = ?", paymentMethod.GetID()).Update(st.paymentMethodRow(paymentMethod))
+	return err
+}
+
+// paymentMethodRow returns the columns of the payment method, which are
+// written on both create and update
+func (st *storeImplementation) paymentMethodRow(paymentMethod PaymentMethodInterface) map[string]any {
+	return map[string]any{
+		COLUMN_SUBSCRIBER_ID: paymentMethod.GetSubscriberID(),
+		COLUMN_PROVIDER:      paymentMethod.GetProvider(),
+		COLUMN_TYPE:          paymentMethod.GetType(),
+		COLUMN_BRAND:         paymentMethod.GetBrand(),
+		COLUMN_LAST4:         paymentMethod.GetLast4(),
+		COLUMN_EXP_MONTH:     paymentMethod.GetExpMonth(),
+		COLUMN_EXP_YEAR:      paymentMethod.GetExpYear(),
+		COLUMN_EXPIRES_AT:    dateTimeValue(paymentMethod.GetExpiresAtCarbon()),
+		COLUMN_IS_DEFAULT:    lo.Ternary(paymentMethod.GetDefault(), YES, NO),
+		COLUMN_UPDATED_AT:    dateTimeValue(paymentMethod.GetUpdatedAtCarbon()),
+	}
+}
+
+// paymentMethodClearDefault unmarks the other default payment methods of the
+// subscriber of the payment method
+func (st *storeImplementation) paymentMethodClearDefault(paymentMethod PaymentMethodInterface) error {
+	_, err := st.query().Table(st.paymentMethodTableName).
+		Where(COLUMN_SUBSCRIBER_ID+" = ?", paymentMethod.GetSubscriberID()).
+		Where(COLUMN_ID+" <> ?", paymentMethod.GetID()).
+		Where(COLUMN_IS_DEFAULT+" = ?", YES).
+		Update(map[string]any{COLUMN_IS_DEFAULT: NO})
+	return err
+}
+
+// paymentMethodDefault returns the default payment method of the
+// subscriber, or nil if it has none
+func (st *storeImplementation) paymentMethodDefault(ctx context.Context, subscriberID string) (PaymentMethodInterface, error) {
+	list, err := st.PaymentMethodList(ctx, PaymentMethodQuery().SetSubscriberID(subscriberID))
+	if err != nil {
+		return nil, err
+	}
+	paymentMethod, found := lo.Find(list, func(paymentMethod PaymentMethodInterface) bool {
+		return paymentMethod.GetDefault()
+	})
+	if !found {
+		return nil, nil
+	}
+	return paymentMethod, nil
+}
+
+// paymentMethodCheckOwner returns an error if the payment method is
+// registered to another subscriber than the given one. Payment method IDs
+// which are not registered are opaque references to the provider, and are
+// not checked.
+func (st *storeImplementation) paymentMethodCheckOwner(ctx context.Context, subscriberID string, paymentMethodID string) error {
+	if paymentMethodID == "" {
+		return nil
+	}
+
+	paymentMethod, err := st.PaymentMethodFindByID(ctx, paymentMethodID)
+	if err != nil {
+		return err
+	}
+	if paymentMethod != nil && paymentMethod.GetSubscriberID() != subscriberID {
+		return errors.New("payment method " + paymentMethodID + " does not belong to subscriber " + subscriberID)
+	}
+
+	return nil
+}
+
+// paymentMethodValidate checks the fields of a payment method
+func paymentMethodValidate(paymentMethod PaymentMethodInterface) error {
+	if paymentMethod.GetSubscriberID() == "" {
+		return errors.New("subscriber id cannot be empty")
+	}
+	if paymentMethod.GetType() == "" {
+		return errors.New("type cannot be empty")
+	}
+	if paymentMethod.GetLast4() != "" && !last4Pattern.MatchString(paymentMethod.GetLast4()) {
+		return errors.New("last4 must be 4 digits")
+	}
+	if paymentMethod.GetExpMonth() < 0 || paymentMethod.GetExpMonth() > 12 {
+		return errors.New("exp month must be between 1 and 12")
+	}
+	if (paymentMethod.GetExpMonth() == 0) != (paymentMethod.GetExpYear() == 0) {
+		return errors.New("exp month and exp year must be set together")
+	}
+	if paymentMethod.GetExpYear() < 0 {
+		return errors.New("exp year cannot be negative")
+	}
+	return nil
+}
+
+// buildPaymentMethodQuery builds a neat query from the payment method query interface.
+func (st *storeImplementation) buildPaymentMethodQuery(query PaymentMethodQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriberID() && query.SubscriberID() != "" {
+		q = q.Where(COLUMN_SUBSCRIBER_ID+" = ?", query.SubscriberID())
+	}
+	if query.HasProvider() && query.Provider() != "" {
+		q = q.Where(COLUMN_PROVIDER+" = ?", query.Provider())
+	}
+	if query.HasType() && query.Type() != "" {
+		q = q.Where(COLUMN_TYPE+" = ?", query.Type())
+	}
+	if query.HasExpiresAtGte() && query.ExpiresAtGte() != "" {
+		q = q.Where(COLUMN_EXPIRES_AT+" >= ?", dateTimeValue(carbon.Parse(query.ExpiresAtGte(), carbon.UTC)))
+	}
+	if query.HasExpiresAtLte() && query.ExpiresAtLte() != "" {
+		q = q.Where(COLUMN_EXPIRES_AT+" <= ?", dateTimeValue(carbon.Parse(query.ExpiresAtLte(), carbon.UTC)))
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
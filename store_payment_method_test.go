@@ -0,0 +1,192 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStorePaymentMethodCreateFindUpdateDelete(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	visa := NewPaymentMethod().
+		SetID("pm_visa").
+		SetSubscriberID("user_1").
+		SetProvider(PAYMENT_PROVIDER_STRIPE).
+		SetBrand("visa").
+		SetLast4("4242").
+		SetExpMonth(3).
+		SetExpYear(2030).
+		SetDefault(true)
+	if err := store.PaymentMethodCreate(ctx, visa); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.PaymentMethodCreate(ctx, NewPaymentMethod().SetID("pm_visa").SetSubscriberID("user_1")); err == nil {
+		t.Error("expected error for an existing payment method")
+	}
+
+	invalid := []PaymentMethodInterface{
+		NewPaymentMethod(),
+		NewPaymentMethod().SetSubscriberID("user_1").SetLast4("42"),
+		NewPaymentMethod().SetSubscriberID("user_1").SetExpMonth(13).SetExpYear(2030),
+		NewPaymentMethod().SetSubscriberID("user_1").SetExpMonth(3),
+	}
+	for _, paymentMethod := range invalid {
+		if err := store.PaymentMethodCreate(ctx, paymentMethod); err == nil {
+			t.Errorf("expected error for invalid payment method %+v", paymentMethod)
+		}
+	}
+
+	// A new default unmarks the previous one
+	sepa := NewPaymentMethod().
+		SetID("pm_sepa").
+		SetSubscriberID("user_1").
+		SetType(PAYMENT_METHOD_TYPE_SEPA_DEBIT).
+		SetLast4("3000").
+		SetDefault(true)
+	if err := store.PaymentMethodCreate(ctx, sepa); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.PaymentMethodFindByID(ctx, "pm_visa")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetBrand() != "visa" || found.GetExpYear() != 2030 {
+		t.Fatalf("expected the created payment method, got %v", found)
+	}
+	if found.GetDefault() {
+		t.Error("expected the previous default to be unmarked")
+	}
+
+	found.SetExpYear(2032).SetDefault(true)
+	if err := store.PaymentMethodUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.PaymentMethodList(ctx, PaymentMethodQuery().SetSubscriberID("user_1").SetOrderBy(COLUMN_ID).SetSortOrder("asc"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 payment methods, got %d", len(list))
+	}
+	if list[0].GetID() != "pm_sepa" || list[0].GetDefault() {
+		t.Errorf("expected pm_sepa no longer the default")
+	}
+	if list[1].GetID() != "pm_visa" || !list[1].GetDefault() || list[1].GetExpYear() != 2032 {
+		t.Errorf("expected pm_visa the default until 2032, got %v / %d", list[1].GetDefault(), list[1].GetExpYear())
+	}
+
+	// A payment method stays with its subscriber
+	found.SetSubscriberID("user_2")
+	if err := store.PaymentMethodUpdate(ctx, found); err == nil {
+		t.Error("expected error for a change of subscriber")
+	}
+	if err := store.PaymentMethodUpdate(ctx, NewPaymentMethod().SetID("pm_missing").SetSubscriberID("user_1")); err == nil {
+		t.Error("expected error for a missing payment method")
+	}
+	moved, err := store.PaymentMethodFindByID(ctx, "pm_visa")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if moved.GetSubscriberID() != "user_1" {
+		t.Errorf("expected pm_visa to stay with user_1, got %s", moved.GetSubscriberID())
+	}
+
+	if err := store.PaymentMethodDeleteByID(ctx, "pm_sepa"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	deleted, err := store.PaymentMethodFindByID(ctx, "pm_sepa")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if deleted != nil {
+		t.Error("expected the payment method to be deleted")
+	}
+}
+
+func TestStorePaymentMethodExpiring(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	paymentMethods := []PaymentMethodInterface{
+		NewPaymentMethod().SetID("pm_april").SetSubscriberID("user_1").SetExpMonth(4).SetExpYear(2025),
+		NewPaymentMethod().SetID("pm_march").SetSubscriberID("user_2").SetExpMonth(3).SetExpYear(2025),
+		NewPaymentMethod().SetID("pm_later").SetSubscriberID("user_3").SetExpMonth(1).SetExpYear(2027),
+		NewPaymentMethod().SetID("pm_sepa").SetSubscriberID("user_4").SetType(PAYMENT_METHOD_TYPE_SEPA_DEBIT),
+	}
+	for _, paymentMethod := range paymentMethods {
+		if err := store.PaymentMethodCreate(ctx, paymentMethod); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	expiring, err := store.PaymentMethodExpiring(ctx, "2025-03-01 00:00:00", "2025-04-30 23:59:59")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(expiring) != 2 {
+		t.Fatalf("expected 2 expiring payment methods, got %d", len(expiring))
+	}
+	if expiring[0].GetID() != "pm_march" || expiring[1].GetID() != "pm_april" {
+		t.Errorf("expected the soonest first, got %s, %s", expiring[0].GetID(), expiring[1].GetID())
+	}
+
+	if _, err := store.PaymentMethodExpiring(ctx, "2025-05-01 00:00:00", "2025-04-01 00:00:00"); err == nil {
+		t.Error("expected error for a range ending before it starts")
+	}
+}
+
+func TestStorePaymentMethodBelongsToSubscriber(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Basic").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_USD).SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	paymentMethod := NewPaymentMethod().SetID("pm_1").SetSubscriberID("user_1").SetDefault(true)
+	if err := store.PaymentMethodCreate(ctx, paymentMethod); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The default payment method of the subscriber is used
+	subscription := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID())
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription.GetPaymentMethodID() != "pm_1" {
+		t.Errorf("expected the default payment method pm_1, got %s", subscription.GetPaymentMethodID())
+	}
+
+	other := NewSubscription().SetSubscriberID("user_2").SetPlanID(plan.GetID()).SetPaymentMethodID("pm_1")
+	if err := store.SubscriptionCreate(ctx, other); err == nil {
+		t.Error("expected error for the payment method of another subscriber")
+	}
+
+	// Unregistered payment methods are not checked
+	other.SetPaymentMethodID("pm_unregistered")
+	if err := store.SubscriptionCreate(ctx, other); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	other.SetPaymentMethodID("pm_1")
+	if err := store.SubscriptionUpdate(ctx, other); err == nil {
+		t.Error("expected error on update for the payment method of another subscriber")
+	}
+
+	if err := store.SubscriberCreate(ctx, NewSubscriber().SetID("user_2").SetPaymentMethodID("pm_1")); err == nil {
+		t.Error("expected error for a subscriber with the payment method of another subscriber")
+	}
+}
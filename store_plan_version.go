@@ -0,0 +1,354 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+)
+
+// PlanVersionFindByID finds a plan version by id
+func (st *storeImplementation) PlanVersionFindByID(ctx context.Context, id string) (PlanVersionInterface, error) {
+	if id == "" {
+		return nil, errors.New("plan version id is empty")
+	}
+	list, err := st.PlanVersionList(ctx, PlanVersionQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// PlanVersionList retrieves a list of plan versions
+func (st *storeImplementation) PlanVersionList(ctx context.Context, query PlanVersionQueryInterface) ([]PlanVersionInterface, error) {
+	if query == nil {
+		return []PlanVersionInterface{}, errors.New("at plan version list > plan version query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PlanVersionInterface{}, err
+	}
+
+	q := st.buildPlanVersionQuery(query)
+
+	type planVersionRow struct {
+		ID           string    `db:"id"`
+		PlanID       string    `db:"plan_id"`
+		Version      int       `db:"version"`
+		Interval     string    `db:"interval"`
+		Currency     string    `db:"currency"`
+		Price        string    `db:"price"`
+		PricingModel string    `db:"pricing_model"`
+		PriceTiers   string    `db:"price_tiers"`
+		Features     string    `db:"features"`
+		CreatedAt    time.Time `db:"created_at"`
+		UpdatedAt    time.Time `db:"updated_at"`
+	}
+
+	var rows []planVersionRow
+	if err := q.Table(st.planVersionTableName).Get(&rows); err != nil {
+		return []PlanVersionInterface{}, err
+	}
+
+	list := make([]PlanVersionInterface, 0, len(rows))
+	for _, r := range rows {
+		v := &planVersionImplementation{}
+		v.SetID(r.ID)
+		v.SetPlanID(r.PlanID)
+		v.SetVersion(r.Version)
+		v.SetInterval(r.Interval)
+		v.SetCurrency(r.Currency)
+		v.SetPrice(r.Price)
+		v.SetPricingModel(r.PricingModel)
+		v.PriceTiersField = r.PriceTiers
+		v.SetFeatures(r.Features)
+		v.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		v.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, v)
+	}
+
+	return list, nil
+}
+
+// SubscriptionPlan returns the plan of the subscription as of the version
+// the subscription is pinned to. The plan keeps its id, title and other
+// descriptive fields, while its price, currency, interval, pricing model and
+// features are those of the version.
+func (st *storeImplementation) SubscriptionPlan(ctx context.Context, subscriptionID string) (PlanInterface, error) {
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > subscription plan. subscription not found")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > subscription plan. plan not found")
+	}
+
+	return plan, nil
+}
+
+// subscriptionPlan returns the plan of the subscription as of its pinned
+// version, or nil if the plan is not found. Subscriptions which are not
+// pinned, or pinned to a version of another plan, get the current plan.
+func (st *storeImplementation) subscriptionPlan(ctx context.Context, subscription SubscriptionInterface) (PlanInterface, error) {
+	plan, err := st.PlanFindByID(ctx, subscription.GetPlanID())
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil || subscription.GetPlanVersionID() == "" {
+		return plan, nil
+	}
+
+	version, err := st.PlanVersionFindByID(ctx, subscription.GetPlanVersionID())
+	if err != nil {
+		return nil, err
+	}
+
+	return planAtPinnedVersion(plan, version)
+}
+
+// planAtPinnedVersion returns the plan as of the version a subscription is
+// pinned to, or the plan itself if the version is nil or of another plan
+func planAtPinnedVersion(plan PlanInterface, version PlanVersionInterface) (PlanInterface, error) {
+	if plan == nil || version == nil || version.GetPlanID() != plan.GetID() {
+		return plan, nil
+	}
+
+	return planAtVersion(plan, version)
+}
+
+// subscriptionPinPlanVersion pins the subscription to the latest version of
+// its plan, unless it is already pinned to a version of the plan
+func (st *storeImplementation) subscriptionPinPlanVersion(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription.GetPlanID() == "" {
+		return nil
+	}
+
+	if subscription.GetPlanVersionID() != "" {
+		version, err := st.PlanVersionFindByID(ctx, subscription.GetPlanVersionID())
+		if err != nil {
+			return err
+		}
+		if version != nil && version.GetPlanID() == subscription.GetPlanID() {
+			return nil
+		}
+	}
+
+	latest, err := st.planVersionLatest(ctx, subscription.GetPlanID())
+	if err != nil {
+		return err
+	}
+
+	if latest == nil {
+		subscription.SetPlanVersionID("")
+	} else {
+		subscription.SetPlanVersionID(latest.GetID())
+	}
+
+	return nil
+}
+
+// planVersionLatest returns the latest version of the plan, or nil if it
+// has none
+func (st *storeImplementation) planVersionLatest(ctx context.Context, planID string) (PlanVersionInterface, error) {
+	list, err := st.PlanVersionList(ctx, PlanVersionQuery().
+		SetPlanID(planID).
+		SetOrderBy(COLUMN_VERSION).
+		SetSortOrder("desc").
+		SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// planVersionSync creates a new version of the plan if its billed fields
+// differ from its latest version, and returns the latest version
+func (st *storeImplementation) planVersionSync(ctx context.Context, plan PlanInterface) (PlanVersionInterface, error) {
+	latest, err := st.planVersionLatest(ctx, plan.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := planVersionNext(plan, latest)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return latest, nil
+	}
+
+	tiers, err := version.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+	tiersStr, err := priceTiersJSON(tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	row := map[string]any{
+		COLUMN_ID:            version.GetID(),
+		COLUMN_PLAN_ID:       version.GetPlanID(),
+		COLUMN_VERSION:       version.GetVersion(),
+		COLUMN_INTERVAL:      version.GetInterval(),
+		COLUMN_CURRENCY:      version.GetCurrency(),
+		COLUMN_PRICE:         version.GetPrice(),
+		COLUMN_PRICING_MODEL: version.GetPricingModel(),
+		COLUMN_PRICE_TIERS:   tiersStr,
+		COLUMN_FEATURES:      version.GetFeatures(),
+		COLUMN_CREATED_AT:    dateTimeValue(version.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:    dateTimeValue(version.GetUpdatedAtCarbon()),
+	}
+
+	if err := st.query().Table(st.planVersionTableName).Create(row); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// planVersionNext returns the version following the latest version with the
+// billed fields of the plan, or nil if the latest version matches the plan
+func planVersionNext(plan PlanInterface, latest PlanVersionInterface) (PlanVersionInterface, error) {
+	matches, err := planVersionMatches(plan, latest)
+	if err != nil {
+		return nil, err
+	}
+	if matches {
+		return nil, nil
+	}
+
+	tiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+
+	version := NewPlanVersion().
+		SetPlanID(plan.GetID()).
+		SetInterval(plan.GetInterval()).
+		SetCurrency(plan.GetCurrency()).
+		SetPrice(plan.GetPrice()).
+		SetPricingModel(plan.GetPricingModel()).
+		SetFeatures(plan.GetFeatures())
+	if latest != nil {
+		version.SetVersion(latest.GetVersion() + 1)
+	}
+	if _, err := version.SetPriceTiers(tiers); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// planVersionMatches returns true if the billed fields of the plan are those
+// of the version. A nil version matches no plan.
+func planVersionMatches(plan PlanInterface, version PlanVersionInterface) (bool, error) {
+	if version == nil {
+		return false, nil
+	}
+
+	planTiers, err := plan.GetPriceTiers()
+	if err != nil {
+		return false, err
+	}
+	planTiersJSON, err := priceTiersJSON(planTiers)
+	if err != nil {
+		return false, err
+	}
+
+	versionTiers, err := version.GetPriceTiers()
+	if err != nil {
+		return false, err
+	}
+	versionTiersJSON, err := priceTiersJSON(versionTiers)
+	if err != nil {
+		return false, err
+	}
+
+	return plan.GetInterval() == version.GetInterval() &&
+		plan.GetCurrency() == version.GetCurrency() &&
+		plan.GetPrice() == version.GetPrice() &&
+		plan.GetPricingModel() == version.GetPricingModel() &&
+		planTiersJSON == versionTiersJSON &&
+		plan.GetFeatures() == version.GetFeatures(), nil
+}
+
+// planAtVersion returns a copy of the plan with the billed fields of the
+// version
+func planAtVersion(plan PlanInterface, version PlanVersionInterface) (PlanInterface, error) {
+	metas, err := plan.GetMetas()
+	if err != nil {
+		return nil, err
+	}
+	tiers, err := version.GetPriceTiers()
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := NewPlan().
+		SetID(plan.GetID()).
+		SetType(plan.GetType()).
+		SetStatus(plan.GetStatus()).
+		SetTitle(plan.GetTitle()).
+		SetDescription(plan.GetDescription()).
+		SetStripePriceID(plan.GetStripePriceID()).
+		SetMemo(plan.GetMemo()).
+		SetCreatedAt(plan.GetCreatedAt()).
+		SetUpdatedAt(plan.GetUpdatedAt()).
+		SetSoftDeletedAt(plan.GetSoftDeletedAt()).
+		SetInterval(version.GetInterval()).
+		SetCurrency(version.GetCurrency()).
+		SetPrice(version.GetPrice()).
+		SetPricingModel(version.GetPricingModel()).
+		SetFeatures(version.GetFeatures())
+
+	if _, err := versioned.SetPriceTiers(tiers); err != nil {
+		return nil, err
+	}
+	if _, err := versioned.SetMetas(metas); err != nil {
+		return nil, err
+	}
+
+	return versioned, nil
+}
+
+// buildPlanVersionQuery builds a neat query from the plan version query interface.
+func (st *storeImplementation) buildPlanVersionQuery(query PlanVersionQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasPlanID() && query.PlanID() != "" {
+		q = q.Where(COLUMN_PLAN_ID+" = ?", query.PlanID())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
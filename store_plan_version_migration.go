@@ -0,0 +1,301 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// PlanVersionMigrationCreate schedules the move of the subscriptions of a
+// plan to a version of the plan.
+//
+// An empty ToVersionID targets the latest version of the plan. The cohort
+// can be narrowed to the subscriptions pinned to FromVersionID, and to the
+// subscriptions created before SubscribedBefore.
+func (st *storeImplementation) PlanVersionMigrationCreate(ctx context.Context, migration PlanVersionMigrationInterface) error {
+	if migration == nil {
+		return errors.New("subscriptionstore > plan version migration create. migration cannot be nil")
+	}
+	if migration.GetPlanID() == "" {
+		return errors.New("subscriptionstore > plan version migration create. plan id cannot be empty")
+	}
+	if migration.GetScheduledAt() == MAX_DATETIME || migration.GetScheduledAtCarbon().IsInvalid() {
+		return errors.New("subscriptionstore > plan version migration create. scheduled at must be a valid date")
+	}
+	if migration.GetSubscribedBeforeCarbon().IsInvalid() {
+		return errors.New("subscriptionstore > plan version migration create. subscribed before must be a valid date")
+	}
+
+	plan, err := st.PlanFindByID(ctx, migration.GetPlanID())
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return errors.New("subscriptionstore > plan version migration create. plan not found")
+	}
+
+	if migration.GetToVersionID() == "" {
+		latest, err := st.planVersionLatest(ctx, plan.GetID())
+		if err != nil {
+			return err
+		}
+		if latest == nil {
+			return errors.New("subscriptionstore > plan version migration create. plan has no versions")
+		}
+		migration.SetToVersionID(latest.GetID())
+	}
+
+	if err := st.planVersionBelongsTo(ctx, migration.GetToVersionID(), plan.GetID()); err != nil {
+		return errors.New("subscriptionstore > plan version migration create. to version " + err.Error())
+	}
+
+	if migration.GetFromVersionID() != "" {
+		if migration.GetFromVersionID() == migration.GetToVersionID() {
+			return errors.New("subscriptionstore > plan version migration create. from version is the to version")
+		}
+		if err := st.planVersionBelongsTo(ctx, migration.GetFromVersionID(), plan.GetID()); err != nil {
+			return errors.New("subscriptionstore > plan version migration create. from version " + err.Error())
+		}
+	}
+
+	migration.SetStatus(PLAN_VERSION_MIGRATION_STATUS_SCHEDULED)
+	migration.SetMigratedCount(0)
+	migration.SetCompletedAt(MAX_DATETIME)
+
+	if migration.GetCreatedAt() == "" {
+		migration.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if migration.GetUpdatedAt() == "" {
+		migration.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := st.planVersionMigrationRow(migration)
+	row[COLUMN_ID] = migration.GetID()
+	row[COLUMN_PLAN_ID] = migration.GetPlanID()
+	row[COLUMN_FROM_VERSION_ID] = migration.GetFromVersionID()
+	row[COLUMN_TO_VERSION_ID] = migration.GetToVersionID()
+	row[COLUMN_SUBSCRIBED_BEFORE] = dateTimeValue(migration.GetSubscribedBeforeCarbon())
+	row[COLUMN_SCHEDULED_AT] = dateTimeValue(migration.GetScheduledAtCarbon())
+	row[COLUMN_CREATED_AT] = dateTimeValue(migration.GetCreatedAtCarbon())
+
+	return st.query().Table(st.planVersionMigrationTableName).Create(row)
+}
+
+// PlanVersionMigrationCancel cancels a scheduled plan version migration
+func (st *storeImplementation) PlanVersionMigrationCancel(ctx context.Context, id string) error {
+	list, err := st.PlanVersionMigrationList(ctx, PlanVersionMigrationQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return errors.New("subscriptionstore > plan version migration cancel. migration not found")
+	}
+
+	migration := list[0]
+	if migration.GetStatus() != PLAN_VERSION_MIGRATION_STATUS_SCHEDULED {
+		return errors.New("subscriptionstore > plan version migration cancel. migration is " + migration.GetStatus())
+	}
+
+	migration.SetStatus(PLAN_VERSION_MIGRATION_STATUS_CANCELLED)
+	return st.planVersionMigrationUpdate(ctx, migration)
+}
+
+// PlanVersionMigrationList retrieves a list of plan version migrations
+func (st *storeImplementation) PlanVersionMigrationList(ctx context.Context, query PlanVersionMigrationQueryInterface) ([]PlanVersionMigrationInterface, error) {
+	if query == nil {
+		return []PlanVersionMigrationInterface{}, errors.New("at plan version migration list > plan version migration query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []PlanVersionMigrationInterface{}, err
+	}
+
+	q := st.buildPlanVersionMigrationQuery(query)
+
+	type planVersionMigrationRow struct {
+		ID               string    `db:"id"`
+		PlanID           string    `db:"plan_id"`
+		FromVersionID    string    `db:"from_version_id"`
+		ToVersionID      string    `db:"to_version_id"`
+		SubscribedBefore time.Time `db:"subscribed_before"`
+		ScheduledAt      time.Time `db:"scheduled_at"`
+		Status           string    `db:"status"`
+		MigratedCount    int       `db:"migrated_count"`
+		CompletedAt      time.Time `db:"completed_at"`
+		CreatedAt        time.Time `db:"created_at"`
+		UpdatedAt        time.Time `db:"updated_at"`
+	}
+
+	var rows []planVersionMigrationRow
+	if err := q.Table(st.planVersionMigrationTableName).Get(&rows); err != nil {
+		return []PlanVersionMigrationInterface{}, err
+	}
+
+	list := make([]PlanVersionMigrationInterface, 0, len(rows))
+	for _, r := range rows {
+		m := &planVersionMigrationImplementation{}
+		m.SetID(r.ID)
+		m.SetPlanID(r.PlanID)
+		m.SetFromVersionID(r.FromVersionID)
+		m.SetToVersionID(r.ToVersionID)
+		m.SetSubscribedBefore(carbon.CreateFromStdTime(r.SubscribedBefore, carbon.UTC).ToDateTimeString(carbon.UTC))
+		m.SetScheduledAt(carbon.CreateFromStdTime(r.ScheduledAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		m.SetStatus(r.Status)
+		m.SetMigratedCount(r.MigratedCount)
+		m.SetCompletedAt(carbon.CreateFromStdTime(r.CompletedAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		m.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		m.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, m)
+	}
+
+	return list, nil
+}
+
+// PlanVersionMigrationRunDue moves the subscriptions of the scheduled
+// migrations which are due at now to the target versions, and returns the
+// completed migrations. Cancelled subscriptions are not moved.
+//
+// Each migration is run in a transaction. A migration failing, e.g. on a
+// subscription which can no longer be updated, moves none of its
+// subscriptions and stays scheduled, and its error is returned joined with
+// those of the other failing migrations.
+func (st *storeImplementation) PlanVersionMigrationRunDue(ctx context.Context, now string) ([]PlanVersionMigrationInterface, error) {
+	nowCarbon := carbon.Parse(now, carbon.UTC)
+	if nowCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > plan version migration run due. now is not a valid date")
+	}
+
+	list, err := st.PlanVersionMigrationList(ctx, PlanVersionMigrationQuery().
+		SetStatus(PLAN_VERSION_MIGRATION_STATUS_SCHEDULED).
+		SetScheduledAtLte(nowCarbon.ToDateTimeString(carbon.UTC)).
+		SetOrderBy(COLUMN_SCHEDULED_AT).
+		SetSortOrder("asc"))
+	if err != nil {
+		return nil, err
+	}
+
+	// A migration failing is rolled back and left scheduled, so it is run
+	// again, and does not hold back the other migrations
+	completed := []PlanVersionMigrationInterface{}
+	var errs []error
+	for _, migration := range list {
+		err := st.transaction(func(txStore *storeImplementation) error {
+			return txStore.planVersionMigrationRun(ctx, migration, nowCarbon)
+		})
+		if err != nil {
+			errs = append(errs, errors.New("subscriptionstore > plan version migration run due. migration "+migration.GetID()+": "+err.Error()))
+			continue
+		}
+		completed = append(completed, migration)
+	}
+
+	return completed, errors.Join(errs...)
+}
+
+// planVersionMigrationRun pins the cohort of the migration to its target
+// version and marks the migration completed, within the transaction of
+// PlanVersionMigrationRunDue
+func (st *storeImplementation) planVersionMigrationRun(ctx context.Context, migration PlanVersionMigrationInterface, now *carbon.Carbon) error {
+	subscriptions, err := st.SubscriptionList(ctx, SubscriptionQuery().SetPlanID(migration.GetPlanID()))
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	for _, subscription := range subscriptions {
+		if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+			continue
+		}
+		if subscription.GetPlanVersionID() == migration.GetToVersionID() {
+			continue
+		}
+		if migration.GetFromVersionID() != "" && subscription.GetPlanVersionID() != migration.GetFromVersionID() {
+			continue
+		}
+		if migration.GetSubscribedBefore() != MAX_DATETIME && !subscription.GetCreatedAtCarbon().Lt(migration.GetSubscribedBeforeCarbon()) {
+			continue
+		}
+
+		subscription.SetPlanVersionID(migration.GetToVersionID())
+		if err := st.SubscriptionUpdate(ctx, subscription); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	migration.SetStatus(PLAN_VERSION_MIGRATION_STATUS_COMPLETED)
+	migration.SetMigratedCount(migrated)
+	migration.SetCompletedAt(now.ToDateTimeString(carbon.UTC))
+
+	return st.planVersionMigrationUpdate(ctx, migration)
+}
+
+// planVersionMigrationUpdate updates the progress of the migration
+func (st *storeImplementation) planVersionMigrationUpdate(ctx context.Context, migration PlanVersionMigrationInterface) error {
+	migration.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	_, err := st.query().Table(st.planVersionMigrationTableName).Where(COLUMN_ID+" = ?", migration.GetID()).Update(st.planVersionMigrationRow(migration))
+	return err
+}
+
+// planVersionMigrationRow returns the columns of the migration, which are
+// written on both create and update
+func (st *storeImplementation) planVersionMigrationRow(migration PlanVersionMigrationInterface) map[string]any {
+	return map[string]any{
+		COLUMN_STATUS:         migration.GetStatus(),
+		COLUMN_MIGRATED_COUNT: migration.GetMigratedCount(),
+		COLUMN_COMPLETED_AT:   dateTimeValue(migration.GetCompletedAtCarbon()),
+		COLUMN_UPDATED_AT:     dateTimeValue(migration.GetUpdatedAtCarbon()),
+	}
+}
+
+// planVersionBelongsTo checks that the version exists and is a version of
+// the plan
+func (st *storeImplementation) planVersionBelongsTo(ctx context.Context, versionID string, planID string) error {
+	version, err := st.PlanVersionFindByID(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		return errors.New("not found")
+	}
+	if version.GetPlanID() != planID {
+		return errors.New("is not a version of the plan")
+	}
+	return nil
+}
+
+// buildPlanVersionMigrationQuery builds a neat query from the plan version migration query interface.
+func (st *storeImplementation) buildPlanVersionMigrationQuery(query PlanVersionMigrationQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasPlanID() && query.PlanID() != "" {
+		q = q.Where(COLUMN_PLAN_ID+" = ?", query.PlanID())
+	}
+	if query.HasStatus() && query.Status() != "" {
+		q = q.Where(COLUMN_STATUS+" = ?", query.Status())
+	}
+	if query.HasScheduledAtLte() && query.ScheduledAtLte() != "" {
+		q = q.Where(COLUMN_SCHEDULED_AT+" <= ?", dateTimeValue(carbon.Parse(query.ScheduledAtLte(), carbon.UTC)))
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,362 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStorePlanVersionsGrandfatherSubscribers(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("19.99").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY).SetFeatures("reports")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	early := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, early); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	versions, err := store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID(plan.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 1 || versions[0].GetVersion() != 1 {
+		t.Fatalf("expected version 1 of the plan, got %d versions", len(versions))
+	}
+	v1 := versions[0]
+	if early.GetPlanVersionID() != v1.GetID() {
+		t.Fatalf("expected the subscription pinned to %s, got %s", v1.GetID(), early.GetPlanVersionID())
+	}
+
+	// Descriptive changes do not create a version
+	plan.SetTitle("Pro Plus")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	plan.SetPrice("29.99").SetFeatures("reports,exports")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	versions, err = store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID(plan.GetID()).SetOrderBy(COLUMN_VERSION).SetSortOrder("asc"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 2 || versions[1].GetVersion() != 2 || versions[1].GetPrice() != "29.99" {
+		t.Fatalf("expected version 2 at 29.99, got %d versions", len(versions))
+	}
+	v2 := versions[1]
+
+	// Existing subscribers keep the price and features they signed up on
+	pinned, err := store.SubscriptionPlan(ctx, early.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if pinned.GetPrice() != "19.99" || pinned.GetFeatures() != "reports" || pinned.GetTitle() != "Pro Plus" {
+		t.Errorf("unexpected pinned plan %s %s %s", pinned.GetTitle(), pinned.GetPrice(), pinned.GetFeatures())
+	}
+	price, err := store.SubscriptionPriceForPeriod(ctx, early.GetID(), "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Total != "19.99" {
+		t.Errorf("expected the grandfathered price 19.99, got %s", price.Total)
+	}
+
+	// New subscribers sign up on the latest version
+	late := NewSubscription().SetSubscriberID("user_2").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, late); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if late.GetPlanVersionID() != v2.GetID() {
+		t.Fatalf("expected the new subscription pinned to %s, got %s", v2.GetID(), late.GetPlanVersionID())
+	}
+	price, err = store.SubscriptionPriceForPeriod(ctx, late.GetID(), "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Total != "29.99" {
+		t.Errorf("expected the current price 29.99, got %s", price.Total)
+	}
+
+	// Changing plans pins the latest version of the new plan
+	other := NewPlan().SetTitle("Team").SetPrice("49.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, other); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	late.SetPlanID(other.GetID())
+	if err := store.SubscriptionUpdate(ctx, late); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	otherPlan, err := store.SubscriptionPlan(ctx, late.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if otherPlan.GetPrice() != "49.00" || late.GetPlanVersionID() == v2.GetID() {
+		t.Errorf("expected the subscription pinned to the new plan, got %s at %s", late.GetPlanVersionID(), otherPlan.GetPrice())
+	}
+}
+
+func TestStorePlanVersionMigrations(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("19.99").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	veteran := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetCreatedAt("2024-01-01 00:00:00")
+	recent := NewSubscription().SetSubscriberID("user_2").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetCreatedAt("2025-02-01 00:00:00")
+	cancelled := NewSubscription().SetSubscriberID("user_3").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_CANCELLED).
+		SetCreatedAt("2024-01-01 00:00:00")
+	for _, s := range []SubscriptionInterface{veteran, recent, cancelled} {
+		if err := store.SubscriptionCreate(ctx, s); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	v1 := veteran.GetPlanVersionID()
+
+	plan.SetPrice("29.99")
+	if err := store.PlanUpdate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	invalid := []PlanVersionMigrationInterface{
+		NewPlanVersionMigration().SetScheduledAt("2025-06-01 00:00:00"),
+		NewPlanVersionMigration().SetPlanID("missing").SetScheduledAt("2025-06-01 00:00:00"),
+		NewPlanVersionMigration().SetPlanID(plan.GetID()),
+		NewPlanVersionMigration().SetPlanID(plan.GetID()).SetScheduledAt("2025-06-01 00:00:00").SetToVersionID("missing"),
+		NewPlanVersionMigration().SetPlanID(plan.GetID()).SetScheduledAt("2025-06-01 00:00:00").SetToVersionID(v1).SetFromVersionID(v1),
+	}
+	for i, m := range invalid {
+		if err := store.PlanVersionMigrationCreate(ctx, m); err == nil {
+			t.Errorf("expected error for invalid migration %d", i)
+		}
+	}
+
+	// Subscribers before 2025 move to the latest version in June
+	migration := NewPlanVersionMigration().
+		SetPlanID(plan.GetID()).
+		SetFromVersionID(v1).
+		SetSubscribedBefore("2025-01-01 00:00:00").
+		SetScheduledAt("2025-06-01 00:00:00")
+	if err := store.PlanVersionMigrationCreate(ctx, migration); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if migration.GetToVersionID() == "" || migration.GetToVersionID() == v1 {
+		t.Fatalf("expected the latest version as target, got %s", migration.GetToVersionID())
+	}
+
+	due, err := store.PlanVersionMigrationRunDue(ctx, "2025-05-31 23:59:59")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no migration due, got %d", len(due))
+	}
+
+	due, err = store.PlanVersionMigrationRunDue(ctx, "2025-06-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 1 || due[0].GetStatus() != PLAN_VERSION_MIGRATION_STATUS_COMPLETED || due[0].GetMigratedCount() != 1 {
+		t.Fatalf("expected 1 completed migration of 1 subscription, got %d", len(due))
+	}
+
+	expected := map[string]string{
+		veteran.GetID():   migration.GetToVersionID(),
+		recent.GetID():    v1,
+		cancelled.GetID(): v1,
+	}
+	for id, versionID := range expected {
+		found, err := store.SubscriptionFindByID(ctx, id)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if found.GetPlanVersionID() != versionID {
+			t.Errorf("expected subscription %s on %s, got %s", id, versionID, found.GetPlanVersionID())
+		}
+	}
+
+	price, err := store.SubscriptionPriceForPeriod(ctx, veteran.GetID(), "2025-06-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Total != "29.99" {
+		t.Errorf("expected the migrated price 29.99, got %s", price.Total)
+	}
+
+	if err := store.PlanVersionMigrationCancel(ctx, migration.GetID()); err == nil {
+		t.Error("expected error cancelling a completed migration")
+	}
+
+	// A cancelled migration never runs
+	later := NewPlanVersionMigration().SetPlanID(plan.GetID()).SetScheduledAt("2025-07-01 00:00:00")
+	if err := store.PlanVersionMigrationCreate(ctx, later); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.PlanVersionMigrationCancel(ctx, later.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	due, err = store.PlanVersionMigrationRunDue(ctx, "2025-08-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("expected the cancelled migration not to run, got %d", len(due))
+	}
+
+	list, err := store.PlanVersionMigrationList(ctx, PlanVersionMigrationQuery().SetPlanID(plan.GetID()).SetStatus(PLAN_VERSION_MIGRATION_STATUS_CANCELLED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 1 || list[0].GetID() != later.GetID() {
+		t.Errorf("expected the cancelled migration to be listed, got %d", len(list))
+	}
+}
+
+func TestStorePlanVersionMigrationRunDueRollsBackFailures(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	failingPlan := NewPlan().SetTitle("Pro").SetPrice("19.99").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	plan := NewPlan().SetTitle("Team").SetPrice("49.99").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	for _, p := range []PlanInterface{failingPlan, plan} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	moved := NewSubscription().SetSubscriberID("user_1").SetPlanID(failingPlan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetCreatedAt("2024-01-01 00:00:00")
+	failing := NewSubscription().SetSubscriberID("user_2").SetPlanID(failingPlan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPaymentMethodID("pm_other").SetCreatedAt("2024-02-01 00:00:00")
+	other := NewSubscription().SetSubscriberID("user_3").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	for _, s := range []SubscriptionInterface{moved, failing, other} {
+		if err := store.SubscriptionCreate(ctx, s); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	v1 := moved.GetPlanVersionID()
+
+	// The payment method of the failing subscription now belongs to
+	// another subscriber, so the subscription can no longer be updated
+	if err := store.PaymentMethodCreate(ctx, NewPaymentMethod().SetID("pm_other").SetSubscriberID("user_4")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	migrations := []PlanVersionMigrationInterface{}
+	for _, p := range []PlanInterface{failingPlan, plan} {
+		p.SetPrice("59.99")
+		if err := store.PlanUpdate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		migration := NewPlanVersionMigration().SetPlanID(p.GetID()).SetScheduledAt("2025-06-01 00:00:00")
+		if err := store.PlanVersionMigrationCreate(ctx, migration); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	due, err := store.PlanVersionMigrationRunDue(ctx, "2025-06-01 00:00:00")
+	if err == nil {
+		t.Error("expected the error of the failing migration")
+	}
+	if len(due) != 1 || due[0].GetID() != migrations[1].GetID() {
+		t.Fatalf("expected the other migration to complete, got %d", len(due))
+	}
+
+	// The failing migration moved none of its subscriptions, and stays
+	// scheduled
+	found, err := store.SubscriptionFindByID(ctx, moved.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetPlanVersionID() != v1 {
+		t.Errorf("expected subscription %s to stay on %s, got %s", moved.GetID(), v1, found.GetPlanVersionID())
+	}
+	scheduled, err := store.PlanVersionMigrationList(ctx, PlanVersionMigrationQuery().SetStatus(PLAN_VERSION_MIGRATION_STATUS_SCHEDULED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(scheduled) != 1 || scheduled[0].GetID() != migrations[0].GetID() {
+		t.Errorf("expected the failing migration to stay scheduled, got %d", len(scheduled))
+	}
+}
+
+func TestStorePlanUpdateWritesVersionInTransaction(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("19.99").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// A concurrent update creating the same version number is rejected
+	st := store.(*storeImplementation)
+	duplicate := NewPlanVersion().SetPlanID(plan.GetID()).SetVersion(1)
+	err = st.query().Table(st.planVersionTableName).Create(map[string]any{
+		COLUMN_ID:            duplicate.GetID(),
+		COLUMN_PLAN_ID:       duplicate.GetPlanID(),
+		COLUMN_VERSION:       duplicate.GetVersion(),
+		COLUMN_INTERVAL:      duplicate.GetInterval(),
+		COLUMN_CURRENCY:      duplicate.GetCurrency(),
+		COLUMN_PRICE:         duplicate.GetPrice(),
+		COLUMN_PRICING_MODEL: duplicate.GetPricingModel(),
+		COLUMN_PRICE_TIERS:   "",
+		COLUMN_FEATURES:      duplicate.GetFeatures(),
+		COLUMN_CREATED_AT:    dateTimeValue(duplicate.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:    dateTimeValue(duplicate.GetUpdatedAtCarbon()),
+	})
+	if err == nil {
+		t.Error("expected the unique index to reject a second version 1")
+	}
+
+	// The plan write is rolled back with its version
+	err = st.transaction(func(txStore *storeImplementation) error {
+		plan.SetPrice("29.99")
+		if err := txStore.PlanUpdate(ctx, plan); err != nil {
+			return err
+		}
+		return errors.New("version write failed")
+	})
+	if err == nil {
+		t.Fatal("expected the transaction error")
+	}
+
+	found, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetPrice() != "19.99" {
+		t.Errorf("expected the plan update rolled back, got price %s", found.GetPrice())
+	}
+
+	versions, err := store.PlanVersionList(ctx, PlanVersionQuery().SetPlanID(plan.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("expected the version rolled back, got %d versions", len(versions))
+	}
+}
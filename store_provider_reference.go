@@ -0,0 +1,178 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// ProviderReferenceCreate creates a new provider reference
+func (st *storeImplementation) ProviderReferenceCreate(ctx context.Context, reference ProviderReferenceInterface) error {
+	if reference == nil {
+		return errors.New("subscriptionstore > provider reference create. reference cannot be nil")
+	}
+	if reference.GetProvider() == "" {
+		return errors.New("subscriptionstore > provider reference create. provider cannot be empty")
+	}
+	if reference.GetObjectType() == "" {
+		return errors.New("subscriptionstore > provider reference create. object type cannot be empty")
+	}
+	if reference.GetLocalID() == "" || reference.GetExternalID() == "" {
+		return errors.New("subscriptionstore > provider reference create. local id and external id cannot be empty")
+	}
+
+	// The unique indexes are checked up front too, as neat creates them as
+	// plain indexes on SQLite
+	existing, err := st.ProviderReferenceFindByLocalID(ctx, reference.GetProvider(), reference.GetObjectType(), reference.GetLocalID())
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		existing, err = st.ProviderReferenceFindByExternalID(ctx, reference.GetProvider(), reference.GetObjectType(), reference.GetExternalID())
+		if err != nil {
+			return err
+		}
+	}
+	if existing != nil {
+		return errors.New("subscriptionstore > provider reference create. reference already exists")
+	}
+
+	if reference.GetCreatedAt() == "" {
+		reference.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if reference.GetUpdatedAt() == "" {
+		reference.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := map[string]any{
+		COLUMN_ID:          reference.GetID(),
+		COLUMN_PROVIDER:    reference.GetProvider(),
+		COLUMN_OBJECT_TYPE: reference.GetObjectType(),
+		COLUMN_LOCAL_ID:    reference.GetLocalID(),
+		COLUMN_EXTERNAL_ID: reference.GetExternalID(),
+		COLUMN_CREATED_AT:  dateTimeValue(reference.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:  dateTimeValue(reference.GetUpdatedAtCarbon()),
+	}
+
+	return st.query().Table(st.providerReferenceTableName).Create(row)
+}
+
+// ProviderReferenceDeleteByID deletes a provider reference by id
+func (st *storeImplementation) ProviderReferenceDeleteByID(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("provider reference id is empty")
+	}
+	_, err := st.query().Table(st.providerReferenceTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	return err
+}
+
+// ProviderReferenceFindByExternalID finds the reference of the given
+// provider object, i.e. the local plan of a Stripe price
+func (st *storeImplementation) ProviderReferenceFindByExternalID(ctx context.Context, provider string, objectType string, externalID string) (ProviderReferenceInterface, error) {
+	return st.providerReferenceFindOne(ctx, ProviderReferenceQuery().
+		SetProvider(provider).
+		SetObjectType(objectType).
+		SetExternalID(externalID))
+}
+
+// ProviderReferenceFindByLocalID finds the reference of the given local
+// object at the provider, i.e. the Stripe customer of a subscriber
+func (st *storeImplementation) ProviderReferenceFindByLocalID(ctx context.Context, provider string, objectType string, localID string) (ProviderReferenceInterface, error) {
+	return st.providerReferenceFindOne(ctx, ProviderReferenceQuery().
+		SetProvider(provider).
+		SetObjectType(objectType).
+		SetLocalID(localID))
+}
+
+// ProviderReferenceList retrieves a list of provider references
+func (st *storeImplementation) ProviderReferenceList(ctx context.Context, query ProviderReferenceQueryInterface) ([]ProviderReferenceInterface, error) {
+	if query == nil {
+		return []ProviderReferenceInterface{}, errors.New("at provider reference list > provider reference query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []ProviderReferenceInterface{}, err
+	}
+
+	q := st.buildProviderReferenceQuery(query)
+
+	type providerReferenceRow struct {
+		ID         string    `db:"id"`
+		Provider   string    `db:"provider"`
+		ObjectType string    `db:"object_type"`
+		LocalID    string    `db:"local_id"`
+		ExternalID string    `db:"external_id"`
+		CreatedAt  time.Time `db:"created_at"`
+		UpdatedAt  time.Time `db:"updated_at"`
+	}
+
+	var rows []providerReferenceRow
+	if err := q.Table(st.providerReferenceTableName).Get(&rows); err != nil {
+		return []ProviderReferenceInterface{}, err
+	}
+
+	list := make([]ProviderReferenceInterface, 0, len(rows))
+	for _, r := range rows {
+		p := &providerReferenceImplementation{}
+		p.SetID(r.ID)
+		p.SetProvider(r.Provider)
+		p.SetObjectType(r.ObjectType)
+		p.SetLocalID(r.LocalID)
+		p.SetExternalID(r.ExternalID)
+		p.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		p.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, p)
+	}
+
+	return list, nil
+}
+
+// providerReferenceFindOne returns the first reference matching the query, or nil
+func (st *storeImplementation) providerReferenceFindOne(ctx context.Context, query ProviderReferenceQueryInterface) (ProviderReferenceInterface, error) {
+	list, err := st.ProviderReferenceList(ctx, query.SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// buildProviderReferenceQuery builds a neat query from the provider reference query interface.
+func (st *storeImplementation) buildProviderReferenceQuery(query ProviderReferenceQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasProvider() && query.Provider() != "" {
+		q = q.Where(COLUMN_PROVIDER+" = ?", query.Provider())
+	}
+	if query.HasObjectType() && query.ObjectType() != "" {
+		q = q.Where(COLUMN_OBJECT_TYPE+" = ?", query.ObjectType())
+	}
+	if query.HasLocalID() && query.LocalID() != "" {
+		q = q.Where(COLUMN_LOCAL_ID+" = ?", query.LocalID())
+	}
+	if query.HasExternalID() && query.ExternalID() != "" {
+		q = q.Where(COLUMN_EXTERNAL_ID+" = ?", query.ExternalID())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
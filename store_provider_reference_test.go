@@ -0,0 +1,95 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreProviderReference(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	references := []ProviderReferenceInterface{
+		NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE).SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).SetLocalID("plan_1").SetExternalID("price_123"),
+		NewProviderReference().SetProvider(PAYMENT_PROVIDER_PADDLE).SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).SetLocalID("plan_1").SetExternalID("pri_456"),
+		NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE).SetObjectType(PROVIDER_OBJECT_TYPE_CUSTOMER).SetLocalID("user_1").SetExternalID("cus_789"),
+	}
+	for _, reference := range references {
+		if err := store.ProviderReferenceCreate(ctx, reference); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	found, err := store.ProviderReferenceFindByLocalID(ctx, PAYMENT_PROVIDER_PADDLE, PROVIDER_OBJECT_TYPE_PRICE, "plan_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetExternalID() != "pri_456" {
+		t.Fatalf("expected the paddle price pri_456, got %v", found)
+	}
+
+	found, err = store.ProviderReferenceFindByExternalID(ctx, PAYMENT_PROVIDER_STRIPE, PROVIDER_OBJECT_TYPE_PRICE, "price_123")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetLocalID() != "plan_1" {
+		t.Fatalf("expected the local plan plan_1, got %v", found)
+	}
+
+	missing, err := store.ProviderReferenceFindByExternalID(ctx, PAYMENT_PROVIDER_PAYPAL, PROVIDER_OBJECT_TYPE_PRICE, "price_123")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if missing != nil {
+		t.Fatal("expected no paypal reference")
+	}
+
+	list, err := store.ProviderReferenceList(ctx, ProviderReferenceQuery().SetLocalID("plan_1"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 references of plan_1, got %d", len(list))
+	}
+
+	if err := store.ProviderReferenceDeleteByID(ctx, references[0].GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found, err = store.ProviderReferenceFindByExternalID(ctx, PAYMENT_PROVIDER_STRIPE, PROVIDER_OBJECT_TYPE_PRICE, "price_123")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found != nil {
+		t.Fatal("expected the reference to be deleted")
+	}
+}
+
+func TestStoreProviderReferenceCreateRejectsDuplicates(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	reference := NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE).SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).SetLocalID("plan_1").SetExternalID("price_123")
+	if err := store.ProviderReferenceCreate(ctx, reference); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	sameLocal := NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE).SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).SetLocalID("plan_1").SetExternalID("price_other")
+	if err := store.ProviderReferenceCreate(ctx, sameLocal); err == nil {
+		t.Error("expected error for a second external object of the same local object")
+	}
+
+	sameExternal := NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE).SetObjectType(PROVIDER_OBJECT_TYPE_PRICE).SetLocalID("plan_2").SetExternalID("price_123")
+	if err := store.ProviderReferenceCreate(ctx, sameExternal); err == nil {
+		t.Error("expected error for a second local object of the same external object")
+	}
+
+	if err := store.ProviderReferenceCreate(ctx, NewProviderReference().SetProvider(PAYMENT_PROVIDER_STRIPE)); err == nil {
+		t.Error("expected error for an incomplete reference")
+	}
+}
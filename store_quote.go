@@ -0,0 +1,59 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+)
+
+// QuotePrice returns the price of a single unit of the plan for the
+// subscriber, with the tax rate of the store's TaxRateResolver. Without a
+// resolver no tax is charged. A subscriber given by ID only takes its
+// country, region and tax ID from its subscriber record, if it has one.
+func (st *storeImplementation) QuotePrice(ctx context.Context, planID string, subscriber SubscriberContext) (PriceQuote, error) {
+	if subscriber.SubscriberID != "" && subscriber.Country == "" && subscriber.Region == "" && subscriber.TaxID == "" {
+		stored, err := st.SubscriberFindByID(ctx, subscriber.SubscriberID)
+		if err != nil {
+			return PriceQuote{}, err
+		}
+		if stored != nil {
+			subscriber.Country = stored.GetCountry()
+			subscriber.Region = stored.GetRegion()
+			subscriber.TaxID = stored.GetTaxID()
+		}
+	}
+
+	plan, err := st.PlanFindByID(ctx, planID)
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	if plan == nil {
+		return PriceQuote{}, errors.New("subscriptionstore > quote price. plan not found")
+	}
+
+	rate := TaxRate{}
+	if st.taxRateResolver != nil {
+		rate, err = st.taxRateResolver.TaxRate(ctx, plan, subscriber)
+		if err != nil {
+			return PriceQuote{}, err
+		}
+	}
+
+	amount, err := CalculateAmount(plan, 1)
+	if err != nil {
+		return PriceQuote{}, errors.New("subscriptionstore > quote price. " + err.Error())
+	}
+
+	net, tax, gross, err := CalculateTax(amount, rate)
+	if err != nil {
+		return PriceQuote{}, errors.New("subscriptionstore > quote price. " + err.Error())
+	}
+
+	return PriceQuote{
+		PlanID:   plan.GetID(),
+		Currency: plan.GetCurrency(),
+		Net:      net,
+		Tax:      tax,
+		Gross:    gross,
+		TaxRate:  rate,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreQuotePrice(t *testing.T) {
+	resolver := CountryTaxRates{
+		Rates: map[string]TaxRate{
+			"DE": {Name: "MwSt", Percent: "19"},
+		},
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+		TaxRateResolver:       resolver,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("10.00").SetCurrency(CURRENCY_EUR)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	quote, err := store.QuotePrice(ctx, plan.GetID(), SubscriberContext{SubscriberID: "user_1", Country: "DE"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if quote.Net != "10.00" || quote.Tax != "1.90" || quote.Gross != "11.90" || quote.Currency != CURRENCY_EUR {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+	if quote.TaxRate.Name != "MwSt" || quote.PlanID != plan.GetID() {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+
+	untaxed, err := store.QuotePrice(ctx, plan.GetID(), SubscriberContext{Country: "CH"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if untaxed.Gross != "10.00" || untaxed.Tax != "0.00" {
+		t.Errorf("expected no tax outside of the configured countries, got %+v", untaxed)
+	}
+
+	// The country of a stored subscriber is used when only its ID is given
+	if err := store.SubscriberCreate(ctx, NewSubscriber().SetID("user_de").SetCountry("DE")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	stored, err := store.QuotePrice(ctx, plan.GetID(), SubscriberContext{SubscriberID: "user_de"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if stored.Tax != "1.90" {
+		t.Errorf("expected the tax of the stored subscriber's country, got %+v", stored)
+	}
+
+	if _, err := store.QuotePrice(ctx, "missing", SubscriberContext{}); err == nil {
+		t.Error("expected error for a missing plan")
+	}
+}
+
+func TestStoreQuotePriceWithoutResolver(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("10.00").SetCurrency(CURRENCY_USD)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	quote, err := store.QuotePrice(ctx, plan.GetID(), SubscriberContext{Country: "US"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if quote.Net != "10.00" || quote.Tax != "0.00" || quote.Gross != "10.00" {
+		t.Errorf("expected no tax without a resolver, got %+v", quote)
+	}
+}
+
+func TestStoreQuotePriceResolverError(t *testing.T) {
+	store, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+		TaxRateResolver: TaxRateResolverFunc(func(ctx context.Context, plan PlanInterface, subscriber SubscriberContext) (TaxRate, error) {
+			return TaxRate{}, errors.New("tax service unavailable")
+		}),
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := store.QuotePrice(ctx, plan.GetID(), SubscriberContext{}); err == nil {
+		t.Fatal("expected the resolver error")
+	}
+}
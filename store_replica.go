@@ -0,0 +1,83 @@
+package subscriptionstore
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// primaryDBContextKey marks the contexts whose reads use the primary DB
+type primaryDBContextKey struct{}
+
+// WithPrimaryDB returns a context whose reads use the primary DB even when
+// read DBs are set, i.e. to read what was just written before it reaches the
+// replicas.
+func WithPrimaryDB(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryDBContextKey{}, true)
+}
+
+// usesPrimaryDB returns whether the reads of the context use the primary DB
+func usesPrimaryDB(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryDBContextKey{}).(bool)
+	return primary
+}
+
+var _ StoreInterface = (*replicaStore)(nil)
+
+// replicaStore sends the plan and subscription lists, counts and exists
+// checks to the read DBs, in turn. All other calls use the primary DB,
+// including the reads made by the writes, so these never act on data which
+// has not reached the replicas yet.
+type replicaStore struct {
+	*storeImplementation
+	// readers are copies of the store reading from each read DB
+	readers []*storeImplementation
+	next    atomic.Uint64
+}
+
+// reader returns the store to read from with the context
+func (st *replicaStore) reader(ctx context.Context) *storeImplementation {
+	if usesPrimaryDB(ctx) {
+		return st.storeImplementation
+	}
+	return st.readers[(st.next.Add(1)-1)%uint64(len(st.readers))]
+}
+
+// EnableDebug enables the debug option of the primary and the read DBs
+func (st *replicaStore) EnableDebug(debug bool) {
+	st.storeImplementation.EnableDebug(debug)
+	for _, reader := range st.readers {
+		reader.EnableDebug(debug)
+	}
+}
+
+func (st *replicaStore) PlanCount(ctx context.Context, query PlanQueryInterface) (int64, error) {
+	return st.reader(ctx).PlanCount(ctx, query)
+}
+
+func (st *replicaStore) PlanCountBy(ctx context.Context, query PlanQueryInterface, column string) (map[string]int64, error) {
+	return st.reader(ctx).PlanCountBy(ctx, query, column)
+}
+
+func (st *replicaStore) PlanExists(ctx context.Context, planID string) (bool, error) {
+	return st.reader(ctx).PlanExists(ctx, planID)
+}
+
+func (st *replicaStore) PlanList(ctx context.Context, query PlanQueryInterface) ([]PlanInterface, error) {
+	return st.reader(ctx).PlanList(ctx, query)
+}
+
+func (st *replicaStore) SubscriptionCount(ctx context.Context, query SubscriptionQueryInterface) (int64, error) {
+	return st.reader(ctx).SubscriptionCount(ctx, query)
+}
+
+func (st *replicaStore) SubscriptionCountBy(ctx context.Context, query SubscriptionQueryInterface, column string) (map[string]int64, error) {
+	return st.reader(ctx).SubscriptionCountBy(ctx, query, column)
+}
+
+func (st *replicaStore) SubscriptionExists(ctx context.Context, subscriptionID string) (bool, error) {
+	return st.reader(ctx).SubscriptionExists(ctx, subscriptionID)
+}
+
+func (st *replicaStore) SubscriptionList(ctx context.Context, query SubscriptionQueryInterface) ([]SubscriptionInterface, error) {
+	return st.reader(ctx).SubscriptionList(ctx, query)
+}
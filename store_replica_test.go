@@ -0,0 +1,167 @@
+package subscriptionstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// initReplicaStore returns a store reading from a replica, which is never
+// updated, and a store writing to the replica directly
+func initReplicaStore(t *testing.T) (StoreInterface, StoreInterface) {
+	replicaDB := initDB(":memory:")
+	replica, err := NewStore(NewStoreOptions{
+		DB:                    replicaDB,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		ReadDBs:               []*sql.DB{replicaDB},
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return store, replica
+}
+
+func TestNewStoreReadDBsCannotContainNil(t *testing.T) {
+	_, err := NewStore(NewStoreOptions{
+		DB:                    initDB(":memory:"),
+		ReadDBs:               []*sql.DB{nil},
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+	})
+	if err == nil {
+		t.Error("expected error for a nil read DB")
+	}
+}
+
+func TestStoreReadDBsServeListsAndCounts(t *testing.T) {
+	store, replica := initReplicaStore(t)
+	ctx := context.Background()
+
+	plan := NewPlan().SetTitle("Primary").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	replicaPlan := NewPlan().SetTitle("Replica").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	if err := replica.PlanCreate(ctx, replicaPlan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// Lists, counts and exists checks read from the replica
+	plans, err := store.PlanList(ctx, PlanQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(plans) != 1 || plans[0].GetTitle() != "Replica" {
+		t.Fatalf("expected the plan of the replica, got %d plans", len(plans))
+	}
+	exists, err := store.PlanExists(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if exists {
+		t.Error("expected the plan not to exist on the replica")
+	}
+
+	// Other reads, and reads with WithPrimaryDB, use the primary
+	found, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Error("expected to find the plan on the primary")
+	}
+	count, err := store.PlanCount(WithPrimaryDB(ctx), PlanQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 plan on the primary, got %d", count)
+	}
+
+	// Writes read what they need from the primary
+	subscription := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription.GetPlanVersionID() == "" {
+		t.Error("expected the plan version to be pinned from the primary")
+	}
+	if err := store.SubscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscriptionCount, err := store.SubscriptionCount(ctx, SubscriptionQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscriptionCount != 0 {
+		t.Errorf("expected no subscriptions on the replica, got %d", subscriptionCount)
+	}
+	subscriptions, err := store.SubscriptionList(WithPrimaryDB(ctx), SubscriptionQuery().SetStatus(SUBSCRIPTION_STATUS_CANCELLED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(subscriptions) != 1 {
+		t.Errorf("expected the cancelled subscription on the primary, got %d", len(subscriptions))
+	}
+}
+
+func TestStoreReadDBsTransactionReadsPrimary(t *testing.T) {
+	store, _ := initReplicaStore(t)
+	ctx := context.Background()
+
+	plan := NewPlan().SetTitle("Primary").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	err := store.Transaction(ctx, func(txStore StoreInterface) error {
+		if err := txStore.PlanCreate(ctx, plan); err != nil {
+			return err
+		}
+
+		// Lists read the write of the transaction, rather than the replica
+		plans, err := txStore.PlanList(ctx, PlanQuery())
+		if err != nil {
+			return err
+		}
+		if len(plans) != 1 || plans[0].GetID() != plan.GetID() {
+			t.Errorf("expected the plan written in the transaction, got %d plans", len(plans))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	rolledBack := NewPlan().SetTitle("Rolled back").SetStatus(PLAN_STATUS_ACTIVE).SetPrice("10.00")
+	err = store.Transaction(ctx, func(txStore StoreInterface) error {
+		if err := txStore.PlanCreate(ctx, rolledBack); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("expected the error of the transaction")
+	}
+
+	for id, expected := range map[string]bool{plan.GetID(): true, rolledBack.GetID(): false} {
+		found, err := store.PlanFindByID(ctx, id)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if (found != nil) != expected {
+			t.Errorf("expected plan %s found to be %t", id, expected)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package subscriptionstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/dracory/neat"
+	"github.com/dracory/neat/database"
+)
+
+// withSQLTx returns a copy of the store whose queries and schema changes run
+// in the given transaction of the caller, and a function releasing it once
+// done. The transactions of the copy are savepoints of the transaction.
+// Only the databases with transactional schema changes are supported.
+func (st *storeImplementation) withSQLTx(ctx context.Context, tx *sql.Tx) (*storeImplementation, func(), error) {
+	driverName := st.query().Driver()
+	if !schemaTransactional(driverName) {
+		return nil, nil, errors.New(string(driverName) + " commits schema changes right away, so they cannot run in a transaction")
+	}
+
+	sqlDB := sql.OpenDB(&sqlTxConnector{tx: tx})
+
+	neatDB, err := neat.NewFromSQLDB(sqlDB, database.WithContext(ctx), database.WithDriver(string(driverName)))
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, err
+	}
+	if st.debugEnabled {
+		neatDB.EnableDebug()
+	}
+
+	txStore := *st
+	txStore.db = neatDB
+	txStore.tx = nil
+
+	return &txStore, func() { sqlDB.Close() }, nil
+}
+
+// sqlTxConnector is a driver.Connector whose connections run their
+// statements in a transaction of the caller, so neat can run on a *sql.Tx.
+// The transactions begun on the connections are savepoints.
+type sqlTxConnector struct {
+	tx *sql.Tx
+
+	mutex      sync.Mutex
+	savepoints int
+}
+
+var _ driver.Connector = (*sqlTxConnector)(nil)
+
+func (c *sqlTxConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &sqlTxConn{connector: c}, nil
+}
+
+func (c *sqlTxConnector) Driver() driver.Driver {
+	return sqlTxDriver{}
+}
+
+// savepoint returns the name of a new savepoint
+func (c *sqlTxConnector) savepoint() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.savepoints++
+	return "subscriptionstore_savepoint_" + strconv.Itoa(c.savepoints)
+}
+
+// sqlTxDriver is the driver of the sqlTxConnector, which opens no
+// connections by name
+type sqlTxDriver struct{}
+
+func (sqlTxDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("subscriptionstore > sql tx. connections are opened by the connector")
+}
+
+// sqlTxConn is a connection running its statements in the transaction of
+// its connector
+type sqlTxConn struct {
+	connector *sqlTxConnector
+}
+
+var _ driver.ExecerContext = (*sqlTxConn)(nil)
+var _ driver.QueryerContext = (*sqlTxConn)(nil)
+var _ driver.ConnBeginTx = (*sqlTxConn)(nil)
+var _ driver.NamedValueChecker = (*sqlTxConn)(nil)
+
+func (c *sqlTxConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("subscriptionstore > sql tx. statements are not prepared")
+}
+
+func (c *sqlTxConn) Close() error {
+	return nil
+}
+
+func (c *sqlTxConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx begins a savepoint of the transaction
+func (c *sqlTxConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	name := c.connector.savepoint()
+	if _, err := c.connector.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &sqlTxSavepoint{tx: c.connector.tx, name: name}, nil
+}
+
+// CheckNamedValue passes the arguments as they are to the transaction,
+// which converts them with the driver of the database
+func (c *sqlTxConn) CheckNamedValue(value *driver.NamedValue) error {
+	return nil
+}
+
+func (c *sqlTxConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.connector.tx.ExecContext(ctx, query, sqlTxArgs(args)...)
+}
+
+func (c *sqlTxConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.connector.tx.QueryContext(ctx, query, sqlTxArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return &sqlTxRows{rows: rows, columnTypes: columnTypes}, nil
+}
+
+// sqlTxArgs returns the arguments of a statement as passed to the
+// transaction
+func sqlTxArgs(args []driver.NamedValue) []any {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		if arg.Name != "" {
+			values = append(values, sql.Named(arg.Name, arg.Value))
+			continue
+		}
+		values = append(values, arg.Value)
+	}
+	return values
+}
+
+// sqlTxSavepoint is a savepoint of the transaction, released on commit
+// and rolled back to on rollback
+type sqlTxSavepoint struct {
+	tx   *sql.Tx
+	name string
+}
+
+func (s *sqlTxSavepoint) Commit() error {
+	_, err := s.tx.Exec("RELEASE SAVEPOINT " + s.name)
+	return err
+}
+
+func (s *sqlTxSavepoint) Rollback() error {
+	if _, err := s.tx.Exec("ROLLBACK TO SAVEPOINT " + s.name); err != nil {
+		return err
+	}
+	_, err := s.tx.Exec("RELEASE SAVEPOINT " + s.name)
+	return err
+}
+
+// sqlTxRows are the rows of a query run in the transaction
+type sqlTxRows struct {
+	rows        *sql.Rows
+	columnTypes []*sql.ColumnType
+}
+
+var _ driver.RowsColumnTypeDatabaseTypeName = (*sqlTxRows)(nil)
+
+func (r *sqlTxRows) Columns() []string {
+	columns := make([]string, len(r.columnTypes))
+	for i, columnType := range r.columnTypes {
+		columns[i] = columnType.Name()
+	}
+	return columns
+}
+
+func (r *sqlTxRows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *sqlTxRows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	values := make([]any, len(dest))
+	pointers := make([]any, len(dest))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		dest[i] = value
+	}
+	return nil
+}
+
+func (r *sqlTxRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.columnTypes[index].DatabaseTypeName()
+}
@@ -0,0 +1,261 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriberCreate creates a new subscriber. Set its ID to the subscriber ID
+// used by its subscriptions, i.e. the ID of the user in the application.
+func (st *storeImplementation) SubscriberCreate(ctx context.Context, subscriber SubscriberInterface) error {
+	if subscriber == nil {
+		return errors.New("subscriptionstore > subscriber create. subscriber cannot be nil")
+	}
+	if subscriber.GetID() == "" {
+		return errors.New("subscriptionstore > subscriber create. id cannot be empty")
+	}
+
+	existing, err := st.SubscriberFindByID(ctx, subscriber.GetID())
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.New("subscriptionstore > subscriber create. subscriber already exists")
+	}
+	if err := st.paymentMethodCheckOwner(ctx, subscriber.GetID(), subscriber.GetPaymentMethodID()); err != nil {
+		return errors.New("subscriptionstore > subscriber create. " + err.Error())
+	}
+
+	if subscriber.GetCreatedAt() == "" {
+		subscriber.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if subscriber.GetUpdatedAt() == "" {
+		subscriber.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := st.subscriberRow(subscriber)
+	row[COLUMN_ID] = subscriber.GetID()
+	row[COLUMN_CREATED_AT] = dateTimeValue(subscriber.GetCreatedAtCarbon())
+
+	return st.query().Table(st.subscriberTableName).Create(row)
+}
+
+// SubscriberFindByID finds a subscriber by id
+func (st *storeImplementation) SubscriberFindByID(ctx context.Context, id string) (SubscriberInterface, error) {
+	if id == "" {
+		return nil, errors.New("subscriber id is empty")
+	}
+	list, err := st.SubscriberList(ctx, SubscriberQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// SubscriberList retrieves a list of subscribers
+func (st *storeImplementation) SubscriberList(ctx context.Context, query SubscriberQueryInterface) ([]SubscriberInterface, error) {
+	if query == nil {
+		return []SubscriberInterface{}, errors.New("at subscriber list > subscriber query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []SubscriberInterface{}, err
+	}
+
+	q := st.buildSubscriberQuery(query)
+
+	type subscriberRow struct {
+		ID              string    `db:"id"`
+		Email           string    `db:"email"`
+		Name            string    `db:"name"`
+		Country         string    `db:"country"`
+		Region          string    `db:"region"`
+		TaxID           string    `db:"tax_id"`
+		Currency        string    `db:"currency"`
+		PaymentMethodID string    `db:"payment_method_id"`
+		Memo            string    `db:"memo"`
+		CreatedAt       time.Time `db:"created_at"`
+		UpdatedAt       time.Time `db:"updated_at"`
+	}
+
+	var rows []subscriberRow
+	if err := q.Table(st.subscriberTableName).Get(&rows); err != nil {
+		return []SubscriberInterface{}, err
+	}
+
+	list := make([]SubscriberInterface, 0, len(rows))
+	for _, r := range rows {
+		s := &subscriberImplementation{}
+		s.SetID(r.ID)
+		s.SetEmail(r.Email)
+		s.SetName(r.Name)
+		s.SetCountry(r.Country)
+		s.SetRegion(r.Region)
+		s.SetTaxID(r.TaxID)
+		s.SetCurrency(r.Currency)
+		s.SetPaymentMethodID(r.PaymentMethodID)
+		s.SetMemo(r.Memo)
+		s.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		s.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+// SubscriberUpdate updates a subscriber. Changing the currency or the payment
+// method does not change the existing subscriptions.
+func (st *storeImplementation) SubscriberUpdate(ctx context.Context, subscriber SubscriberInterface) error {
+	if subscriber == nil {
+		return errors.New("subscriptionstore > subscriber update. subscriber cannot be nil")
+	}
+	if err := st.paymentMethodCheckOwner(ctx, subscriber.GetID(), subscriber.GetPaymentMethodID()); err != nil {
+		return errors.New("subscriptionstore > subscriber update. " + err.Error())
+	}
+
+	subscriber.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	_, err := st.query().Table(st.subscriberTableName).Where(COLUMN_ID+" = ?", subscriber.GetID()).Update(st.subscriberRow(subscriber))
+	return err
+}
+
+// subscriberRow returns the columns of the subscriber, which are written on
+// both create and update
+func (st *storeImplementation) subscriberRow(subscriber SubscriberInterface) map[string]any {
+	return map[string]any{
+		COLUMN_EMAIL:             subscriber.GetEmail(),
+		COLUMN_NAME:              subscriber.GetName(),
+		COLUMN_COUNTRY:           subscriber.GetCountry(),
+		COLUMN_REGION:            subscriber.GetRegion(),
+		COLUMN_TAX_ID:            subscriber.GetTaxID(),
+		COLUMN_CURRENCY:          subscriber.GetCurrency(),
+		COLUMN_PAYMENT_METHOD_ID: subscriber.GetPaymentMethodID(),
+		COLUMN_MEMO:              subscriber.GetMemo(),
+		COLUMN_UPDATED_AT:        dateTimeValue(subscriber.GetUpdatedAtCarbon()),
+	}
+}
+
+// subscriptionSubscriberDefaults fills in the currency and the payment method
+// of a new subscription from its subscriber, if it has a record, and the
+// currency from its plan otherwise. Without a payment method on the
+// subscriber record, the default registered payment method of the subscriber
+// is used. The plan must be priced in the currency of the subscription.
+func (st *storeImplementation) subscriptionSubscriberDefaults(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription.GetSubscriberID() != "" {
+		subscriber, err := st.SubscriberFindByID(ctx, subscription.GetSubscriberID())
+		if err != nil {
+			return err
+		}
+		if subscriber != nil {
+			if subscription.GetCurrency() == "" {
+				subscription.SetCurrency(subscriber.GetCurrency())
+			}
+			if subscription.GetPaymentMethodID() == "" {
+				subscription.SetPaymentMethodID(subscriber.GetPaymentMethodID())
+			}
+		}
+
+		if subscription.GetPaymentMethodID() == "" {
+			paymentMethod, err := st.paymentMethodDefault(ctx, subscription.GetSubscriberID())
+			if err != nil {
+				return err
+			}
+			if paymentMethod != nil {
+				subscription.SetPaymentMethodID(paymentMethod.GetID())
+			}
+		}
+	}
+
+	if subscription.GetPlanID() == "" {
+		return nil
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	return subscriptionPlanCurrency(subscription, plan)
+}
+
+// subscriptionPlanChangeCurrency checks that the plan of the subscription is
+// priced in its currency, when the plan differs from the stored one
+func (st *storeImplementation) subscriptionPlanChangeCurrency(ctx context.Context, subscription SubscriptionInterface) error {
+	if subscription.GetPlanID() == "" {
+		return nil
+	}
+
+	stored, err := st.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.GetPlanID() == subscription.GetPlanID() {
+		return nil
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	return subscriptionPlanCurrency(subscription, plan)
+}
+
+// subscriptionPlanCurrency defaults the currency of the subscription to the
+// currency of its plan, which the subscription must otherwise be in. Plans
+// not found or without a currency are skipped.
+func subscriptionPlanCurrency(subscription SubscriptionInterface, plan PlanInterface) error {
+	if plan == nil || plan.GetCurrency() == "" {
+		return nil
+	}
+
+	if subscription.GetCurrency() == "" {
+		subscription.SetCurrency(plan.GetCurrency())
+		return nil
+	}
+	if !strings.EqualFold(subscription.GetCurrency(), plan.GetCurrency()) {
+		return errors.New("plan is priced in " + plan.GetCurrency() + ", not in the subscription currency " + subscription.GetCurrency())
+	}
+
+	return nil
+}
+
+// buildSubscriberQuery builds a neat query from the subscriber query interface.
+func (st *storeImplementation) buildSubscriberQuery(query SubscriberQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasEmail() && query.Email() != "" {
+		q = q.Where(COLUMN_EMAIL+" = ?", query.Email())
+	}
+	if query.HasCountry() && query.Country() != "" {
+		q = q.Where(COLUMN_COUNTRY+" = ?", query.Country())
+	}
+	if query.HasCurrency() && query.Currency() != "" {
+		q = q.Where(COLUMN_CURRENCY+" = ?", query.Currency())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,196 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreSubscriberCreateFindUpdateList(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscriber := NewSubscriber().
+		SetID("user_1").
+		SetEmail("jane@example.com").
+		SetName("Jane Doe").
+		SetCountry("DE").
+		SetCurrency(CURRENCY_EUR)
+	if err := store.SubscriberCreate(ctx, subscriber); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriberCreate(ctx, NewSubscriber().SetID("user_1")); err == nil {
+		t.Error("expected error for an existing subscriber")
+	}
+	if err := store.SubscriberCreate(ctx, NewSubscriber().SetID("user_2").SetCountry("US")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SubscriberFindByID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil || found.GetEmail() != "jane@example.com" || found.GetCurrency() != CURRENCY_EUR {
+		t.Fatalf("expected the created subscriber, got %v", found)
+	}
+
+	found.SetPaymentMethodID("pm_1")
+	if err := store.SubscriberUpdate(ctx, found); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found, err = store.SubscriberFindByID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetPaymentMethodID() != "pm_1" {
+		t.Errorf("expected payment method pm_1, got %s", found.GetPaymentMethodID())
+	}
+
+	list, err := store.SubscriberList(ctx, SubscriberQuery().SetCountry("DE"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 1 || list[0].GetID() != "user_1" {
+		t.Errorf("expected 1 subscriber in DE, got %d", len(list))
+	}
+
+	missing, err := store.SubscriberFindByID(ctx, "user_3")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if missing != nil {
+		t.Error("expected no subscriber")
+	}
+}
+
+func TestStoreSubscriptionCreateSubscriberDefaults(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	euroPlan := NewPlan().SetTitle("Euro").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_EUR).SetPrice("9.00")
+	dollarPlan := NewPlan().SetTitle("Dollar").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_USD).SetPrice("10.00")
+	for _, plan := range []PlanInterface{euroPlan, dollarPlan} {
+		if err := store.PlanCreate(ctx, plan); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscriber := NewSubscriber().
+		SetID("user_1").
+		SetCurrency(CURRENCY_EUR).
+		SetPaymentMethodID("pm_1")
+	if err := store.SubscriberCreate(ctx, subscriber); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(euroPlan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetCurrency() != CURRENCY_EUR || found.GetPaymentMethodID() != "pm_1" {
+		t.Errorf("expected the subscriber's currency and payment method, got %s / %s", found.GetCurrency(), found.GetPaymentMethodID())
+	}
+
+	// An explicit payment method wins over the subscriber's
+	explicit := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(euroPlan.GetID()).
+		SetPaymentMethodID("pm_2")
+	if err := store.SubscriptionCreate(ctx, explicit); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if explicit.GetPaymentMethodID() != "pm_2" {
+		t.Errorf("expected payment method pm_2, got %s", explicit.GetPaymentMethodID())
+	}
+
+	mismatch := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(dollarPlan.GetID())
+	if err := store.SubscriptionCreate(ctx, mismatch); err == nil {
+		t.Error("expected error for a plan in another currency than the subscriber's")
+	}
+
+	// Without a subscriber record the currency of the plan is used
+	anonymous := NewSubscription().
+		SetSubscriberID("user_2").
+		SetPlanID(dollarPlan.GetID())
+	if err := store.SubscriptionCreate(ctx, anonymous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if anonymous.GetCurrency() != CURRENCY_USD || anonymous.GetPaymentMethodID() != "" {
+		t.Errorf("expected the plan's currency and no payment method, got %s / %s", anonymous.GetCurrency(), anonymous.GetPaymentMethodID())
+	}
+}
+
+func TestStoreSubscriptionUpdatePlanCurrency(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	euroPlan := NewPlan().SetTitle("Euro").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_EUR).SetPrice("9.00")
+	dollarPlan := NewPlan().SetTitle("Dollar").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_USD).SetPrice("10.00")
+	teamPlan := NewPlan().SetTitle("Team").SetStatus(PLAN_STATUS_ACTIVE).SetCurrency(CURRENCY_USD).SetPrice("20.00")
+	for _, plan := range []PlanInterface{euroPlan, dollarPlan, teamPlan} {
+		if err := store.PlanCreate(ctx, plan); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(dollarPlan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SubscriptionUpdate(ctx, subscription.SetPlanID(teamPlan.GetID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionUpdate(ctx, subscription.SetPlanID(euroPlan.GetID())); err == nil {
+		t.Error("expected error for a plan in another currency than the subscription's")
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetPlanID() != teamPlan.GetID() {
+		t.Errorf("expected plan %s, got %s", teamPlan.GetID(), found.GetPlanID())
+	}
+
+	// Schedules cannot move the subscription to another currency either
+	schedules := map[string][]SubscriptionSchedulePhase{
+		"a plan in another currency": {
+			{PlanID: euroPlan.GetID(), StartsAt: "2025-03-01 00:00:00"},
+		},
+		"plans in different currencies": {
+			{PlanID: dollarPlan.GetID(), StartsAt: "2025-03-01 00:00:00", EndsAt: "2025-04-01 00:00:00"},
+			{PlanID: euroPlan.GetID(), StartsAt: "2025-04-01 00:00:00"},
+		},
+	}
+	for name, phases := range schedules {
+		schedule := NewSubscriptionSchedule().SetSubscriptionID(subscription.GetID())
+		if _, err := schedule.SetPhases(phases); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if err := store.SubscriptionScheduleCreate(ctx, schedule); err == nil {
+			t.Errorf("expected error for a schedule with %s", name)
+		}
+	}
+}
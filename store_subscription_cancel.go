@@ -0,0 +1,182 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// SubscriptionCancelOptions describe the cancellation of a subscription
+type SubscriptionCancelOptions struct {
+	// AtPeriodEnd keeps the subscription until the end of its current period,
+	// instead of cancelling it immediately
+	AtPeriodEnd bool
+	// Reason is one of the CANCELLATION_REASON_* constants, or empty
+	Reason string
+	// Feedback is the free text comment of the subscriber
+	Feedback string
+	// CancelledBy is one of the CANCELLED_BY_* constants, or empty
+	CancelledBy string
+}
+
+// subscriptionWorkflowStore finds, lists and updates subscriptions, which is
+// all the cancel, pause and active subscription lookups need, so the SQL and
+// the memory stores share them
+type subscriptionWorkflowStore interface {
+	SubscriptionFindByID(ctx context.Context, id string) (SubscriptionInterface, error)
+	SubscriptionList(ctx context.Context, query SubscriptionQueryInterface) ([]SubscriptionInterface, error)
+	SubscriptionUpdate(ctx context.Context, subscription SubscriptionInterface) error
+}
+
+var cancellationReasons = []string{
+	CANCELLATION_REASON_CUSTOMER_SERVICE,
+	CANCELLATION_REASON_LOW_QUALITY,
+	CANCELLATION_REASON_MISSING_FEATURES,
+	CANCELLATION_REASON_OTHER,
+	CANCELLATION_REASON_SWITCHED_SERVICE,
+	CANCELLATION_REASON_TOO_COMPLEX,
+	CANCELLATION_REASON_TOO_EXPENSIVE,
+	CANCELLATION_REASON_UNUSED,
+}
+
+var cancelledBys = []string{
+	CANCELLED_BY_ADMIN,
+	CANCELLED_BY_SUBSCRIBER,
+	CANCELLED_BY_SYSTEM,
+}
+
+// SubscriptionCancel cancels the subscription, immediately or at the end of
+// its current period, recording when it was requested, when it takes effect,
+// and why
+func (st *storeImplementation) SubscriptionCancel(ctx context.Context, subscriptionID string, options SubscriptionCancelOptions) error {
+	return st.subscriptionCancel(ctx, subscriptionID, options, carbon.Now(carbon.UTC))
+}
+
+// subscriptionCancel cancels the subscription as requested at the given time
+func (st *storeImplementation) subscriptionCancel(ctx context.Context, subscriptionID string, options SubscriptionCancelOptions, now *carbon.Carbon) error {
+	return cancelSubscription(ctx, st, subscriptionID, options, now)
+}
+
+// cancelSubscription cancels the subscription of the store as requested at
+// the given time
+func cancelSubscription(ctx context.Context, st subscriptionWorkflowStore, subscriptionID string, options SubscriptionCancelOptions, now *carbon.Carbon) error {
+	if options.Reason != "" && !lo.Contains(cancellationReasons, options.Reason) {
+		return errors.New("subscriptionstore > subscription cancel. unsupported reason: " + options.Reason)
+	}
+	if options.CancelledBy != "" && !lo.Contains(cancelledBys, options.CancelledBy) {
+		return errors.New("subscriptionstore > subscription cancel. unsupported cancelled by: " + options.CancelledBy)
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription cancel. subscription not found")
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return errors.New("subscriptionstore > subscription cancel. subscription is already cancelled")
+	}
+
+	effectiveAt := now
+	if options.AtPeriodEnd {
+		if subscription.GetPeriodEnd() == MAX_DATETIME {
+			return errors.New("subscriptionstore > subscription cancel. subscription period does not end")
+		}
+		effectiveAt = subscription.GetPeriodEndCarbon()
+	}
+
+	subscription.SetCancelAtPeriodEnd(options.AtPeriodEnd)
+	subscription.SetCancellationRequestedAt(now.ToDateTimeString(carbon.UTC))
+	subscription.SetCancellationEffectiveAt(effectiveAt.ToDateTimeString(carbon.UTC))
+	subscription.SetCancellationReason(options.Reason)
+	subscription.SetCancellationFeedback(options.Feedback)
+	subscription.SetCancelledBy(options.CancelledBy)
+	if !options.AtPeriodEnd {
+		subscription.SetStatus(SUBSCRIPTION_STATUS_CANCELLED)
+	}
+
+	return st.SubscriptionUpdate(ctx, subscription)
+}
+
+// SubscriptionUncancel withdraws the pending cancellation at the end of the
+// period of the subscription, clearing the recorded cancellation
+func (st *storeImplementation) SubscriptionUncancel(ctx context.Context, subscriptionID string) error {
+	return uncancelSubscription(ctx, st, subscriptionID)
+}
+
+// uncancelSubscription withdraws the pending cancellation of the
+// subscription of the store
+func uncancelSubscription(ctx context.Context, st subscriptionWorkflowStore, subscriptionID string) error {
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription uncancel. subscription not found")
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return errors.New("subscriptionstore > subscription uncancel. subscription is already cancelled")
+	}
+	if !subscription.GetCancelAtPeriodEnd() {
+		return errors.New("subscriptionstore > subscription uncancel. subscription has no pending cancellation")
+	}
+
+	subscription.SetCancelAtPeriodEnd(false)
+	subscription.SetCancellationRequestedAt(MAX_DATETIME)
+	subscription.SetCancellationEffectiveAt(MAX_DATETIME)
+	subscription.SetCancellationReason("")
+	subscription.SetCancellationFeedback("")
+	subscription.SetCancelledBy("")
+
+	return st.SubscriptionUpdate(ctx, subscription)
+}
+
+// SubscriptionCancellationsByReason counts the cancellations of the
+// subscriptions matching the query by reason, including those pending at
+// the end of the period. Cancellations without a reason are counted under
+// an empty reason. Use SetCancellationRequestedAtGte and
+// SetCancellationRequestedAtLte to report on a date range.
+func (st *storeImplementation) SubscriptionCancellationsByReason(ctx context.Context, query SubscriptionQueryInterface) (map[string]int64, error) {
+	if query == nil {
+		return nil, errors.New("subscriptionstore > subscription cancellations by reason. query cannot be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if query.HasLimit() || query.HasOffset() || query.HasOrderBy() {
+		return nil, errors.New("subscriptionstore > subscription cancellations by reason. query cannot be paginated or ordered")
+	}
+
+	type reasonRow struct {
+		Reason string `db:"cancellation_reason"`
+		Total  int64  `db:"total"`
+	}
+
+	var rows []reasonRow
+	err := st.buildSubscriptionCancellationsByReasonQuery(query).Get(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+	for _, r := range rows {
+		counts[r.Reason] = r.Total
+	}
+
+	return counts, nil
+}
+
+// buildSubscriptionCancellationsByReasonQuery builds the query counting the
+// cancellations of the subscriptions matching the query by reason.
+// Subscriptions which are not cancelled are requested at MAX_DATETIME.
+func (st *storeImplementation) buildSubscriptionCancellationsByReasonQuery(query SubscriptionQueryInterface) contractsorm.Query {
+	return st.buildSubscriptionQuery(query).
+		Table(st.subscriptionTableName).
+		Select(COLUMN_CANCELLATION_REASON+", COUNT(*) AS total").
+		Where(COLUMN_CANCELLATION_REQUESTED_AT+" < ?", dateTimeValue(carbon.Parse(MAX_DATETIME, carbon.UTC))).
+		Group(COLUMN_CANCELLATION_REASON)
+}
@@ -0,0 +1,199 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreSubscriptionCancelAndUncancel(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetPeriodEnd("2025-02-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SubscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{Reason: "bored"}); err == nil {
+		t.Error("expected error for an unsupported reason")
+	}
+	if err := store.SubscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{CancelledBy: "robot"}); err == nil {
+		t.Error("expected error for an unsupported cancelled by")
+	}
+	if err := store.SubscriptionCancel(ctx, "missing", SubscriptionCancelOptions{}); err == nil {
+		t.Error("expected error for a missing subscription")
+	}
+	if err := store.SubscriptionUncancel(ctx, subscription.GetID()); err == nil {
+		t.Error("expected error for a subscription without pending cancellation")
+	}
+
+	st := store.(*storeImplementation)
+	err = st.subscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{
+		AtPeriodEnd: true,
+		Reason:      CANCELLATION_REASON_TOO_EXPENSIVE,
+		Feedback:    "Cheaper elsewhere",
+		CancelledBy: CANCELLED_BY_SUBSCRIBER,
+	}, carbon.Parse("2025-01-20 10:00:00", carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE || !found.GetCancelAtPeriodEnd() {
+		t.Errorf("expected an active subscription cancelling at period end, got %s / %v", found.GetStatus(), found.GetCancelAtPeriodEnd())
+	}
+	if found.GetCancellationRequestedAt() != "2025-01-20 10:00:00" || found.GetCancellationEffectiveAt() != "2025-02-01 00:00:00" {
+		t.Errorf("unexpected cancellation dates %s / %s", found.GetCancellationRequestedAt(), found.GetCancellationEffectiveAt())
+	}
+	if found.GetCancellationReason() != CANCELLATION_REASON_TOO_EXPENSIVE || found.GetCancellationFeedback() != "Cheaper elsewhere" || found.GetCancelledBy() != CANCELLED_BY_SUBSCRIBER {
+		t.Errorf("unexpected cancellation %s / %s / %s", found.GetCancellationReason(), found.GetCancellationFeedback(), found.GetCancelledBy())
+	}
+
+	// Won back before the end of the period
+	if err := store.SubscriptionUncancel(ctx, subscription.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found, err = store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetCancelAtPeriodEnd() || found.GetCancellationRequestedAt() != MAX_DATETIME || found.GetCancellationReason() != "" || found.GetCancellationFeedback() != "" {
+		t.Errorf("expected the cancellation cleared, got %v / %s / %s", found.GetCancelAtPeriodEnd(), found.GetCancellationRequestedAt(), found.GetCancellationReason())
+	}
+
+	err = st.subscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{
+		Reason:      CANCELLATION_REASON_OTHER,
+		CancelledBy: CANCELLED_BY_ADMIN,
+	}, carbon.Parse("2025-01-25 00:00:00", carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found, err = store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_CANCELLED || found.GetCancellationEffectiveAt() != "2025-01-25 00:00:00" {
+		t.Errorf("expected cancelled immediately, got %s at %s", found.GetStatus(), found.GetCancellationEffectiveAt())
+	}
+	if err := store.SubscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{}); err == nil {
+		t.Error("expected error cancelling a cancelled subscription")
+	}
+	if err := store.SubscriptionUncancel(ctx, subscription.GetID()); err == nil {
+		t.Error("expected error uncancelling a cancelled subscription")
+	}
+
+	// Subscriptions which never renew can only be cancelled immediately
+	lifetime := NewSubscription().SetSubscriberID("user_2").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, lifetime); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionCancel(ctx, lifetime.GetID(), SubscriptionCancelOptions{AtPeriodEnd: true}); err == nil {
+		t.Error("expected error cancelling at the end of a period which does not end")
+	}
+}
+
+func TestStoreSubscriptionCancellationsByReason(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	st := store.(*storeImplementation)
+
+	cancellations := []struct {
+		reason      string
+		requestedAt string
+		atPeriodEnd bool
+	}{
+		{CANCELLATION_REASON_TOO_EXPENSIVE, "2025-01-05 00:00:00", false},
+		{CANCELLATION_REASON_TOO_EXPENSIVE, "2025-01-10 00:00:00", true},
+		{CANCELLATION_REASON_UNUSED, "2025-01-15 00:00:00", false},
+		{"", "2025-01-20 00:00:00", false},
+		{CANCELLATION_REASON_UNUSED, "2025-02-15 00:00:00", false},
+	}
+	for _, c := range cancellations {
+		subscription := NewSubscription().
+			SetSubscriberID("user_1").
+			SetPlanID("plan_1").
+			SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+			SetPeriodEnd("2025-03-01 00:00:00")
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		options := SubscriptionCancelOptions{AtPeriodEnd: c.atPeriodEnd, Reason: c.reason}
+		if err := st.subscriptionCancel(ctx, subscription.GetID(), options, carbon.Parse(c.requestedAt, carbon.UTC)); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// Active subscriptions are not counted
+	active := NewSubscription().SetSubscriberID("user_2").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, active); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	counts, err := store.SubscriptionCancellationsByReason(ctx, SubscriptionQuery().
+		SetCancellationRequestedAtGte("2025-01-01 00:00:00").
+		SetCancellationRequestedAtLte("2025-01-31 23:59:59"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := map[string]int64{
+		CANCELLATION_REASON_TOO_EXPENSIVE: 2,
+		CANCELLATION_REASON_UNUSED:        1,
+		"":                                1,
+	}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+	for reason, count := range expected {
+		if counts[reason] != count {
+			t.Errorf("expected %d cancellations for %q, got %d", count, reason, counts[reason])
+		}
+	}
+
+	counts, err = store.SubscriptionCancellationsByReason(ctx, SubscriptionQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if counts[""] != 1 {
+		t.Errorf("expected the active subscription not to be counted, got %v", counts)
+	}
+
+	counts, err = store.SubscriptionCancellationsByReason(ctx, SubscriptionQuery().SetStatus(SUBSCRIPTION_STATUS_CANCELLED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if counts[CANCELLATION_REASON_TOO_EXPENSIVE] != 1 || counts[CANCELLATION_REASON_UNUSED] != 2 {
+		t.Errorf("unexpected counts of the cancelled subscriptions %v", counts)
+	}
+
+	list, err := store.SubscriptionList(ctx, SubscriptionQuery().SetCancellationReason(CANCELLATION_REASON_UNUSED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("expected 2 subscriptions cancelled as unused, got %d", len(list))
+	}
+
+	if _, err := store.SubscriptionCancellationsByReason(ctx, SubscriptionQuery().SetLimit(10)); err == nil {
+		t.Error("expected error for a paginated query")
+	}
+	if _, err := store.SubscriptionCancellationsByReason(ctx, nil); err == nil {
+		t.Error("expected error for a nil query")
+	}
+}
@@ -0,0 +1,449 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionApplyCoupon applies the coupon to the subscription, replacing
+// its current discount if any.
+//
+// The discount starts with the current billing period, unless it has already
+// been invoiced, in which case it starts with the next one. It lasts one
+// billing period for COUPON_DURATION_ONCE, DurationPeriods periods for
+// COUPON_DURATION_REPEATING, and indefinitely for COUPON_DURATION_FOREVER.
+func (st *storeImplementation) SubscriptionApplyCoupon(ctx context.Context, subscriptionID string, couponID string) (SubscriptionDiscountInterface, error) {
+	return st.subscriptionApplyCoupon(ctx, subscriptionID, couponID, nil)
+}
+
+// SubscriptionApplyPromotionCode applies the coupon of the promotion code to
+// the subscription, as SubscriptionApplyCoupon does, and counts the
+// redemption of the code
+func (st *storeImplementation) SubscriptionApplyPromotionCode(ctx context.Context, subscriptionID string, code string) (SubscriptionDiscountInterface, error) {
+	promotionCode, err := st.PromotionCodeFindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if promotionCode == nil {
+		return nil, errors.New("subscriptionstore > subscription apply promotion code. promotion code not found")
+	}
+
+	if err := promotionCodeRedeemable(promotionCode, carbon.Now(carbon.UTC)); err != nil {
+		return nil, errors.New("subscriptionstore > subscription apply promotion code. " + err.Error())
+	}
+
+	return st.subscriptionApplyCoupon(ctx, subscriptionID, promotionCode.GetCouponID(), promotionCode)
+}
+
+// SubscriptionDiscountList retrieves a list of subscription discounts
+func (st *storeImplementation) SubscriptionDiscountList(ctx context.Context, query SubscriptionDiscountQueryInterface) ([]SubscriptionDiscountInterface, error) {
+	if query == nil {
+		return []SubscriptionDiscountInterface{}, errors.New("at subscription discount list > subscription discount query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []SubscriptionDiscountInterface{}, err
+	}
+
+	q := st.buildSubscriptionDiscountQuery(query)
+
+	type subscriptionDiscountRow struct {
+		ID              string    `db:"id"`
+		SubscriptionID  string    `db:"subscription_id"`
+		CouponID        string    `db:"coupon_id"`
+		PromotionCodeID string    `db:"promotion_code_id"`
+		StartsAt        time.Time `db:"starts_at"`
+		EndsAt          time.Time `db:"ends_at"`
+		CreatedAt       time.Time `db:"created_at"`
+		UpdatedAt       time.Time `db:"updated_at"`
+	}
+
+	var rows []subscriptionDiscountRow
+	if err := q.Table(st.subscriptionDiscountTableName).Get(&rows); err != nil {
+		return []SubscriptionDiscountInterface{}, err
+	}
+
+	list := make([]SubscriptionDiscountInterface, 0, len(rows))
+	for _, r := range rows {
+		d := &subscriptionDiscountImplementation{}
+		d.SetID(r.ID)
+		d.SetSubscriptionID(r.SubscriptionID)
+		d.SetCouponID(r.CouponID)
+		d.SetPromotionCodeID(r.PromotionCodeID)
+		d.SetStartsAt(carbon.CreateFromStdTime(r.StartsAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		d.SetEndsAt(carbon.CreateFromStdTime(r.EndsAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		d.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		d.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, d)
+	}
+
+	return list, nil
+}
+
+// SubscriptionPriceForPeriod returns what the subscription is charged for
+// the billing period starting at periodStart: the price of its plan and its
+// items, less the discount applying to the period
+func (st *storeImplementation) SubscriptionPriceForPeriod(ctx context.Context, subscriptionID string, periodStart string) (PeriodPrice, error) {
+	periodStartCarbon := carbon.Parse(periodStart, carbon.UTC)
+	if periodStartCarbon.IsInvalid() {
+		return PeriodPrice{}, errors.New("subscriptionstore > subscription price for period. period start is not a valid date")
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+	if subscription == nil {
+		return PeriodPrice{}, errors.New("subscriptionstore > subscription price for period. subscription not found")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+	if plan == nil {
+		return PeriodPrice{}, errors.New("subscriptionstore > subscription price for period. plan not found")
+	}
+
+	return st.periodPrice(ctx, subscription, plan, periodStartCarbon)
+}
+
+// periodPrice returns the price of the plan and the items of the
+// subscription for its billing period starting at periodStart
+func (st *storeImplementation) periodPrice(ctx context.Context, subscription SubscriptionInterface, plan PlanInterface, periodStart *carbon.Carbon) (PeriodPrice, error) {
+	coupon, err := st.periodCoupon(ctx, subscription, periodStart)
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+
+	itemLines, err := st.subscriptionItemLines(ctx, subscription, plan)
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+
+	return calculatePeriodPrice(subscription, plan, itemLines, coupon, periodStart)
+}
+
+// calculatePeriodPrice returns the price of the plan and the item lines of
+// the subscription, less the coupon if not nil, for its billing period
+// starting at periodStart
+func calculatePeriodPrice(subscription SubscriptionInterface, plan PlanInterface, itemLines []PriceLine, coupon CouponInterface, periodStart *carbon.Carbon) (PeriodPrice, error) {
+	periodEnd, err := planIntervalPeriodEnd(periodStart, plan.GetInterval())
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+
+	price, err := calculatePrice(plan, subscription.GetQuantity(), itemLines, coupon)
+	if err != nil {
+		return PeriodPrice{}, err
+	}
+
+	price.PeriodStart = periodStart.ToDateTimeString(carbon.UTC)
+	price.PeriodEnd = periodEnd.ToDateTimeString(carbon.UTC)
+	return price, nil
+}
+
+// periodCoupon returns the coupon of the discount applying to the billing
+// period of the subscription starting at periodStart, or nil if there is none
+func (st *storeImplementation) periodCoupon(ctx context.Context, subscription SubscriptionInterface, periodStart *carbon.Carbon) (CouponInterface, error) {
+	discounts, err := st.SubscriptionDiscountList(ctx, SubscriptionDiscountQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetActiveAt(periodStart.ToDateTimeString(carbon.UTC)).
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortOrder("desc").
+		SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(discounts) == 0 {
+		return nil, nil
+	}
+
+	return st.CouponFindByID(ctx, discounts[0].GetCouponID())
+}
+
+// subscriptionApplyCoupon applies the coupon, redeemed with the promotion
+// code if it is not nil. The redemptions, the replaced discounts and the new
+// discount are written in one transaction, so a failure leaves the
+// subscription with its discounts and the coupon with its redemptions.
+func (st *storeImplementation) subscriptionApplyCoupon(ctx context.Context, subscriptionID string, couponID string, promotionCode PromotionCodeInterface) (SubscriptionDiscountInterface, error) {
+	if couponID == "" {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. coupon id cannot be empty")
+	}
+
+	var discount SubscriptionDiscountInterface
+	err := st.transaction(func(txStore *storeImplementation) error {
+		var err error
+		discount, err = txStore.subscriptionApplyCouponTx(ctx, subscriptionID, couponID, promotionCode)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return discount, nil
+}
+
+// subscriptionApplyCouponTx applies the coupon, within the transaction of
+// subscriptionApplyCoupon
+func (st *storeImplementation) subscriptionApplyCouponTx(ctx context.Context, subscriptionID string, couponID string, promotionCode PromotionCodeInterface) (SubscriptionDiscountInterface, error) {
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. subscription not found")
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. subscription is cancelled")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. plan not found")
+	}
+
+	coupon, err := st.CouponFindByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. coupon not found")
+	}
+
+	if err := couponRedeemable(coupon, carbon.Now(carbon.UTC)); err != nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. " + err.Error())
+	}
+
+	// An amount off in another currency cannot be applied to the plan
+	if _, err := CalculatePrice(plan, coupon); err != nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. " + err.Error())
+	}
+
+	startsAt, err := st.discountStart(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	endsAt, err := discountEnd(coupon, startsAt, plan.GetInterval())
+	if err != nil {
+		return nil, errors.New("subscriptionstore > subscription apply coupon. " + err.Error())
+	}
+
+	existing, err := st.SubscriptionDiscountList(ctx, SubscriptionDiscountQuery().SetSubscriptionID(subscription.GetID()))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, discount := range existing {
+		if !startsAt.Lt(discount.GetEndsAtCarbon()) {
+			continue
+		}
+		if discount.GetCouponID() == coupon.GetID() {
+			return nil, errors.New("subscriptionstore > subscription apply coupon. coupon already applied")
+		}
+	}
+
+	if err := st.couponRedeem(coupon, promotionCode); err != nil {
+		return nil, err
+	}
+
+	// The new discount replaces the ones still running when it starts. The
+	// ones not started yet are deleted rather than ended before their start.
+	for _, discount := range existing {
+		if !startsAt.Lt(discount.GetEndsAtCarbon()) {
+			continue
+		}
+		if !discount.GetStartsAtCarbon().Lt(startsAt) {
+			if err := st.subscriptionDiscountDelete(ctx, discount.GetID()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		discount.SetEndsAt(startsAt.ToDateTimeString(carbon.UTC))
+		if err := st.subscriptionDiscountUpdate(ctx, discount); err != nil {
+			return nil, err
+		}
+	}
+
+	discount := NewSubscriptionDiscount().
+		SetSubscriptionID(subscription.GetID()).
+		SetCouponID(coupon.GetID()).
+		SetStartsAt(startsAt.ToDateTimeString(carbon.UTC)).
+		SetEndsAt(endsAt.ToDateTimeString(carbon.UTC))
+
+	if promotionCode != nil {
+		discount.SetPromotionCodeID(promotionCode.GetID())
+	}
+
+	if err := st.subscriptionDiscountCreate(ctx, discount); err != nil {
+		return nil, err
+	}
+
+	return discount, nil
+}
+
+// couponRedeem counts the redemption of the coupon, and of the promotion code
+// if it is not nil. It fails if either is fully redeemed, and is run in the
+// transaction of subscriptionApplyCoupon, so nothing is counted then.
+func (st *storeImplementation) couponRedeem(coupon CouponInterface, promotionCode PromotionCodeInterface) error {
+	counted, err := st.redemptionCount(st.couponTableName, coupon.GetID())
+	if err != nil {
+		return err
+	}
+	if !counted {
+		return errors.New("subscriptionstore > subscription apply coupon. coupon fully redeemed")
+	}
+	coupon.SetTimesRedeemed(coupon.GetTimesRedeemed() + 1)
+
+	if promotionCode == nil {
+		return nil
+	}
+
+	counted, err = st.redemptionCount(st.promotionCodeTableName, promotionCode.GetID())
+	if err != nil {
+		return err
+	}
+	if !counted {
+		return errors.New("subscriptionstore > subscription apply promotion code. promotion code fully redeemed")
+	}
+	promotionCode.SetTimesRedeemed(promotionCode.GetTimesRedeemed() + 1)
+
+	return nil
+}
+
+// discountStart returns the start of the first billing period a new discount
+// of the subscription applies to: the current period, unless it has already
+// been invoiced. Subscriptions without a period start now.
+func (st *storeImplementation) discountStart(ctx context.Context, subscription SubscriptionInterface) (*carbon.Carbon, error) {
+	periodStart := subscription.GetPeriodStartCarbon()
+	if periodStart.IsInvalid() || subscription.GetPeriodStart() == MAX_DATETIME {
+		return carbon.Now(carbon.UTC), nil
+	}
+
+	invoices, err := st.InvoiceList(ctx, InvoiceQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetPeriodStart(periodStart.ToDateTimeString(carbon.UTC)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, invoice := range invoices {
+		if invoice.GetStatus() != INVOICE_STATUS_VOID {
+			return subscription.GetPeriodEndCarbon(), nil
+		}
+	}
+
+	return periodStart, nil
+}
+
+// discountEnd returns the end of the last billing period the coupon applies
+// to, for a discount starting at startsAt
+func discountEnd(coupon CouponInterface, startsAt *carbon.Carbon, interval string) (*carbon.Carbon, error) {
+	periods := 0
+	switch coupon.GetDuration() {
+	case COUPON_DURATION_FOREVER:
+		return carbon.Parse(MAX_DATETIME, carbon.UTC), nil
+	case COUPON_DURATION_ONCE:
+		periods = 1
+	case COUPON_DURATION_REPEATING:
+		periods = coupon.GetDurationPeriods()
+	default:
+		return nil, errors.New("unknown coupon duration " + coupon.GetDuration())
+	}
+
+	endsAt := startsAt
+	for i := 0; i < periods; i++ {
+		var err error
+		endsAt, err = planIntervalPeriodEnd(endsAt, interval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return endsAt, nil
+}
+
+// subscriptionDiscountCreate creates a new subscription discount
+func (st *storeImplementation) subscriptionDiscountCreate(ctx context.Context, discount SubscriptionDiscountInterface) error {
+	if discount.GetCreatedAt() == "" {
+		discount.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if discount.GetUpdatedAt() == "" {
+		discount.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := st.subscriptionDiscountRow(discount)
+	row[COLUMN_ID] = discount.GetID()
+	row[COLUMN_CREATED_AT] = dateTimeValue(discount.GetCreatedAtCarbon())
+
+	return st.query().Table(st.subscriptionDiscountTableName).Create(row)
+}
+
+// subscriptionDiscountUpdate updates a subscription discount
+func (st *storeImplementation) subscriptionDiscountUpdate(ctx context.Context, discount SubscriptionDiscountInterface) error {
+	discount.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	_, err := st.query().Table(st.subscriptionDiscountTableName).Where(COLUMN_ID+" = ?", discount.GetID()).Update(st.subscriptionDiscountRow(discount))
+	return err
+}
+
+// subscriptionDiscountDelete deletes a subscription discount
+func (st *storeImplementation) subscriptionDiscountDelete(ctx context.Context, id string) error {
+	_, err := st.query().Table(st.subscriptionDiscountTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	return err
+}
+
+// subscriptionDiscountRow returns the columns of the subscription discount,
+// which are written on both create and update
+func (st *storeImplementation) subscriptionDiscountRow(discount SubscriptionDiscountInterface) map[string]any {
+	return map[string]any{
+		COLUMN_SUBSCRIPTION_ID:   discount.GetSubscriptionID(),
+		COLUMN_COUPON_ID:         discount.GetCouponID(),
+		COLUMN_PROMOTION_CODE_ID: discount.GetPromotionCodeID(),
+		COLUMN_STARTS_AT:         dateTimeValue(discount.GetStartsAtCarbon()),
+		COLUMN_ENDS_AT:           dateTimeValue(discount.GetEndsAtCarbon()),
+		COLUMN_UPDATED_AT:        dateTimeValue(discount.GetUpdatedAtCarbon()),
+	}
+}
+
+// buildSubscriptionDiscountQuery builds a neat query from the subscription discount query interface.
+func (st *storeImplementation) buildSubscriptionDiscountQuery(query SubscriptionDiscountQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriptionID() && query.SubscriptionID() != "" {
+		q = q.Where(COLUMN_SUBSCRIPTION_ID+" = ?", query.SubscriptionID())
+	}
+	if query.HasCouponID() && query.CouponID() != "" {
+		q = q.Where(COLUMN_COUPON_ID+" = ?", query.CouponID())
+	}
+	if query.HasActiveAt() && query.ActiveAt() != "" {
+		activeAt := dateTimeValue(carbon.Parse(query.ActiveAt(), carbon.UTC))
+		q = q.Where(COLUMN_STARTS_AT+" <= ?", activeAt).Where(COLUMN_ENDS_AT+" > ?", activeAt)
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
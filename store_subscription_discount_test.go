@@ -0,0 +1,300 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+// initDiscountSubscription creates a monthly plan of 20.00 USD and an
+// active subscription to it, in its January 2025 billing period
+func initDiscountSubscription(t *testing.T, store StoreInterface) SubscriptionInterface {
+	ctx := context.Background()
+	plan := NewPlan().
+		SetTitle("Pro").
+		SetPrice("20.00").
+		SetCurrency(CURRENCY_USD).
+		SetInterval(PLAN_INTERVAL_MONTHLY).
+		SetStatus(PLAN_STATUS_ACTIVE)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(plan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetPeriodEnd("2025-02-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return subscription
+}
+
+func createCoupon(t *testing.T, store StoreInterface, coupon CouponInterface) CouponInterface {
+	if err := store.CouponCreate(context.Background(), coupon); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return coupon
+}
+
+func expectPeriodTotal(t *testing.T, store StoreInterface, subscriptionID string, periodStart string, total string) {
+	t.Helper()
+	price, err := store.SubscriptionPriceForPeriod(context.Background(), subscriptionID, periodStart)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Total != total {
+		t.Errorf("expected total %s for the period starting %s, got %s", total, periodStart, price.Total)
+	}
+}
+
+func TestStoreSubscriptionApplyCouponRepeating(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := initDiscountSubscription(t, store)
+	coupon := createCoupon(t, store, NewCoupon().
+		SetPercentOff("25").
+		SetDuration(COUPON_DURATION_REPEATING).
+		SetDurationPeriods(3))
+
+	discount, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), coupon.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if discount.GetStartsAt() != "2025-01-01 00:00:00" || discount.GetEndsAt() != "2025-04-01 00:00:00" {
+		t.Errorf("expected the discount for January to March, got %s - %s", discount.GetStartsAt(), discount.GetEndsAt())
+	}
+
+	price, err := store.SubscriptionPriceForPeriod(ctx, subscription.GetID(), "2025-01-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Subtotal != "20.00" || price.Discount != "5.00" || price.Total != "15.00" || price.CouponID != coupon.GetID() {
+		t.Errorf("unexpected price: %+v", price)
+	}
+	if price.PeriodEnd != "2025-02-01 00:00:00" {
+		t.Errorf("expected the period to end 2025-02-01 00:00:00, got %s", price.PeriodEnd)
+	}
+
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-03-01 00:00:00", "15.00")
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-04-01 00:00:00", "20.00")
+
+	invoice, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-02-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if invoice.GetAmount() != "15.00" {
+		t.Errorf("expected the invoice to bill the discounted 15.00, got %s", invoice.GetAmount())
+	}
+	snapshot, err := invoice.GetPlanSnapshot()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if snapshot[COLUMN_COUPON_ID] != coupon.GetID() || snapshot[COLUMN_PRICE] != "20.00" {
+		t.Errorf("expected the snapshot to record the coupon and the plan price, got %v", snapshot)
+	}
+
+	redeemed, err := store.CouponFindByID(ctx, coupon.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if redeemed.GetTimesRedeemed() != 1 {
+		t.Errorf("expected the coupon to be redeemed once, got %d", redeemed.GetTimesRedeemed())
+	}
+
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), coupon.GetID()); err == nil {
+		t.Error("expected error applying the running coupon again")
+	}
+}
+
+func TestStoreSubscriptionApplyCouponAfterInvoicedPeriod(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := initDiscountSubscription(t, store)
+
+	if _, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), subscription.GetPeriodStart()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	once := createCoupon(t, store, NewCoupon().SetPercentOff("50"))
+	discount, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), once.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if discount.GetStartsAt() != "2025-02-01 00:00:00" || discount.GetEndsAt() != "2025-03-01 00:00:00" {
+		t.Errorf("expected the discount for February only, got %s - %s", discount.GetStartsAt(), discount.GetEndsAt())
+	}
+
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-01-01 00:00:00", "20.00")
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-02-01 00:00:00", "10.00")
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-03-01 00:00:00", "20.00")
+
+	// A new coupon replaces the current one, which has not started yet
+	forever := createCoupon(t, store, NewCoupon().
+		SetType(COUPON_TYPE_AMOUNT).
+		SetAmountOff("2.50").
+		SetCurrency(CURRENCY_USD).
+		SetDuration(COUPON_DURATION_FOREVER))
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), forever.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expectPeriodTotal(t, store, subscription.GetID(), "2025-02-01 00:00:00", "17.50")
+	expectPeriodTotal(t, store, subscription.GetID(), "2030-02-01 00:00:00", "17.50")
+
+	discounts, err := store.SubscriptionDiscountList(ctx, SubscriptionDiscountQuery().SetSubscriptionID(subscription.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(discounts) != 1 {
+		t.Fatalf("expected the discount not started yet to be deleted, got %d discounts", len(discounts))
+	}
+	if discounts[0].GetCouponID() != forever.GetID() {
+		t.Errorf("expected the discount of the new coupon, got coupon %s", discounts[0].GetCouponID())
+	}
+}
+
+func TestStoreSubscriptionApplyCouponRedeemedConcurrently(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := initDiscountSubscription(t, store)
+
+	coupon := createCoupon(t, store, NewCoupon().SetPercentOff("10").SetMaxRedemptions(1))
+
+	// Both redemptions read the coupon before either counted it
+	st := store.(*storeImplementation)
+	if err := st.couponRedeem(coupon, nil); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	coupon.SetTimesRedeemed(0)
+	if err := st.couponRedeem(coupon, nil); err == nil {
+		t.Error("expected error counting a redemption over max_redemptions")
+	}
+
+	redeemed, err := store.CouponFindByID(ctx, coupon.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if redeemed.GetTimesRedeemed() != 1 {
+		t.Errorf("expected the coupon to be redeemed once, got %d", redeemed.GetTimesRedeemed())
+	}
+
+	// A fully redeemed promotion code rolls back the coupon redemption
+	unlimited := createCoupon(t, store, NewCoupon().SetPercentOff("10"))
+	code := NewPromotionCode().SetCouponID(unlimited.GetID()).SetCode("TAKEN").SetMaxRedemptions(1).SetTimesRedeemed(1)
+	if err := store.PromotionCodeCreate(ctx, code); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	code.SetTimesRedeemed(0)
+	err = st.transaction(func(txStore *storeImplementation) error {
+		return txStore.couponRedeem(unlimited, code)
+	})
+	if err == nil {
+		t.Error("expected error for a fully redeemed promotion code")
+	}
+
+	unlimited, err = store.CouponFindByID(ctx, unlimited.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if unlimited.GetTimesRedeemed() != 0 {
+		t.Errorf("expected the coupon redemption to be rolled back, got %d", unlimited.GetTimesRedeemed())
+	}
+
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), unlimited.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreSubscriptionApplyCouponErrors(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := initDiscountSubscription(t, store)
+
+	euros := createCoupon(t, store, NewCoupon().SetType(COUPON_TYPE_AMOUNT).SetAmountOff("5.00").SetCurrency(CURRENCY_EUR))
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), euros.GetID()); err == nil {
+		t.Error("expected error for an amount off in another currency")
+	}
+
+	expired := createCoupon(t, store, NewCoupon().SetPercentOff("10").SetRedeemBy("2020-01-01 00:00:00"))
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), expired.GetID()); err == nil {
+		t.Error("expected error for an expired coupon")
+	}
+
+	redeemed := createCoupon(t, store, NewCoupon().SetPercentOff("10").SetMaxRedemptions(1).SetTimesRedeemed(1))
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), redeemed.GetID()); err == nil {
+		t.Error("expected error for a fully redeemed coupon")
+	}
+
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), "missing"); err == nil {
+		t.Error("expected error for a missing coupon")
+	}
+
+	valid := createCoupon(t, store, NewCoupon().SetPercentOff("10"))
+	subscription.SetStatus(SUBSCRIPTION_STATUS_CANCELLED)
+	if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := store.SubscriptionApplyCoupon(ctx, subscription.GetID(), valid.GetID()); err == nil {
+		t.Error("expected error for a cancelled subscription")
+	}
+}
+
+func TestStoreSubscriptionApplyPromotionCode(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	first := initDiscountSubscription(t, store)
+	second := initDiscountSubscription(t, store)
+
+	coupon := createCoupon(t, store, NewCoupon().SetPercentOff("10").SetDuration(COUPON_DURATION_FOREVER))
+	promotionCode := NewPromotionCode().SetCouponID(coupon.GetID()).SetCode("WELCOME").SetMaxRedemptions(1)
+	if err := store.PromotionCodeCreate(ctx, promotionCode); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	discount, err := store.SubscriptionApplyPromotionCode(ctx, first.GetID(), "welcome")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if discount.GetPromotionCodeID() != promotionCode.GetID() {
+		t.Errorf("expected the discount to record the promotion code, got %s", discount.GetPromotionCodeID())
+	}
+	expectPeriodTotal(t, store, first.GetID(), "2025-06-01 00:00:00", "18.00")
+
+	if _, err := store.SubscriptionApplyPromotionCode(ctx, second.GetID(), "WELCOME"); err == nil {
+		t.Error("expected error for a fully redeemed promotion code")
+	}
+
+	expiredCode := NewPromotionCode().SetCouponID(coupon.GetID()).SetCode("LASTYEAR").SetExpiresAt("2020-01-01 00:00:00")
+	if err := store.PromotionCodeCreate(ctx, expiredCode); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := store.SubscriptionApplyPromotionCode(ctx, second.GetID(), "LASTYEAR"); err == nil {
+		t.Error("expected error for an expired promotion code")
+	}
+
+	if _, err := store.SubscriptionApplyPromotionCode(ctx, second.GetID(), "NOSUCHCODE"); err == nil {
+		t.Error("expected error for an unknown promotion code")
+	}
+}
@@ -0,0 +1,229 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionItemCreate adds the plan of the item to its subscription.
+//
+// The plan must have the currency and the interval of the subscription's
+// plan, as it is billed with it, for the same billing period. A plan can be
+// added to a subscription only once; change the quantity of the item
+// instead. A unique index on the subscription and the plan rejects
+// concurrent adds of the same plan.
+func (st *storeImplementation) SubscriptionItemCreate(ctx context.Context, item SubscriptionItemInterface) error {
+	if item == nil {
+		return errors.New("subscriptionstore > subscription item create. item cannot be nil")
+	}
+	if item.GetSubscriptionID() == "" {
+		return errors.New("subscriptionstore > subscription item create. subscription id cannot be empty")
+	}
+	if item.GetPlanID() == "" {
+		return errors.New("subscriptionstore > subscription item create. plan id cannot be empty")
+	}
+	if item.GetQuantity() < 1 {
+		return errors.New("subscriptionstore > subscription item create. quantity must be at least 1")
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, item.GetSubscriptionID())
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription item create. subscription not found")
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return errors.New("subscriptionstore > subscription item create. subscription is cancelled")
+	}
+	if subscription.GetPlanID() == item.GetPlanID() {
+		return errors.New("subscriptionstore > subscription item create. plan is the plan of the subscription")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return errors.New("subscriptionstore > subscription item create. subscription plan not found")
+	}
+
+	itemPlan, err := st.PlanFindByID(ctx, item.GetPlanID())
+	if err != nil {
+		return err
+	}
+	if itemPlan == nil {
+		return errors.New("subscriptionstore > subscription item create. plan not found")
+	}
+
+	if !strings.EqualFold(itemPlan.GetCurrency(), plan.GetCurrency()) {
+		return errors.New("subscriptionstore > subscription item create. plan currency " + itemPlan.GetCurrency() + " does not match " + plan.GetCurrency())
+	}
+	if itemPlan.GetInterval() != plan.GetInterval() {
+		return errors.New("subscriptionstore > subscription item create. plan interval " + itemPlan.GetInterval() + " does not match " + plan.GetInterval())
+	}
+
+	existing, err := st.SubscriptionItemList(ctx, SubscriptionItemQuery().
+		SetSubscriptionID(item.GetSubscriptionID()).
+		SetPlanID(item.GetPlanID()).
+		SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return errors.New("subscriptionstore > subscription item create. plan already added to the subscription")
+	}
+
+	if item.GetCreatedAt() == "" {
+		item.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if item.GetUpdatedAt() == "" {
+		item.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row := map[string]any{
+		COLUMN_ID:              item.GetID(),
+		COLUMN_SUBSCRIPTION_ID: item.GetSubscriptionID(),
+		COLUMN_PLAN_ID:         item.GetPlanID(),
+		COLUMN_QUANTITY:        item.GetQuantity(),
+		COLUMN_CREATED_AT:      dateTimeValue(item.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(item.GetUpdatedAtCarbon()),
+	}
+
+	return st.query().Table(st.subscriptionItemTableName).Create(row)
+}
+
+// SubscriptionItemList retrieves a list of subscription items
+func (st *storeImplementation) SubscriptionItemList(ctx context.Context, query SubscriptionItemQueryInterface) ([]SubscriptionItemInterface, error) {
+	if query == nil {
+		return []SubscriptionItemInterface{}, errors.New("at subscription item list > subscription item query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []SubscriptionItemInterface{}, err
+	}
+
+	q := st.buildSubscriptionItemQuery(query)
+
+	type subscriptionItemRow struct {
+		ID             string    `db:"id"`
+		SubscriptionID string    `db:"subscription_id"`
+		PlanID         string    `db:"plan_id"`
+		Quantity       int       `db:"quantity"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}
+
+	var rows []subscriptionItemRow
+	if err := q.Table(st.subscriptionItemTableName).Get(&rows); err != nil {
+		return []SubscriptionItemInterface{}, err
+	}
+
+	list := make([]SubscriptionItemInterface, 0, len(rows))
+	for _, r := range rows {
+		i := &subscriptionItemImplementation{}
+		i.SetID(r.ID)
+		i.SetSubscriptionID(r.SubscriptionID)
+		i.SetPlanID(r.PlanID)
+		i.SetQuantity(r.Quantity)
+		i.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		i.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, i)
+	}
+
+	return list, nil
+}
+
+// SubscriptionItemRemove removes an item from its subscription. The plan of
+// the item is no longer billed from the next billing period which has not
+// been invoiced.
+func (st *storeImplementation) SubscriptionItemRemove(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("subscription item id is empty")
+	}
+	_, err := st.query().Table(st.subscriptionItemTableName).Where(COLUMN_ID+" = ?", id).Delete()
+	return err
+}
+
+// subscriptionItemLines returns the price lines of the items of the
+// subscription, which is on the given plan
+func (st *storeImplementation) subscriptionItemLines(ctx context.Context, subscription SubscriptionInterface, plan PlanInterface) ([]PriceLine, error) {
+	items, err := st.SubscriptionItemList(ctx, SubscriptionItemQuery().
+		SetSubscriptionID(subscription.GetID()).
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortOrder("asc"))
+	if err != nil {
+		return nil, err
+	}
+
+	itemPlans := map[string]PlanInterface{}
+	for _, item := range items {
+		itemPlan, err := st.PlanFindByID(ctx, item.GetPlanID())
+		if err != nil {
+			return nil, err
+		}
+		if itemPlan != nil {
+			itemPlans[itemPlan.GetID()] = itemPlan
+		}
+	}
+
+	return itemPriceLines(items, itemPlans, plan)
+}
+
+// itemPriceLines returns the price lines of the items, priced with their
+// plans by ID, for a subscription on the given plan
+func itemPriceLines(items []SubscriptionItemInterface, itemPlans map[string]PlanInterface, plan PlanInterface) ([]PriceLine, error) {
+	lines := make([]PriceLine, 0, len(items))
+	for _, item := range items {
+		itemPlan := itemPlans[item.GetPlanID()]
+		if itemPlan == nil {
+			return nil, errors.New("plan " + item.GetPlanID() + " of subscription item not found")
+		}
+		if !strings.EqualFold(itemPlan.GetCurrency(), plan.GetCurrency()) {
+			return nil, errors.New("plan currency " + itemPlan.GetCurrency() + " of subscription item does not match " + plan.GetCurrency())
+		}
+
+		amount, err := CalculateAmount(itemPlan, item.GetQuantity())
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, PriceLine{PlanID: itemPlan.GetID(), Quantity: item.GetQuantity(), Amount: amount})
+	}
+
+	return lines, nil
+}
+
+// buildSubscriptionItemQuery builds a neat query from the subscription item query interface.
+func (st *storeImplementation) buildSubscriptionItemQuery(query SubscriptionItemQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriptionID() && query.SubscriptionID() != "" {
+		q = q.Where(COLUMN_SUBSCRIPTION_ID+" = ?", query.SubscriptionID())
+	}
+	if query.HasPlanID() && query.PlanID() != "" {
+		q = q.Where(COLUMN_PLAN_ID+" = ?", query.PlanID())
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,172 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreSubscriptionItems(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Team").SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	storage := NewPlan().SetTitle("Extra storage").SetPrice("2.50").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	support := NewPlan().SetTitle("Priority support").SetPrice("15.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY).
+		SetPricingModel(PLAN_PRICING_MODEL_FLAT)
+	for _, p := range []PlanInterface{plan, storage, support} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("team_1").
+		SetPlanID(plan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetQuantity(2)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	storageItem := NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(storage.GetID()).SetQuantity(4)
+	if err := store.SubscriptionItemCreate(ctx, storageItem); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	supportItem := NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(support.GetID()).SetQuantity(2)
+	if err := store.SubscriptionItemCreate(ctx, supportItem); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	items, err := store.SubscriptionItemList(ctx, SubscriptionItemQuery().SetSubscriptionID(subscription.GetID()))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	// 2 seats at 10.00, 4 x 2.50 of storage and flat 15.00 of support
+	price, err := store.SubscriptionPriceForPeriod(ctx, subscription.GetID(), "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Subtotal != "45.00" || price.Total != "45.00" {
+		t.Errorf("expected 45.00, got subtotal %s total %s", price.Subtotal, price.Total)
+	}
+	if len(price.Lines) != 3 || price.Lines[0].PlanID != plan.GetID() || price.Lines[1].Amount != "10.00" || price.Lines[2].Amount != "15.00" {
+		t.Errorf("unexpected price lines: %+v", price.Lines)
+	}
+
+	invoice, err := store.InvoiceCreateForPeriod(ctx, subscription.GetID(), "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if invoice.GetAmount() != "45.00" {
+		t.Errorf("expected invoice of 45.00, got %s", invoice.GetAmount())
+	}
+
+	if err := store.SubscriptionItemRemove(ctx, supportItem.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	price, err = store.SubscriptionPriceForPeriod(ctx, subscription.GetID(), "2025-04-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Total != "30.00" {
+		t.Errorf("expected 30.00 without the removed item, got %s", price.Total)
+	}
+}
+
+func TestStoreSubscriptionItemCreateErrors(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	addOn := NewPlan().SetPrice("2.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	yearly := NewPlan().SetPrice("20.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_YEARLY)
+	euro := NewPlan().SetPrice("2.00").SetCurrency(CURRENCY_EUR).SetInterval(PLAN_INTERVAL_MONTHLY)
+	for _, p := range []PlanInterface{plan, addOn, yearly, euro} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SubscriptionItemCreate(ctx, NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		name string
+		item SubscriptionItemInterface
+	}{
+		{name: "nil item", item: nil},
+		{name: "missing subscription", item: NewSubscriptionItem().SetSubscriptionID("missing").SetPlanID(addOn.GetID())},
+		{name: "missing plan", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID("missing")},
+		{name: "zero quantity", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID()).SetQuantity(0)},
+		{name: "plan of the subscription", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(plan.GetID())},
+		{name: "plan already added", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID())},
+		{name: "other interval", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(yearly.GetID())},
+		{name: "other currency", item: NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(euro.GetID())},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := store.SubscriptionItemCreate(ctx, tc.item); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestStoreSubscriptionItemUniqueIndex(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	addOn := NewPlan().SetPrice("2.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	for _, p := range []PlanInterface{plan, addOn} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SubscriptionItemCreate(ctx, NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// A concurrent add passes the check before the first item is written,
+	// and is rejected by the index
+	st := store.(*storeImplementation)
+	duplicate := NewSubscriptionItem().SetSubscriptionID(subscription.GetID()).SetPlanID(addOn.GetID())
+	err = st.db.Query().Table(st.subscriptionItemTableName).Create(map[string]any{
+		COLUMN_ID:              duplicate.GetID(),
+		COLUMN_SUBSCRIPTION_ID: duplicate.GetSubscriptionID(),
+		COLUMN_PLAN_ID:         duplicate.GetPlanID(),
+		COLUMN_QUANTITY:        duplicate.GetQuantity(),
+		COLUMN_CREATED_AT:      dateTimeValue(duplicate.GetCreatedAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(duplicate.GetUpdatedAtCarbon()),
+	})
+	if err == nil {
+		t.Error("expected the unique index to reject the duplicate item")
+	}
+}
@@ -0,0 +1,126 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionPause pauses an active subscription until the given resume date.
+// The remaining billing period is preserved, and is extended by the paused
+// duration when the subscription is resumed.
+func (st *storeImplementation) SubscriptionPause(ctx context.Context, id string, resumeAt string) error {
+	return pauseSubscription(ctx, st, id, resumeAt)
+}
+
+// pauseSubscription pauses the subscription of the store until the resume date
+func pauseSubscription(ctx context.Context, st subscriptionWorkflowStore, id string, resumeAt string) error {
+	subscription, err := st.SubscriptionFindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription pause. subscription not found")
+	}
+	if subscription.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		return errors.New("subscriptionstore > subscription pause. only active subscriptions can be paused")
+	}
+
+	now := carbon.Now(carbon.UTC)
+	resumeAtCarbon := carbon.Parse(resumeAt, carbon.UTC)
+	if resumeAtCarbon.IsInvalid() {
+		return errors.New("subscriptionstore > subscription pause. resume at is not a valid date")
+	}
+	if resumeAtCarbon.Lte(now) {
+		return errors.New("subscriptionstore > subscription pause. resume at must be in the future")
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_PAUSED)
+	subscription.SetPausedAt(now.ToDateTimeString(carbon.UTC))
+	subscription.SetResumeAt(resumeAtCarbon.ToDateTimeString(carbon.UTC))
+
+	return st.SubscriptionUpdate(ctx, subscription)
+}
+
+// SubscriptionResume reactivates a paused subscription, shifting the end of
+// its billing period by the time it spent paused
+func (st *storeImplementation) SubscriptionResume(ctx context.Context, id string) error {
+	return resumeSubscription(ctx, st, id)
+}
+
+// resumeSubscription reactivates the paused subscription of the store
+func resumeSubscription(ctx context.Context, st subscriptionWorkflowStore, id string) error {
+	subscription, err := st.SubscriptionFindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("subscriptionstore > subscription resume. subscription not found")
+	}
+	if !subscription.IsPaused() {
+		return errors.New("subscriptionstore > subscription resume. subscription is not paused")
+	}
+
+	return subscriptionResume(ctx, st, subscription, carbon.Now(carbon.UTC))
+}
+
+// SubscriptionResumeDue resumes all paused subscriptions whose resume date
+// is at or before now, and returns them. It is meant to be run periodically
+// by a background job. The subscriptions failing to resume are skipped, and
+// their errors are returned joined, along with the resumed subscriptions.
+func (st *storeImplementation) SubscriptionResumeDue(ctx context.Context, now string) ([]SubscriptionInterface, error) {
+	return resumeDueSubscriptions(ctx, st, now)
+}
+
+// resumeDueSubscriptions resumes the paused subscriptions of the store due at
+// or before now
+func resumeDueSubscriptions(ctx context.Context, st subscriptionWorkflowStore, now string) ([]SubscriptionInterface, error) {
+	nowCarbon := carbon.Parse(now, carbon.UTC)
+	if nowCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > subscription resume due. now is not a valid date")
+	}
+
+	list, err := st.SubscriptionList(ctx, SubscriptionQuery().
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetResumeAtLte(nowCarbon.ToDateTimeString(carbon.UTC)))
+	if err != nil {
+		return nil, err
+	}
+
+	// A subscription failing to resume does not hold back the others, which
+	// are returned with the errors
+	resumed := []SubscriptionInterface{}
+	var errs []error
+	for _, subscription := range list {
+		if err := subscriptionResume(ctx, st, subscription, nowCarbon); err != nil {
+			errs = append(errs, errors.New("subscriptionstore > subscription resume due. subscription "+subscription.GetID()+": "+err.Error()))
+			continue
+		}
+		resumed = append(resumed, subscription)
+	}
+
+	return resumed, errors.Join(errs...)
+}
+
+// subscriptionResume reactivates the subscription at the given time. The paused
+// duration never exceeds the scheduled resume date, so a late running job does
+// not grant extra days.
+func subscriptionResume(ctx context.Context, st subscriptionWorkflowStore, subscription SubscriptionInterface, now *carbon.Carbon) error {
+	resumedAt := now
+	if subscription.GetResumeAtCarbon().Lt(now) {
+		resumedAt = subscription.GetResumeAtCarbon()
+	}
+
+	pausedSeconds := subscription.GetPausedAtCarbon().DiffInSeconds(resumedAt)
+	if pausedSeconds > 0 && subscription.GetPeriodEnd() != MAX_DATETIME {
+		periodEnd := subscription.GetPeriodEndCarbon().AddSeconds(int(pausedSeconds))
+		subscription.SetPeriodEnd(periodEnd.ToDateTimeString(carbon.UTC))
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	subscription.SetPausedAt(MAX_DATETIME)
+	subscription.SetResumeAt(MAX_DATETIME)
+
+	return st.SubscriptionUpdate(ctx, subscription)
+}
@@ -0,0 +1,247 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreSubscriptionPause(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	resumeAt := carbon.Now(carbon.UTC).AddMonth().ToDateTimeString(carbon.UTC)
+	if err := store.SubscriptionPause(ctx, subscription.GetID(), resumeAt); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	paused, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !paused.IsPaused() {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_PAUSED, paused.GetStatus())
+	}
+	if paused.GetResumeAt() != resumeAt {
+		t.Errorf("expected ResumeAt %s, got %s", resumeAt, paused.GetResumeAt())
+	}
+	if paused.GetPausedAt() == MAX_DATETIME {
+		t.Error("expected PausedAt to be set")
+	}
+
+	if err := store.SubscriptionPause(ctx, subscription.GetID(), resumeAt); err == nil {
+		t.Error("expected error pausing an already paused subscription")
+	}
+}
+
+func TestStoreSubscriptionPauseRequiresFutureResumeDate(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	resumeAt := carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC)
+	if err := store.SubscriptionPause(ctx, subscription.GetID(), resumeAt); err == nil {
+		t.Error("expected error for a resume date in the past")
+	}
+	if err := store.SubscriptionPause(ctx, subscription.GetID(), "not a date"); err == nil {
+		t.Error("expected error for an invalid resume date")
+	}
+}
+
+func TestStoreSubscriptionResume(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	now := carbon.Now(carbon.UTC)
+	subscription := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPeriodEnd(now.AddDays(5).ToDateTimeString(carbon.UTC)).
+		SetPausedAt(now.SubDays(10).ToDateTimeString(carbon.UTC)).
+		SetResumeAt(now.AddDays(20).ToDateTimeString(carbon.UTC))
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SubscriptionResume(ctx, subscription.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	resumed, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if resumed.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_ACTIVE, resumed.GetStatus())
+	}
+	if resumed.GetPausedAt() != MAX_DATETIME || resumed.GetResumeAt() != MAX_DATETIME {
+		t.Error("expected the pause window to be cleared")
+	}
+
+	expected := now.AddDays(15)
+	if diff := resumed.GetPeriodEndCarbon().DiffAbsInSeconds(expected); diff > 5 {
+		t.Errorf("expected PeriodEnd around %s, got %s", expected.ToDateTimeString(carbon.UTC), resumed.GetPeriodEnd())
+	}
+
+	if err := store.SubscriptionResume(ctx, subscription.GetID()); err == nil {
+		t.Error("expected error resuming a subscription which is not paused")
+	}
+}
+
+func TestStoreSubscriptionResumeDue(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	due := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPeriodEnd("2025-01-20 00:00:00").
+		SetPausedAt("2025-01-01 00:00:00").
+		SetResumeAt("2025-01-11 00:00:00")
+	notDue := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPeriodEnd("2025-03-20 00:00:00").
+		SetPausedAt("2025-01-15 00:00:00").
+		SetResumeAt("2025-03-01 00:00:00")
+	for _, subscription := range []SubscriptionInterface{due, notDue} {
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	resumed, err := store.SubscriptionResumeDue(ctx, "2025-02-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(resumed) != 1 || resumed[0].GetID() != due.GetID() {
+		t.Fatalf("expected only subscription %s to be resumed, got %d", due.GetID(), len(resumed))
+	}
+
+	dueFound, err := store.SubscriptionFindByID(ctx, due.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if dueFound.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("expected status %s, got %s", SUBSCRIPTION_STATUS_ACTIVE, dueFound.GetStatus())
+	}
+	// Shifted by the scheduled 10 day pause, not by the late job run
+	if dueFound.GetPeriodEnd() != "2025-01-30 00:00:00" {
+		t.Errorf("expected PeriodEnd 2025-01-30 00:00:00, got %s", dueFound.GetPeriodEnd())
+	}
+
+	notDueFound, err := store.SubscriptionFindByID(ctx, notDue.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !notDueFound.IsPaused() {
+		t.Errorf("expected status %s, got %s", SUBSCRIPTION_STATUS_PAUSED, notDueFound.GetStatus())
+	}
+}
+
+func TestStoreSubscriptionResumeDueSkipsFailures(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	failing := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPaymentMethodID("pm_other").
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPausedAt("2025-01-01 00:00:00").
+		SetResumeAt("2025-01-10 00:00:00")
+	due := NewSubscription().
+		SetSubscriberID("user_2").
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPausedAt("2025-01-01 00:00:00").
+		SetResumeAt("2025-01-11 00:00:00")
+	for _, subscription := range []SubscriptionInterface{failing, due} {
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// The payment method of the failing subscription now belongs to
+	// another subscriber, so the subscription can no longer be updated
+	if err := store.PaymentMethodCreate(ctx, NewPaymentMethod().SetID("pm_other").SetSubscriberID("user_3")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	resumed, err := store.SubscriptionResumeDue(ctx, "2025-02-01 00:00:00")
+	if err == nil {
+		t.Error("expected the error of the failing subscription")
+	}
+	if len(resumed) != 1 || resumed[0].GetID() != due.GetID() {
+		t.Fatalf("expected subscription %s to be resumed, got %d", due.GetID(), len(resumed))
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, failing.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !found.IsPaused() {
+		t.Errorf("expected status %s, got %s", SUBSCRIPTION_STATUS_PAUSED, found.GetStatus())
+	}
+}
+
+func TestRunSubscriptionResumeJob(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_PAUSED).
+		SetPausedAt("2025-01-01 00:00:00").
+		SetResumeAt("2025-01-11 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	err = RunSubscriptionResumeJob(jobCtx, store, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected context deadline error, got:", err)
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("expected status %s, got %s", SUBSCRIPTION_STATUS_ACTIVE, found.GetStatus())
+	}
+
+	if err := RunSubscriptionResumeJob(ctx, store, 0); err == nil {
+		t.Error("expected error for a zero interval")
+	}
+}
@@ -0,0 +1,120 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionHasSeats returns true if the subscription is entitled to the
+// given number of seats: it is active, or past due and still being retried,
+// and its quantity covers the seats
+func (st *storeImplementation) SubscriptionHasSeats(ctx context.Context, subscriptionID string, seats int) (bool, error) {
+	if seats < 0 {
+		return false, errors.New("subscriptionstore > subscription has seats. seats cannot be negative")
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+	if subscription == nil {
+		return false, errors.New("subscriptionstore > subscription has seats. subscription not found")
+	}
+
+	if subscription.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE && subscription.GetStatus() != SUBSCRIPTION_STATUS_PAST_DUE {
+		return false, nil
+	}
+
+	return subscription.GetQuantity() >= seats, nil
+}
+
+// SubscriptionSetQuantity changes the number of seats of the subscription,
+// and returns the proration of the change for the rest of the current
+// billing period: the difference between the prices of the new and the old
+// quantity, discounts included, times the share of the period left.
+// The next billing periods are charged for the new quantity.
+//
+// Only active and past due subscriptions are prorated; for other statuses
+// the amount of the proration is zero.
+func (st *storeImplementation) SubscriptionSetQuantity(ctx context.Context, subscriptionID string, quantity int) (Proration, error) {
+	return st.subscriptionSetQuantity(ctx, subscriptionID, quantity, carbon.Now(carbon.UTC))
+}
+
+// subscriptionSetQuantity changes the quantity, prorated at the given time
+func (st *storeImplementation) subscriptionSetQuantity(ctx context.Context, subscriptionID string, quantity int, at *carbon.Carbon) (Proration, error) {
+	if quantity < 1 {
+		return Proration{}, errors.New("subscriptionstore > subscription set quantity. quantity must be at least 1")
+	}
+
+	subscription, err := st.SubscriptionFindByID(ctx, subscriptionID)
+	if err != nil {
+		return Proration{}, err
+	}
+	if subscription == nil {
+		return Proration{}, errors.New("subscriptionstore > subscription set quantity. subscription not found")
+	}
+	if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+		return Proration{}, errors.New("subscriptionstore > subscription set quantity. subscription is cancelled")
+	}
+
+	plan, err := st.subscriptionPlan(ctx, subscription)
+	if err != nil {
+		return Proration{}, err
+	}
+	if plan == nil {
+		return Proration{}, errors.New("subscriptionstore > subscription set quantity. plan not found")
+	}
+
+	proration := Proration{
+		SubscriptionID: subscription.GetID(),
+		FromQuantity:   subscription.GetQuantity(),
+		ToQuantity:     quantity,
+		ProratedAt:     at.ToDateTimeString(carbon.UTC),
+		PeriodEnd:      subscription.GetPeriodEnd(),
+		Currency:       plan.GetCurrency(),
+		Amount:         centsAmount(0),
+	}
+
+	if quantity == subscription.GetQuantity() {
+		return proration, nil
+	}
+
+	status := subscription.GetStatus()
+	if status == SUBSCRIPTION_STATUS_ACTIVE || status == SUBSCRIPTION_STATUS_PAST_DUE {
+		periodStart := subscription.GetPeriodStartCarbon()
+
+		coupon, err := st.periodCoupon(ctx, subscription, periodStart)
+		if err != nil {
+			return Proration{}, err
+		}
+
+		from, err := CalculatePriceForQuantity(plan, subscription.GetQuantity(), coupon)
+		if err != nil {
+			return Proration{}, errors.New("subscriptionstore > subscription set quantity. " + err.Error())
+		}
+		to, err := CalculatePriceForQuantity(plan, quantity, coupon)
+		if err != nil {
+			return Proration{}, errors.New("subscriptionstore > subscription set quantity. " + err.Error())
+		}
+
+		fromCents, err := amountCents(from.Total)
+		if err != nil {
+			return Proration{}, err
+		}
+		toCents, err := amountCents(to.Total)
+		if err != nil {
+			return Proration{}, err
+		}
+
+		proration.Amount = centsAmount(prorateCents(toCents-fromCents, periodStart, subscription.GetPeriodEndCarbon(), at))
+	}
+
+	subscription.SetQuantity(quantity)
+	if err := st.SubscriptionUpdate(ctx, subscription); err != nil {
+		return Proration{}, err
+	}
+
+	return proration, nil
+}
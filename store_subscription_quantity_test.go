@@ -0,0 +1,165 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreSubscriptionSetQuantity(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().
+		SetTitle("Team").
+		SetPrice("10.00").
+		SetCurrency(CURRENCY_USD).
+		SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("team_1").
+		SetPlanID(plan.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetQuantity(2).
+		SetPeriodStart("2025-04-01 00:00:00").
+		SetPeriodEnd("2025-05-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	st := store.(*storeImplementation)
+
+	// Three more seats with two thirds of the period left
+	proration, err := st.subscriptionSetQuantity(ctx, subscription.GetID(), 5, carbon.Parse("2025-04-11 00:00:00", carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if proration.FromQuantity != 2 || proration.ToQuantity != 5 {
+		t.Errorf("expected 2 to 5 seats, got %d to %d", proration.FromQuantity, proration.ToQuantity)
+	}
+	if proration.Amount != "20.00" || proration.Currency != CURRENCY_USD {
+		t.Errorf("expected a charge of 20.00 USD, got %s %s", proration.Amount, proration.Currency)
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetQuantity() != 5 {
+		t.Fatalf("expected quantity 5, got %d", found.GetQuantity())
+	}
+
+	// Dropping seats with a third of the period left is credited
+	proration, err = st.subscriptionSetQuantity(ctx, subscription.GetID(), 2, carbon.Parse("2025-04-21 00:00:00", carbon.UTC))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if proration.Amount != "-10.00" {
+		t.Errorf("expected a credit of -10.00, got %s", proration.Amount)
+	}
+
+	// The next periods are billed for the new quantity
+	price, err := store.SubscriptionPriceForPeriod(ctx, subscription.GetID(), "2025-05-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if price.Quantity != 2 || price.Total != "20.00" {
+		t.Errorf("expected 2 seats for 20.00, got %d seats for %s", price.Quantity, price.Total)
+	}
+
+	if _, err := store.SubscriptionSetQuantity(ctx, subscription.GetID(), 0); err == nil {
+		t.Error("expected error for a zero quantity")
+	}
+	if _, err := store.SubscriptionSetQuantity(ctx, "missing", 3); err == nil {
+		t.Error("expected error for a missing subscription")
+	}
+}
+
+func TestStoreSubscriptionSetQuantityInactive(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription := NewSubscription().SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_INACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	proration, err := store.SubscriptionSetQuantity(ctx, subscription.GetID(), 3)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if proration.Amount != "0.00" {
+		t.Errorf("expected nothing prorated for an inactive subscription, got %s", proration.Amount)
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_CANCELLED)
+	if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := store.SubscriptionSetQuantity(ctx, subscription.GetID(), 4); err == nil {
+		t.Error("expected error for a cancelled subscription")
+	}
+}
+
+func TestStoreSubscriptionHasSeats(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscription := NewSubscription().
+		SetSubscriberID("team_1").
+		SetPlanID("plan_1").
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetQuantity(3)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testCases := []struct {
+		seats    int
+		expected bool
+	}{
+		{seats: 1, expected: true},
+		{seats: 3, expected: true},
+		{seats: 4, expected: false},
+	}
+
+	for _, tc := range testCases {
+		has, err := store.SubscriptionHasSeats(ctx, subscription.GetID(), tc.seats)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if has != tc.expected {
+			t.Errorf("expected %v for %d seats, got %v", tc.expected, tc.seats, has)
+		}
+	}
+
+	subscription.SetStatus(SUBSCRIPTION_STATUS_CANCELLED)
+	if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if has, _ := store.SubscriptionHasSeats(ctx, subscription.GetID(), 1); has {
+		t.Error("expected a cancelled subscription to have no seats")
+	}
+
+	if _, err := store.SubscriptionHasSeats(ctx, subscription.GetID(), -1); err == nil {
+		t.Error("expected error for negative seats")
+	}
+}
@@ -0,0 +1,457 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	contractsorm "github.com/dracory/neat/contracts/database/orm"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionScheduleCreate schedules the phases of a subscription.
+//
+// Without a SubscriptionID the subscription of the subscriber is created at
+// the start of the first phase, i.e. to start an enterprise plan on March
+// 1st. With a SubscriptionID the existing subscription is changed at the
+// start of each phase, i.e. to switch to the yearly plan at the next renewal
+// with a phase starting at the end of the current period. A subscription has
+// at most one active schedule.
+func (st *storeImplementation) SubscriptionScheduleCreate(ctx context.Context, schedule SubscriptionScheduleInterface) error {
+	if schedule == nil {
+		return errors.New("subscriptionstore > subscription schedule create. schedule cannot be nil")
+	}
+
+	phases, err := schedule.GetPhases()
+	if err != nil {
+		return err
+	}
+	phases, err = schedulePhasesNormalize(phases)
+	if err != nil {
+		return errors.New("subscriptionstore > subscription schedule create. " + err.Error())
+	}
+
+	// The phases are applied to the same subscription, so their plans are
+	// priced in the same currency
+	currency := ""
+	for _, phase := range phases {
+		plan, err := st.PlanFindByID(ctx, phase.PlanID)
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			return errors.New("subscriptionstore > subscription schedule create. plan " + phase.PlanID + " not found")
+		}
+		if currency == "" {
+			currency = plan.GetCurrency()
+		}
+		if plan.GetCurrency() != "" && !strings.EqualFold(plan.GetCurrency(), currency) {
+			return errors.New("subscriptionstore > subscription schedule create. plan " + phase.PlanID + " is priced in " + plan.GetCurrency() + ", not in " + currency)
+		}
+	}
+
+	if schedule.GetSubscriptionID() != "" {
+		subscription, err := st.SubscriptionFindByID(ctx, schedule.GetSubscriptionID())
+		if err != nil {
+			return err
+		}
+		if subscription == nil {
+			return errors.New("subscriptionstore > subscription schedule create. subscription not found")
+		}
+		if subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+			return errors.New("subscriptionstore > subscription schedule create. subscription is cancelled")
+		}
+		if schedule.GetSubscriberID() == "" {
+			schedule.SetSubscriberID(subscription.GetSubscriberID())
+		}
+		if schedule.GetSubscriberID() != subscription.GetSubscriberID() {
+			return errors.New("subscriptionstore > subscription schedule create. subscription belongs to another subscriber")
+		}
+		if currency != "" && subscription.GetCurrency() != "" && !strings.EqualFold(subscription.GetCurrency(), currency) {
+			return errors.New("subscriptionstore > subscription schedule create. plans are priced in " + currency + ", not in the subscription currency " + subscription.GetCurrency())
+		}
+
+		active, err := st.SubscriptionScheduleList(ctx, SubscriptionScheduleQuery().
+			SetSubscriptionID(subscription.GetID()).
+			SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE).
+			SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if len(active) > 0 {
+			return errors.New("subscriptionstore > subscription schedule create. subscription already has an active schedule")
+		}
+	}
+
+	if schedule.GetSubscriberID() == "" {
+		return errors.New("subscriptionstore > subscription schedule create. subscriber id cannot be empty")
+	}
+
+	if _, err := schedule.SetPhases(phases); err != nil {
+		return err
+	}
+	schedule.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE)
+	schedule.SetCurrentPhase(-1)
+	schedule.SetNextPhaseAt(scheduleNextPhaseAt(phases, -1))
+
+	if schedule.GetCreatedAt() == "" {
+		schedule.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+	if schedule.GetUpdatedAt() == "" {
+		schedule.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	}
+
+	row, err := st.subscriptionScheduleRow(schedule)
+	if err != nil {
+		return err
+	}
+	row[COLUMN_ID] = schedule.GetID()
+	row[COLUMN_CREATED_AT] = dateTimeValue(schedule.GetCreatedAtCarbon())
+
+	return st.query().Table(st.subscriptionScheduleTableName).Create(row)
+}
+
+// SubscriptionScheduleCancel releases the subscription from an active
+// schedule. The subscription stays as it is.
+func (st *storeImplementation) SubscriptionScheduleCancel(ctx context.Context, id string) error {
+	schedule, err := st.SubscriptionScheduleFindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if schedule == nil {
+		return errors.New("subscriptionstore > subscription schedule cancel. schedule not found")
+	}
+	if schedule.GetStatus() != SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE {
+		return errors.New("subscriptionstore > subscription schedule cancel. schedule is " + schedule.GetStatus())
+	}
+
+	schedule.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_CANCELLED)
+	schedule.SetNextPhaseAt(MAX_DATETIME)
+	return st.subscriptionScheduleUpdate(ctx, schedule)
+}
+
+// SubscriptionScheduleFindByID finds a subscription schedule by id
+func (st *storeImplementation) SubscriptionScheduleFindByID(ctx context.Context, id string) (SubscriptionScheduleInterface, error) {
+	if id == "" {
+		return nil, errors.New("subscription schedule id is empty")
+	}
+	list, err := st.SubscriptionScheduleList(ctx, SubscriptionScheduleQuery().SetID(id).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+	return nil, nil
+}
+
+// SubscriptionScheduleList retrieves a list of subscription schedules
+func (st *storeImplementation) SubscriptionScheduleList(ctx context.Context, query SubscriptionScheduleQueryInterface) ([]SubscriptionScheduleInterface, error) {
+	if query == nil {
+		return []SubscriptionScheduleInterface{}, errors.New("at subscription schedule list > subscription schedule query is nil")
+	}
+	if err := query.Validate(); err != nil {
+		return []SubscriptionScheduleInterface{}, err
+	}
+
+	q := st.buildSubscriptionScheduleQuery(query)
+
+	type subscriptionScheduleRow struct {
+		ID             string    `db:"id"`
+		SubscriberID   string    `db:"subscriber_id"`
+		SubscriptionID string    `db:"subscription_id"`
+		Status         string    `db:"status"`
+		Phases         string    `db:"phases"`
+		CurrentPhase   int       `db:"current_phase"`
+		NextPhaseAt    time.Time `db:"next_phase_at"`
+		CreatedAt      time.Time `db:"created_at"`
+		UpdatedAt      time.Time `db:"updated_at"`
+	}
+
+	var rows []subscriptionScheduleRow
+	if err := q.Table(st.subscriptionScheduleTableName).Get(&rows); err != nil {
+		return []SubscriptionScheduleInterface{}, err
+	}
+
+	list := make([]SubscriptionScheduleInterface, 0, len(rows))
+	for _, r := range rows {
+		s := &subscriptionScheduleImplementation{}
+		s.SetID(r.ID)
+		s.SetSubscriberID(r.SubscriberID)
+		s.SetSubscriptionID(r.SubscriptionID)
+		s.SetStatus(r.Status)
+		s.PhasesField = r.Phases
+		s.SetCurrentPhase(r.CurrentPhase)
+		s.SetNextPhaseAt(carbon.CreateFromStdTime(r.NextPhaseAt, carbon.UTC).ToDateTimeString(carbon.UTC))
+		s.CreatedAtField.CreatedAt = r.CreatedAt.UTC()
+		s.UpdatedAtField.UpdatedAt = r.UpdatedAt.UTC()
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+// SubscriptionScheduleRunDue applies the phases of the active schedules
+// which started by now, and returns the schedules which were run. When
+// several phases started since the last run, only the latest is applied.
+// A schedule whose subscription was cancelled meanwhile is cancelled.
+func (st *storeImplementation) SubscriptionScheduleRunDue(ctx context.Context, now string) ([]SubscriptionScheduleInterface, error) {
+	nowCarbon := carbon.Parse(now, carbon.UTC)
+	if nowCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > subscription schedule run due. now is not a valid date")
+	}
+
+	list, err := st.SubscriptionScheduleList(ctx, SubscriptionScheduleQuery().
+		SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE).
+		SetNextPhaseAtLte(nowCarbon.ToDateTimeString(carbon.UTC)).
+		SetOrderBy(COLUMN_NEXT_PHASE_AT).
+		SetSortOrder("asc"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schedule := range list {
+		if err := st.subscriptionScheduleRun(ctx, schedule, nowCarbon); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// SubscriptionScheduleUpcoming returns the changes the active schedules make
+// from from until until, inclusive, in chronological order
+func (st *storeImplementation) SubscriptionScheduleUpcoming(ctx context.Context, from string, until string) ([]ScheduledChange, error) {
+	fromCarbon := carbon.Parse(from, carbon.UTC)
+	if fromCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > subscription schedule upcoming. from is not a valid date")
+	}
+	untilCarbon := carbon.Parse(until, carbon.UTC)
+	if untilCarbon.IsInvalid() {
+		return nil, errors.New("subscriptionstore > subscription schedule upcoming. until is not a valid date")
+	}
+
+	list, err := st.SubscriptionScheduleList(ctx, SubscriptionScheduleQuery().
+		SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE).
+		SetNextPhaseAtLte(untilCarbon.ToDateTimeString(carbon.UTC)))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []ScheduledChange{}
+	for _, schedule := range list {
+		phases, err := schedule.GetPhases()
+		if err != nil {
+			return nil, err
+		}
+
+		for i := schedule.GetCurrentPhase() + 1; i <= len(phases); i++ {
+			change := ScheduledChange{
+				ScheduleID:     schedule.GetID(),
+				SubscriberID:   schedule.GetSubscriberID(),
+				SubscriptionID: schedule.GetSubscriptionID(),
+				Phase:          i,
+			}
+			if i < len(phases) {
+				change.PlanID = phases[i].PlanID
+				change.Quantity = phases[i].Quantity
+				change.At = phases[i].StartsAt
+			} else {
+				change.At = phases[len(phases)-1].EndsAt
+			}
+
+			at := carbon.Parse(change.At, carbon.UTC)
+			if change.At == MAX_DATETIME || at.Gt(untilCarbon) {
+				break
+			}
+			if at.Lt(fromCarbon) {
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return carbon.Parse(changes[i].At, carbon.UTC).Lt(carbon.Parse(changes[j].At, carbon.UTC))
+	})
+
+	return changes, nil
+}
+
+// subscriptionScheduleRun applies the latest phase of the schedule which
+// started by now, and cancels the subscription when the last phase ended
+func (st *storeImplementation) subscriptionScheduleRun(ctx context.Context, schedule SubscriptionScheduleInterface, now *carbon.Carbon) error {
+	phases, err := schedule.GetPhases()
+	if err != nil {
+		return err
+	}
+	if len(phases) == 0 {
+		return errors.New("subscriptionstore > subscription schedule run. schedule " + schedule.GetID() + " has no phases")
+	}
+
+	var subscription SubscriptionInterface
+	if schedule.GetSubscriptionID() != "" {
+		subscription, err = st.SubscriptionFindByID(ctx, schedule.GetSubscriptionID())
+		if err != nil {
+			return err
+		}
+		if subscription == nil || subscription.GetStatus() == SUBSCRIPTION_STATUS_CANCELLED {
+			schedule.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_CANCELLED)
+			schedule.SetNextPhaseAt(MAX_DATETIME)
+			return st.subscriptionScheduleUpdate(ctx, schedule)
+		}
+	}
+
+	current := schedule.GetCurrentPhase()
+	for current+1 < len(phases) && !now.Lt(carbon.Parse(phases[current+1].StartsAt, carbon.UTC)) {
+		current++
+	}
+
+	if current > schedule.GetCurrentPhase() {
+		subscription, err = st.subscriptionScheduleApplyPhase(ctx, subscription, schedule.GetSubscriberID(), phases[current])
+		if err != nil {
+			return err
+		}
+		schedule.SetSubscriptionID(subscription.GetID())
+		schedule.SetCurrentPhase(current)
+	}
+
+	schedule.SetNextPhaseAt(scheduleNextPhaseAt(phases, current))
+
+	if current == len(phases)-1 {
+		last := phases[current]
+		if last.EndsAt == MAX_DATETIME {
+			schedule.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_COMPLETED)
+		} else if !now.Lt(carbon.Parse(last.EndsAt, carbon.UTC)) {
+			endsAt := carbon.Parse(last.EndsAt, carbon.UTC)
+			if err := st.subscriptionCancel(ctx, subscription.GetID(), SubscriptionCancelOptions{CancelledBy: CANCELLED_BY_SYSTEM}, endsAt); err != nil {
+				return err
+			}
+			schedule.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_COMPLETED)
+			schedule.SetNextPhaseAt(MAX_DATETIME)
+		}
+	}
+
+	return st.subscriptionScheduleUpdate(ctx, schedule)
+}
+
+// subscriptionScheduleApplyPhase puts the subscription on the plan and the
+// quantity of the phase, starting a billing period at the start of the
+// phase, which does not outlast the phase. A nil subscription is created.
+func (st *storeImplementation) subscriptionScheduleApplyPhase(ctx context.Context, subscription SubscriptionInterface, subscriberID string, phase SubscriptionSchedulePhase) (SubscriptionInterface, error) {
+	plan, err := st.PlanFindByID(ctx, phase.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, errors.New("subscriptionstore > subscription schedule run. plan " + phase.PlanID + " not found")
+	}
+
+	startsAt := carbon.Parse(phase.StartsAt, carbon.UTC)
+	endsAt := carbon.Parse(phase.EndsAt, carbon.UTC)
+	periodEnd, err := planIntervalPeriodEnd(startsAt, plan.GetInterval())
+	if err != nil {
+		return nil, errors.New("subscriptionstore > subscription schedule run. " + err.Error())
+	}
+	if endsAt.Lt(periodEnd) {
+		periodEnd = endsAt
+	}
+
+	if subscription == nil {
+		subscription = NewSubscription().
+			SetSubscriberID(subscriberID).
+			SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+			SetPlanID(plan.GetID()).
+			SetQuantity(phase.Quantity).
+			SetPeriodStart(startsAt.ToDateTimeString(carbon.UTC)).
+			SetPeriodEnd(periodEnd.ToDateTimeString(carbon.UTC))
+		if err := st.SubscriptionCreate(ctx, subscription); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	}
+
+	subscription.SetPlanID(plan.GetID())
+	subscription.SetQuantity(phase.Quantity)
+	subscription.SetPeriodStart(startsAt.ToDateTimeString(carbon.UTC))
+	subscription.SetPeriodEnd(periodEnd.ToDateTimeString(carbon.UTC))
+	if err := st.SubscriptionUpdate(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// subscriptionScheduleUpdate updates the progress of the schedule
+func (st *storeImplementation) subscriptionScheduleUpdate(ctx context.Context, schedule SubscriptionScheduleInterface) error {
+	schedule.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	row, err := st.subscriptionScheduleRow(schedule)
+	if err != nil {
+		return err
+	}
+
+	_, err = st.query().Table(st.subscriptionScheduleTableName).Where(COLUMN_ID+" = ?", schedule.GetID()).Update(row)
+	return err
+}
+
+// subscriptionScheduleRow returns the columns of the schedule, which are
+// written on both create and update
+func (st *storeImplementation) subscriptionScheduleRow(schedule SubscriptionScheduleInterface) (map[string]any, error) {
+	phases, err := schedule.GetPhases()
+	if err != nil {
+		return nil, err
+	}
+	phasesStr, err := schedulePhasesJSON(phases)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		COLUMN_SUBSCRIBER_ID:   schedule.GetSubscriberID(),
+		COLUMN_SUBSCRIPTION_ID: schedule.GetSubscriptionID(),
+		COLUMN_STATUS:          schedule.GetStatus(),
+		COLUMN_PHASES:          phasesStr,
+		COLUMN_CURRENT_PHASE:   schedule.GetCurrentPhase(),
+		COLUMN_NEXT_PHASE_AT:   dateTimeValue(schedule.GetNextPhaseAtCarbon()),
+		COLUMN_UPDATED_AT:      dateTimeValue(schedule.GetUpdatedAtCarbon()),
+	}, nil
+}
+
+// buildSubscriptionScheduleQuery builds a neat query from the subscription schedule query interface.
+func (st *storeImplementation) buildSubscriptionScheduleQuery(query SubscriptionScheduleQueryInterface) contractsorm.Query {
+	q := st.query()
+
+	if query.HasID() && query.ID() != "" {
+		q = q.Where(COLUMN_ID+" = ?", query.ID())
+	}
+	if query.HasSubscriberID() && query.SubscriberID() != "" {
+		q = q.Where(COLUMN_SUBSCRIBER_ID+" = ?", query.SubscriberID())
+	}
+	if query.HasSubscriptionID() && query.SubscriptionID() != "" {
+		q = q.Where(COLUMN_SUBSCRIPTION_ID+" = ?", query.SubscriptionID())
+	}
+	if query.HasStatus() && query.Status() != "" {
+		q = q.Where(COLUMN_STATUS+" = ?", query.Status())
+	}
+	if query.HasNextPhaseAtLte() && query.NextPhaseAtLte() != "" {
+		q = q.Where(COLUMN_NEXT_PHASE_AT+" <= ?", dateTimeValue(carbon.Parse(query.NextPhaseAtLte(), carbon.UTC)))
+	}
+	if query.HasLimit() && query.Limit() > 0 {
+		q = q.Limit(query.Limit())
+	}
+	if query.HasOffset() && query.Offset() > 0 {
+		q = q.Offset(query.Offset())
+	}
+	if query.HasOrderBy() && query.OrderBy() != "" {
+		sortOrder := "desc"
+		if query.HasSortOrder() && query.SortOrder() != "" {
+			sortOrder = query.SortOrder()
+		}
+		q = q.OrderBy(query.OrderBy(), sortOrder)
+	}
+
+	return q
+}
@@ -0,0 +1,314 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreSubscriptionScheduleSwitchAtRenewal(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	monthly := NewPlan().SetTitle("Monthly").SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	yearly := NewPlan().SetTitle("Yearly").SetPrice("100.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_YEARLY)
+	for _, p := range []PlanInterface{monthly, yearly} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	subscription := NewSubscription().
+		SetSubscriberID("user_1").
+		SetPlanID(monthly.GetID()).
+		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
+		SetPeriodStart("2025-01-01 00:00:00").
+		SetPeriodEnd("2025-02-01 00:00:00")
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	schedule := NewSubscriptionSchedule().SetSubscriptionID(subscription.GetID())
+	if _, err := schedule.SetPhases([]SubscriptionSchedulePhase{
+		{PlanID: yearly.GetID(), StartsAt: subscription.GetPeriodEnd()},
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, schedule); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if schedule.GetSubscriberID() != "user_1" || schedule.GetNextPhaseAt() != "2025-02-01 00:00:00" {
+		t.Fatalf("unexpected subscriber %s / next phase at %s", schedule.GetSubscriberID(), schedule.GetNextPhaseAt())
+	}
+
+	another := NewSubscriptionSchedule().SetSubscriptionID(subscription.GetID())
+	if _, err := another.SetPhases([]SubscriptionSchedulePhase{{PlanID: yearly.GetID(), StartsAt: "2025-03-01 00:00:00"}}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, another); err == nil {
+		t.Error("expected error for a second active schedule")
+	}
+
+	changes, err := store.SubscriptionScheduleUpcoming(ctx, "2025-01-15 00:00:00", "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(changes) != 1 || changes[0].PlanID != yearly.GetID() || changes[0].At != "2025-02-01 00:00:00" || changes[0].SubscriptionID != subscription.GetID() {
+		t.Fatalf("unexpected upcoming changes %+v", changes)
+	}
+
+	due, err := store.SubscriptionScheduleRunDue(ctx, "2025-01-31 23:59:59")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no schedule due, got %d", len(due))
+	}
+
+	due, err = store.SubscriptionScheduleRunDue(ctx, "2025-02-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 1 || due[0].GetStatus() != SUBSCRIPTION_SCHEDULE_STATUS_COMPLETED {
+		t.Fatalf("expected the schedule completed, got %d", len(due))
+	}
+
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetPlanID() != yearly.GetID() || found.GetPeriodStart() != "2025-02-01 00:00:00" || found.GetPeriodEnd() != "2026-02-01 00:00:00" {
+		t.Errorf("unexpected subscription %s %s - %s", found.GetPlanID(), found.GetPeriodStart(), found.GetPeriodEnd())
+	}
+	plan, err := store.SubscriptionPlan(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if plan.GetPrice() != "100.00" {
+		t.Errorf("expected the yearly price, got %s", plan.GetPrice())
+	}
+
+	changes, err = store.SubscriptionScheduleUpcoming(ctx, "2025-01-15 00:00:00", "2025-03-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no upcoming changes, got %+v", changes)
+	}
+}
+
+func TestStoreSubscriptionScheduleCreatesAndEndsSubscription(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	enterprise := NewPlan().SetTitle("Enterprise").SetPrice("50.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	team := NewPlan().SetTitle("Team").SetPrice("20.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	for _, p := range []PlanInterface{enterprise, team} {
+		if err := store.PlanCreate(ctx, p); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	phases := []SubscriptionSchedulePhase{
+		{PlanID: enterprise.GetID(), Quantity: 5, StartsAt: "2025-03-01 00:00:00", EndsAt: "2025-04-01 00:00:00"},
+		{PlanID: team.GetID(), StartsAt: "2025-04-01 00:00:00", EndsAt: "2025-05-01 00:00:00"},
+	}
+
+	invalid := []SubscriptionScheduleInterface{
+		NewSubscriptionSchedule(),
+		NewSubscriptionSchedule().SetSubscriptionID("missing"),
+	}
+	for i, s := range invalid {
+		if _, err := s.SetPhases(phases); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if err := store.SubscriptionScheduleCreate(ctx, s); err == nil {
+			t.Errorf("expected error for invalid schedule %d", i)
+		}
+	}
+	missingPlan := NewSubscriptionSchedule().SetSubscriberID("acme")
+	if _, err := missingPlan.SetPhases([]SubscriptionSchedulePhase{{PlanID: "missing", StartsAt: "2025-03-01 00:00:00"}}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, missingPlan); err == nil {
+		t.Error("expected error for a missing plan")
+	}
+
+	schedule := NewSubscriptionSchedule().SetSubscriberID("acme")
+	if _, err := schedule.SetPhases(phases); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, schedule); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	changes, err := store.SubscriptionScheduleUpcoming(ctx, "2025-01-01 00:00:00", "2025-12-31 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(changes) != 3 || changes[0].Quantity != 5 || changes[1].PlanID != team.GetID() || changes[2].PlanID != "" || changes[2].At != "2025-05-01 00:00:00" {
+		t.Fatalf("unexpected upcoming changes %+v", changes)
+	}
+
+	if _, err := store.SubscriptionScheduleRunDue(ctx, "2025-03-01 12:00:00"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	schedule, err = store.SubscriptionScheduleFindByID(ctx, schedule.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if schedule.GetSubscriptionID() == "" || schedule.GetCurrentPhase() != 0 || schedule.GetNextPhaseAt() != "2025-04-01 00:00:00" {
+		t.Fatalf("unexpected schedule %s / %d / %s", schedule.GetSubscriptionID(), schedule.GetCurrentPhase(), schedule.GetNextPhaseAt())
+	}
+
+	subscription, err := store.SubscriptionFindByID(ctx, schedule.GetSubscriptionID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription.GetSubscriberID() != "acme" || subscription.GetPlanID() != enterprise.GetID() || subscription.GetQuantity() != 5 || subscription.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("unexpected subscription %s %s %d %s", subscription.GetSubscriberID(), subscription.GetPlanID(), subscription.GetQuantity(), subscription.GetStatus())
+	}
+	if subscription.GetPeriodStart() != "2025-03-01 00:00:00" || subscription.GetPeriodEnd() != "2025-04-01 00:00:00" {
+		t.Errorf("unexpected period %s - %s", subscription.GetPeriodStart(), subscription.GetPeriodEnd())
+	}
+
+	// A missed run applies the latest phase, then ends the schedule
+	if _, err := store.SubscriptionScheduleRunDue(ctx, "2025-05-02 00:00:00"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	schedule, err = store.SubscriptionScheduleFindByID(ctx, schedule.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if schedule.GetStatus() != SUBSCRIPTION_SCHEDULE_STATUS_COMPLETED || schedule.GetCurrentPhase() != 1 {
+		t.Errorf("expected the schedule completed on phase 1, got %s on %d", schedule.GetStatus(), schedule.GetCurrentPhase())
+	}
+	subscription, err = store.SubscriptionFindByID(ctx, schedule.GetSubscriptionID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription.GetPlanID() != team.GetID() || subscription.GetQuantity() != 1 || subscription.GetStatus() != SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("unexpected subscription %s %d %s", subscription.GetPlanID(), subscription.GetQuantity(), subscription.GetStatus())
+	}
+	if subscription.GetCancelledBy() != CANCELLED_BY_SYSTEM || subscription.GetCancellationEffectiveAt() != "2025-05-01 00:00:00" {
+		t.Errorf("expected a system cancellation effective at the end of the last phase, got %s at %s", subscription.GetCancelledBy(), subscription.GetCancellationEffectiveAt())
+	}
+}
+
+func TestStoreSubscriptionScheduleCancel(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Pro").SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	subscription := NewSubscription().SetSubscriberID("user_1").SetPlanID(plan.GetID()).SetStatus(SUBSCRIPTION_STATUS_ACTIVE)
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	released := NewSubscriptionSchedule().SetSubscriptionID(subscription.GetID())
+	if _, err := released.SetPhases([]SubscriptionSchedulePhase{{PlanID: plan.GetID(), Quantity: 3, StartsAt: "2025-02-01 00:00:00"}}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, released); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCancel(ctx, released.GetID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCancel(ctx, released.GetID()); err == nil {
+		t.Error("expected error cancelling a cancelled schedule")
+	}
+
+	due, err := store.SubscriptionScheduleRunDue(ctx, "2025-02-01 00:00:00")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("expected the cancelled schedule not to run, got %d", len(due))
+	}
+
+	// A schedule of a subscription cancelled meanwhile is cancelled
+	orphan := NewSubscriptionSchedule().SetSubscriptionID(subscription.GetID())
+	if _, err := orphan.SetPhases([]SubscriptionSchedulePhase{{PlanID: plan.GetID(), Quantity: 3, StartsAt: "2025-02-01 00:00:00"}}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, orphan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	subscription.SetStatus(SUBSCRIPTION_STATUS_CANCELLED)
+	if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := store.SubscriptionScheduleRunDue(ctx, "2025-02-01 00:00:00"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	orphan, err = store.SubscriptionScheduleFindByID(ctx, orphan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if orphan.GetStatus() != SUBSCRIPTION_SCHEDULE_STATUS_CANCELLED {
+		t.Errorf("expected the schedule cancelled, got %s", orphan.GetStatus())
+	}
+	found, err := store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found.GetQuantity() != 1 {
+		t.Errorf("expected the quantity unchanged, got %d", found.GetQuantity())
+	}
+}
+
+// customSubscriptionSchedule is a caller's own implementation of
+// SubscriptionScheduleInterface
+type customSubscriptionSchedule struct {
+	SubscriptionScheduleInterface
+}
+
+func TestStoreSubscriptionScheduleCreateCustomImplementation(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Monthly").SetPrice("10.00").SetCurrency(CURRENCY_USD).SetInterval(PLAN_INTERVAL_MONTHLY)
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	schedule := customSubscriptionSchedule{NewSubscriptionSchedule().SetSubscriberID("user_1")}
+	if _, err := schedule.SetPhases([]SubscriptionSchedulePhase{
+		{PlanID: plan.GetID(), StartsAt: "2030-01-01 00:00:00"},
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.SubscriptionScheduleCreate(ctx, schedule); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SubscriptionScheduleFindByID(ctx, schedule.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if found == nil {
+		t.Fatal("expected the schedule to be created")
+	}
+	phases, err := found.GetPhases()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(phases) != 1 || phases[0].PlanID != plan.GetID() {
+		t.Errorf("expected the phases to be stored, got %+v", phases)
+	}
+}
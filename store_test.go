@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -321,6 +322,49 @@ func TestStorePlanCount(t *testing.T) {
 	}
 }
 
+func TestStorePlanCountBy(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plans := []PlanInterface{
+		NewPlan().SetTitle("Gold Monthly").SetType(PLAN_TYPE_GOLD).SetStatus(PLAN_STATUS_ACTIVE).SetInterval(PLAN_INTERVAL_MONTHLY),
+		NewPlan().SetTitle("Gold Yearly").SetType(PLAN_TYPE_GOLD).SetStatus(PLAN_STATUS_ACTIVE).SetInterval(PLAN_INTERVAL_YEARLY),
+		NewPlan().SetTitle("Silver Monthly").SetType(PLAN_TYPE_SILVER).SetStatus(PLAN_STATUS_INACTIVE).SetInterval(PLAN_INTERVAL_MONTHLY),
+	}
+	for _, plan := range plans {
+		if err := store.PlanCreate(ctx, plan); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	byType, err := store.PlanCountBy(ctx, PlanQuery(), COLUMN_TYPE)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(byType) != 2 || byType[PLAN_TYPE_GOLD] != 2 || byType[PLAN_TYPE_SILVER] != 1 {
+		t.Errorf("unexpected counts by type %v", byType)
+	}
+
+	// Filters of the query still apply
+	byInterval, err := store.PlanCountBy(ctx, PlanQuery().SetStatus(PLAN_STATUS_ACTIVE), COLUMN_INTERVAL)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(byInterval) != 2 || byInterval[PLAN_INTERVAL_MONTHLY] != 1 || byInterval[PLAN_INTERVAL_YEARLY] != 1 {
+		t.Errorf("unexpected counts by interval %v", byInterval)
+	}
+
+	if _, err := store.PlanCountBy(ctx, PlanQuery(), COLUMN_TITLE); err == nil {
+		t.Error("expected error for an unsupported column")
+	}
+	if _, err := store.PlanCountBy(ctx, PlanQuery().SetOffset(1), COLUMN_STATUS); err == nil {
+		t.Error("expected error for a paginated query")
+	}
+}
+
 func TestStorePlanList(t *testing.T) {
 	store, err := initStore()
 	if err != nil {
@@ -476,6 +520,55 @@ func TestStorePlanUpdate(t *testing.T) {
 	}
 }
 
+func TestStorePlanPricingTiers(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	plan := NewPlan().
+		SetTitle("Usage").
+		SetCurrency(CURRENCY_USD).
+		SetPricingModel(PLAN_PRICING_MODEL_GRADUATED)
+	if _, err := plan.SetPriceTiers([]PriceTier{
+		{UpTo: 10, UnitPrice: "1.00"},
+		{UnitPrice: "0.50", FlatPrice: "2.00"},
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	planFound, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if planFound.GetPricingModel() != PLAN_PRICING_MODEL_GRADUATED {
+		t.Errorf("expected pricing model %s, got %s", PLAN_PRICING_MODEL_GRADUATED, planFound.GetPricingModel())
+	}
+	tiers, err := planFound.GetPriceTiers()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(tiers) != 2 || tiers[0].UpTo != 10 || tiers[1].FlatPrice != "2.00" {
+		t.Errorf("unexpected price tiers: %+v", tiers)
+	}
+
+	// Tiered plans without tiers are rejected
+	if _, err := planFound.SetPriceTiers(nil); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := store.PlanUpdate(ctx, planFound); err == nil {
+		t.Error("expected error for a graduated plan without tiers")
+	}
+	if err := store.PlanCreate(ctx, NewPlan().SetPricingModel("custom")); err == nil {
+		t.Error("expected error for an unknown pricing model")
+	}
+}
+
 // == SUBSCRIPTION TESTS ========================================================
 
 func TestStoreSubscriptionCreate(t *testing.T) {
@@ -642,6 +735,49 @@ func TestStoreSubscriptionCount(t *testing.T) {
 	}
 }
 
+func TestStoreSubscriptionCountBy(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscriptions := []SubscriptionInterface{
+		NewSubscription().SetSubscriberID("user_1").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_ACTIVE),
+		NewSubscription().SetSubscriberID("user_2").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_PAST_DUE),
+		NewSubscription().SetSubscriberID("user_3").SetPlanID("plan_2").SetStatus(SUBSCRIPTION_STATUS_ACTIVE),
+	}
+	for _, subscription := range subscriptions {
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	byStatus, err := store.SubscriptionCountBy(ctx, SubscriptionQuery(), COLUMN_STATUS)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(byStatus) != 2 || byStatus[SUBSCRIPTION_STATUS_ACTIVE] != 2 || byStatus[SUBSCRIPTION_STATUS_PAST_DUE] != 1 {
+		t.Errorf("unexpected counts by status %v", byStatus)
+	}
+
+	// Filters of the query still apply
+	byPlan, err := store.SubscriptionCountBy(ctx, SubscriptionQuery().SetStatus(SUBSCRIPTION_STATUS_ACTIVE), COLUMN_PLAN_ID)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(byPlan) != 2 || byPlan["plan_1"] != 1 || byPlan["plan_2"] != 1 {
+		t.Errorf("unexpected counts by plan %v", byPlan)
+	}
+
+	if _, err := store.SubscriptionCountBy(ctx, SubscriptionQuery(), COLUMN_MEMO); err == nil {
+		t.Error("expected error for an unsupported column")
+	}
+	if _, err := store.SubscriptionCountBy(ctx, nil, COLUMN_STATUS); err == nil {
+		t.Error("expected error for a nil query")
+	}
+}
+
 func TestStoreSubscriptionList(t *testing.T) {
 	store, err := initStore()
 	if err != nil {
@@ -804,3 +940,83 @@ func TestStoreSubscriptionCancelAtPeriodEnd(t *testing.T) {
 		t.Fatal("CancelAtPeriodEnd should be true")
 	}
 }
+
+func TestStoreSoftDeleteIsTimezoneSafe(t *testing.T) {
+	// A host running west of UTC must still see rows soft deleted "now" in UTC
+	local := time.Local
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	defer func() { time.Local = local }()
+
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	plan := NewPlan().SetTitle("Timezone Plan")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	planFound, err := store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if planFound == nil {
+		t.Fatal("Plan MUST NOT be nil")
+	}
+	if planFound.GetCreatedAt() != plan.GetCreatedAt() {
+		t.Errorf("expected CreatedAt %s, got %s", plan.GetCreatedAt(), planFound.GetCreatedAt())
+	}
+
+	if err := store.PlanSoftDelete(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	planFound, err = store.PlanFindByID(ctx, plan.GetID())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if planFound != nil {
+		t.Fatal("soft deleted plan MUST NOT be found")
+	}
+}
+
+func TestStoreSubscriptionFindActiveBySubscriberID(t *testing.T) {
+	store, err := initStore()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ctx := context.Background()
+	subscriptions := []SubscriptionInterface{
+		NewSubscription().SetSubscriberID("user_1").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_CANCELLED),
+		NewSubscription().SetSubscriberID("user_1").SetPlanID("plan_2").SetStatus(SUBSCRIPTION_STATUS_PAST_DUE),
+		NewSubscription().SetSubscriberID("user_2").SetPlanID("plan_1").SetStatus(SUBSCRIPTION_STATUS_INACTIVE),
+	}
+	for _, subscription := range subscriptions {
+		if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	active, err := store.SubscriptionFindActiveBySubscriberID(ctx, "user_1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if active == nil || active.GetID() != subscriptions[1].GetID() {
+		t.Errorf("expected the past due subscription, got %v", active)
+	}
+
+	active, err = store.SubscriptionFindActiveBySubscriberID(ctx, "user_2")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if active != nil {
+		t.Errorf("expected no active subscription, got %s", active.GetID())
+	}
+
+	if _, err := store.SubscriptionFindActiveBySubscriberID(ctx, ""); err == nil {
+		t.Error("expected error for an empty subscriber id")
+	}
+}
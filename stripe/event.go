@@ -0,0 +1,125 @@
+package stripe
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const EVENT_CUSTOMER_SUBSCRIPTION_CREATED = "customer.subscription.created"
+const EVENT_CUSTOMER_SUBSCRIPTION_UPDATED = "customer.subscription.updated"
+const EVENT_CUSTOMER_SUBSCRIPTION_DELETED = "customer.subscription.deleted"
+const EVENT_INVOICE_PAID = "invoice.paid"
+const EVENT_INVOICE_PAYMENT_FAILED = "invoice.payment_failed"
+
+// Event is a Stripe webhook event. Only the fields used to reconcile
+// subscriptions are decoded.
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// Subscription is the object of the customer.subscription.* events
+type Subscription struct {
+	ID                   string `json:"id"`
+	Customer             string `json:"customer"`
+	Status               string `json:"status"`
+	CancelAtPeriodEnd    bool   `json:"cancel_at_period_end"`
+	CurrentPeriodStart   int64  `json:"current_period_start"`
+	CurrentPeriodEnd     int64  `json:"current_period_end"`
+	DefaultPaymentMethod string `json:"default_payment_method"`
+	Items                struct {
+		Data []SubscriptionItem `json:"data"`
+	} `json:"items"`
+}
+
+// SubscriptionItem is a price the subscription is billed for. Since the
+// 2025-03-31 API version the billing period is reported per item.
+type SubscriptionItem struct {
+	ID                 string `json:"id"`
+	CurrentPeriodStart int64  `json:"current_period_start"`
+	CurrentPeriodEnd   int64  `json:"current_period_end"`
+	Price              struct {
+		ID string `json:"id"`
+	} `json:"price"`
+}
+
+// Invoice is the object of the invoice.* events
+type Invoice struct {
+	ID           string `json:"id"`
+	Customer     string `json:"customer"`
+	Status       string `json:"status"`
+	Subscription string `json:"subscription"`
+	AttemptCount int    `json:"attempt_count"`
+	Parent       struct {
+		SubscriptionDetails struct {
+			Subscription string `json:"subscription"`
+		} `json:"subscription_details"`
+	} `json:"parent"`
+}
+
+// ParseEvent decodes a webhook payload. It does not verify the signature,
+// use ConstructEvent for payloads received over the network.
+func ParseEvent(payload []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, err
+	}
+	if event.ID == "" || event.Type == "" {
+		return Event{}, errors.New("stripe > parse event. payload is not a stripe event")
+	}
+	return event, nil
+}
+
+// Subscription decodes the event object as a subscription
+func (e Event) Subscription() (Subscription, error) {
+	var subscription Subscription
+	if err := json.Unmarshal(e.Data.Object, &subscription); err != nil {
+		return Subscription{}, err
+	}
+	if subscription.ID == "" {
+		return Subscription{}, errors.New("stripe > event subscription. subscription id is empty")
+	}
+	return subscription, nil
+}
+
+// Invoice decodes the event object as an invoice
+func (e Event) Invoice() (Invoice, error) {
+	var invoice Invoice
+	if err := json.Unmarshal(e.Data.Object, &invoice); err != nil {
+		return Invoice{}, err
+	}
+	if invoice.ID == "" {
+		return Invoice{}, errors.New("stripe > event invoice. invoice id is empty")
+	}
+	return invoice, nil
+}
+
+// PriceID returns the price of the first subscription item
+func (s Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// Period returns the current billing period as unix timestamps, falling back
+// to the first subscription item for API versions which report it per item
+func (s Subscription) Period() (start int64, end int64) {
+	if s.CurrentPeriodEnd > 0 || len(s.Items.Data) == 0 {
+		return s.CurrentPeriodStart, s.CurrentPeriodEnd
+	}
+	return s.Items.Data[0].CurrentPeriodStart, s.Items.Data[0].CurrentPeriodEnd
+}
+
+// SubscriptionID returns the subscription the invoice was issued for, or an
+// empty string for one-off invoices
+func (i Invoice) SubscriptionID() string {
+	if i.Subscription != "" {
+		return i.Subscription
+	}
+	return i.Parent.SubscriptionDetails.Subscription
+}
@@ -0,0 +1,83 @@
+package stripe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadFixture(t *testing.T, eventType string) []byte {
+	payload, err := os.ReadFile(filepath.Join("testdata", eventType+".json"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return payload
+}
+
+func TestParseEventSubscription(t *testing.T) {
+	event, err := ParseEvent(loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_CREATED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if event.Type != EVENT_CUSTOMER_SUBSCRIPTION_CREATED {
+		t.Fatalf("expected type %s, got %s", EVENT_CUSTOMER_SUBSCRIPTION_CREATED, event.Type)
+	}
+
+	subscription, err := event.Subscription()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription.ID != "sub_1QxYz2AbCdEfGh" || subscription.Customer != "cus_Ra1b2c3d4e" {
+		t.Fatalf("unexpected subscription %s of customer %s", subscription.ID, subscription.Customer)
+	}
+	if subscription.PriceID() != "price_1ProMonthly" {
+		t.Fatalf("expected price price_1ProMonthly, got %s", subscription.PriceID())
+	}
+	start, end := subscription.Period()
+	if start != 1735689600 || end != 1738368000 {
+		t.Fatalf("unexpected period %d - %d", start, end)
+	}
+}
+
+func TestParseEventSubscriptionItemPeriod(t *testing.T) {
+	event, err := ParseEvent(loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_UPDATED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	subscription, err := event.Subscription()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	start, end := subscription.Period()
+	if start != 1735689600 || end != 1738368000 {
+		t.Fatalf("expected the period of the first item, got %d - %d", start, end)
+	}
+}
+
+func TestParseEventInvoiceSubscriptionID(t *testing.T) {
+	for _, eventType := range []string{EVENT_INVOICE_PAID, EVENT_INVOICE_PAYMENT_FAILED} {
+		t.Run(eventType, func(t *testing.T) {
+			event, err := ParseEvent(loadFixture(t, eventType))
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			invoice, err := event.Invoice()
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if invoice.SubscriptionID() != "sub_1QxYz2AbCdEfGh" {
+				t.Fatalf("expected subscription sub_1QxYz2AbCdEfGh, got %s", invoice.SubscriptionID())
+			}
+		})
+	}
+}
+
+func TestParseEventInvalidPayload(t *testing.T) {
+	if _, err := ParseEvent([]byte("not json")); err == nil {
+		t.Error("expected error for an invalid payload")
+	}
+	if _, err := ParseEvent([]byte(`{"object":"customer"}`)); err == nil {
+		t.Error("expected error for a payload which is not an event")
+	}
+}
@@ -0,0 +1,108 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTolerance is the maximum age of a signed payload, as recommended by
+// Stripe to protect against replay attacks
+const DefaultTolerance = 5 * time.Minute
+
+var ErrInvalidHeader = errors.New("stripe > signature. header has an invalid format")
+var ErrNoValidSignature = errors.New("stripe > signature. no signature matches the payload")
+var ErrTimestampOutsideTolerance = errors.New("stripe > signature. timestamp is outside the tolerance")
+
+// ConstructEvent verifies the signature of a webhook payload against the
+// Stripe-Signature header and the endpoint secret, and parses the event
+func ConstructEvent(payload []byte, header string, secret string) (Event, error) {
+	if err := VerifySignature(payload, header, secret, DefaultTolerance, time.Now()); err != nil {
+		return Event{}, err
+	}
+	return ParseEvent(payload)
+}
+
+// VerifySignature checks that the payload was signed with the endpoint secret
+// no longer than tolerance before now. A zero tolerance disables the age check.
+func VerifySignature(payload []byte, header string, secret string, tolerance time.Duration, now time.Time) error {
+	timestamp, signatures, err := parseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+
+	expected := computeSignature(payload, timestamp, secret)
+
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal(expected, signature) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrNoValidSignature
+	}
+
+	if tolerance > 0 && now.Sub(time.Unix(timestamp, 0)) > tolerance {
+		return ErrTimestampOutsideTolerance
+	}
+
+	return nil
+}
+
+// SignatureHeader returns the Stripe-Signature header value for the payload.
+// It is meant for tests which replay recorded payloads.
+func SignatureHeader(payload []byte, secret string, timestamp time.Time) string {
+	signature := computeSignature(payload, timestamp.Unix(), secret)
+	return "t=" + strconv.FormatInt(timestamp.Unix(), 10) + ",v1=" + hex.EncodeToString(signature)
+}
+
+// parseSignatureHeader returns the timestamp and the v1 signatures of
+// a header such as "t=1492774577,v1=5257a869...,v0=6ffbb59b..."
+func parseSignatureHeader(header string) (int64, [][]byte, error) {
+	var timestamp int64
+	signatures := [][]byte{}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return 0, nil, ErrInvalidHeader
+		}
+
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, nil, ErrInvalidHeader
+			}
+			timestamp = t
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+
+	if timestamp == 0 {
+		return 0, nil, ErrInvalidHeader
+	}
+	if len(signatures) == 0 {
+		return 0, nil, ErrNoValidSignature
+	}
+
+	return timestamp, signatures, nil
+}
+
+func computeSignature(payload []byte, timestamp int64, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,60 @@
+package stripe
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test_secret"
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"invoice.paid"}`)
+	signedAt := time.Unix(1735689600, 0)
+	header := SignatureHeader(payload, testSecret, signedAt)
+
+	testCases := []struct {
+		name      string
+		payload   []byte
+		header    string
+		secret    string
+		now       time.Time
+		tolerance time.Duration
+		expected  error
+	}{
+		{"valid", payload, header, testSecret, signedAt.Add(time.Minute), DefaultTolerance, nil},
+		{"valid among several signatures", payload, "t=1735689600,v1=00ff," + header[len("t=1735689600,"):] + ",v0=abc", testSecret, signedAt, DefaultTolerance, nil},
+		{"tampered payload", []byte(`{"id":"evt_2","type":"invoice.paid"}`), header, testSecret, signedAt, DefaultTolerance, ErrNoValidSignature},
+		{"wrong secret", payload, header, "whsec_other", signedAt, DefaultTolerance, ErrNoValidSignature},
+		{"too old", payload, header, testSecret, signedAt.Add(time.Hour), DefaultTolerance, ErrTimestampOutsideTolerance},
+		{"too old without tolerance", payload, header, testSecret, signedAt.Add(time.Hour), 0, nil},
+		{"missing timestamp", payload, "v1=abc", testSecret, signedAt, DefaultTolerance, ErrInvalidHeader},
+		{"malformed header", payload, "garbage", testSecret, signedAt, DefaultTolerance, ErrInvalidHeader},
+		{"no v1 signature", payload, "t=1735689600,v0=abc", testSecret, signedAt, DefaultTolerance, ErrNoValidSignature},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifySignature(tc.payload, tc.header, tc.secret, tc.tolerance, tc.now)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestConstructEvent(t *testing.T) {
+	payload := loadFixture(t, EVENT_INVOICE_PAID)
+
+	event, err := ConstructEvent(payload, SignatureHeader(payload, testSecret, time.Now()), testSecret)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if event.ID != "evt_1QpaidJ1k2L3" {
+		t.Fatalf("expected event evt_1QpaidJ1k2L3, got %s", event.ID)
+	}
+
+	if _, err := ConstructEvent(payload, SignatureHeader(payload, "whsec_other", time.Now()), testSecret); err == nil {
+		t.Fatal("expected error for a payload signed with another secret")
+	}
+}
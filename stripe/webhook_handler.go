@@ -0,0 +1,348 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dracory/subscriptionstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// META_STRIPE_EVENT_ID and META_STRIPE_EVENT_CREATED, followed by "_" and
+// the event stream ("subscription" or "invoice"), record on the local
+// subscription the last Stripe event of the stream applied to it. They make
+// redelivered and out of order events no-ops. The subscription and the
+// invoice events are ordered separately, so a newer subscription update
+// does not drop a late invoice event.
+const META_STRIPE_EVENT_ID = "stripe_event_id"
+const META_STRIPE_EVENT_CREATED = "stripe_event_created"
+
+// maxPayloadBytes is the largest webhook payload accepted by ServeHTTP.
+// Larger payloads are rejected with 413, rather than cut short.
+const maxPayloadBytes = 1 << 20
+
+// NewWebhookHandlerOptions define the options for creating a new webhook handler
+type NewWebhookHandlerOptions struct {
+	Store subscriptionstore.StoreInterface
+	// WebhookSecret is the signing secret of the webhook endpoint (whsec_...)
+	WebhookSecret string
+}
+
+// WebhookHandler reconciles the subscriptions of the store with the
+// Stripe webhook events.
+//
+// Local subscriptions use the Stripe subscription id as their id, the Stripe
+// customer id as their subscriber id, and are linked to the local plan of the
+// price of the first subscription item: the plan referenced by the price in
+// the provider reference table, or else the plan with a matching stripe price id.
+type WebhookHandler struct {
+	store  subscriptionstore.StoreInterface
+	secret string
+}
+
+var _ http.Handler = (*WebhookHandler)(nil)
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(opts NewWebhookHandlerOptions) (*WebhookHandler, error) {
+	if opts.Store == nil {
+		return nil, errors.New("stripe webhook handler: Store is required")
+	}
+	if opts.WebhookSecret == "" {
+		return nil, errors.New("stripe webhook handler: WebhookSecret is required")
+	}
+
+	return &WebhookHandler{
+		store:  opts.Store,
+		secret: opts.WebhookSecret,
+	}, nil
+}
+
+// ServeHTTP verifies and handles a webhook request. Requests with an invalid
+// signature are rejected with 400, so they are not retried; payloads over
+// maxPayloadBytes are rejected with 413, and failures to apply the event
+// return 500, so Stripe retries the delivery.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := ConstructEvent(payload, r.Header.Get("Stripe-Signature"), h.secret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.HandleEvent(r.Context(), event); err != nil {
+		http.Error(w, "cannot handle event", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleEvent applies a verified event to the store. Events of other types
+// are ignored.
+//
+// The event is applied in a transaction with the record of the event, so
+// an event is either applied and recorded, or neither, and a retry after a
+// failure applies it again.
+func (h *WebhookHandler) HandleEvent(ctx context.Context, event Event) error {
+	switch event.Type {
+	case EVENT_CUSTOMER_SUBSCRIPTION_CREATED, EVENT_CUSTOMER_SUBSCRIPTION_UPDATED, EVENT_CUSTOMER_SUBSCRIPTION_DELETED:
+		return h.store.Transaction(ctx, func(txStore subscriptionstore.StoreInterface) error {
+			return handleSubscriptionEvent(ctx, txStore, event)
+		})
+	case EVENT_INVOICE_PAID, EVENT_INVOICE_PAYMENT_FAILED:
+		return h.store.Transaction(ctx, func(txStore subscriptionstore.StoreInterface) error {
+			return handleInvoiceEvent(ctx, txStore, event)
+		})
+	}
+	return nil
+}
+
+// handleSubscriptionEvent creates or updates the local subscription
+func handleSubscriptionEvent(ctx context.Context, store subscriptionstore.StoreInterface, event Event) error {
+	stripeSubscription, err := event.Subscription()
+	if err != nil {
+		return err
+	}
+
+	subscription, err := store.SubscriptionFindByID(ctx, stripeSubscription.ID)
+	if err != nil {
+		return err
+	}
+
+	if subscription != nil {
+		applied, err := eventApplied(subscription, event)
+		if err != nil || applied {
+			return err
+		}
+	}
+
+	planID, err := pricePlanID(ctx, store, stripeSubscription.PriceID())
+	if err != nil {
+		return err
+	}
+
+	isNew := subscription == nil
+	if isNew {
+		subscription = subscriptionstore.NewSubscription().SetID(stripeSubscription.ID)
+	}
+
+	periodStart, periodEnd := stripeSubscription.Period()
+
+	// A cancellation is recorded by the store, so it is reported with the
+	// other cancellations
+	status := subscriptionStatus(event.Type, stripeSubscription.Status)
+	cancelled := status == subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED && subscription.GetStatus() != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED
+	if cancelled {
+		status = subscription.GetStatus()
+	}
+
+	subscription.
+		SetSubscriberID(stripeSubscription.Customer).
+		SetPlanID(planID).
+		SetStatus(status).
+		SetCancelAtPeriodEnd(stripeSubscription.CancelAtPeriodEnd).
+		SetPeriodStart(dateTime(periodStart)).
+		SetPeriodEnd(dateTime(periodEnd))
+
+	if stripeSubscription.DefaultPaymentMethod != "" {
+		subscription.SetPaymentMethodID(stripeSubscription.DefaultPaymentMethod)
+	}
+
+	if err := setEventApplied(subscription, event); err != nil {
+		return err
+	}
+
+	if isNew {
+		err = store.SubscriptionCreate(ctx, subscription)
+	} else {
+		err = store.SubscriptionUpdate(ctx, subscription)
+	}
+	if err != nil || !cancelled {
+		return err
+	}
+
+	return store.SubscriptionCancel(ctx, subscription.GetID(), subscriptionstore.SubscriptionCancelOptions{
+		CancelledBy: subscriptionstore.CANCELLED_BY_SYSTEM,
+	})
+}
+
+// handleInvoiceEvent records the outcome of a renewal charge with the store's
+// dunning schedule
+func handleInvoiceEvent(ctx context.Context, store subscriptionstore.StoreInterface, event Event) error {
+	invoice, err := event.Invoice()
+	if err != nil {
+		return err
+	}
+
+	// One-off invoices are not related to a subscription
+	if invoice.SubscriptionID() == "" {
+		return nil
+	}
+
+	subscription, err := store.SubscriptionFindByID(ctx, invoice.SubscriptionID())
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return errors.New("stripe > invoice event. subscription not found " + invoice.SubscriptionID())
+	}
+
+	applied, err := eventApplied(subscription, event)
+	if err != nil || applied {
+		return err
+	}
+
+	switch event.Type {
+	case EVENT_INVOICE_PAID:
+		if subscription.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE {
+			if err := store.DunningRecordSuccess(ctx, subscription.GetID()); err != nil {
+				return err
+			}
+		}
+	case EVENT_INVOICE_PAYMENT_FAILED:
+		status := subscription.GetStatus()
+		if status == subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE || status == subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE {
+			reason := "stripe invoice " + invoice.ID + " payment failed (attempt " + strconv.Itoa(invoice.AttemptCount) + ")"
+			if _, err := store.DunningRecordFailure(ctx, subscription.GetID(), reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The dunning methods update the subscription, so it is read again
+	// before recording the event
+	subscription, err = store.SubscriptionFindByID(ctx, subscription.GetID())
+	if err != nil {
+		return err
+	}
+	if err := setEventApplied(subscription, event); err != nil {
+		return err
+	}
+	return store.SubscriptionUpdate(ctx, subscription)
+}
+
+// pricePlanID returns the local plan of the Stripe price, as recorded in the
+// provider reference table, or else by the plan's stripe price id
+func pricePlanID(ctx context.Context, store subscriptionstore.StoreInterface, priceID string) (string, error) {
+	if priceID == "" {
+		return "", errors.New("stripe > subscription event. subscription has no price")
+	}
+
+	reference, err := store.ProviderReferenceFindByExternalID(ctx, subscriptionstore.PAYMENT_PROVIDER_STRIPE, subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE, priceID)
+	if err != nil {
+		return "", err
+	}
+	if reference != nil {
+		return reference.GetLocalID(), nil
+	}
+
+	plans, err := store.PlanList(ctx, subscriptionstore.PlanQuery().
+		SetStripePriceID(priceID).
+		SetLimit(1))
+	if err != nil {
+		return "", err
+	}
+	if len(plans) == 0 {
+		return "", errors.New("stripe > subscription event. no plan with stripe price id " + priceID)
+	}
+
+	return plans[0].GetID(), nil
+}
+
+// eventStream returns the stream the event is ordered in: the events of
+// the subscription, or the events of its invoices
+func eventStream(event Event) string {
+	switch event.Type {
+	case EVENT_INVOICE_PAID, EVENT_INVOICE_PAYMENT_FAILED:
+		return "invoice"
+	}
+	return "subscription"
+}
+
+// eventApplied returns true if the event, or a more recent one of the
+// same stream, was already applied to the subscription
+func eventApplied(subscription subscriptionstore.SubscriptionInterface, event Event) (bool, error) {
+	stream := eventStream(event)
+
+	lastID, err := subscription.Meta(META_STRIPE_EVENT_ID + "_" + stream)
+	if err != nil {
+		return false, err
+	}
+	if lastID == event.ID {
+		return true, nil
+	}
+
+	lastCreated, err := subscription.Meta(META_STRIPE_EVENT_CREATED + "_" + stream)
+	if err != nil {
+		return false, err
+	}
+	if lastCreated == "" {
+		return false, nil
+	}
+
+	created, err := strconv.ParseInt(lastCreated, 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return event.Created < created, nil
+}
+
+// setEventApplied records the event as the last one of its stream applied
+// to the subscription
+func setEventApplied(subscription subscriptionstore.SubscriptionInterface, event Event) error {
+	stream := eventStream(event)
+
+	if _, err := subscription.SetMeta(META_STRIPE_EVENT_ID+"_"+stream, event.ID); err != nil {
+		return err
+	}
+	_, err := subscription.SetMeta(META_STRIPE_EVENT_CREATED+"_"+stream, strconv.FormatInt(event.Created, 10))
+	return err
+}
+
+// subscriptionStatus maps a Stripe subscription status onto the store's statuses
+func subscriptionStatus(eventType string, status string) string {
+	if eventType == EVENT_CUSTOMER_SUBSCRIPTION_DELETED {
+		return subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED
+	}
+
+	switch status {
+	case "active", "trialing":
+		return subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE
+	case "past_due", "unpaid":
+		return subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE
+	case "paused":
+		return subscriptionstore.SUBSCRIPTION_STATUS_PAUSED
+	case "canceled", "incomplete_expired":
+		return subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED
+	}
+	return subscriptionstore.SUBSCRIPTION_STATUS_INACTIVE
+}
+
+// dateTime converts a unix timestamp to a UTC datetime string. Missing
+// timestamps are open ended.
+func dateTime(timestamp int64) string {
+	if timestamp <= 0 {
+		return subscriptionstore.MAX_DATETIME
+	}
+	return carbon.CreateFromStdTime(time.Unix(timestamp, 0), carbon.UTC).ToDateTimeString(carbon.UTC)
+}
@@ -0,0 +1,307 @@
+package stripe
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dracory/subscriptionstore"
+	_ "modernc.org/sqlite"
+)
+
+const testSubscriptionID = "sub_1QxYz2AbCdEfGh"
+
+func initHandler(t *testing.T) (*WebhookHandler, subscriptionstore.StoreInterface) {
+	db, err := sql.Open("sqlite", ":memory:?parseTime=true&loc=UTC&_loc=UTC")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := subscriptionstore.NewStore(subscriptionstore.NewStoreOptions{
+		DB:                    db,
+		PlanTableName:         "plan_table",
+		SubscriptionTableName: "subscription_table",
+		AutomigrateEnabled:    true,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	plan := subscriptionstore.NewPlan().
+		SetTitle("Pro").
+		SetPrice("19.99").
+		SetInterval(subscriptionstore.PLAN_INTERVAL_MONTHLY).
+		SetStripePriceID("price_1ProMonthly").
+		SetStatus(subscriptionstore.PLAN_STATUS_ACTIVE)
+	if err := store.PlanCreate(context.Background(), plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	handler, err := NewWebhookHandler(NewWebhookHandlerOptions{
+		Store:         store,
+		WebhookSecret: testSecret,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return handler, store
+}
+
+func handleFixture(t *testing.T, handler *WebhookHandler, eventType string) {
+	event, err := ParseEvent(loadFixture(t, eventType))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := handler.HandleEvent(context.Background(), event); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func findSubscription(t *testing.T, store subscriptionstore.StoreInterface) subscriptionstore.SubscriptionInterface {
+	subscription, err := store.SubscriptionFindByID(context.Background(), testSubscriptionID)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if subscription == nil {
+		t.Fatal("expected subscription to exist")
+	}
+	return subscription
+}
+
+func TestWebhookHandlerSubscriptionLifecycle(t *testing.T) {
+	handler, store := initHandler(t)
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	subscription := findSubscription(t, store)
+	if subscription.GetStatus() != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE, subscription.GetStatus())
+	}
+	if subscription.GetSubscriberID() != "cus_Ra1b2c3d4e" {
+		t.Errorf("expected subscriber cus_Ra1b2c3d4e, got %s", subscription.GetSubscriberID())
+	}
+	if subscription.GetPaymentMethodID() != "pm_1QpmCard4242" {
+		t.Errorf("expected payment method pm_1QpmCard4242, got %s", subscription.GetPaymentMethodID())
+	}
+	if subscription.GetPeriodStart() != "2025-01-01 00:00:00" || subscription.GetPeriodEnd() != "2025-02-01 00:00:00" {
+		t.Errorf("unexpected period %s - %s", subscription.GetPeriodStart(), subscription.GetPeriodEnd())
+	}
+
+	// Redelivery is a no-op
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_UPDATED)
+	if !findSubscription(t, store).GetCancelAtPeriodEnd() {
+		t.Error("expected cancel at period end to be set by the update")
+	}
+
+	// An older event delivered late does not overwrite the update
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+	if !findSubscription(t, store).GetCancelAtPeriodEnd() {
+		t.Error("expected the late created event to be ignored")
+	}
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_DELETED)
+	if status := findSubscription(t, store).GetStatus(); status != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED, status)
+	}
+	if cancelledBy := findSubscription(t, store).GetCancelledBy(); cancelledBy != subscriptionstore.CANCELLED_BY_SYSTEM {
+		t.Errorf("expected cancelled by %s, got %s", subscriptionstore.CANCELLED_BY_SYSTEM, cancelledBy)
+	}
+
+	count, err := store.SubscriptionCount(context.Background(), subscriptionstore.SubscriptionQuery())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 subscription, got %d", count)
+	}
+}
+
+// failingCancelStore fails the cancellations, including those made in its
+// transactions
+type failingCancelStore struct {
+	subscriptionstore.StoreInterface
+}
+
+func (s failingCancelStore) SubscriptionCancel(ctx context.Context, subscriptionID string, options subscriptionstore.SubscriptionCancelOptions) error {
+	return errors.New("cancel failed")
+}
+
+func (s failingCancelStore) Transaction(ctx context.Context, fn func(txStore subscriptionstore.StoreInterface) error) error {
+	return s.StoreInterface.Transaction(ctx, func(txStore subscriptionstore.StoreInterface) error {
+		return fn(failingCancelStore{txStore})
+	})
+}
+
+func TestWebhookHandlerRetriesFailedEvent(t *testing.T) {
+	handler, store := initHandler(t)
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	failing, err := NewWebhookHandler(NewWebhookHandlerOptions{
+		Store:         failingCancelStore{store},
+		WebhookSecret: testSecret,
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	event, err := ParseEvent(loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_DELETED))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := failing.HandleEvent(context.Background(), event); err == nil {
+		t.Fatal("expected the error of the cancellation")
+	}
+
+	// The failed event is neither applied nor recorded, so the retry
+	// cancels the subscription
+	subscription := findSubscription(t, store)
+	if subscription.GetStatus() != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Fatalf("expected the failed event to be rolled back, got status %s", subscription.GetStatus())
+	}
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_DELETED)
+	if status := findSubscription(t, store).GetStatus(); status != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED, status)
+	}
+}
+
+func TestWebhookHandlerInvoiceEvents(t *testing.T) {
+	handler, store := initHandler(t)
+	ctx := context.Background()
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	handleFixture(t, handler, EVENT_INVOICE_PAYMENT_FAILED)
+	if status := findSubscription(t, store).GetStatus(); status != subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE {
+		t.Fatalf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE, status)
+	}
+
+	// Redelivery does not record a second failure
+	handleFixture(t, handler, EVENT_INVOICE_PAYMENT_FAILED)
+	attempts, err := store.DunningAttemptList(ctx, subscriptionstore.DunningAttemptQuery().SetSubscriptionID(testSubscriptionID))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected a failed and a scheduled attempt, got %d attempts", len(attempts))
+	}
+
+	handleFixture(t, handler, EVENT_INVOICE_PAID)
+	if status := findSubscription(t, store).GetStatus(); status != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Fatalf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE, status)
+	}
+}
+
+func TestWebhookHandlerLateInvoiceEvent(t *testing.T) {
+	handler, store := initHandler(t)
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	// A newer subscription event does not drop an older invoice event
+	// delivered late
+	updated, err := ParseEvent(bytes.Replace(loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_UPDATED), []byte("1735776000"), []byte("1736000000"), 1))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := handler.HandleEvent(context.Background(), updated); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	handleFixture(t, handler, EVENT_INVOICE_PAYMENT_FAILED)
+	if status := findSubscription(t, store).GetStatus(); status != subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE {
+		t.Fatalf("expected status %s, got %s", subscriptionstore.SUBSCRIPTION_STATUS_PAST_DUE, status)
+	}
+}
+
+func TestWebhookHandlerUnknownPlan(t *testing.T) {
+	handler, _ := initHandler(t)
+
+	event, err := ParseEvent(bytes.Replace(loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_CREATED), []byte("price_1ProMonthly"), []byte("price_unknown"), 1))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := handler.HandleEvent(context.Background(), event); err == nil {
+		t.Fatal("expected error for a price without a local plan")
+	}
+}
+
+func TestWebhookHandlerPlanFromProviderReference(t *testing.T) {
+	handler, store := initHandler(t)
+	ctx := context.Background()
+
+	plan := subscriptionstore.NewPlan().SetTitle("Pro Paddle Migrated")
+	if err := store.PlanCreate(ctx, plan); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The reference takes precedence over the stripe price id of the plans
+	reference := subscriptionstore.NewProviderReference().
+		SetProvider(subscriptionstore.PAYMENT_PROVIDER_STRIPE).
+		SetObjectType(subscriptionstore.PROVIDER_OBJECT_TYPE_PRICE).
+		SetLocalID(plan.GetID()).
+		SetExternalID("price_1ProMonthly")
+	if err := store.ProviderReferenceCreate(ctx, reference); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	handleFixture(t, handler, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	if planID := findSubscription(t, store).GetPlanID(); planID != plan.GetID() {
+		t.Fatalf("expected plan %s, got %s", plan.GetID(), planID)
+	}
+}
+
+func TestWebhookHandlerServeHTTP(t *testing.T) {
+	handler, store := initHandler(t)
+	payload := loadFixture(t, EVENT_CUSTOMER_SUBSCRIPTION_CREATED)
+
+	testCases := []struct {
+		name     string
+		method   string
+		header   string
+		expected int
+	}{
+		{"invalid signature", http.MethodPost, SignatureHeader(payload, "whsec_other", time.Now()), http.StatusBadRequest},
+		{"payload too large", http.MethodPost, SignatureHeader(payload, testSecret, time.Now()), http.StatusRequestEntityTooLarge},
+		{"wrong method", http.MethodGet, SignatureHeader(payload, testSecret, time.Now()), http.StatusMethodNotAllowed},
+		{"valid", http.MethodPost, SignatureHeader(payload, testSecret, time.Now()), http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := payload
+			if tc.expected == http.StatusRequestEntityTooLarge {
+				body = append(bytes.Repeat([]byte(" "), maxPayloadBytes), payload...)
+			}
+			request := httptest.NewRequest(tc.method, "/webhooks/stripe", bytes.NewReader(body))
+			request.Header.Set("Stripe-Signature", tc.header)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.expected {
+				t.Fatalf("expected status code %d, got %d", tc.expected, recorder.Code)
+			}
+		})
+	}
+
+	findSubscription(t, store)
+}
+
+func TestNewWebhookHandlerValidation(t *testing.T) {
+	if _, err := NewWebhookHandler(NewWebhookHandlerOptions{WebhookSecret: testSecret}); err == nil {
+		t.Error("expected error without a store")
+	}
+	_, store := initHandler(t)
+	if _, err := NewWebhookHandler(NewWebhookHandlerOptions{Store: store}); err == nil {
+		t.Error("expected error without a webhook secret")
+	}
+}
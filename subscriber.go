@@ -0,0 +1,207 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriberInterface defines the methods for a Subscriber entity.
+// A subscriber is the customer owning subscriptions, and its ID is the
+// subscriber ID of its subscriptions. Its currency and payment method are the
+// defaults of its new subscriptions.
+type SubscriberInterface interface {
+	GetCountry() string
+	SetCountry(country string) SubscriberInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) SubscriberInterface
+
+	GetCurrency() string
+	SetCurrency(currency string) SubscriberInterface
+
+	GetEmail() string
+	SetEmail(email string) SubscriberInterface
+
+	GetID() string
+	SetID(id string) SubscriberInterface
+
+	GetMemo() string
+	SetMemo(memo string) SubscriberInterface
+
+	GetName() string
+	SetName(name string) SubscriberInterface
+
+	GetPaymentMethodID() string
+	SetPaymentMethodID(paymentMethodID string) SubscriberInterface
+
+	GetRegion() string
+	SetRegion(region string) SubscriberInterface
+
+	GetTaxID() string
+	SetTaxID(taxID string) SubscriberInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) SubscriberInterface
+}
+
+var _ SubscriberInterface = (*subscriberImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type subscriberImplementation struct {
+	orm.ShortID
+
+	EmailField           string `db:"email"`
+	NameField            string `db:"name"`
+	CountryField         string `db:"country"`
+	RegionField          string `db:"region"`
+	TaxIDField           string `db:"tax_id"`
+	CurrencyField        string `db:"currency"`
+	PaymentMethodIDField string `db:"payment_method_id"`
+	MemoField            string `db:"memo"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewSubscriber() SubscriberInterface {
+	o := &subscriberImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetEmail("")
+	o.SetName("")
+	o.SetCountry("")
+	o.SetRegion("")
+	o.SetTaxID("")
+	o.SetCurrency("")
+	o.SetPaymentMethodID("")
+	o.SetMemo("")
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *subscriberImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *subscriberImplementation) SetID(id string) SubscriberInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *subscriberImplementation) GetEmail() string {
+	return o.EmailField
+}
+
+func (o *subscriberImplementation) SetEmail(email string) SubscriberInterface {
+	o.EmailField = email
+	return o
+}
+
+func (o *subscriberImplementation) GetName() string {
+	return o.NameField
+}
+
+func (o *subscriberImplementation) SetName(name string) SubscriberInterface {
+	o.NameField = name
+	return o
+}
+
+func (o *subscriberImplementation) GetCountry() string {
+	return o.CountryField
+}
+
+func (o *subscriberImplementation) SetCountry(country string) SubscriberInterface {
+	o.CountryField = country
+	return o
+}
+
+func (o *subscriberImplementation) GetRegion() string {
+	return o.RegionField
+}
+
+func (o *subscriberImplementation) SetRegion(region string) SubscriberInterface {
+	o.RegionField = region
+	return o
+}
+
+func (o *subscriberImplementation) GetTaxID() string {
+	return o.TaxIDField
+}
+
+func (o *subscriberImplementation) SetTaxID(taxID string) SubscriberInterface {
+	o.TaxIDField = taxID
+	return o
+}
+
+func (o *subscriberImplementation) GetCurrency() string {
+	return o.CurrencyField
+}
+
+func (o *subscriberImplementation) SetCurrency(currency string) SubscriberInterface {
+	o.CurrencyField = currency
+	return o
+}
+
+func (o *subscriberImplementation) GetPaymentMethodID() string {
+	return o.PaymentMethodIDField
+}
+
+func (o *subscriberImplementation) SetPaymentMethodID(paymentMethodID string) SubscriberInterface {
+	o.PaymentMethodIDField = paymentMethodID
+	return o
+}
+
+func (o *subscriberImplementation) GetMemo() string {
+	return o.MemoField
+}
+
+func (o *subscriberImplementation) SetMemo(memo string) SubscriberInterface {
+	o.MemoField = memo
+	return o
+}
+
+func (o *subscriberImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *subscriberImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *subscriberImplementation) SetCreatedAt(createdAt string) SubscriberInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *subscriberImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *subscriberImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *subscriberImplementation) SetUpdatedAt(updatedAt string) SubscriberInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
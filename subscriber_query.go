@@ -0,0 +1,189 @@
+package subscriptionstore
+
+import "errors"
+
+// SubscriberQueryInterface defines the interface for querying subscribers.
+type SubscriberQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) SubscriberQueryInterface
+
+	HasEmail() bool
+	Email() string
+	SetEmail(email string) SubscriberQueryInterface
+
+	HasCountry() bool
+	Country() string
+	SetCountry(country string) SubscriberQueryInterface
+
+	HasCurrency() bool
+	Currency() string
+	SetCurrency(currency string) SubscriberQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) SubscriberQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) SubscriberQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) SubscriberQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) SubscriberQueryInterface
+}
+
+// SubscriberQuery is a shortcut alias for NewSubscriberQuery
+func SubscriberQuery() SubscriberQueryInterface {
+	return NewSubscriberQuery()
+}
+
+// NewSubscriberQuery creates a new subscriber query
+func NewSubscriberQuery() SubscriberQueryInterface {
+	return &subscriberQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ SubscriberQueryInterface = (*subscriberQueryImplementation)(nil)
+
+type subscriberQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *subscriberQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("subscriber query. id cannot be empty")
+	}
+	if q.HasEmail() && q.Email() == "" {
+		return errors.New("subscriber query. email cannot be empty")
+	}
+	if q.HasCountry() && q.Country() == "" {
+		return errors.New("subscriber query. country cannot be empty")
+	}
+	if q.HasCurrency() && q.Currency() == "" {
+		return errors.New("subscriber query. currency cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("subscriber query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("subscriber query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *subscriberQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *subscriberQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetID(id string) SubscriberQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasEmail() bool {
+	return q.hasProperty("email")
+}
+
+func (q *subscriberQueryImplementation) Email() string {
+	return q.properties["email"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetEmail(email string) SubscriberQueryInterface {
+	q.properties["email"] = email
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasCountry() bool {
+	return q.hasProperty("country")
+}
+
+func (q *subscriberQueryImplementation) Country() string {
+	return q.properties["country"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetCountry(country string) SubscriberQueryInterface {
+	q.properties["country"] = country
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasCurrency() bool {
+	return q.hasProperty("currency")
+}
+
+func (q *subscriberQueryImplementation) Currency() string {
+	return q.properties["currency"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetCurrency(currency string) SubscriberQueryInterface {
+	q.properties["currency"] = currency
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *subscriberQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *subscriberQueryImplementation) SetOffset(offset int) SubscriberQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *subscriberQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *subscriberQueryImplementation) SetLimit(limit int) SubscriberQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *subscriberQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetOrderBy(orderBy string) SubscriberQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *subscriberQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *subscriberQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *subscriberQueryImplementation) SetSortOrder(sortOrder string) SubscriberQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *subscriberQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
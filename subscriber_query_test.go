@@ -0,0 +1,49 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscriberQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(SubscriberQueryInterface)
+		contains string
+	}{
+		{
+			name:     "email empty",
+			setup:    func(q SubscriberQueryInterface) { q.SetEmail("") },
+			contains: "email cannot be empty",
+		},
+		{
+			name:     "country empty",
+			setup:    func(q SubscriberQueryInterface) { q.SetCountry("") },
+			contains: "country cannot be empty",
+		},
+		{
+			name:     "currency empty",
+			setup:    func(q SubscriberQueryInterface) { q.SetCurrency("") },
+			contains: "currency cannot be empty",
+		},
+		{
+			name:     "limit negative",
+			setup:    func(q SubscriberQueryInterface) { q.SetLimit(-1) },
+			contains: "limit cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewSubscriberQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
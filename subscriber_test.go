@@ -0,0 +1,46 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewSubscriberDefaults(t *testing.T) {
+	subscriber := NewSubscriber()
+
+	if subscriber.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if subscriber.GetEmail() != "" || subscriber.GetCurrency() != "" || subscriber.GetPaymentMethodID() != "" {
+		t.Fatalf("expected empty defaults, got %s / %s / %s", subscriber.GetEmail(), subscriber.GetCurrency(), subscriber.GetPaymentMethodID())
+	}
+	if subscriber.GetCreatedAt() == "" || subscriber.GetUpdatedAt() == "" {
+		t.Fatal("expected created and updated dates to be set")
+	}
+}
+
+func TestSubscriberSettersAndGetters(t *testing.T) {
+	subscriber := NewSubscriber().
+		SetID("user_1").
+		SetEmail("jane@example.com").
+		SetName("Jane Doe").
+		SetCountry("DE").
+		SetRegion("BE").
+		SetTaxID("DE123456789").
+		SetCurrency(CURRENCY_EUR).
+		SetPaymentMethodID("pm_1").
+		SetMemo("Key account")
+
+	if subscriber.GetID() != "user_1" {
+		t.Fatalf("expected ID user_1, got %s", subscriber.GetID())
+	}
+	if subscriber.GetEmail() != "jane@example.com" || subscriber.GetName() != "Jane Doe" {
+		t.Fatalf("unexpected email and name %s / %s", subscriber.GetEmail(), subscriber.GetName())
+	}
+	if subscriber.GetCountry() != "DE" || subscriber.GetRegion() != "BE" || subscriber.GetTaxID() != "DE123456789" {
+		t.Fatalf("unexpected tax details %s / %s / %s", subscriber.GetCountry(), subscriber.GetRegion(), subscriber.GetTaxID())
+	}
+	if subscriber.GetCurrency() != CURRENCY_EUR || subscriber.GetPaymentMethodID() != "pm_1" {
+		t.Fatalf("unexpected defaults %s / %s", subscriber.GetCurrency(), subscriber.GetPaymentMethodID())
+	}
+	if subscriber.GetMemo() != "Key account" {
+		t.Fatalf("expected memo Key account, got %s", subscriber.GetMemo())
+	}
+}
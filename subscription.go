@@ -8,16 +8,21 @@ import (
 	"github.com/dracory/neat/database/soft_delete"
 	neatuid "github.com/dracory/neat/support/uid"
 	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
 )
 
 // SubscriptionInterface defines the methods for a Subscription entity
 type SubscriptionInterface interface {
 	IsSoftDeleted() bool
+	IsPaused() bool
 
 	GetCreatedAt() string
 	GetCreatedAtCarbon() *carbon.Carbon
 	SetCreatedAt(createdAt string) SubscriptionInterface
 
+	GetCurrency() string
+	SetCurrency(currency string) SubscriptionInterface
+
 	GetID() string
 	SetID(id string) SubscriptionInterface
 
@@ -30,6 +35,12 @@ type SubscriptionInterface interface {
 	GetPlanID() string
 	SetPlanID(planID string) SubscriptionInterface
 
+	GetPlanVersionID() string
+	SetPlanVersionID(planVersionID string) SubscriptionInterface
+
+	GetQuantity() int
+	SetQuantity(quantity int) SubscriptionInterface
+
 	GetPeriodStart() string
 	GetPeriodStartCarbon() *carbon.Carbon
 	SetPeriodStart(periodStart string) SubscriptionInterface
@@ -41,9 +52,34 @@ type SubscriptionInterface interface {
 	GetCancelAtPeriodEnd() bool
 	SetCancelAtPeriodEnd(cancelAtPeriodEnd bool) SubscriptionInterface
 
+	GetCancellationRequestedAt() string
+	GetCancellationRequestedAtCarbon() *carbon.Carbon
+	SetCancellationRequestedAt(cancellationRequestedAt string) SubscriptionInterface
+
+	GetCancellationEffectiveAt() string
+	GetCancellationEffectiveAtCarbon() *carbon.Carbon
+	SetCancellationEffectiveAt(cancellationEffectiveAt string) SubscriptionInterface
+
+	GetCancellationReason() string
+	SetCancellationReason(cancellationReason string) SubscriptionInterface
+
+	GetCancellationFeedback() string
+	SetCancellationFeedback(cancellationFeedback string) SubscriptionInterface
+
+	GetCancelledBy() string
+	SetCancelledBy(cancelledBy string) SubscriptionInterface
+
 	GetPaymentMethodID() string
 	SetPaymentMethodID(paymentMethodID string) SubscriptionInterface
 
+	GetPausedAt() string
+	GetPausedAtCarbon() *carbon.Carbon
+	SetPausedAt(pausedAt string) SubscriptionInterface
+
+	GetResumeAt() string
+	GetResumeAtCarbon() *carbon.Carbon
+	SetResumeAt(resumeAt string) SubscriptionInterface
+
 	GetMemo() string
 	SetMemo(memo string) SubscriptionInterface
 
@@ -73,13 +109,24 @@ type subscriptionImplementation struct {
 	StatusField            string `db:"status"`
 	SubscriberIDField      string `db:"subscriber_id"`
 	PlanIDField            string `db:"plan_id"`
+	PlanVersionIDField     string `db:"plan_version_id"`
+	QuantityField          int    `db:"quantity"`
 	PeriodStartField       string `db:"period_start"`
 	PeriodEndField         string `db:"period_end"`
 	CancelAtPeriodEndField string `db:"cancel_at_period_end"`
 	PaymentMethodIDField   string `db:"payment_method_id"`
+	CurrencyField          string `db:"currency"`
+	PausedAtField          string `db:"paused_at"`
+	ResumeAtField          string `db:"resume_at"`
 	MemoField              string `db:"memo"`
 	MetasField             string `db:"metas"`
 
+	CancellationRequestedAtField string `db:"cancellation_requested_at"`
+	CancellationEffectiveAtField string `db:"cancellation_effective_at"`
+	CancellationReasonField      string `db:"cancellation_reason"`
+	CancellationFeedbackField    string `db:"cancellation_feedback"`
+	CancelledByField             string `db:"cancelled_by"`
+
 	CreatedAtField orm.CreatedAt
 	UpdatedAtField orm.UpdatedAt
 	soft_delete.SoftDeletesMaxDate
@@ -96,11 +143,21 @@ func NewSubscription() SubscriptionInterface {
 	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	o.SetStatus(SUBSCRIPTION_STATUS_INACTIVE)
 	o.SetPlanID("")
+	o.SetPlanVersionID("")
+	o.SetQuantity(1)
 	o.SetSubscriberID("")
 	o.SetPaymentMethodID("")
+	o.SetCurrency("")
 	o.SetPeriodStart(MAX_DATETIME)
 	o.SetPeriodEnd(MAX_DATETIME)
 	o.SetCancelAtPeriodEnd(false)
+	o.SetCancellationRequestedAt(MAX_DATETIME)
+	o.SetCancellationEffectiveAt(MAX_DATETIME)
+	o.SetCancellationReason("")
+	o.SetCancellationFeedback("")
+	o.SetCancelledBy("")
+	o.SetPausedAt(MAX_DATETIME)
+	o.SetResumeAt(MAX_DATETIME)
 	if _, err := o.SetMetas(map[string]string{}); err != nil {
 		log.Println(err.Error())
 	}
@@ -113,10 +170,29 @@ func NewSubscriptionFromExistingData(data map[string]string) SubscriptionInterfa
 	o.SetStatus(data[COLUMN_STATUS])
 	o.SetSubscriberID(data[COLUMN_SUBSCRIBER_ID])
 	o.SetPlanID(data[COLUMN_PLAN_ID])
+	o.SetPlanVersionID(data[COLUMN_PLAN_VERSION_ID])
+	o.SetQuantity(1)
+	if v, ok := data[COLUMN_QUANTITY]; ok {
+		o.SetQuantity(cast.ToInt(v))
+	}
 	o.SetPeriodStart(data[COLUMN_PERIOD_START])
 	o.SetPeriodEnd(data[COLUMN_PERIOD_END])
 	o.SetCancelAtPeriodEnd(data[COLUMN_CANCEL_AT_PERIOD_END] == YES)
+	o.SetCancellationRequestedAt(MAX_DATETIME)
+	if v, ok := data[COLUMN_CANCELLATION_REQUESTED_AT]; ok {
+		o.SetCancellationRequestedAt(v)
+	}
+	o.SetCancellationEffectiveAt(MAX_DATETIME)
+	if v, ok := data[COLUMN_CANCELLATION_EFFECTIVE_AT]; ok {
+		o.SetCancellationEffectiveAt(v)
+	}
+	o.SetCancellationReason(data[COLUMN_CANCELLATION_REASON])
+	o.SetCancellationFeedback(data[COLUMN_CANCELLATION_FEEDBACK])
+	o.SetCancelledBy(data[COLUMN_CANCELLED_BY])
 	o.SetPaymentMethodID(data[COLUMN_PAYMENT_METHOD_ID])
+	o.SetCurrency(data[COLUMN_CURRENCY])
+	o.SetPausedAt(data[COLUMN_PAUSED_AT])
+	o.SetResumeAt(data[COLUMN_RESUME_AT])
 	o.SetMemo(data[COLUMN_MEMO])
 	o.MetasField = data[COLUMN_METAS]
 	if v, ok := data[COLUMN_CREATED_AT]; ok {
@@ -137,6 +213,10 @@ func (o *subscriptionImplementation) IsSoftDeleted() bool {
 	return o.SoftDeletesMaxDate.IsSoftDeleted()
 }
 
+func (o *subscriptionImplementation) IsPaused() bool {
+	return o.GetStatus() == SUBSCRIPTION_STATUS_PAUSED
+}
+
 // == SETTERS AND GETTERS ======================================================
 
 func (o *subscriptionImplementation) GetID() string {
@@ -175,6 +255,24 @@ func (o *subscriptionImplementation) SetPlanID(planID string) SubscriptionInterf
 	return o
 }
 
+func (o *subscriptionImplementation) GetPlanVersionID() string {
+	return o.PlanVersionIDField
+}
+
+func (o *subscriptionImplementation) SetPlanVersionID(planVersionID string) SubscriptionInterface {
+	o.PlanVersionIDField = planVersionID
+	return o
+}
+
+func (o *subscriptionImplementation) GetQuantity() int {
+	return o.QuantityField
+}
+
+func (o *subscriptionImplementation) SetQuantity(quantity int) SubscriptionInterface {
+	o.QuantityField = quantity
+	return o
+}
+
 func (o *subscriptionImplementation) GetPeriodStart() string {
 	if o.PeriodStartField == "" {
 		return ""
@@ -220,6 +318,68 @@ func (o *subscriptionImplementation) SetCancelAtPeriodEnd(cancelAtPeriodEnd bool
 	return o
 }
 
+func (o *subscriptionImplementation) GetCancellationRequestedAt() string {
+	return o.CancellationRequestedAtField
+}
+
+func (o *subscriptionImplementation) GetCancellationRequestedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetCancellationRequestedAt(), carbon.UTC)
+}
+
+func (o *subscriptionImplementation) SetCancellationRequestedAt(cancellationRequestedAt string) SubscriptionInterface {
+	o.CancellationRequestedAtField = cancellationRequestedAt
+	return o
+}
+
+func (o *subscriptionImplementation) GetCancellationEffectiveAt() string {
+	return o.CancellationEffectiveAtField
+}
+
+func (o *subscriptionImplementation) GetCancellationEffectiveAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetCancellationEffectiveAt(), carbon.UTC)
+}
+
+func (o *subscriptionImplementation) SetCancellationEffectiveAt(cancellationEffectiveAt string) SubscriptionInterface {
+	o.CancellationEffectiveAtField = cancellationEffectiveAt
+	return o
+}
+
+func (o *subscriptionImplementation) GetCancellationReason() string {
+	return o.CancellationReasonField
+}
+
+func (o *subscriptionImplementation) SetCancellationReason(cancellationReason string) SubscriptionInterface {
+	o.CancellationReasonField = cancellationReason
+	return o
+}
+
+func (o *subscriptionImplementation) GetCancellationFeedback() string {
+	return o.CancellationFeedbackField
+}
+
+func (o *subscriptionImplementation) SetCancellationFeedback(cancellationFeedback string) SubscriptionInterface {
+	o.CancellationFeedbackField = cancellationFeedback
+	return o
+}
+
+func (o *subscriptionImplementation) GetCancelledBy() string {
+	return o.CancelledByField
+}
+
+func (o *subscriptionImplementation) SetCancelledBy(cancelledBy string) SubscriptionInterface {
+	o.CancelledByField = cancelledBy
+	return o
+}
+
+func (o *subscriptionImplementation) GetCurrency() string {
+	return o.CurrencyField
+}
+
+func (o *subscriptionImplementation) SetCurrency(currency string) SubscriptionInterface {
+	o.CurrencyField = currency
+	return o
+}
+
 func (o *subscriptionImplementation) GetPaymentMethodID() string {
 	return o.PaymentMethodIDField
 }
@@ -229,6 +389,32 @@ func (o *subscriptionImplementation) SetPaymentMethodID(paymentMethodID string)
 	return o
 }
 
+func (o *subscriptionImplementation) GetPausedAt() string {
+	return o.PausedAtField
+}
+
+func (o *subscriptionImplementation) GetPausedAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetPausedAt(), carbon.UTC)
+}
+
+func (o *subscriptionImplementation) SetPausedAt(pausedAt string) SubscriptionInterface {
+	o.PausedAtField = pausedAt
+	return o
+}
+
+func (o *subscriptionImplementation) GetResumeAt() string {
+	return o.ResumeAtField
+}
+
+func (o *subscriptionImplementation) GetResumeAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetResumeAt(), carbon.UTC)
+}
+
+func (o *subscriptionImplementation) SetResumeAt(resumeAt string) SubscriptionInterface {
+	o.ResumeAtField = resumeAt
+	return o
+}
+
 func (o *subscriptionImplementation) GetMemo() string {
 	return o.MemoField
 }
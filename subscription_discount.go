@@ -0,0 +1,174 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionDiscountInterface defines the methods for a SubscriptionDiscount entity.
+// A subscription discount is a coupon applied to a subscription. It applies to
+// the billing periods starting from StartsAt and before EndsAt.
+type SubscriptionDiscountInterface interface {
+	GetCouponID() string
+	SetCouponID(couponID string) SubscriptionDiscountInterface
+
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) SubscriptionDiscountInterface
+
+	GetEndsAt() string
+	GetEndsAtCarbon() *carbon.Carbon
+	SetEndsAt(endsAt string) SubscriptionDiscountInterface
+
+	GetID() string
+	SetID(id string) SubscriptionDiscountInterface
+
+	GetPromotionCodeID() string
+	SetPromotionCodeID(promotionCodeID string) SubscriptionDiscountInterface
+
+	GetStartsAt() string
+	GetStartsAtCarbon() *carbon.Carbon
+	SetStartsAt(startsAt string) SubscriptionDiscountInterface
+
+	GetSubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionDiscountInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) SubscriptionDiscountInterface
+}
+
+var _ SubscriptionDiscountInterface = (*subscriptionDiscountImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type subscriptionDiscountImplementation struct {
+	orm.ShortID
+
+	SubscriptionIDField  string `db:"subscription_id"`
+	CouponIDField        string `db:"coupon_id"`
+	PromotionCodeIDField string `db:"promotion_code_id"`
+	StartsAtField        string `db:"starts_at"`
+	EndsAtField          string `db:"ends_at"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewSubscriptionDiscount() SubscriptionDiscountInterface {
+	o := &subscriptionDiscountImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetSubscriptionID("")
+	o.SetCouponID("")
+	o.SetPromotionCodeID("")
+	o.SetStartsAt(MAX_DATETIME)
+	o.SetEndsAt(MAX_DATETIME)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *subscriptionDiscountImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *subscriptionDiscountImplementation) SetID(id string) SubscriptionDiscountInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetSubscriptionID() string {
+	return o.SubscriptionIDField
+}
+
+func (o *subscriptionDiscountImplementation) SetSubscriptionID(subscriptionID string) SubscriptionDiscountInterface {
+	o.SubscriptionIDField = subscriptionID
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetCouponID() string {
+	return o.CouponIDField
+}
+
+func (o *subscriptionDiscountImplementation) SetCouponID(couponID string) SubscriptionDiscountInterface {
+	o.CouponIDField = couponID
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetPromotionCodeID() string {
+	return o.PromotionCodeIDField
+}
+
+func (o *subscriptionDiscountImplementation) SetPromotionCodeID(promotionCodeID string) SubscriptionDiscountInterface {
+	o.PromotionCodeIDField = promotionCodeID
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetStartsAt() string {
+	return o.StartsAtField
+}
+
+func (o *subscriptionDiscountImplementation) GetStartsAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetStartsAt(), carbon.UTC)
+}
+
+func (o *subscriptionDiscountImplementation) SetStartsAt(startsAt string) SubscriptionDiscountInterface {
+	o.StartsAtField = startsAt
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetEndsAt() string {
+	return o.EndsAtField
+}
+
+func (o *subscriptionDiscountImplementation) GetEndsAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetEndsAt(), carbon.UTC)
+}
+
+func (o *subscriptionDiscountImplementation) SetEndsAt(endsAt string) SubscriptionDiscountInterface {
+	o.EndsAtField = endsAt
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionDiscountImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *subscriptionDiscountImplementation) SetCreatedAt(createdAt string) SubscriptionDiscountInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *subscriptionDiscountImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionDiscountImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *subscriptionDiscountImplementation) SetUpdatedAt(updatedAt string) SubscriptionDiscountInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
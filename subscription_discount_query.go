@@ -0,0 +1,190 @@
+package subscriptionstore
+
+import "errors"
+
+// SubscriptionDiscountQueryInterface defines the interface for querying subscription discounts.
+type SubscriptionDiscountQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) SubscriptionDiscountQueryInterface
+
+	HasSubscriptionID() bool
+	SubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionDiscountQueryInterface
+
+	HasCouponID() bool
+	CouponID() string
+	SetCouponID(couponID string) SubscriptionDiscountQueryInterface
+
+	HasActiveAt() bool
+	ActiveAt() string
+	SetActiveAt(activeAt string) SubscriptionDiscountQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) SubscriptionDiscountQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) SubscriptionDiscountQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) SubscriptionDiscountQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) SubscriptionDiscountQueryInterface
+}
+
+// SubscriptionDiscountQuery is a shortcut alias for NewSubscriptionDiscountQuery
+func SubscriptionDiscountQuery() SubscriptionDiscountQueryInterface {
+	return NewSubscriptionDiscountQuery()
+}
+
+// NewSubscriptionDiscountQuery creates a new subscription discount query
+func NewSubscriptionDiscountQuery() SubscriptionDiscountQueryInterface {
+	return &subscriptionDiscountQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ SubscriptionDiscountQueryInterface = (*subscriptionDiscountQueryImplementation)(nil)
+
+type subscriptionDiscountQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *subscriptionDiscountQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("subscription discount query. id cannot be empty")
+	}
+	if q.HasSubscriptionID() && q.SubscriptionID() == "" {
+		return errors.New("subscription discount query. subscription_id cannot be empty")
+	}
+	if q.HasCouponID() && q.CouponID() == "" {
+		return errors.New("subscription discount query. coupon_id cannot be empty")
+	}
+	if q.HasActiveAt() && q.ActiveAt() == "" {
+		return errors.New("subscription discount query. active_at cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("subscription discount query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("subscription discount query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *subscriptionDiscountQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetID(id string) SubscriptionDiscountQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasSubscriptionID() bool {
+	return q.hasProperty("subscription_id")
+}
+
+func (q *subscriptionDiscountQueryImplementation) SubscriptionID() string {
+	return q.properties["subscription_id"].(string)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetSubscriptionID(subscriptionID string) SubscriptionDiscountQueryInterface {
+	q.properties["subscription_id"] = subscriptionID
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasCouponID() bool {
+	return q.hasProperty("coupon_id")
+}
+
+func (q *subscriptionDiscountQueryImplementation) CouponID() string {
+	return q.properties["coupon_id"].(string)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetCouponID(couponID string) SubscriptionDiscountQueryInterface {
+	q.properties["coupon_id"] = couponID
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasActiveAt() bool {
+	return q.hasProperty("active_at")
+}
+
+func (q *subscriptionDiscountQueryImplementation) ActiveAt() string {
+	return q.properties["active_at"].(string)
+}
+
+// SetActiveAt matches the discounts whose window contains the date
+func (q *subscriptionDiscountQueryImplementation) SetActiveAt(activeAt string) SubscriptionDiscountQueryInterface {
+	q.properties["active_at"] = activeAt
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *subscriptionDiscountQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetOffset(offset int) SubscriptionDiscountQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *subscriptionDiscountQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetLimit(limit int) SubscriptionDiscountQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *subscriptionDiscountQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetOrderBy(orderBy string) SubscriptionDiscountQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *subscriptionDiscountQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *subscriptionDiscountQueryImplementation) SetSortOrder(sortOrder string) SubscriptionDiscountQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *subscriptionDiscountQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
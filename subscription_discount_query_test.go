@@ -0,0 +1,49 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionDiscountQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(SubscriptionDiscountQueryInterface)
+		contains string
+	}{
+		{
+			name:     "subscription_id empty",
+			setup:    func(q SubscriptionDiscountQueryInterface) { q.SetSubscriptionID("") },
+			contains: "subscription_id cannot be empty",
+		},
+		{
+			name:     "coupon_id empty",
+			setup:    func(q SubscriptionDiscountQueryInterface) { q.SetCouponID("") },
+			contains: "coupon_id cannot be empty",
+		},
+		{
+			name:     "active_at empty",
+			setup:    func(q SubscriptionDiscountQueryInterface) { q.SetActiveAt("") },
+			contains: "active_at cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q SubscriptionDiscountQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewSubscriptionDiscountQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewSubscriptionDiscountDefaults(t *testing.T) {
+	discount := NewSubscriptionDiscount()
+
+	if discount.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if discount.GetStartsAt() != MAX_DATETIME || discount.GetEndsAt() != MAX_DATETIME {
+		t.Fatalf("expected window %s - %s, got %s - %s", MAX_DATETIME, MAX_DATETIME, discount.GetStartsAt(), discount.GetEndsAt())
+	}
+}
+
+func TestSubscriptionDiscountSettersAndGetters(t *testing.T) {
+	discount := NewSubscriptionDiscount().
+		SetSubscriptionID("sub_1").
+		SetCouponID("coupon_1").
+		SetPromotionCodeID("promo_1").
+		SetStartsAt("2025-01-01 00:00:00").
+		SetEndsAt("2025-04-01 00:00:00")
+
+	if discount.GetSubscriptionID() != "sub_1" {
+		t.Fatalf("expected subscription id sub_1, got %s", discount.GetSubscriptionID())
+	}
+	if discount.GetCouponID() != "coupon_1" {
+		t.Fatalf("expected coupon id coupon_1, got %s", discount.GetCouponID())
+	}
+	if discount.GetPromotionCodeID() != "promo_1" {
+		t.Fatalf("expected promotion code id promo_1, got %s", discount.GetPromotionCodeID())
+	}
+	if discount.GetStartsAtCarbon().ToDateTimeString() != "2025-01-01 00:00:00" {
+		t.Fatalf("expected starts at 2025-01-01 00:00:00, got %s", discount.GetStartsAt())
+	}
+	if discount.GetEndsAtCarbon().ToDateTimeString() != "2025-04-01 00:00:00" {
+		t.Fatalf("expected ends at 2025-04-01 00:00:00, got %s", discount.GetEndsAt())
+	}
+}
@@ -0,0 +1,136 @@
+package subscriptionstore
+
+import (
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionItemInterface defines the methods for a SubscriptionItem entity.
+// An item adds a plan, i.e. an add-on, to a subscription. It is billed
+// with the subscription's plan, for the same billing period.
+type SubscriptionItemInterface interface {
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) SubscriptionItemInterface
+
+	GetID() string
+	SetID(id string) SubscriptionItemInterface
+
+	GetPlanID() string
+	SetPlanID(planID string) SubscriptionItemInterface
+
+	GetQuantity() int
+	SetQuantity(quantity int) SubscriptionItemInterface
+
+	GetSubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionItemInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) SubscriptionItemInterface
+}
+
+var _ SubscriptionItemInterface = (*subscriptionItemImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type subscriptionItemImplementation struct {
+	orm.ShortID
+
+	SubscriptionIDField string `db:"subscription_id"`
+	PlanIDField         string `db:"plan_id"`
+	QuantityField       int    `db:"quantity"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewSubscriptionItem() SubscriptionItemInterface {
+	o := &subscriptionItemImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetSubscriptionID("")
+	o.SetPlanID("")
+	o.SetQuantity(1)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *subscriptionItemImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *subscriptionItemImplementation) SetID(id string) SubscriptionItemInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *subscriptionItemImplementation) GetSubscriptionID() string {
+	return o.SubscriptionIDField
+}
+
+func (o *subscriptionItemImplementation) SetSubscriptionID(subscriptionID string) SubscriptionItemInterface {
+	o.SubscriptionIDField = subscriptionID
+	return o
+}
+
+func (o *subscriptionItemImplementation) GetPlanID() string {
+	return o.PlanIDField
+}
+
+func (o *subscriptionItemImplementation) SetPlanID(planID string) SubscriptionItemInterface {
+	o.PlanIDField = planID
+	return o
+}
+
+func (o *subscriptionItemImplementation) GetQuantity() int {
+	return o.QuantityField
+}
+
+func (o *subscriptionItemImplementation) SetQuantity(quantity int) SubscriptionItemInterface {
+	o.QuantityField = quantity
+	return o
+}
+
+func (o *subscriptionItemImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionItemImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *subscriptionItemImplementation) SetCreatedAt(createdAt string) SubscriptionItemInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *subscriptionItemImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionItemImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *subscriptionItemImplementation) SetUpdatedAt(updatedAt string) SubscriptionItemInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
@@ -0,0 +1,169 @@
+package subscriptionstore
+
+import "errors"
+
+// SubscriptionItemQueryInterface defines the interface for querying subscription items.
+type SubscriptionItemQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) SubscriptionItemQueryInterface
+
+	HasSubscriptionID() bool
+	SubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionItemQueryInterface
+
+	HasPlanID() bool
+	PlanID() string
+	SetPlanID(planID string) SubscriptionItemQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) SubscriptionItemQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) SubscriptionItemQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) SubscriptionItemQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) SubscriptionItemQueryInterface
+}
+
+// SubscriptionItemQuery is a shortcut alias for NewSubscriptionItemQuery
+func SubscriptionItemQuery() SubscriptionItemQueryInterface {
+	return NewSubscriptionItemQuery()
+}
+
+// NewSubscriptionItemQuery creates a new subscription item query
+func NewSubscriptionItemQuery() SubscriptionItemQueryInterface {
+	return &subscriptionItemQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ SubscriptionItemQueryInterface = (*subscriptionItemQueryImplementation)(nil)
+
+type subscriptionItemQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *subscriptionItemQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("subscription item query. id cannot be empty")
+	}
+	if q.HasSubscriptionID() && q.SubscriptionID() == "" {
+		return errors.New("subscription item query. subscription_id cannot be empty")
+	}
+	if q.HasPlanID() && q.PlanID() == "" {
+		return errors.New("subscription item query. plan_id cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("subscription item query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("subscription item query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *subscriptionItemQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *subscriptionItemQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *subscriptionItemQueryImplementation) SetID(id string) SubscriptionItemQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasSubscriptionID() bool {
+	return q.hasProperty("subscription_id")
+}
+
+func (q *subscriptionItemQueryImplementation) SubscriptionID() string {
+	return q.properties["subscription_id"].(string)
+}
+
+func (q *subscriptionItemQueryImplementation) SetSubscriptionID(subscriptionID string) SubscriptionItemQueryInterface {
+	q.properties["subscription_id"] = subscriptionID
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasPlanID() bool {
+	return q.hasProperty("plan_id")
+}
+
+func (q *subscriptionItemQueryImplementation) PlanID() string {
+	return q.properties["plan_id"].(string)
+}
+
+func (q *subscriptionItemQueryImplementation) SetPlanID(planID string) SubscriptionItemQueryInterface {
+	q.properties["plan_id"] = planID
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *subscriptionItemQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *subscriptionItemQueryImplementation) SetOffset(offset int) SubscriptionItemQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *subscriptionItemQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *subscriptionItemQueryImplementation) SetLimit(limit int) SubscriptionItemQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *subscriptionItemQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *subscriptionItemQueryImplementation) SetOrderBy(orderBy string) SubscriptionItemQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *subscriptionItemQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *subscriptionItemQueryImplementation) SetSortOrder(sortOrder string) SubscriptionItemQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *subscriptionItemQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
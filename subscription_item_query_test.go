@@ -0,0 +1,44 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionItemQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(SubscriptionItemQueryInterface)
+		contains string
+	}{
+		{
+			name:     "subscription_id empty",
+			setup:    func(q SubscriptionItemQueryInterface) { q.SetSubscriptionID("") },
+			contains: "subscription_id cannot be empty",
+		},
+		{
+			name:     "plan_id empty",
+			setup:    func(q SubscriptionItemQueryInterface) { q.SetPlanID("") },
+			contains: "plan_id cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q SubscriptionItemQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewSubscriptionItemQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
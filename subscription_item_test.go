@@ -0,0 +1,34 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewSubscriptionItemDefaults(t *testing.T) {
+	item := NewSubscriptionItem()
+
+	if item.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if item.GetQuantity() != 1 {
+		t.Fatalf("expected quantity 1, got %d", item.GetQuantity())
+	}
+	if item.GetCreatedAt() == "" || item.GetUpdatedAt() == "" {
+		t.Fatal("timestamps should not be empty")
+	}
+}
+
+func TestSubscriptionItemSettersAndGetters(t *testing.T) {
+	item := NewSubscriptionItem().
+		SetSubscriptionID("sub_1").
+		SetPlanID("plan_storage").
+		SetQuantity(3)
+
+	if item.GetSubscriptionID() != "sub_1" {
+		t.Fatalf("expected subscription id sub_1, got %s", item.GetSubscriptionID())
+	}
+	if item.GetPlanID() != "plan_storage" {
+		t.Fatalf("expected plan id plan_storage, got %s", item.GetPlanID())
+	}
+	if item.GetQuantity() != 3 {
+		t.Fatalf("expected quantity 3, got %d", item.GetQuantity())
+	}
+}
@@ -34,6 +34,30 @@ type SubscriptionQueryInterface interface {
 	PlanID() string
 	SetPlanID(planID string) SubscriptionQueryInterface
 
+	HasResumeAtLte() bool
+	ResumeAtLte() string
+	SetResumeAtLte(resumeAtLte string) SubscriptionQueryInterface
+
+	HasCancellationReason() bool
+	CancellationReason() string
+	SetCancellationReason(cancellationReason string) SubscriptionQueryInterface
+
+	HasCancellationRequestedAtGte() bool
+	CancellationRequestedAtGte() string
+	SetCancellationRequestedAtGte(cancellationRequestedAtGte string) SubscriptionQueryInterface
+
+	HasCancellationRequestedAtLte() bool
+	CancellationRequestedAtLte() string
+	SetCancellationRequestedAtLte(cancellationRequestedAtLte string) SubscriptionQueryInterface
+
+	HasMetaEquals() bool
+	MetaEquals() map[string]string
+	SetMetaEquals(key string, value string) SubscriptionQueryInterface
+
+	HasMetaHasKey() bool
+	MetaHasKey() []string
+	SetMetaHasKey(key string) SubscriptionQueryInterface
+
 	HasOffset() bool
 	Offset() int
 	SetOffset(offset int) SubscriptionQueryInterface
@@ -92,6 +116,32 @@ func (q *subscriptionQueryImplementation) Validate() error {
 	if q.HasPlanID() && q.PlanID() == "" {
 		return errors.New("subscription query. plan_id cannot be empty")
 	}
+	if q.HasResumeAtLte() && q.ResumeAtLte() == "" {
+		return errors.New("subscription query. resume_at_lte cannot be empty")
+	}
+	if q.HasCancellationReason() && q.CancellationReason() == "" {
+		return errors.New("subscription query. cancellation_reason cannot be empty")
+	}
+	if q.HasCancellationRequestedAtGte() && q.CancellationRequestedAtGte() == "" {
+		return errors.New("subscription query. cancellation_requested_at_gte cannot be empty")
+	}
+	if q.HasCancellationRequestedAtLte() && q.CancellationRequestedAtLte() == "" {
+		return errors.New("subscription query. cancellation_requested_at_lte cannot be empty")
+	}
+	if q.HasMetaEquals() {
+		for key := range q.MetaEquals() {
+			if key == "" {
+				return errors.New("subscription query. meta_equals key cannot be empty")
+			}
+		}
+	}
+	if q.HasMetaHasKey() {
+		for _, key := range q.MetaHasKey() {
+			if key == "" {
+				return errors.New("subscription query. meta_has_key key cannot be empty")
+			}
+		}
+	}
 	if q.HasLimit() && q.Limit() < 0 {
 		return errors.New("subscription query. limit cannot be negative")
 	}
@@ -192,6 +242,94 @@ func (q *subscriptionQueryImplementation) SetPlanID(planID string) SubscriptionQ
 	return q
 }
 
+func (q *subscriptionQueryImplementation) HasResumeAtLte() bool {
+	return q.hasProperty("resume_at_lte")
+}
+
+func (q *subscriptionQueryImplementation) ResumeAtLte() string {
+	return q.properties["resume_at_lte"].(string)
+}
+
+func (q *subscriptionQueryImplementation) SetResumeAtLte(resumeAtLte string) SubscriptionQueryInterface {
+	q.properties["resume_at_lte"] = resumeAtLte
+	return q
+}
+
+func (q *subscriptionQueryImplementation) HasCancellationReason() bool {
+	return q.hasProperty("cancellation_reason")
+}
+
+func (q *subscriptionQueryImplementation) CancellationReason() string {
+	return q.properties["cancellation_reason"].(string)
+}
+
+func (q *subscriptionQueryImplementation) SetCancellationReason(cancellationReason string) SubscriptionQueryInterface {
+	q.properties["cancellation_reason"] = cancellationReason
+	return q
+}
+
+func (q *subscriptionQueryImplementation) HasCancellationRequestedAtGte() bool {
+	return q.hasProperty("cancellation_requested_at_gte")
+}
+
+func (q *subscriptionQueryImplementation) CancellationRequestedAtGte() string {
+	return q.properties["cancellation_requested_at_gte"].(string)
+}
+
+func (q *subscriptionQueryImplementation) SetCancellationRequestedAtGte(cancellationRequestedAtGte string) SubscriptionQueryInterface {
+	q.properties["cancellation_requested_at_gte"] = cancellationRequestedAtGte
+	return q
+}
+
+func (q *subscriptionQueryImplementation) HasCancellationRequestedAtLte() bool {
+	return q.hasProperty("cancellation_requested_at_lte")
+}
+
+func (q *subscriptionQueryImplementation) CancellationRequestedAtLte() string {
+	return q.properties["cancellation_requested_at_lte"].(string)
+}
+
+func (q *subscriptionQueryImplementation) SetCancellationRequestedAtLte(cancellationRequestedAtLte string) SubscriptionQueryInterface {
+	q.properties["cancellation_requested_at_lte"] = cancellationRequestedAtLte
+	return q
+}
+
+func (q *subscriptionQueryImplementation) HasMetaEquals() bool {
+	return q.hasProperty("meta_equals")
+}
+
+func (q *subscriptionQueryImplementation) MetaEquals() map[string]string {
+	return q.properties["meta_equals"].(map[string]string)
+}
+
+// SetMetaEquals filters on the meta key having the value. Repeated calls
+// filter on all of the keys.
+func (q *subscriptionQueryImplementation) SetMetaEquals(key string, value string) SubscriptionQueryInterface {
+	if !q.HasMetaEquals() {
+		q.properties["meta_equals"] = map[string]string{}
+	}
+	q.MetaEquals()[key] = value
+	return q
+}
+
+func (q *subscriptionQueryImplementation) HasMetaHasKey() bool {
+	return q.hasProperty("meta_has_key")
+}
+
+func (q *subscriptionQueryImplementation) MetaHasKey() []string {
+	return q.properties["meta_has_key"].([]string)
+}
+
+// SetMetaHasKey filters on the meta key being set. Repeated calls filter on
+// all of the keys.
+func (q *subscriptionQueryImplementation) SetMetaHasKey(key string) SubscriptionQueryInterface {
+	if !q.HasMetaHasKey() {
+		q.properties["meta_has_key"] = []string{}
+	}
+	q.properties["meta_has_key"] = append(q.MetaHasKey(), key)
+	return q
+}
+
 func (q *subscriptionQueryImplementation) HasOffset() bool {
 	return q.hasProperty("offset")
 }
@@ -13,6 +13,10 @@ func TestSubscriptionQuerySettersAndGetters(t *testing.T) {
 		SetStatusIn([]string{SUBSCRIPTION_STATUS_ACTIVE, SUBSCRIPTION_STATUS_CANCELLED}).
 		SetSubscriberID("subscriber_1").
 		SetPlanID("plan_1").
+		SetResumeAtLte("2025-01-01 00:00:00").
+		SetCancellationReason(CANCELLATION_REASON_TOO_EXPENSIVE).
+		SetCancellationRequestedAtGte("2025-01-01 00:00:00").
+		SetCancellationRequestedAtLte("2025-02-01 00:00:00").
 		SetOffset(5).
 		SetLimit(10).
 		SetOrderBy("created_at").
@@ -38,6 +42,18 @@ func TestSubscriptionQuerySettersAndGetters(t *testing.T) {
 	if !query.HasPlanID() || query.PlanID() != "plan_1" {
 		t.Fatalf("expected HasPlanID true with value plan_1")
 	}
+	if !query.HasResumeAtLte() || query.ResumeAtLte() != "2025-01-01 00:00:00" {
+		t.Fatalf("expected HasResumeAtLte true with value 2025-01-01 00:00:00")
+	}
+	if !query.HasCancellationReason() || query.CancellationReason() != CANCELLATION_REASON_TOO_EXPENSIVE {
+		t.Fatalf("expected HasCancellationReason true with value %s", CANCELLATION_REASON_TOO_EXPENSIVE)
+	}
+	if !query.HasCancellationRequestedAtGte() || query.CancellationRequestedAtGte() != "2025-01-01 00:00:00" {
+		t.Fatalf("expected HasCancellationRequestedAtGte true with value 2025-01-01 00:00:00")
+	}
+	if !query.HasCancellationRequestedAtLte() || query.CancellationRequestedAtLte() != "2025-02-01 00:00:00" {
+		t.Fatalf("expected HasCancellationRequestedAtLte true with value 2025-02-01 00:00:00")
+	}
 	if !query.HasOffset() || query.Offset() != 5 {
 		t.Fatalf("expected HasOffset true with value 5")
 	}
@@ -106,6 +122,48 @@ func TestSubscriptionQueryValidateErrors(t *testing.T) {
 			},
 			contains: "plan_id cannot be empty",
 		},
+		{
+			name: "resume_at_lte empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetResumeAtLte("")
+			},
+			contains: "resume_at_lte cannot be empty",
+		},
+		{
+			name: "cancellation_reason empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetCancellationReason("")
+			},
+			contains: "cancellation_reason cannot be empty",
+		},
+		{
+			name: "cancellation_requested_at_gte empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetCancellationRequestedAtGte("")
+			},
+			contains: "cancellation_requested_at_gte cannot be empty",
+		},
+		{
+			name: "cancellation_requested_at_lte empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetCancellationRequestedAtLte("")
+			},
+			contains: "cancellation_requested_at_lte cannot be empty",
+		},
+		{
+			name: "meta equals key empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetMetaEquals("", "acme")
+			},
+			contains: "meta_equals key cannot be empty",
+		},
+		{
+			name: "meta has key empty",
+			setup: func(q SubscriptionQueryInterface) {
+				q.SetMetaHasKey("")
+			},
+			contains: "meta_has_key key cannot be empty",
+		},
 		{
 			name: "limit negative",
 			setup: func(q SubscriptionQueryInterface) {
@@ -146,3 +204,21 @@ func TestSubscriptionQueryValidateSuccess(t *testing.T) {
 		t.Fatalf("unexpected validation error: %v", err)
 	}
 }
+
+func TestSubscriptionQueryMetaFilters(t *testing.T) {
+	query := NewSubscriptionQuery().
+		SetMetaEquals("tenant_id", "acme").
+		SetMetaEquals("region", "eu").
+		SetMetaHasKey("crm_id").
+		SetMetaHasKey("referrer")
+
+	if !query.HasMetaEquals() || len(query.MetaEquals()) != 2 || query.MetaEquals()["tenant_id"] != "acme" || query.MetaEquals()["region"] != "eu" {
+		t.Fatalf("expected both meta equals filters, got %v", query.MetaEquals())
+	}
+	if !query.HasMetaHasKey() || len(query.MetaHasKey()) != 2 || query.MetaHasKey()[0] != "crm_id" || query.MetaHasKey()[1] != "referrer" {
+		t.Fatalf("expected both meta has key filters, got %v", query.MetaHasKey())
+	}
+	if err := query.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
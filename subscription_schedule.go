@@ -0,0 +1,202 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+
+	"github.com/dracory/neat/database/orm"
+	neatuid "github.com/dracory/neat/support/uid"
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionScheduleInterface defines the methods for a SubscriptionSchedule entity.
+// A schedule changes the plan and the quantity of a subscription at the start
+// of each of its phases, creating the subscription at the start of the first
+// phase if it does not exist yet.
+type SubscriptionScheduleInterface interface {
+	GetCreatedAt() string
+	GetCreatedAtCarbon() *carbon.Carbon
+	SetCreatedAt(createdAt string) SubscriptionScheduleInterface
+
+	GetCurrentPhase() int
+	SetCurrentPhase(currentPhase int) SubscriptionScheduleInterface
+
+	GetID() string
+	SetID(id string) SubscriptionScheduleInterface
+
+	GetNextPhaseAt() string
+	GetNextPhaseAtCarbon() *carbon.Carbon
+	SetNextPhaseAt(nextPhaseAt string) SubscriptionScheduleInterface
+
+	GetPhases() ([]SubscriptionSchedulePhase, error)
+	SetPhases(phases []SubscriptionSchedulePhase) (SubscriptionScheduleInterface, error)
+
+	GetStatus() string
+	SetStatus(status string) SubscriptionScheduleInterface
+
+	GetSubscriberID() string
+	SetSubscriberID(subscriberID string) SubscriptionScheduleInterface
+
+	GetSubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionScheduleInterface
+
+	GetUpdatedAt() string
+	GetUpdatedAtCarbon() *carbon.Carbon
+	SetUpdatedAt(updatedAt string) SubscriptionScheduleInterface
+}
+
+var _ SubscriptionScheduleInterface = (*subscriptionScheduleImplementation)(nil)
+
+// == TYPE =====================================================================
+
+type subscriptionScheduleImplementation struct {
+	orm.ShortID
+
+	SubscriberIDField   string `db:"subscriber_id"`
+	SubscriptionIDField string `db:"subscription_id"`
+	StatusField         string `db:"status"`
+	PhasesField         string `db:"phases"`
+	CurrentPhaseField   int    `db:"current_phase"`
+	NextPhaseAtField    string `db:"next_phase_at"`
+
+	CreatedAtField orm.CreatedAt
+	UpdatedAtField orm.UpdatedAt
+}
+
+// == CONSTRUCTORS =============================================================
+
+func NewSubscriptionSchedule() SubscriptionScheduleInterface {
+	o := &subscriptionScheduleImplementation{}
+	o.SetID(neatuid.GenerateShortID())
+	o.SetSubscriberID("")
+	o.SetSubscriptionID("")
+	o.SetStatus(SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE)
+	o.PhasesField = ""
+	o.SetCurrentPhase(-1)
+	o.SetNextPhaseAt(MAX_DATETIME)
+	o.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	o.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	return o
+}
+
+// == SETTERS AND GETTERS ======================================================
+
+func (o *subscriptionScheduleImplementation) GetID() string {
+	return o.ShortID.ID
+}
+
+func (o *subscriptionScheduleImplementation) SetID(id string) SubscriptionScheduleInterface {
+	o.ShortID.ID = id
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetSubscriberID() string {
+	return o.SubscriberIDField
+}
+
+func (o *subscriptionScheduleImplementation) SetSubscriberID(subscriberID string) SubscriptionScheduleInterface {
+	o.SubscriberIDField = subscriberID
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetSubscriptionID() string {
+	return o.SubscriptionIDField
+}
+
+func (o *subscriptionScheduleImplementation) SetSubscriptionID(subscriptionID string) SubscriptionScheduleInterface {
+	o.SubscriptionIDField = subscriptionID
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetStatus() string {
+	return o.StatusField
+}
+
+func (o *subscriptionScheduleImplementation) SetStatus(status string) SubscriptionScheduleInterface {
+	o.StatusField = status
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetPhases() ([]SubscriptionSchedulePhase, error) {
+	if o.PhasesField == "" {
+		return nil, nil
+	}
+	var phases []SubscriptionSchedulePhase
+	err := json.Unmarshal([]byte(o.PhasesField), &phases)
+	if err != nil {
+		return nil, err
+	}
+	return phases, nil
+}
+
+func (o *subscriptionScheduleImplementation) SetPhases(phases []SubscriptionSchedulePhase) (SubscriptionScheduleInterface, error) {
+	if len(phases) == 0 {
+		o.PhasesField = ""
+		return o, nil
+	}
+	phasesJSON, err := json.Marshal(phases)
+	if err != nil {
+		return nil, err
+	}
+	o.PhasesField = string(phasesJSON)
+	return o, nil
+}
+
+func (o *subscriptionScheduleImplementation) GetCurrentPhase() int {
+	return o.CurrentPhaseField
+}
+
+func (o *subscriptionScheduleImplementation) SetCurrentPhase(currentPhase int) SubscriptionScheduleInterface {
+	o.CurrentPhaseField = currentPhase
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetNextPhaseAt() string {
+	return o.NextPhaseAtField
+}
+
+func (o *subscriptionScheduleImplementation) GetNextPhaseAtCarbon() *carbon.Carbon {
+	return carbon.Parse(o.GetNextPhaseAt(), carbon.UTC)
+}
+
+func (o *subscriptionScheduleImplementation) SetNextPhaseAt(nextPhaseAt string) SubscriptionScheduleInterface {
+	o.NextPhaseAtField = nextPhaseAt
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetCreatedAt() string {
+	if o.CreatedAtField.CreatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionScheduleImplementation) GetCreatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.CreatedAtField.CreatedAt)
+}
+
+func (o *subscriptionScheduleImplementation) SetCreatedAt(createdAt string) SubscriptionScheduleInterface {
+	if createdAt == "" {
+		return o
+	}
+	o.CreatedAtField.CreatedAt = carbon.Parse(createdAt, carbon.UTC).StdTime()
+	return o
+}
+
+func (o *subscriptionScheduleImplementation) GetUpdatedAt() string {
+	if o.UpdatedAtField.UpdatedAt.IsZero() {
+		return ""
+	}
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt).ToDateTimeString()
+}
+
+func (o *subscriptionScheduleImplementation) GetUpdatedAtCarbon() *carbon.Carbon {
+	return carbon.CreateFromStdTime(o.UpdatedAtField.UpdatedAt)
+}
+
+func (o *subscriptionScheduleImplementation) SetUpdatedAt(updatedAt string) SubscriptionScheduleInterface {
+	if updatedAt == "" {
+		return o
+	}
+	o.UpdatedAtField.UpdatedAt = carbon.Parse(updatedAt, carbon.UTC).StdTime()
+	return o
+}
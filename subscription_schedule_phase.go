@@ -0,0 +1,111 @@
+package subscriptionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// SubscriptionSchedulePhase is a phase of a subscription schedule, during
+// which the subscription is on the plan, for the quantity
+type SubscriptionSchedulePhase struct {
+	PlanID string `json:"plan_id"`
+	// Quantity defaults to 1
+	Quantity int `json:"quantity"`
+	// StartsAt is the start of the phase, which is the end of the previous
+	// phase
+	StartsAt string `json:"starts_at"`
+	// EndsAt is the end of the phase. The last phase may have no end
+	// (MAX_DATETIME), otherwise the subscription is cancelled when it ends.
+	EndsAt string `json:"ends_at"`
+}
+
+// ScheduledChange is an upcoming change of a subscription by a schedule
+type ScheduledChange struct {
+	ScheduleID     string
+	SubscriberID   string
+	SubscriptionID string
+	// Phase is the index of the phase which starts, or the number of phases
+	// when the schedule ends
+	Phase int
+	// PlanID and Quantity are empty when the schedule ends, cancelling the
+	// subscription
+	PlanID   string
+	Quantity int
+	At       string
+}
+
+// schedulePhasesNormalize defaults the quantities and the end of the last
+// phase, and checks that the phases are contiguous and ordered
+func schedulePhasesNormalize(phases []SubscriptionSchedulePhase) ([]SubscriptionSchedulePhase, error) {
+	if len(phases) == 0 {
+		return nil, errors.New("phases cannot be empty")
+	}
+
+	normalized := make([]SubscriptionSchedulePhase, len(phases))
+	for i, phase := range phases {
+		label := "phase " + strconv.Itoa(i+1)
+
+		if phase.PlanID == "" {
+			return nil, errors.New(label + " plan id cannot be empty")
+		}
+		if phase.Quantity < 0 {
+			return nil, errors.New(label + " quantity cannot be negative")
+		}
+		if phase.Quantity == 0 {
+			phase.Quantity = 1
+		}
+		if phase.EndsAt == "" {
+			phase.EndsAt = MAX_DATETIME
+		}
+
+		startsAt := carbon.Parse(phase.StartsAt, carbon.UTC)
+		if startsAt.IsInvalid() {
+			return nil, errors.New(label + " starts at must be a valid date")
+		}
+		endsAt := carbon.Parse(phase.EndsAt, carbon.UTC)
+		if endsAt.IsInvalid() {
+			return nil, errors.New(label + " ends at must be a valid date")
+		}
+		if !startsAt.Lt(endsAt) {
+			return nil, errors.New(label + " must end after it starts")
+		}
+		if phase.EndsAt == MAX_DATETIME && i < len(phases)-1 {
+			return nil, errors.New(label + " must end, as it is not the last phase")
+		}
+		if i > 0 && !startsAt.Eq(carbon.Parse(normalized[i-1].EndsAt, carbon.UTC)) {
+			return nil, errors.New(label + " must start when phase " + strconv.Itoa(i) + " ends")
+		}
+
+		phase.StartsAt = startsAt.ToDateTimeString(carbon.UTC)
+		phase.EndsAt = endsAt.ToDateTimeString(carbon.UTC)
+		normalized[i] = phase
+	}
+
+	return normalized, nil
+}
+
+// scheduleNextPhaseAt returns when the schedule changes the subscription
+// next, after the current phase: the start of the next phase, or the end of
+// the last phase. It is MAX_DATETIME when the last phase has no end.
+func scheduleNextPhaseAt(phases []SubscriptionSchedulePhase, currentPhase int) string {
+	if currentPhase+1 < len(phases) {
+		return phases[currentPhase+1].StartsAt
+	}
+	return phases[len(phases)-1].EndsAt
+}
+
+// schedulePhasesJSON encodes the phases as stored in the subscription
+// schedule table, empty if there are none
+func schedulePhasesJSON(phases []SubscriptionSchedulePhase) (string, error) {
+	if len(phases) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(phases)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
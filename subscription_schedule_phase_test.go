@@ -0,0 +1,93 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchedulePhasesNormalize(t *testing.T) {
+	phases, err := schedulePhasesNormalize([]SubscriptionSchedulePhase{
+		{PlanID: "monthly", StartsAt: "2025-01-01", EndsAt: "2025-02-01 00:00:00"},
+		{PlanID: "yearly", StartsAt: "2025-02-01 00:00:00"},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if phases[0].StartsAt != "2025-01-01 00:00:00" || phases[0].Quantity != 1 {
+		t.Errorf("unexpected first phase %+v", phases[0])
+	}
+	if phases[1].EndsAt != MAX_DATETIME {
+		t.Errorf("expected the last phase without end, got %s", phases[1].EndsAt)
+	}
+
+	if next := scheduleNextPhaseAt(phases, -1); next != "2025-01-01 00:00:00" {
+		t.Errorf("expected the start of the first phase, got %s", next)
+	}
+	if next := scheduleNextPhaseAt(phases, 0); next != "2025-02-01 00:00:00" {
+		t.Errorf("expected the start of the second phase, got %s", next)
+	}
+	if next := scheduleNextPhaseAt(phases, 1); next != MAX_DATETIME {
+		t.Errorf("expected no next phase, got %s", next)
+	}
+}
+
+func TestSchedulePhasesNormalizeErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		phases   []SubscriptionSchedulePhase
+		contains string
+	}{
+		{
+			name:     "no phases",
+			contains: "phases cannot be empty",
+		},
+		{
+			name:     "no plan",
+			phases:   []SubscriptionSchedulePhase{{StartsAt: "2025-01-01 00:00:00"}},
+			contains: "phase 1 plan id cannot be empty",
+		},
+		{
+			name:     "negative quantity",
+			phases:   []SubscriptionSchedulePhase{{PlanID: "p", Quantity: -1, StartsAt: "2025-01-01 00:00:00"}},
+			contains: "phase 1 quantity cannot be negative",
+		},
+		{
+			name:     "invalid start",
+			phases:   []SubscriptionSchedulePhase{{PlanID: "p", StartsAt: "soon"}},
+			contains: "phase 1 starts at must be a valid date",
+		},
+		{
+			name:     "ends before start",
+			phases:   []SubscriptionSchedulePhase{{PlanID: "p", StartsAt: "2025-02-01 00:00:00", EndsAt: "2025-01-01 00:00:00"}},
+			contains: "phase 1 must end after it starts",
+		},
+		{
+			name: "open phase before last",
+			phases: []SubscriptionSchedulePhase{
+				{PlanID: "p", StartsAt: "2025-01-01 00:00:00"},
+				{PlanID: "p", StartsAt: "2025-02-01 00:00:00"},
+			},
+			contains: "phase 1 must end",
+		},
+		{
+			name: "gap between phases",
+			phases: []SubscriptionSchedulePhase{
+				{PlanID: "p", StartsAt: "2025-01-01 00:00:00", EndsAt: "2025-02-01 00:00:00"},
+				{PlanID: "p", StartsAt: "2025-03-01 00:00:00"},
+			},
+			contains: "phase 2 must start when phase 1 ends",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := schedulePhasesNormalize(tc.phases)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
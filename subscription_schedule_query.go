@@ -0,0 +1,209 @@
+package subscriptionstore
+
+import "errors"
+
+// SubscriptionScheduleQueryInterface defines the interface for querying subscription schedules.
+type SubscriptionScheduleQueryInterface interface {
+	Validate() error
+
+	HasID() bool
+	ID() string
+	SetID(id string) SubscriptionScheduleQueryInterface
+
+	HasSubscriberID() bool
+	SubscriberID() string
+	SetSubscriberID(subscriberID string) SubscriptionScheduleQueryInterface
+
+	HasSubscriptionID() bool
+	SubscriptionID() string
+	SetSubscriptionID(subscriptionID string) SubscriptionScheduleQueryInterface
+
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) SubscriptionScheduleQueryInterface
+
+	HasNextPhaseAtLte() bool
+	NextPhaseAtLte() string
+	SetNextPhaseAtLte(nextPhaseAtLte string) SubscriptionScheduleQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) SubscriptionScheduleQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) SubscriptionScheduleQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) SubscriptionScheduleQueryInterface
+
+	HasSortOrder() bool
+	SortOrder() string
+	SetSortOrder(sortOrder string) SubscriptionScheduleQueryInterface
+}
+
+// SubscriptionScheduleQuery is a shortcut alias for NewSubscriptionScheduleQuery
+func SubscriptionScheduleQuery() SubscriptionScheduleQueryInterface {
+	return NewSubscriptionScheduleQuery()
+}
+
+// NewSubscriptionScheduleQuery creates a new subscription schedule query
+func NewSubscriptionScheduleQuery() SubscriptionScheduleQueryInterface {
+	return &subscriptionScheduleQueryImplementation{
+		properties: make(map[string]interface{}),
+	}
+}
+
+var _ SubscriptionScheduleQueryInterface = (*subscriptionScheduleQueryImplementation)(nil)
+
+type subscriptionScheduleQueryImplementation struct {
+	properties map[string]interface{}
+}
+
+func (q *subscriptionScheduleQueryImplementation) Validate() error {
+	if q.HasID() && q.ID() == "" {
+		return errors.New("subscription schedule query. id cannot be empty")
+	}
+	if q.HasSubscriberID() && q.SubscriberID() == "" {
+		return errors.New("subscription schedule query. subscriber_id cannot be empty")
+	}
+	if q.HasSubscriptionID() && q.SubscriptionID() == "" {
+		return errors.New("subscription schedule query. subscription_id cannot be empty")
+	}
+	if q.HasStatus() && q.Status() == "" {
+		return errors.New("subscription schedule query. status cannot be empty")
+	}
+	if q.HasNextPhaseAtLte() && q.NextPhaseAtLte() == "" {
+		return errors.New("subscription schedule query. next_phase_at_lte cannot be empty")
+	}
+	if q.HasLimit() && q.Limit() < 0 {
+		return errors.New("subscription schedule query. limit cannot be negative")
+	}
+	if q.HasOffset() && q.Offset() < 0 {
+		return errors.New("subscription schedule query. offset cannot be negative")
+	}
+	return nil
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasID() bool {
+	return q.hasProperty("id")
+}
+
+func (q *subscriptionScheduleQueryImplementation) ID() string {
+	return q.properties["id"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetID(id string) SubscriptionScheduleQueryInterface {
+	q.properties["id"] = id
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasSubscriberID() bool {
+	return q.hasProperty("subscriber_id")
+}
+
+func (q *subscriptionScheduleQueryImplementation) SubscriberID() string {
+	return q.properties["subscriber_id"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetSubscriberID(subscriberID string) SubscriptionScheduleQueryInterface {
+	q.properties["subscriber_id"] = subscriberID
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasSubscriptionID() bool {
+	return q.hasProperty("subscription_id")
+}
+
+func (q *subscriptionScheduleQueryImplementation) SubscriptionID() string {
+	return q.properties["subscription_id"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetSubscriptionID(subscriptionID string) SubscriptionScheduleQueryInterface {
+	q.properties["subscription_id"] = subscriptionID
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasStatus() bool {
+	return q.hasProperty("status")
+}
+
+func (q *subscriptionScheduleQueryImplementation) Status() string {
+	return q.properties["status"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetStatus(status string) SubscriptionScheduleQueryInterface {
+	q.properties["status"] = status
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasNextPhaseAtLte() bool {
+	return q.hasProperty("next_phase_at_lte")
+}
+
+func (q *subscriptionScheduleQueryImplementation) NextPhaseAtLte() string {
+	return q.properties["next_phase_at_lte"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetNextPhaseAtLte(nextPhaseAtLte string) SubscriptionScheduleQueryInterface {
+	q.properties["next_phase_at_lte"] = nextPhaseAtLte
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasOffset() bool {
+	return q.hasProperty("offset")
+}
+
+func (q *subscriptionScheduleQueryImplementation) Offset() int {
+	return q.properties["offset"].(int)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetOffset(offset int) SubscriptionScheduleQueryInterface {
+	q.properties["offset"] = offset
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasLimit() bool {
+	return q.hasProperty("limit")
+}
+
+func (q *subscriptionScheduleQueryImplementation) Limit() int {
+	return q.properties["limit"].(int)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetLimit(limit int) SubscriptionScheduleQueryInterface {
+	q.properties["limit"] = limit
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasOrderBy() bool {
+	return q.hasProperty("order_by")
+}
+
+func (q *subscriptionScheduleQueryImplementation) OrderBy() string {
+	return q.properties["order_by"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetOrderBy(orderBy string) SubscriptionScheduleQueryInterface {
+	q.properties["order_by"] = orderBy
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) HasSortOrder() bool {
+	return q.hasProperty("sort_order")
+}
+
+func (q *subscriptionScheduleQueryImplementation) SortOrder() string {
+	return q.properties["sort_order"].(string)
+}
+
+func (q *subscriptionScheduleQueryImplementation) SetSortOrder(sortOrder string) SubscriptionScheduleQueryInterface {
+	q.properties["sort_order"] = sortOrder
+	return q
+}
+
+func (q *subscriptionScheduleQueryImplementation) hasProperty(key string) bool {
+	_, ok := q.properties[key]
+	return ok
+}
@@ -0,0 +1,54 @@
+package subscriptionstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionScheduleQueryValidateErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(SubscriptionScheduleQueryInterface)
+		contains string
+	}{
+		{
+			name:     "subscriber_id empty",
+			setup:    func(q SubscriptionScheduleQueryInterface) { q.SetSubscriberID("") },
+			contains: "subscriber_id cannot be empty",
+		},
+		{
+			name:     "subscription_id empty",
+			setup:    func(q SubscriptionScheduleQueryInterface) { q.SetSubscriptionID("") },
+			contains: "subscription_id cannot be empty",
+		},
+		{
+			name:     "status empty",
+			setup:    func(q SubscriptionScheduleQueryInterface) { q.SetStatus("") },
+			contains: "status cannot be empty",
+		},
+		{
+			name:     "next_phase_at_lte empty",
+			setup:    func(q SubscriptionScheduleQueryInterface) { q.SetNextPhaseAtLte("") },
+			contains: "next_phase_at_lte cannot be empty",
+		},
+		{
+			name:     "offset negative",
+			setup:    func(q SubscriptionScheduleQueryInterface) { q.SetOffset(-1) },
+			contains: "offset cannot be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := NewSubscriptionScheduleQuery()
+			tc.setup(query)
+			err := query.Validate()
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.contains)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("unexpected error %q, expected to contain %q", err.Error(), tc.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package subscriptionstore
+
+import "testing"
+
+func TestNewSubscriptionScheduleDefaults(t *testing.T) {
+	schedule := NewSubscriptionSchedule()
+
+	if schedule.GetID() == "" {
+		t.Fatal("ID should not be empty")
+	}
+	if schedule.GetStatus() != SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE {
+		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_SCHEDULE_STATUS_ACTIVE, schedule.GetStatus())
+	}
+	if schedule.GetCurrentPhase() != -1 {
+		t.Fatalf("expected current phase -1, got %d", schedule.GetCurrentPhase())
+	}
+	if schedule.GetNextPhaseAt() != MAX_DATETIME {
+		t.Fatalf("expected next phase at %s, got %s", MAX_DATETIME, schedule.GetNextPhaseAt())
+	}
+	phases, err := schedule.GetPhases()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(phases) != 0 {
+		t.Fatalf("expected no phases, got %d", len(phases))
+	}
+}
+
+func TestSubscriptionSchedulePhases(t *testing.T) {
+	schedule := NewSubscriptionSchedule().SetSubscriberID("user_1").SetSubscriptionID("sub_1")
+	if _, err := schedule.SetPhases([]SubscriptionSchedulePhase{
+		{PlanID: "monthly", StartsAt: "2025-01-01 00:00:00", EndsAt: "2025-02-01 00:00:00"},
+		{PlanID: "yearly", Quantity: 2, StartsAt: "2025-02-01 00:00:00"},
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if schedule.GetSubscriberID() != "user_1" || schedule.GetSubscriptionID() != "sub_1" {
+		t.Fatalf("unexpected subscriber %s / subscription %s", schedule.GetSubscriberID(), schedule.GetSubscriptionID())
+	}
+	phases, err := schedule.GetPhases()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(phases) != 2 || phases[0].PlanID != "monthly" || phases[1].Quantity != 2 {
+		t.Fatalf("unexpected phases %+v", phases)
+	}
+}
@@ -21,6 +21,9 @@ func TestNewSubscriptionDefaults(t *testing.T) {
 	if subscription.GetSubscriberID() != "" {
 		t.Fatalf("expected empty subscriber ID, got %s", subscription.GetSubscriberID())
 	}
+	if subscription.GetQuantity() != 1 {
+		t.Fatalf("expected quantity 1, got %d", subscription.GetQuantity())
+	}
 	if subscription.GetPaymentMethodID() != "" {
 		t.Fatalf("expected empty payment method ID, got %s", subscription.GetPaymentMethodID())
 	}
@@ -33,6 +36,21 @@ func TestNewSubscriptionDefaults(t *testing.T) {
 	if subscription.GetCancelAtPeriodEnd() {
 		t.Fatal("expected cancel at period end to be false")
 	}
+	if subscription.GetPausedAt() != MAX_DATETIME {
+		t.Fatalf("expected paused at %s, got %s", MAX_DATETIME, subscription.GetPausedAt())
+	}
+	if subscription.GetResumeAt() != MAX_DATETIME {
+		t.Fatalf("expected resume at %s, got %s", MAX_DATETIME, subscription.GetResumeAt())
+	}
+	if subscription.GetCancellationRequestedAt() != MAX_DATETIME || subscription.GetCancellationEffectiveAt() != MAX_DATETIME {
+		t.Fatalf("expected no cancellation, got %s / %s", subscription.GetCancellationRequestedAt(), subscription.GetCancellationEffectiveAt())
+	}
+	if subscription.GetCancellationReason() != "" || subscription.GetCancellationFeedback() != "" || subscription.GetCancelledBy() != "" {
+		t.Fatal("expected empty cancellation reason, feedback and cancelled by")
+	}
+	if subscription.IsPaused() {
+		t.Fatal("expected subscription not to be paused")
+	}
 	if subscription.GetMemo() != "" {
 		t.Fatalf("expected empty memo, got %s", subscription.GetMemo())
 	}
@@ -69,15 +87,37 @@ func TestSubscriptionSettersAndGetters(t *testing.T) {
 	subscription = subscription.SetPlanID("plan_123").
 		SetSubscriberID("subscriber_456").
 		SetPaymentMethodID("pm_789").
+		SetCurrency(CURRENCY_EUR).
 		SetStatus(SUBSCRIPTION_STATUS_ACTIVE).
 		SetMemo("Important notes").
 		SetSoftDeletedAt("2025-01-01 00:00:00").
 		SetCreatedAt("2025-01-02 00:00:00").
 		SetUpdatedAt("2025-01-03 00:00:00").
 		SetPeriodStart("2025-02-01 00:00:00").
-		SetPeriodEnd("2025-03-01 00:00:00")
+		SetPeriodEnd("2025-03-01 00:00:00").
+		SetPausedAt("2025-02-10 00:00:00").
+		SetResumeAt("2025-02-20 00:00:00")
+
+	subscription = subscription.SetCancelAtPeriodEnd(true).
+		SetCancellationRequestedAt("2025-02-15 00:00:00").
+		SetCancellationEffectiveAt("2025-03-01 00:00:00").
+		SetCancellationReason(CANCELLATION_REASON_UNUSED).
+		SetCancellationFeedback("Not using it").
+		SetCancelledBy(CANCELLED_BY_SUBSCRIBER)
 
-	subscription = subscription.SetCancelAtPeriodEnd(true)
+	if subscription.GetCancellationRequestedAt() != "2025-02-15 00:00:00" || subscription.GetCancellationEffectiveAt() != "2025-03-01 00:00:00" {
+		t.Fatalf("unexpected cancellation dates %s / %s", subscription.GetCancellationRequestedAt(), subscription.GetCancellationEffectiveAt())
+	}
+	if subscription.GetCancellationReason() != CANCELLATION_REASON_UNUSED || subscription.GetCancellationFeedback() != "Not using it" || subscription.GetCancelledBy() != CANCELLED_BY_SUBSCRIBER {
+		t.Fatalf("unexpected cancellation %s / %s / %s", subscription.GetCancellationReason(), subscription.GetCancellationFeedback(), subscription.GetCancelledBy())
+	}
+
+	if subscription.GetPausedAt() != "2025-02-10 00:00:00" {
+		t.Fatalf("expected paused at 2025-02-10 00:00:00, got %s", subscription.GetPausedAt())
+	}
+	if subscription.GetResumeAt() != "2025-02-20 00:00:00" {
+		t.Fatalf("expected resume at 2025-02-20 00:00:00, got %s", subscription.GetResumeAt())
+	}
 
 	if !subscription.GetCancelAtPeriodEnd() {
 		t.Fatal("expected cancel at period end to be true")
@@ -91,6 +131,9 @@ func TestSubscriptionSettersAndGetters(t *testing.T) {
 	if subscription.GetPaymentMethodID() != "pm_789" {
 		t.Fatalf("expected payment method id pm_789, got %s", subscription.GetPaymentMethodID())
 	}
+	if subscription.GetCurrency() != CURRENCY_EUR {
+		t.Fatalf("expected currency %s, got %s", CURRENCY_EUR, subscription.GetCurrency())
+	}
 	if subscription.GetStatus() != SUBSCRIPTION_STATUS_ACTIVE {
 		t.Fatalf("expected status %s, got %s", SUBSCRIPTION_STATUS_ACTIVE, subscription.GetStatus())
 	}
@@ -237,6 +280,7 @@ func TestNewSubscriptionFromExistingData(t *testing.T) {
 		COLUMN_PERIOD_START:         "2024-02-01 00:00:00",
 		COLUMN_PERIOD_END:           "2024-03-01 00:00:00",
 		COLUMN_CANCEL_AT_PERIOD_END: YES,
+		COLUMN_QUANTITY:             "5",
 	}
 
 	subscription := NewSubscriptionFromExistingData(data)
@@ -259,4 +303,7 @@ func TestNewSubscriptionFromExistingData(t *testing.T) {
 	if subscription.GetPeriodEnd() != "2024-03-01 00:00:00" {
 		t.Fatalf("expected period end 2024-03-01 00:00:00, got %s", subscription.GetPeriodEnd())
 	}
+	if subscription.GetQuantity() != 5 {
+		t.Fatalf("expected quantity 5, got %d", subscription.GetQuantity())
+	}
 }
@@ -0,0 +1,109 @@
+package subscriptionstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// SubscriberContext describes the subscriber a price is quoted for
+type SubscriberContext struct {
+	SubscriberID string
+	// Country is the ISO 3166-1 alpha-2 code of the subscriber's country
+	Country string
+	// Region is the state or province, where taxes differ within a country
+	Region string
+	// TaxID is the subscriber's VAT or tax number, if it is a business
+	TaxID string
+}
+
+// TaxRate is the tax charged on a price
+type TaxRate struct {
+	// Name is shown to the subscriber, i.e. "VAT"
+	Name string
+	// Percent is the rate, i.e. "20" for 20%
+	Percent string
+	// Inclusive is true if the plan price already includes the tax
+	Inclusive bool
+}
+
+// TaxRateResolver resolves the tax rate of a plan for a subscriber.
+//
+// A zero TaxRate means no tax.
+type TaxRateResolver interface {
+	TaxRate(ctx context.Context, plan PlanInterface, subscriber SubscriberContext) (TaxRate, error)
+}
+
+// TaxRateResolverFunc adapts a function to a TaxRateResolver
+type TaxRateResolverFunc func(ctx context.Context, plan PlanInterface, subscriber SubscriberContext) (TaxRate, error)
+
+func (f TaxRateResolverFunc) TaxRate(ctx context.Context, plan PlanInterface, subscriber SubscriberContext) (TaxRate, error) {
+	return f(ctx, plan, subscriber)
+}
+
+// CountryTaxRates is a TaxRateResolver with a fixed rate per country, or per
+// country and region. Regions are keyed as "US-CA"; a region without its own
+// rate falls back to the rate of its country, and a country without a rate
+// to Default.
+type CountryTaxRates struct {
+	Rates   map[string]TaxRate
+	Default TaxRate
+}
+
+var _ TaxRateResolver = CountryTaxRates{}
+
+func (r CountryTaxRates) TaxRate(ctx context.Context, plan PlanInterface, subscriber SubscriberContext) (TaxRate, error) {
+	country := strings.ToUpper(subscriber.Country)
+
+	if subscriber.Region != "" {
+		if rate, ok := r.Rates[country+"-"+strings.ToUpper(subscriber.Region)]; ok {
+			return rate, nil
+		}
+	}
+	if rate, ok := r.Rates[country]; ok {
+		return rate, nil
+	}
+
+	return r.Default, nil
+}
+
+// PriceQuote is the price of a plan for a subscriber, split into the net
+// amount, the tax and the gross amount, in the currency of the plan
+type PriceQuote struct {
+	PlanID   string
+	Currency string
+	Net      string
+	Tax      string
+	Gross    string
+	TaxRate  TaxRate
+}
+
+// CalculateTax splits the amount into net, tax and gross with the tax rate.
+// For inclusive rates the amount is the gross amount, otherwise it is the
+// net amount. The tax is rounded to the cent. An empty percent is no tax;
+// percents which are not numbers, or are negative, are rejected.
+func CalculateTax(amount string, rate TaxRate) (net string, tax string, gross string, err error) {
+	cents, err := amountCents(amount)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	percent, err := cast.ToFloat64E(rate.Percent)
+	if err != nil || math.IsNaN(percent) || math.IsInf(percent, 0) {
+		return "", "", "", errors.New("tax rate " + rate.Percent + " is not a number")
+	}
+	if percent < 0 {
+		return "", "", "", errors.New("tax rate cannot be negative")
+	}
+
+	if rate.Inclusive {
+		netCents := int64(math.Round(float64(cents) * 100 / (100 + percent)))
+		return centsAmount(netCents), centsAmount(cents - netCents), centsAmount(cents), nil
+	}
+
+	taxCents := int64(math.Round(float64(cents) * percent / 100))
+	return centsAmount(cents), centsAmount(taxCents), centsAmount(cents + taxCents), nil
+}
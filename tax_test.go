@@ -0,0 +1,102 @@
+package subscriptionstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCalculateTax(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount string
+		rate   TaxRate
+		net    string
+		tax    string
+		gross  string
+	}{
+		{
+			name:   "no tax",
+			amount: "19.99",
+			rate:   TaxRate{},
+			net:    "19.99",
+			tax:    "0.00",
+			gross:  "19.99",
+		},
+		{
+			name:   "exclusive",
+			amount: "19.99",
+			rate:   TaxRate{Name: "VAT", Percent: "20"},
+			net:    "19.99",
+			tax:    "4.00",
+			gross:  "23.99",
+		},
+		{
+			name:   "inclusive",
+			amount: "24.00",
+			rate:   TaxRate{Name: "VAT", Percent: "20", Inclusive: true},
+			net:    "20.00",
+			tax:    "4.00",
+			gross:  "24.00",
+		},
+		{
+			name:   "fractional rate",
+			amount: "100.00",
+			rate:   TaxRate{Name: "Sales tax", Percent: "7.25"},
+			net:    "100.00",
+			tax:    "7.25",
+			gross:  "107.25",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			net, tax, gross, err := CalculateTax(tc.amount, tc.rate)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if net != tc.net || tax != tc.tax || gross != tc.gross {
+				t.Errorf("expected %s + %s = %s, got %s + %s = %s", tc.net, tc.tax, tc.gross, net, tax, gross)
+			}
+		})
+	}
+
+	if _, _, _, err := CalculateTax("10.00", TaxRate{Percent: "-5"}); err == nil {
+		t.Error("expected error for a negative rate")
+	}
+
+	for _, percent := range []string{"20%", "twenty", "NaN", "Inf"} {
+		if _, _, _, err := CalculateTax("10.00", TaxRate{Percent: percent}); err == nil {
+			t.Errorf("expected error for the rate %q", percent)
+		}
+	}
+}
+
+func TestCountryTaxRates(t *testing.T) {
+	resolver := CountryTaxRates{
+		Rates: map[string]TaxRate{
+			"GB":    {Name: "VAT", Percent: "20", Inclusive: true},
+			"US-CA": {Name: "Sales tax", Percent: "7.25"},
+		},
+		Default: TaxRate{Name: "None"},
+	}
+
+	testCases := []struct {
+		subscriber SubscriberContext
+		name       string
+	}{
+		{subscriber: SubscriberContext{Country: "gb"}, name: "VAT"},
+		{subscriber: SubscriberContext{Country: "US", Region: "ca"}, name: "Sales tax"},
+		{subscriber: SubscriberContext{Country: "US", Region: "OR"}, name: "None"},
+		{subscriber: SubscriberContext{}, name: "None"},
+	}
+
+	for _, tc := range testCases {
+		rate, err := resolver.TaxRate(context.Background(), NewPlan(), tc.subscriber)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if rate.Name != tc.name {
+			t.Errorf("expected %s for %+v, got %s", tc.name, tc.subscriber, rate.Name)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+-- plan list
+SELECT * FROM `plans` WHERE `status` = ? AND `interval` IN (?, ?) AND `soft_deleted_at` > ? ORDER BY `created_at` asc LIMIT 10 OFFSET 20;
+
+-- plan count
+SELECT COUNT(*) FROM `plans` WHERE `id` = ? AND `soft_deleted_at` > ?;
+
+-- plan list with soft deleted
+SELECT * FROM `plans`;
+
+-- plan count by type
+SELECT `type` AS value, COUNT(*) AS total FROM `plans` WHERE `status` = ? AND `soft_deleted_at` > ? GROUP BY `type`;
+
+-- plan count by interval
+SELECT `interval` AS value, COUNT(*) AS total FROM `plans` WHERE `soft_deleted_at` > ? GROUP BY `interval`;
+
+-- subscription list
+SELECT * FROM `subscriptions` WHERE `status` IN (?, ?) AND `subscriber_id` = ? AND `plan_id` = ? AND `soft_deleted_at` > ?;
+
+-- subscription count
+SELECT COUNT(*) FROM `subscriptions` WHERE `status` = ? AND `soft_deleted_at` > ?;
+
+-- subscription cancellations by reason
+SELECT cancellation_reason, COUNT(*) AS total FROM `subscriptions` WHERE `cancellation_requested_at` >= ? AND `soft_deleted_at` > ? AND `cancellation_requested_at` < ? GROUP BY `cancellation_reason`;
+
+-- subscription list by meta
+SELECT * FROM `subscriptions` WHERE (CASE WHEN `metas` = '' THEN NULL ELSE JSON_UNQUOTE(JSON_EXTRACT(metas, ?)) END) = ? AND (CASE WHEN `metas` = '' THEN NULL ELSE JSON_UNQUOTE(JSON_EXTRACT(metas, ?)) END) IS NOT NULL AND `soft_deleted_at` > ?;
+
+-- plan list by promoted meta
+SELECT * FROM `plans` WHERE `meta_tenant_id` = ? AND `meta_tenant_id` <> ? AND `soft_deleted_at` > ?;
+
+-- subscription count by status
+SELECT `status` AS value, COUNT(*) AS total FROM `subscriptions` WHERE `plan_id` = ? AND `soft_deleted_at` > ? GROUP BY `status`;
+
@@ -0,0 +1,145 @@
+-- migration table
+create table `subscriptions_migrations` (`id` varchar(100) not null, `applied_at` datetime not null, primary key (`id`));
+
+-- 0001_create_plan_table
+create table `plans` (`id` varchar(40) not null, `type` varchar(50) not null, `status` varchar(40) not null, `title` varchar(100) not null, `description` text not null, `interval` varchar(40) not null, `currency` varchar(40) not null, `price` varchar(40) not null, `stripe_price_id` varchar(100) not null, `features` text not null, `memo` text not null, `metas` text not null, `created_at` datetime not null, `updated_at` datetime not null, `soft_deleted_at` datetime not null, primary key (`id`));
+
+-- 0002_create_subscription_table
+create table `subscriptions` (`id` varchar(40) not null, `status` varchar(40) not null, `subscriber_id` varchar(50) not null, `plan_id` varchar(50) not null, `period_start` datetime not null, `period_end` datetime not null, `cancel_at_period_end` varchar(3) not null, `payment_method_id` varchar(40) not null, `memo` text not null, `metas` text not null, `created_at` datetime not null, `updated_at` datetime not null, `soft_deleted_at` datetime not null, primary key (`id`));
+
+-- 0003_add_plan_indexes
+alter table `plans` add index `plans_status_index`(`status`);
+alter table `plans` add index `plans_soft_deleted_at_index`(`soft_deleted_at`);
+
+-- 0004_add_subscription_indexes
+alter table `subscriptions` add index `subscriptions_subscriber_id_index`(`subscriber_id`);
+alter table `subscriptions` add index `subscriptions_plan_id_index`(`plan_id`);
+alter table `subscriptions` add index `subscriptions_status_index`(`status`);
+alter table `subscriptions` add index `subscriptions_period_end_index`(`period_end`);
+alter table `subscriptions` add index `subscriptions_soft_deleted_at_index`(`soft_deleted_at`);
+alter table `subscriptions` add index `subscriptions_subscriber_id_status_soft_deleted_at_index`(`subscriber_id`, `status`, `soft_deleted_at`);
+
+-- 0005_add_subscription_pause_columns
+alter table `subscriptions` add `paused_at` datetime not null default '9999-12-31 23:59:59';
+alter table `subscriptions` add `resume_at` datetime not null default '9999-12-31 23:59:59';
+
+-- 0006_create_dunning_attempt_table
+create table `subscriptions_dunning_attempts` (`id` varchar(40) not null, `subscription_id` varchar(40) not null, `period_start` datetime not null, `period_end` datetime not null, `attempt` int not null, `status` varchar(40) not null, `scheduled_at` datetime not null, `attempted_at` datetime not null, `failure_reason` text not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0006_create_dunning_attempt_table indexes
+alter table `subscriptions_dunning_attempts` add index `subscriptions_dunning_attempts_subscription_id_index`(`subscription_id`);
+alter table `subscriptions_dunning_attempts` add index `subscriptions_dunning_attempts_status_scheduled_at_index`(`status`, `scheduled_at`);
+
+-- 0007_create_invoice_table
+create table `subscriptions_invoices` (`id` varchar(40) not null, `subscription_id` varchar(40) not null, `subscriber_id` varchar(50) not null, `plan_id` varchar(50) not null, `plan_snapshot` text not null, `period_start` datetime not null, `period_end` datetime not null, `amount` varchar(40) not null, `currency` varchar(40) not null, `status` varchar(40) not null, `memo` text not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0007_create_invoice_table indexes
+alter table `subscriptions_invoices` add index `subscriptions_invoices_subscription_id_index`(`subscription_id`);
+alter table `subscriptions_invoices` add index `subscriptions_invoices_subscriber_id_index`(`subscriber_id`);
+alter table `subscriptions_invoices` add index `subscriptions_invoices_status_index`(`status`);
+
+-- 0008_create_provider_reference_table
+create table `subscriptions_provider_references` (`id` varchar(40) not null, `provider` varchar(40) not null, `object_type` varchar(40) not null, `local_id` varchar(50) not null, `external_id` varchar(255) not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0008_create_provider_reference_table unique indexes
+alter table `subscriptions_provider_references` add unique `subscriptions_provider_references_provider_object_type_c6f21b8a`(`provider`, `object_type`, `local_id`);
+alter table `subscriptions_provider_references` add unique `subscriptions_provider_references_provider_object_type_905ef77d`(`provider`, `object_type`, `external_id`);
+
+-- 0009_create_coupon_table
+create table `subscriptions_coupons` (`id` varchar(40) not null, `name` varchar(100) not null, `type` varchar(40) not null, `percent_off` varchar(40) not null, `amount_off` varchar(40) not null, `currency` varchar(40) not null, `duration` varchar(40) not null, `duration_periods` int not null, `max_redemptions` int not null, `times_redeemed` int not null, `redeem_by` datetime not null, `memo` text not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0010_create_promotion_code_table
+create table `subscriptions_promotion_codes` (`id` varchar(40) not null, `coupon_id` varchar(40) not null, `code` varchar(100) not null, `status` varchar(40) not null, `max_redemptions` int not null, `times_redeemed` int not null, `expires_at` datetime not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0010_create_promotion_code_table unique indexes
+alter table `subscriptions_promotion_codes` add unique `subscriptions_promotion_codes_code_unique`(`code`);
+
+-- 0010_create_promotion_code_table indexes
+alter table `subscriptions_promotion_codes` add index `subscriptions_promotion_codes_coupon_id_index`(`coupon_id`);
+
+-- 0011_create_subscription_discount_table
+create table `subscriptions_discounts` (`id` varchar(40) not null, `subscription_id` varchar(40) not null, `coupon_id` varchar(40) not null, `promotion_code_id` varchar(40) not null, `starts_at` datetime not null, `ends_at` datetime not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0011_create_subscription_discount_table indexes
+alter table `subscriptions_discounts` add index `subscriptions_discounts_subscription_id_index`(`subscription_id`);
+alter table `subscriptions_discounts` add index `subscriptions_discounts_coupon_id_index`(`coupon_id`);
+
+-- 0012_add_subscription_quantity_column
+alter table `subscriptions` add `quantity` int not null default '1';
+
+-- 0013_add_plan_pricing_columns
+alter table `plans` add `pricing_model` varchar(40) not null default 'per_unit';
+alter table `plans` add `price_tiers` text null;
+
+-- 0014_create_subscription_item_table
+create table `subscriptions_items` (`id` varchar(40) not null, `subscription_id` varchar(40) not null, `plan_id` varchar(50) not null, `quantity` int not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0014_create_subscription_item_table indexes
+alter table `subscriptions_items` add index `subscriptions_items_subscription_id_index`(`subscription_id`);
+alter table `subscriptions_items` add index `subscriptions_items_plan_id_index`(`plan_id`);
+
+-- 0015_create_plan_version_table
+create table `plans_versions` (`id` varchar(40) not null, `plan_id` varchar(50) not null, `version` int not null, `interval` varchar(40) not null, `currency` varchar(40) not null, `price` varchar(40) not null, `pricing_model` varchar(40) not null, `price_tiers` text not null, `features` text not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0015_create_plan_version_table indexes
+alter table `plans_versions` add index `plans_versions_plan_id_version_index`(`plan_id`, `version`);
+
+-- 0016_add_subscription_plan_version_column
+alter table `subscriptions` add `plan_version_id` varchar(40) not null default '';
+
+-- 0017_create_plan_version_migration_table
+create table `plans_version_migrations` (`id` varchar(40) not null, `plan_id` varchar(50) not null, `from_version_id` varchar(40) not null, `to_version_id` varchar(40) not null, `subscribed_before` datetime not null, `scheduled_at` datetime not null, `status` varchar(40) not null, `migrated_count` int not null, `completed_at` datetime not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0017_create_plan_version_migration_table indexes
+alter table `plans_version_migrations` add index `plans_version_migrations_plan_id_index`(`plan_id`);
+alter table `plans_version_migrations` add index `plans_version_migrations_status_scheduled_at_index`(`status`, `scheduled_at`);
+
+-- 0018_create_subscription_schedule_table
+create table `subscriptions_schedules` (`id` varchar(40) not null, `subscriber_id` varchar(40) not null, `subscription_id` varchar(40) not null, `status` varchar(40) not null, `phases` text not null, `current_phase` int not null, `next_phase_at` datetime not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0018_create_subscription_schedule_table indexes
+alter table `subscriptions_schedules` add index `subscriptions_schedules_subscriber_id_index`(`subscriber_id`);
+alter table `subscriptions_schedules` add index `subscriptions_schedules_subscription_id_index`(`subscription_id`);
+alter table `subscriptions_schedules` add index `subscriptions_schedules_status_next_phase_at_index`(`status`, `next_phase_at`);
+
+-- 0019_add_subscription_cancellation_columns
+alter table `subscriptions` add `cancellation_requested_at` datetime not null default '9999-12-31 23:59:59';
+alter table `subscriptions` add `cancellation_effective_at` datetime not null default '9999-12-31 23:59:59';
+alter table `subscriptions` add `cancellation_reason` varchar(40) not null default '';
+alter table `subscriptions` add `cancellation_feedback` text null;
+alter table `subscriptions` add `cancelled_by` varchar(40) not null default '';
+
+-- 0019_add_subscription_cancellation_columns indexes
+alter table `subscriptions` add index `subscriptions_cancellation_requested_at_index`(`cancellation_requested_at`);
+
+-- 0020_create_subscriber_table
+create table `subscriptions_subscribers` (`id` varchar(50) not null, `email` varchar(191) not null, `name` varchar(191) not null, `country` varchar(40) not null, `region` varchar(40) not null, `tax_id` varchar(50) not null, `currency` varchar(40) not null, `payment_method_id` varchar(40) not null, `memo` text not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0020_create_subscriber_table indexes
+alter table `subscriptions_subscribers` add index `subscriptions_subscribers_email_index`(`email`);
+
+-- 0021_add_subscription_currency_column
+alter table `subscriptions` add `currency` varchar(40) not null default '';
+
+-- 0022_create_payment_method_table
+create table `subscriptions_payment_methods` (`id` varchar(40) not null, `subscriber_id` varchar(50) not null, `provider` varchar(40) not null, `type` varchar(40) not null, `brand` varchar(40) not null, `last4` varchar(4) not null, `exp_month` int not null, `exp_year` int not null, `expires_at` datetime not null, `is_default` varchar(3) not null, `created_at` datetime not null, `updated_at` datetime not null, primary key (`id`));
+
+-- 0022_create_payment_method_table indexes
+alter table `subscriptions_payment_methods` add index `subscriptions_payment_methods_subscriber_id_index`(`subscriber_id`);
+alter table `subscriptions_payment_methods` add index `subscriptions_payment_methods_expires_at_index`(`expires_at`);
+
+-- 0023_add_subscription_item_unique_index
+alter table `subscriptions_items` add unique `subscriptions_items_subscription_id_plan_id_unique`(`subscription_id`, `plan_id`);
+
+-- 0024_add_plan_version_unique_index
+alter table `plans_versions` add unique `plans_versions_plan_id_version_unique`(`plan_id`, `version`);
+
+-- 0024_add_plan_version_unique_index dropped indexes
+alter table `plans_versions` drop index `plans_versions_plan_id_version_index`;
+
+-- 0025_add_invoice_void_id_column
+alter table `subscriptions_invoices` add `void_id` varchar(40) not null default '';
+
+-- 0026_add_invoice_period_unique_index
+alter table `subscriptions_invoices` add unique `subscriptions_invoices_subscription_id_period_start_vo_9ec59edf`(`subscription_id`, `period_start`, `void_id`);
+
@@ -0,0 +1,33 @@
+-- plan list
+SELECT * FROM "plans" WHERE "status" = $1 AND "interval" IN ($2, $3) AND "soft_deleted_at" > $4 ORDER BY "created_at" asc LIMIT 10 OFFSET 20;
+
+-- plan count
+SELECT COUNT(*) FROM "plans" WHERE "id" = $1 AND "soft_deleted_at" > $2;
+
+-- plan list with soft deleted
+SELECT * FROM "plans";
+
+-- plan count by type
+SELECT "type" AS value, COUNT(*) AS total FROM "plans" WHERE "status" = $1 AND "soft_deleted_at" > $2 GROUP BY "type";
+
+-- plan count by interval
+SELECT "interval" AS value, COUNT(*) AS total FROM "plans" WHERE "soft_deleted_at" > $1 GROUP BY "interval";
+
+-- subscription list
+SELECT * FROM "subscriptions" WHERE "status" IN ($1, $2) AND "subscriber_id" = $3 AND "plan_id" = $4 AND "soft_deleted_at" > $5;
+
+-- subscription count
+SELECT COUNT(*) FROM "subscriptions" WHERE "status" = $1 AND "soft_deleted_at" > $2;
+
+-- subscription cancellations by reason
+SELECT cancellation_reason, COUNT(*) AS total FROM "subscriptions" WHERE "cancellation_requested_at" >= $1 AND "soft_deleted_at" > $2 AND "cancellation_requested_at" < $3 GROUP BY "cancellation_reason";
+
+-- subscription list by meta
+SELECT * FROM "subscriptions" WHERE (CASE WHEN "metas" = '' THEN NULL ELSE CAST(metas AS jsonb) ->> CAST($1 AS TEXT) END) = $2 AND (CASE WHEN "metas" = '' THEN NULL ELSE CAST(metas AS jsonb) ->> CAST($3 AS TEXT) END) IS NOT NULL AND "soft_deleted_at" > $4;
+
+-- plan list by promoted meta
+SELECT * FROM "plans" WHERE "meta_tenant_id" = $1 AND "meta_tenant_id" <> $2 AND "soft_deleted_at" > $3;
+
+-- subscription count by status
+SELECT "status" AS value, COUNT(*) AS total FROM "subscriptions" WHERE "plan_id" = $1 AND "soft_deleted_at" > $2 GROUP BY "status";
+
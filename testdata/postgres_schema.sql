@@ -0,0 +1,160 @@
+-- migration table
+create table "subscriptions_migrations" ("id" varchar(100) not null, "applied_at" timestamp(0) without time zone not null);
+alter table "subscriptions_migrations" add primary key ("id");
+
+-- 0001_create_plan_table
+create table "plans" ("id" varchar(40) not null, "type" varchar(50) not null, "status" varchar(40) not null, "title" varchar(100) not null, "description" text not null, "interval" varchar(40) not null, "currency" varchar(40) not null, "price" varchar(40) not null, "stripe_price_id" varchar(100) not null, "features" text not null, "memo" text not null, "metas" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null, "soft_deleted_at" timestamp(0) without time zone not null);
+alter table "plans" add primary key ("id");
+
+-- 0002_create_subscription_table
+create table "subscriptions" ("id" varchar(40) not null, "status" varchar(40) not null, "subscriber_id" varchar(50) not null, "plan_id" varchar(50) not null, "period_start" timestamp(0) without time zone not null, "period_end" timestamp(0) without time zone not null, "cancel_at_period_end" varchar(3) not null, "payment_method_id" varchar(40) not null, "memo" text not null, "metas" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null, "soft_deleted_at" timestamp(0) without time zone not null);
+alter table "subscriptions" add primary key ("id");
+
+-- 0003_add_plan_indexes
+create index "plans_status_index" on "plans" ("status");
+create index "plans_soft_deleted_at_index" on "plans" ("soft_deleted_at");
+
+-- 0004_add_subscription_indexes
+create index "subscriptions_subscriber_id_index" on "subscriptions" ("subscriber_id");
+create index "subscriptions_plan_id_index" on "subscriptions" ("plan_id");
+create index "subscriptions_status_index" on "subscriptions" ("status");
+create index "subscriptions_period_end_index" on "subscriptions" ("period_end");
+create index "subscriptions_soft_deleted_at_index" on "subscriptions" ("soft_deleted_at");
+create index "subscriptions_subscriber_id_status_soft_deleted_at_index" on "subscriptions" ("subscriber_id", "status", "soft_deleted_at");
+
+-- 0005_add_subscription_pause_columns
+alter table "subscriptions" add column "paused_at" timestamp(0) without time zone default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "resume_at" timestamp(0) without time zone default '9999-12-31 23:59:59' not null;
+
+-- 0006_create_dunning_attempt_table
+create table "subscriptions_dunning_attempts" ("id" varchar(40) not null, "subscription_id" varchar(40) not null, "period_start" timestamp(0) without time zone not null, "period_end" timestamp(0) without time zone not null, "attempt" integer not null, "status" varchar(40) not null, "scheduled_at" timestamp(0) without time zone not null, "attempted_at" timestamp(0) without time zone not null, "failure_reason" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_dunning_attempts" add primary key ("id");
+
+-- 0006_create_dunning_attempt_table indexes
+create index "subscriptions_dunning_attempts_subscription_id_index" on "subscriptions_dunning_attempts" ("subscription_id");
+create index "subscriptions_dunning_attempts_status_scheduled_at_index" on "subscriptions_dunning_attempts" ("status", "scheduled_at");
+
+-- 0007_create_invoice_table
+create table "subscriptions_invoices" ("id" varchar(40) not null, "subscription_id" varchar(40) not null, "subscriber_id" varchar(50) not null, "plan_id" varchar(50) not null, "plan_snapshot" text not null, "period_start" timestamp(0) without time zone not null, "period_end" timestamp(0) without time zone not null, "amount" varchar(40) not null, "currency" varchar(40) not null, "status" varchar(40) not null, "memo" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_invoices" add primary key ("id");
+
+-- 0007_create_invoice_table indexes
+create index "subscriptions_invoices_subscription_id_index" on "subscriptions_invoices" ("subscription_id");
+create index "subscriptions_invoices_subscriber_id_index" on "subscriptions_invoices" ("subscriber_id");
+create index "subscriptions_invoices_status_index" on "subscriptions_invoices" ("status");
+
+-- 0008_create_provider_reference_table
+create table "subscriptions_provider_references" ("id" varchar(40) not null, "provider" varchar(40) not null, "object_type" varchar(40) not null, "local_id" varchar(50) not null, "external_id" varchar(255) not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_provider_references" add primary key ("id");
+
+-- 0008_create_provider_reference_table unique indexes
+alter table "subscriptions_provider_references" add constraint "subscriptions_provider_references_provider_object_type_c6f21b8a" unique ("provider", "object_type", "local_id");
+alter table "subscriptions_provider_references" add constraint "subscriptions_provider_references_provider_object_type_905ef77d" unique ("provider", "object_type", "external_id");
+
+-- 0009_create_coupon_table
+create table "subscriptions_coupons" ("id" varchar(40) not null, "name" varchar(100) not null, "type" varchar(40) not null, "percent_off" varchar(40) not null, "amount_off" varchar(40) not null, "currency" varchar(40) not null, "duration" varchar(40) not null, "duration_periods" integer not null, "max_redemptions" integer not null, "times_redeemed" integer not null, "redeem_by" timestamp(0) without time zone not null, "memo" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_coupons" add primary key ("id");
+
+-- 0010_create_promotion_code_table
+create table "subscriptions_promotion_codes" ("id" varchar(40) not null, "coupon_id" varchar(40) not null, "code" varchar(100) not null, "status" varchar(40) not null, "max_redemptions" integer not null, "times_redeemed" integer not null, "expires_at" timestamp(0) without time zone not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_promotion_codes" add primary key ("id");
+
+-- 0010_create_promotion_code_table unique indexes
+alter table "subscriptions_promotion_codes" add constraint "subscriptions_promotion_codes_code_unique" unique ("code");
+
+-- 0010_create_promotion_code_table indexes
+create index "subscriptions_promotion_codes_coupon_id_index" on "subscriptions_promotion_codes" ("coupon_id");
+
+-- 0011_create_subscription_discount_table
+create table "subscriptions_discounts" ("id" varchar(40) not null, "subscription_id" varchar(40) not null, "coupon_id" varchar(40) not null, "promotion_code_id" varchar(40) not null, "starts_at" timestamp(0) without time zone not null, "ends_at" timestamp(0) without time zone not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_discounts" add primary key ("id");
+
+-- 0011_create_subscription_discount_table indexes
+create index "subscriptions_discounts_subscription_id_index" on "subscriptions_discounts" ("subscription_id");
+create index "subscriptions_discounts_coupon_id_index" on "subscriptions_discounts" ("coupon_id");
+
+-- 0012_add_subscription_quantity_column
+alter table "subscriptions" add column "quantity" integer default '1' not null;
+
+-- 0013_add_plan_pricing_columns
+alter table "plans" add column "pricing_model" varchar(40) default 'per_unit' not null;
+alter table "plans" add column "price_tiers" text null;
+
+-- 0014_create_subscription_item_table
+create table "subscriptions_items" ("id" varchar(40) not null, "subscription_id" varchar(40) not null, "plan_id" varchar(50) not null, "quantity" integer not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_items" add primary key ("id");
+
+-- 0014_create_subscription_item_table indexes
+create index "subscriptions_items_subscription_id_index" on "subscriptions_items" ("subscription_id");
+create index "subscriptions_items_plan_id_index" on "subscriptions_items" ("plan_id");
+
+-- 0015_create_plan_version_table
+create table "plans_versions" ("id" varchar(40) not null, "plan_id" varchar(50) not null, "version" integer not null, "interval" varchar(40) not null, "currency" varchar(40) not null, "price" varchar(40) not null, "pricing_model" varchar(40) not null, "price_tiers" text not null, "features" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "plans_versions" add primary key ("id");
+
+-- 0015_create_plan_version_table indexes
+create index "plans_versions_plan_id_version_index" on "plans_versions" ("plan_id", "version");
+
+-- 0016_add_subscription_plan_version_column
+alter table "subscriptions" add column "plan_version_id" varchar(40) default '' not null;
+
+-- 0017_create_plan_version_migration_table
+create table "plans_version_migrations" ("id" varchar(40) not null, "plan_id" varchar(50) not null, "from_version_id" varchar(40) not null, "to_version_id" varchar(40) not null, "subscribed_before" timestamp(0) without time zone not null, "scheduled_at" timestamp(0) without time zone not null, "status" varchar(40) not null, "migrated_count" integer not null, "completed_at" timestamp(0) without time zone not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "plans_version_migrations" add primary key ("id");
+
+-- 0017_create_plan_version_migration_table indexes
+create index "plans_version_migrations_plan_id_index" on "plans_version_migrations" ("plan_id");
+create index "plans_version_migrations_status_scheduled_at_index" on "plans_version_migrations" ("status", "scheduled_at");
+
+-- 0018_create_subscription_schedule_table
+create table "subscriptions_schedules" ("id" varchar(40) not null, "subscriber_id" varchar(40) not null, "subscription_id" varchar(40) not null, "status" varchar(40) not null, "phases" text not null, "current_phase" integer not null, "next_phase_at" timestamp(0) without time zone not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_schedules" add primary key ("id");
+
+-- 0018_create_subscription_schedule_table indexes
+create index "subscriptions_schedules_subscriber_id_index" on "subscriptions_schedules" ("subscriber_id");
+create index "subscriptions_schedules_subscription_id_index" on "subscriptions_schedules" ("subscription_id");
+create index "subscriptions_schedules_status_next_phase_at_index" on "subscriptions_schedules" ("status", "next_phase_at");
+
+-- 0019_add_subscription_cancellation_columns
+alter table "subscriptions" add column "cancellation_requested_at" timestamp(0) without time zone default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "cancellation_effective_at" timestamp(0) without time zone default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "cancellation_reason" varchar(40) default '' not null;
+alter table "subscriptions" add column "cancellation_feedback" text null;
+alter table "subscriptions" add column "cancelled_by" varchar(40) default '' not null;
+
+-- 0019_add_subscription_cancellation_columns indexes
+create index "subscriptions_cancellation_requested_at_index" on "subscriptions" ("cancellation_requested_at");
+
+-- 0020_create_subscriber_table
+create table "subscriptions_subscribers" ("id" varchar(50) not null, "email" varchar(191) not null, "name" varchar(191) not null, "country" varchar(40) not null, "region" varchar(40) not null, "tax_id" varchar(50) not null, "currency" varchar(40) not null, "payment_method_id" varchar(40) not null, "memo" text not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_subscribers" add primary key ("id");
+
+-- 0020_create_subscriber_table indexes
+create index "subscriptions_subscribers_email_index" on "subscriptions_subscribers" ("email");
+
+-- 0021_add_subscription_currency_column
+alter table "subscriptions" add column "currency" varchar(40) default '' not null;
+
+-- 0022_create_payment_method_table
+create table "subscriptions_payment_methods" ("id" varchar(40) not null, "subscriber_id" varchar(50) not null, "provider" varchar(40) not null, "type" varchar(40) not null, "brand" varchar(40) not null, "last4" varchar(4) not null, "exp_month" integer not null, "exp_year" integer not null, "expires_at" timestamp(0) without time zone not null, "is_default" varchar(3) not null, "created_at" timestamp(0) without time zone not null, "updated_at" timestamp(0) without time zone not null);
+alter table "subscriptions_payment_methods" add primary key ("id");
+
+-- 0022_create_payment_method_table indexes
+create index "subscriptions_payment_methods_subscriber_id_index" on "subscriptions_payment_methods" ("subscriber_id");
+create index "subscriptions_payment_methods_expires_at_index" on "subscriptions_payment_methods" ("expires_at");
+
+-- 0023_add_subscription_item_unique_index
+alter table "subscriptions_items" add constraint "subscriptions_items_subscription_id_plan_id_unique" unique ("subscription_id", "plan_id");
+
+-- 0024_add_plan_version_unique_index
+alter table "plans_versions" add constraint "plans_versions_plan_id_version_unique" unique ("plan_id", "version");
+
+-- 0024_add_plan_version_unique_index dropped indexes
+drop index "plans_versions_plan_id_version_index";
+
+-- 0025_add_invoice_void_id_column
+alter table "subscriptions_invoices" add column "void_id" varchar(40) default '' not null;
+
+-- 0026_add_invoice_period_unique_index
+alter table "subscriptions_invoices" add constraint "subscriptions_invoices_subscription_id_period_start_vo_9ec59edf" unique ("subscription_id", "period_start", "void_id");
+
@@ -0,0 +1,145 @@
+-- migration table
+create table "subscriptions_migrations" ("id" varchar not null, "applied_at" datetime not null, primary key ("id"));
+
+-- 0001_create_plan_table
+create table "plans" ("id" varchar not null, "type" varchar not null, "status" varchar not null, "title" varchar not null, "description" text not null, "interval" varchar not null, "currency" varchar not null, "price" varchar not null, "stripe_price_id" varchar not null, "features" text not null, "memo" text not null, "metas" text not null, "created_at" datetime not null, "updated_at" datetime not null, "soft_deleted_at" datetime not null, primary key ("id"));
+
+-- 0002_create_subscription_table
+create table "subscriptions" ("id" varchar not null, "status" varchar not null, "subscriber_id" varchar not null, "plan_id" varchar not null, "period_start" datetime not null, "period_end" datetime not null, "cancel_at_period_end" varchar not null, "payment_method_id" varchar not null, "memo" text not null, "metas" text not null, "created_at" datetime not null, "updated_at" datetime not null, "soft_deleted_at" datetime not null, primary key ("id"));
+
+-- 0003_add_plan_indexes
+create index "plans_status_index" on "plans" ("status");
+create index "plans_soft_deleted_at_index" on "plans" ("soft_deleted_at");
+
+-- 0004_add_subscription_indexes
+create index "subscriptions_subscriber_id_index" on "subscriptions" ("subscriber_id");
+create index "subscriptions_plan_id_index" on "subscriptions" ("plan_id");
+create index "subscriptions_status_index" on "subscriptions" ("status");
+create index "subscriptions_period_end_index" on "subscriptions" ("period_end");
+create index "subscriptions_soft_deleted_at_index" on "subscriptions" ("soft_deleted_at");
+create index "subscriptions_subscriber_id_status_soft_deleted_at_index" on "subscriptions" ("subscriber_id", "status", "soft_deleted_at");
+
+-- 0005_add_subscription_pause_columns
+alter table "subscriptions" add column "paused_at" datetime default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "resume_at" datetime default '9999-12-31 23:59:59' not null;
+
+-- 0006_create_dunning_attempt_table
+create table "subscriptions_dunning_attempts" ("id" varchar not null, "subscription_id" varchar not null, "period_start" datetime not null, "period_end" datetime not null, "attempt" integer not null, "status" varchar not null, "scheduled_at" datetime not null, "attempted_at" datetime not null, "failure_reason" text not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0006_create_dunning_attempt_table indexes
+create index "subscriptions_dunning_attempts_subscription_id_index" on "subscriptions_dunning_attempts" ("subscription_id");
+create index "subscriptions_dunning_attempts_status_scheduled_at_index" on "subscriptions_dunning_attempts" ("status", "scheduled_at");
+
+-- 0007_create_invoice_table
+create table "subscriptions_invoices" ("id" varchar not null, "subscription_id" varchar not null, "subscriber_id" varchar not null, "plan_id" varchar not null, "plan_snapshot" text not null, "period_start" datetime not null, "period_end" datetime not null, "amount" varchar not null, "currency" varchar not null, "status" varchar not null, "memo" text not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0007_create_invoice_table indexes
+create index "subscriptions_invoices_subscription_id_index" on "subscriptions_invoices" ("subscription_id");
+create index "subscriptions_invoices_subscriber_id_index" on "subscriptions_invoices" ("subscriber_id");
+create index "subscriptions_invoices_status_index" on "subscriptions_invoices" ("status");
+
+-- 0008_create_provider_reference_table
+create table "subscriptions_provider_references" ("id" varchar not null, "provider" varchar not null, "object_type" varchar not null, "local_id" varchar not null, "external_id" varchar not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0008_create_provider_reference_table unique indexes
+create unique index "subscriptions_provider_references_provider_object_type_c6f21b8a" on "subscriptions_provider_references" ("provider", "object_type", "local_id");
+create unique index "subscriptions_provider_references_provider_object_type_905ef77d" on "subscriptions_provider_references" ("provider", "object_type", "external_id");
+
+-- 0009_create_coupon_table
+create table "subscriptions_coupons" ("id" varchar not null, "name" varchar not null, "type" varchar not null, "percent_off" varchar not null, "amount_off" varchar not null, "currency" varchar not null, "duration" varchar not null, "duration_periods" integer not null, "max_redemptions" integer not null, "times_redeemed" integer not null, "redeem_by" datetime not null, "memo" text not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0010_create_promotion_code_table
+create table "subscriptions_promotion_codes" ("id" varchar not null, "coupon_id" varchar not null, "code" varchar not null, "status" varchar not null, "max_redemptions" integer not null, "times_redeemed" integer not null, "expires_at" datetime not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0010_create_promotion_code_table unique indexes
+create unique index "subscriptions_promotion_codes_code_unique" on "subscriptions_promotion_codes" ("code");
+
+-- 0010_create_promotion_code_table indexes
+create index "subscriptions_promotion_codes_coupon_id_index" on "subscriptions_promotion_codes" ("coupon_id");
+
+-- 0011_create_subscription_discount_table
+create table "subscriptions_discounts" ("id" varchar not null, "subscription_id" varchar not null, "coupon_id" varchar not null, "promotion_code_id" varchar not null, "starts_at" datetime not null, "ends_at" datetime not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0011_create_subscription_discount_table indexes
+create index "subscriptions_discounts_subscription_id_index" on "subscriptions_discounts" ("subscription_id");
+create index "subscriptions_discounts_coupon_id_index" on "subscriptions_discounts" ("coupon_id");
+
+-- 0012_add_subscription_quantity_column
+alter table "subscriptions" add column "quantity" integer default '1' not null;
+
+-- 0013_add_plan_pricing_columns
+alter table "plans" add column "pricing_model" varchar default 'per_unit' not null;
+alter table "plans" add column "price_tiers" text null;
+
+-- 0014_create_subscription_item_table
+create table "subscriptions_items" ("id" varchar not null, "subscription_id" varchar not null, "plan_id" varchar not null, "quantity" integer not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0014_create_subscription_item_table indexes
+create index "subscriptions_items_subscription_id_index" on "subscriptions_items" ("subscription_id");
+create index "subscriptions_items_plan_id_index" on "subscriptions_items" ("plan_id");
+
+-- 0015_create_plan_version_table
+create table "plans_versions" ("id" varchar not null, "plan_id" varchar not null, "version" integer not null, "interval" varchar not null, "currency" varchar not null, "price" varchar not null, "pricing_model" varchar not null, "price_tiers" text not null, "features" text not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0015_create_plan_version_table indexes
+create index "plans_versions_plan_id_version_index" on "plans_versions" ("plan_id", "version");
+
+-- 0016_add_subscription_plan_version_column
+alter table "subscriptions" add column "plan_version_id" varchar default '' not null;
+
+-- 0017_create_plan_version_migration_table
+create table "plans_version_migrations" ("id" varchar not null, "plan_id" varchar not null, "from_version_id" varchar not null, "to_version_id" varchar not null, "subscribed_before" datetime not null, "scheduled_at" datetime not null, "status" varchar not null, "migrated_count" integer not null, "completed_at" datetime not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0017_create_plan_version_migration_table indexes
+create index "plans_version_migrations_plan_id_index" on "plans_version_migrations" ("plan_id");
+create index "plans_version_migrations_status_scheduled_at_index" on "plans_version_migrations" ("status", "scheduled_at");
+
+-- 0018_create_subscription_schedule_table
+create table "subscriptions_schedules" ("id" varchar not null, "subscriber_id" varchar not null, "subscription_id" varchar not null, "status" varchar not null, "phases" text not null, "current_phase" integer not null, "next_phase_at" datetime not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0018_create_subscription_schedule_table indexes
+create index "subscriptions_schedules_subscriber_id_index" on "subscriptions_schedules" ("subscriber_id");
+create index "subscriptions_schedules_subscription_id_index" on "subscriptions_schedules" ("subscription_id");
+create index "subscriptions_schedules_status_next_phase_at_index" on "subscriptions_schedules" ("status", "next_phase_at");
+
+-- 0019_add_subscription_cancellation_columns
+alter table "subscriptions" add column "cancellation_requested_at" datetime default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "cancellation_effective_at" datetime default '9999-12-31 23:59:59' not null;
+alter table "subscriptions" add column "cancellation_reason" varchar default '' not null;
+alter table "subscriptions" add column "cancellation_feedback" text null;
+alter table "subscriptions" add column "cancelled_by" varchar default '' not null;
+
+-- 0019_add_subscription_cancellation_columns indexes
+create index "subscriptions_cancellation_requested_at_index" on "subscriptions" ("cancellation_requested_at");
+
+-- 0020_create_subscriber_table
+create table "subscriptions_subscribers" ("id" varchar not null, "email" varchar not null, "name" varchar not null, "country" varchar not null, "region" varchar not null, "tax_id" varchar not null, "currency" varchar not null, "payment_method_id" varchar not null, "memo" text not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0020_create_subscriber_table indexes
+create index "subscriptions_subscribers_email_index" on "subscriptions_subscribers" ("email");
+
+-- 0021_add_subscription_currency_column
+alter table "subscriptions" add column "currency" varchar default '' not null;
+
+-- 0022_create_payment_method_table
+create table "subscriptions_payment_methods" ("id" varchar not null, "subscriber_id" varchar not null, "provider" varchar not null, "type" varchar not null, "brand" varchar not null, "last4" varchar not null, "exp_month" integer not null, "exp_year" integer not null, "expires_at" datetime not null, "is_default" varchar not null, "created_at" datetime not null, "updated_at" datetime not null, primary key ("id"));
+
+-- 0022_create_payment_method_table indexes
+create index "subscriptions_payment_methods_subscriber_id_index" on "subscriptions_payment_methods" ("subscriber_id");
+create index "subscriptions_payment_methods_expires_at_index" on "subscriptions_payment_methods" ("expires_at");
+
+-- 0023_add_subscription_item_unique_index
+create unique index "subscriptions_items_subscription_id_plan_id_unique" on "subscriptions_items" ("subscription_id", "plan_id");
+
+-- 0024_add_plan_version_unique_index
+create unique index "plans_versions_plan_id_version_unique" on "plans_versions" ("plan_id", "version");
+
+-- 0024_add_plan_version_unique_index dropped indexes
+drop index "plans_versions_plan_id_version_index";
+
+-- 0025_add_invoice_void_id_column
+alter table "subscriptions_invoices" add column "void_id" varchar default '' not null;
+
+-- 0026_add_invoice_period_unique_index
+create unique index "subscriptions_invoices_subscription_id_period_start_vo_9ec59edf" on "subscriptions_invoices" ("subscription_id", "period_start", "void_id");
+